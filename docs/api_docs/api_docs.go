@@ -0,0 +1,9 @@
+// Package api_docs 內嵌 API 的 OpenAPI 規格，由 pkg/openapi 提供與驗證
+package api_docs
+
+import _ "embed"
+
+// Spec api.yaml 的內容
+//
+//go:embed api.yaml
+var Spec []byte
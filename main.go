@@ -1,35 +1,206 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
 	"log"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"portal_link/docs/api_docs"
 	portal_page_restapi "portal_link/modules/portal_page/adapter/restapi"
 	user_restapi "portal_link/modules/user/adapter/restapi"
+	user_domain "portal_link/modules/user/domain"
 	user_repository "portal_link/modules/user/repository"
+	"portal_link/pkg/auth"
+	"portal_link/pkg/config"
+	"portal_link/pkg/database"
+	"portal_link/pkg/database/migrate"
+	"portal_link/pkg/health"
+	"portal_link/pkg/httpserver"
+	"portal_link/pkg/logger"
+	"portal_link/pkg/mailer"
+	"portal_link/pkg/metrics"
+	"portal_link/pkg/openapi"
+	"portal_link/pkg/password"
+	"portal_link/pkg/requestlog"
+	"portal_link/pkg/tracing"
+	"portal_link/pkg/transaction"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/spf13/pflag"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
+// tracerShutdownTimeout 結束時等待尚未輸出的 span 送出的最長時間
+const tracerShutdownTimeout = 5 * time.Second
+
 func main() {
-	r := gin.Default()
+	cfg, args, err := config.Load(os.Args[1:], os.Stderr)
+	if errors.Is(err, pflag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if len(args) > 0 {
+		switch args[0] {
+		case "migrate":
+			if err := runMigrate(context.Background(), cfg.Database, args[1:], os.Stdout); err != nil {
+				log.Fatal(err)
+			}
+		case "config":
+			// 輸出實際使用的設定（密鑰已遮蔽），用於除錯
+			if err := cfg.Print(os.Stdout); err != nil {
+				log.Fatal(err)
+			}
+		default:
+			log.Fatalf("unknown command %q, expected migrate or config", args[0])
+		}
+		return
+	}
+
+	// 以 JSON 輸出日誌，log 套件的輸出也會轉送到 slog
+	level, err := logger.ParseLevel(cfg.Log.Level)
+	if err != nil {
+		log.Fatal(err)
+	}
+	slog.SetDefault(logger.New(os.Stdout, level))
+
+	if level <= slog.LevelDebug {
+		gin.SetMode(gin.DebugMode)
+		var effective strings.Builder
+		if err := cfg.Print(&effective); err != nil {
+			exit(err)
+		}
+		slog.Debug("effective config", "config", effective.String())
+	} else {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	// OpenTelemetry tracing，以 W3C trace context 傳遞；tracing.exporter 為 none 時不輸出 span
+	tracerProvider, err := newTracerProvider(context.Background(), cfg.Tracing)
+	if err != nil {
+		exit(err)
+	}
+	tracing.Install(tracerProvider)
+	defer func() {
+		// 結束前送出尚未輸出的 span
+		ctx, cancel := context.WithTimeout(context.Background(), tracerShutdownTimeout)
+		defer cancel()
+		if err := tracerProvider.Shutdown(ctx); err != nil {
+			slog.Warn("failed to flush spans", "error", err)
+		}
+	}()
+
+	// Prometheus 指標，於 /metrics 輸出
+	m := metrics.New()
+
+	r := gin.New()
+	// 只採用 server.trusted_proxies 中反向代理送來的 X-Forwarded-For，避免用戶端偽造 IP 規避登入鎖定
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		exit(err)
+	}
+	// 指定 request ID 並輸出 access log，panic 時回應 500
+	r.Use(requestlog.Middleware(slog.Default()), requestlog.Recovery())
+	// 依 Gin 路由樣板建立 span，並記錄請求次數與延遲
+	r.Use(tracing.Middleware(), m.Middleware())
 
 	// 配置 CORS 中間件
 	r.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"http://localhost:3000"}, // Nuxt.js 預設端口
+		AllowOrigins:     cfg.CORS.AllowOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", requestlog.HeaderRequestID, "traceparent", "tracestate"},
+		ExposeHeaders:    []string{"Content-Length", requestlog.HeaderRequestID},
 		AllowCredentials: true,
 	}))
 
-	// Create in-memory user repository (shared across all handlers)
-	userRepo := user_repository.NewInMemoryUserRepository()
+	// 依內嵌的 OpenAPI 規格驗證請求，openapi.validate_responses 開啟時也檢查回應
+	spec, err := openapi.Load(api_docs.Spec)
+	if err != nil {
+		exit(err)
+	}
+	r.Use(spec.Middleware(openapi.Options{
+		ValidateRequests:  cfg.OpenAPI.ValidateRequests,
+		ValidateResponses: cfg.OpenAPI.ValidateResponses,
+	}))
 
-	if err := user_restapi.NewInMemUserHandler(r, userRepo); err != nil {
-		log.Fatal(err)
+	// 設定 database.url 時資料保存在 database.driver 指定的資料庫（postgres 或 sqlite），否則保存在記憶體中
+	var db *sql.DB
+	if !cfg.Database.InMemory() {
+		db, err = openDatabase(context.Background(), cfg.Database)
+		if err != nil {
+			exit(err)
+		}
+		defer db.Close()
+	} else {
+		slog.Warn("database.url is not set, data is kept in memory only")
 	}
-	if err := portal_page_restapi.NewInMemPortalPageHandler(r, userRepo); err != nil {
-		log.Fatal(err)
+
+	// personal access token 由 tokenManager 驗證，與用戶處理器共用同一個 repository
+	var personalAccessTokenRepo user_domain.PersonalAccessTokenRepository = user_repository.NewInMemoryPersonalAccessTokenRepository()
+	if db != nil {
+		personalAccessTokenRepo = user_repository.NewSQLPersonalAccessTokenRepository(db)
+	}
+	tokenManager, err := newTokenManager(cfg.Auth, db, personalAccessTokenRepo, m)
+	if err != nil {
+		exit(err)
+	}
+
+	outbox, err := newMailer(cfg.Mail)
+	if err != nil {
+		exit(err)
+	}
+
+	passwordHasher, err := password.NewHasher(password.Config{})
+	if err != nil {
+		exit(err)
+	}
+
+	// readiness 檢查，記憶體模式沒有外部依賴
+	var readinessChecks []health.Check
+
+	if db != nil {
+		migrator, err := migrate.New(db, cfg.Database.Driver)
+		if err != nil {
+			exit(err)
+		}
+		readinessChecks = append(readinessChecks, health.DatabaseCheck(db), health.MigrationCheck(migrator))
+
+		// 序列化失敗（PostgreSQL SERIALIZABLE 交易衝突）時最多重試 database.tx_max_retries 次
+		txManager := database.NewTxManager(db, cfg.Database.Driver, cfg.Database.TxMaxRetries)
+
+		if err := user_restapi.NewUserHandler(r, db, txManager, personalAccessTokenRepo, passwordHasher, tokenManager, outbox, emailLinks(cfg.Mail), m); err != nil {
+			exit(err)
+		}
+		if err := portal_page_restapi.NewPortalPageHandler(r, db, txManager, tokenManager, cfg.Auth.RequireVerifiedEmail, m); err != nil {
+			exit(err)
+		}
+	} else {
+		// Create in-memory user repository (shared across all handlers)
+		userRepo := user_repository.NewInMemoryUserRepository()
+		refreshTokenRepo := user_repository.NewInMemoryRefreshTokenRepository()
+		loginAttemptRepo := user_repository.NewInMemoryLoginAttemptRepository()
+		passwordResetTokenRepo := user_repository.NewInMemoryPasswordResetTokenRepository()
+		emailVerificationTokenRepo := user_repository.NewInMemoryEmailVerificationTokenRepository()
+
+		if err := user_restapi.NewInMemUserHandler(r, transaction.NewInMemoryTxManager(), userRepo, refreshTokenRepo, loginAttemptRepo, passwordResetTokenRepo, emailVerificationTokenRepo, personalAccessTokenRepo, passwordHasher, tokenManager, outbox, emailLinks(cfg.Mail), m); err != nil {
+			exit(err)
+		}
+		if err := portal_page_restapi.NewInMemPortalPageHandler(r, userRepo, tokenManager, cfg.Auth.RequireVerifiedEmail, m); err != nil {
+			exit(err)
+		}
 	}
 
 	// 根路由
@@ -39,6 +210,185 @@ func main() {
 		})
 	})
 
-	// 啟動服務器
-	r.Run(":8080")
+	// 健康檢查：/healthz（liveness）與 /readyz（readiness）
+	healthHandler := health.NewHandler(health.DefaultTimeout, readinessChecks...)
+	healthHandler.Register(r)
+
+	// Prometheus 指標
+	m.Register(r)
+
+	// OpenAPI 規格與 Swagger UI
+	spec.Register(r)
+
+	// 啟動服務器，收到 SIGINT 或 SIGTERM 時等待處理中的請求完成後關閉
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	listener, err := net.Listen("tcp", cfg.Server.Addr)
+	if err != nil {
+		exit(err)
+	}
+	slog.Info("listening", "addr", listener.Addr().String())
+
+	srv := &http.Server{
+		Handler:           r,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+	if err := httpserver.Serve(ctx, srv, listener, httpserver.ShutdownOptions{
+		OnShutdown: func() {
+			slog.Info("shutting down, draining in-flight requests")
+			healthHandler.Drain()
+		},
+		Delay:   cfg.Server.ShutdownDelay,
+		Timeout: cfg.Server.ShutdownTimeout,
+	}); err != nil {
+		exit(err)
+	}
+	slog.Info("server stopped")
+}
+
+// exit 記錄錯誤後以狀態碼 1 結束程式
+func exit(err error) {
+	slog.Error("fatal error", "error", err)
+	os.Exit(1)
+}
+
+// newTokenManager 建立 access token 的簽發與驗證器
+// 未設定 auth.jwt_secret 時使用隨機金鑰，重新啟動後先前簽發的 token 將失效
+// db 不為 nil 時撤銷紀錄與 token 版本保存在資料庫，重新啟動與多個實例間皆有效，否則保存在記憶體中
+// personal access token 以 personalAccessTokenRepo 驗證，驗證失敗的原因記錄到 failureRecorder
+func newTokenManager(cfg config.AuthConfig, db *sql.DB, personalAccessTokenRepo user_domain.PersonalAccessTokenRepository, failureRecorder auth.TokenFailureRecorder) (*auth.TokenManager, error) {
+	keyring, err := newKeyring(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var revocationStore auth.RevocationStore
+	if db != nil {
+		revocationStore = auth.NewSQLRevocationStore(db)
+	}
+
+	return auth.NewTokenManager(keyring, auth.TokenManagerConfig{
+		AccessTokenTTL:       cfg.AccessTokenTTL,
+		RefreshTokenTTL:      cfg.RefreshTokenTTL,
+		RevocationStore:      revocationStore,
+		FailureRecorder:      failureRecorder,
+		PersonalAccessTokens: personalAccessTokenRepo,
+	}), nil
+}
+
+// newKeyring 以 auth.jwt_secret、auth.verification_keys 與 auth.keys 建立 keyring，
+// 由 auth.signing_key_id（未設定時為 auth.jwt_key_id）指定的金鑰簽發 token
+func newKeyring(cfg config.AuthConfig) (*auth.Keyring, error) {
+	signingKeyID := cfg.SigningKeyID
+	if signingKeyID == "" {
+		signingKeyID = cfg.JWTKeyID
+	}
+
+	var keys []*auth.Key
+	// 由 auth.keys 簽發且未設定 jwt_secret 時，不需要 HMAC 金鑰
+	if cfg.JWTSecret != "" || signingKeyID == cfg.JWTKeyID {
+		secret := []byte(cfg.JWTSecret)
+		if len(secret) == 0 {
+			slog.Warn("auth.jwt_secret is not set, using a random signing key")
+			secret = make([]byte, 32)
+			if _, err := rand.Read(secret); err != nil {
+				return nil, err
+			}
+		}
+		key, err := auth.NewHMACKey(cfg.JWTKeyID, secret)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	for _, verificationKey := range cfg.VerificationKeys {
+		key, err := auth.NewHMACKey(verificationKey.ID, []byte(verificationKey.Secret))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	for i, keyConfig := range cfg.Keys {
+		key, err := keyConfig.Load()
+		if err != nil {
+			return nil, fmt.Errorf("auth.keys[%d]: %w", i, err)
+		}
+		keys = append(keys, key)
+	}
+
+	i := slices.IndexFunc(keys, func(key *auth.Key) bool { return key.ID == signingKeyID })
+	if i < 0 {
+		return nil, fmt.Errorf("auth.signing_key_id: %w: %q", auth.ErrKeyNotFound, signingKeyID)
+	}
+	signingKey := keys[i]
+	keyring, err := auth.NewKeyring(signingKey, slices.Delete(keys, i, i+1)...)
+	if err != nil {
+		return nil, fmt.Errorf("auth.signing_key_id: %w", err)
+	}
+	return keyring, nil
+}
+
+// newTracerProvider 建立 tracer provider
+// tracing.exporter 為 otlp 時以 OTLP/HTTP 將 span 送到 tracing.endpoint，為 none 時不輸出 span
+func newTracerProvider(ctx context.Context, cfg config.TracingConfig) (*sdktrace.TracerProvider, error) {
+	var exporter sdktrace.SpanExporter
+	if cfg.Exporter == tracing.ExporterOTLP {
+		var err error
+		exporter, err = tracing.NewOTLPExporter(ctx, cfg.Endpoint)
+		if err != nil {
+			return nil, err
+		}
+		slog.Info("exporting spans", "endpoint", cfg.Endpoint, "sample_ratio", cfg.SampleRatio)
+	}
+	return tracing.NewProvider(exporter, cfg.ServiceName, cfg.SampleRatio), nil
+}
+
+// openDatabase 開啟資料庫連線，database.auto_migrate 為 true 時套用尚未套用的 migration
+// driver 為 sqlite 時 url 為資料庫檔案路徑，或使用 :memory: 開啟記憶體資料庫
+func openDatabase(ctx context.Context, cfg config.DatabaseConfig) (*sql.DB, error) {
+	db, err := database.Open(ctx, cfg.Driver, cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	if !cfg.AutoMigrate {
+		return db, nil
+	}
+
+	migrator, err := migrate.New(db, cfg.Driver)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	for _, migration := range applied {
+		slog.Info("applied migration", "version", migration.Version, "name", migration.Name)
+	}
+	return db, nil
+}
+
+// newMailer 建立寄信用的 outbox
+// 設定 mail.outbox_dir 時將信件寫入該目錄，否則僅保存在記憶體中
+func newMailer(cfg config.MailConfig) (user_domain.Mailer, error) {
+	if cfg.OutboxDir == "" {
+		slog.Warn("mail.outbox_dir is not set, emails are kept in memory only")
+		return mailer.NewInMemoryOutbox(), nil
+	}
+	return mailer.NewFileOutbox(cfg.OutboxDir)
+}
+
+// emailLinks 信件中使用的網址
+func emailLinks(cfg config.MailConfig) user_restapi.EmailLinks {
+	return user_restapi.EmailLinks{
+		PasswordResetURL: cfg.PasswordResetURL,
+		VerifyEmailURL:   cfg.VerifyEmailURL,
+	}
 }
@@ -0,0 +1,21 @@
+package restapi
+
+import (
+	"net/http"
+	"portal_link/modules/portal_page/domain"
+	"portal_link/pkg/http_error"
+)
+
+// errorMappings 個人頁面模組的 domain error 與 HTTP 回應的對應（狀態碼與代碼依 api.yaml）
+// 與其他錯誤共用 Code 的項目以 MessageKey 指定 pkg/i18n 訊息目錄中的訊息
+var errorMappings = []http_error.Mapping{
+	{Err: domain.ErrInvalidParams, Status: http.StatusBadRequest, Code: http_error.ErrInvalidParams},
+	{Err: domain.ErrSlugExists, Status: http.StatusBadRequest, Code: http_error.ErrInvalidParams, MessageKey: "ErrSlugExists"},
+	{Err: domain.ErrLinkNotFound, Status: http.StatusBadRequest, Code: http_error.ErrInvalidParams, MessageKey: "ErrLinkNotFound"},
+	{Err: domain.ErrForbidden, Status: http.StatusForbidden, Code: http_error.ErrForbidden},
+	{Err: domain.ErrPortalPageNotFound, Status: http.StatusNotFound, Code: http_error.ErrNotFound, MessageKey: "ErrPortalPageNotFound"},
+}
+
+func init() {
+	http_error.Register(errorMappings...)
+}
@@ -1,16 +1,220 @@
 package restapi
 
 import (
+	"database/sql"
+	"net/http"
+	"portal_link/modules/portal_page/domain"
+	"portal_link/modules/portal_page/repository"
+	"portal_link/modules/portal_page/usecase"
 	user_domain "portal_link/modules/user/domain"
+	user_repository "portal_link/modules/user/repository"
+	"portal_link/pkg/auth"
+	"portal_link/pkg/http_error"
+	"portal_link/pkg/metrics"
+	"portal_link/pkg/transaction"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
 // PortalPageHandler 個人頁面處理器
 type PortalPageHandler struct {
+	createPortalPageUC     *usecase.CreatePortalPageUC
+	listPortalPagesUC      *usecase.ListPortalPagesUC
+	findMyPortalPageByIDUC *usecase.FindMyPortalPageByIDUC
+	updatePortalPageUC     *usecase.UpdatePortalPageUC
+	findPortalPageBySlugUC *usecase.FindPortalPageBySlugUC
 }
 
 // NewInMemPortalPageHandler 建立新的個人頁面處理器 (in-memory version)
-func NewInMemPortalPageHandler(e *gin.Engine, userRepo user_domain.UserRepository) error {
+// requireVerifiedEmail 為 true 時，建立與更新 Portal Page 需要已驗證電子郵件
+func NewInMemPortalPageHandler(e *gin.Engine, userRepo user_domain.UserRepository, tokenManager *auth.TokenManager, requireVerifiedEmail bool, m *metrics.Metrics) error {
+	portalPageRepo := repository.NewInMemoryPortalPageRepository()
+	return registerPortalPageHandler(e, transaction.NewInMemoryTxManager(), portalPageRepo, userRepo, tokenManager, requireVerifiedEmail, m)
+}
+
+// NewPortalPageHandler 建立新的個人頁面處理器，Portal Page 與使用者保存在 SQL 資料庫（PostgreSQL 或 SQLite）
+func NewPortalPageHandler(e *gin.Engine, db *sql.DB, txManager transaction.TxManager, tokenManager *auth.TokenManager, requireVerifiedEmail bool, m *metrics.Metrics) error {
+	return registerPortalPageHandler(e, txManager, repository.NewSQLPortalPageRepository(db), user_repository.NewSQLUserRepository(db), tokenManager, requireVerifiedEmail, m)
+}
+
+// registerPortalPageHandler 建立個人頁面處理器並註冊路由，repository 的呼叫記錄到 m
+func registerPortalPageHandler(e *gin.Engine, txManager transaction.TxManager, portalPageRepo domain.PortalPageRepository, userRepo user_domain.UserRepository, tokenManager *auth.TokenManager, requireVerifiedEmail bool, m *metrics.Metrics) error {
+	portalPageRepo = repository.NewInstrumentedPortalPageRepository(portalPageRepo, m)
+	userRepo = user_repository.NewInstrumentedUserRepository(userRepo, m)
+
+	handler := &PortalPageHandler{
+		createPortalPageUC:     usecase.NewCreatePortalPageUC(portalPageRepo),
+		listPortalPagesUC:      usecase.NewListPortalPagesUC(portalPageRepo),
+		findMyPortalPageByIDUC: usecase.NewFindMyPortalPageByIDUC(portalPageRepo),
+		updatePortalPageUC:     usecase.NewUpdatePortalPageUC(txManager, portalPageRepo),
+		findPortalPageBySlugUC: usecase.NewFindPortalPageBySlugUC(portalPageRepo),
+	}
+
+	// personal access token 需有對應的 scope，登入取得的 access token 不受 scope 限制
+	read := e.Group("/api/v1/me/portal-pages", auth.AuthMiddleware(tokenManager, userRepo, user_domain.ScopePortalPagesRead))
+	{
+		read.GET("", handler.ListPortalPages)
+		read.GET("/:id", handler.FindMyPortalPageByID)
+	}
+
+	// 建立與更新 Portal Page 視設定需要已驗證電子郵件
+	write := e.Group("/api/v1/me/portal-pages", auth.AuthMiddleware(tokenManager, userRepo, user_domain.ScopePortalPagesWrite))
+	if requireVerifiedEmail {
+		write.Use(auth.RequireVerifiedEmail(userRepo))
+	}
+	{
+		write.POST("", handler.CreatePortalPage)
+		write.PUT("/:id", handler.UpdatePortalPage)
+	}
+
+	public := e.Group("/api/v1/portal-pages")
+	{
+		public.GET("/:slug", handler.FindPortalPageBySlug)
+	}
 	return nil
 }
+
+// CreatePortalPage 處理建立 Portal Page 請求
+func (h *PortalPageHandler) CreatePortalPage(c *gin.Context) {
+	var req usecase.CreatePortalPageParams
+
+	// 綁定並驗證請求體
+	if err := c.ShouldBindJSON(&req); err != nil {
+		http_error.ResponseBadRequest(c, nil)
+		return
+	}
+
+	userID, err := h.currentUserID(c)
+	if err != nil {
+		http_error.Respond(c, err)
+		return
+	}
+
+	// 執行建立 Portal Page 用例
+	result, err := h.createPortalPageUC.Execute(c.Request.Context(), &usecase.CreatePortalPageParams{
+		UserID:          userID,
+		Slug:            req.Slug,
+		Title:           req.Title,
+		Bio:             req.Bio,
+		ProfileImageURL: req.ProfileImageURL,
+		Theme:           req.Theme,
+	})
+	if err != nil {
+		http_error.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// ListPortalPages 處理列出自己所有 Portal Page 的請求
+func (h *PortalPageHandler) ListPortalPages(c *gin.Context) {
+	userID, err := h.currentUserID(c)
+	if err != nil {
+		http_error.Respond(c, err)
+		return
+	}
+
+	// 執行列出 Portal Page 用例
+	result, err := h.listPortalPagesUC.Execute(c.Request.Context(), &usecase.ListPortalPagesParams{
+		UserID: userID,
+	})
+	if err != nil {
+		http_error.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// FindMyPortalPageByID 處理查詢自己的單一 Portal Page 請求
+func (h *PortalPageHandler) FindMyPortalPageByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		http_error.ResponseBadRequest(c, nil)
+		return
+	}
+
+	userID, err := h.currentUserID(c)
+	if err != nil {
+		http_error.Respond(c, err)
+		return
+	}
+
+	// 執行查詢 Portal Page 用例
+	result, err := h.findMyPortalPageByIDUC.Execute(c.Request.Context(), &usecase.FindMyPortalPageByIDParams{
+		UserID: userID,
+		ID:     id,
+	})
+	if err != nil {
+		http_error.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// UpdatePortalPage 處理更新 Portal Page 請求
+func (h *PortalPageHandler) UpdatePortalPage(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		http_error.ResponseBadRequest(c, nil)
+		return
+	}
+
+	var req usecase.UpdatePortalPageParams
+
+	// 綁定並驗證請求體
+	if err := c.ShouldBindJSON(&req); err != nil {
+		http_error.ResponseBadRequest(c, nil)
+		return
+	}
+
+	userID, err := h.currentUserID(c)
+	if err != nil {
+		http_error.Respond(c, err)
+		return
+	}
+
+	// 執行更新 Portal Page 用例
+	result, err := h.updatePortalPageUC.Execute(c.Request.Context(), &usecase.UpdatePortalPageParams{
+		UserID:          userID,
+		ID:              id,
+		Slug:            req.Slug,
+		Title:           req.Title,
+		Bio:             req.Bio,
+		ProfileImageURL: req.ProfileImageURL,
+		Theme:           req.Theme,
+		Links:           req.Links,
+	})
+	if err != nil {
+		http_error.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// FindPortalPageBySlug 處理以 slug 查詢公開 Portal Page 的請求
+func (h *PortalPageHandler) FindPortalPageBySlug(c *gin.Context) {
+	// 執行以 slug 查詢 Portal Page 用例
+	result, err := h.findPortalPageBySlugUC.Execute(c.Request.Context(), &usecase.FindPortalPageBySlugParams{
+		Slug: c.Param("slug"),
+	})
+	if err != nil {
+		http_error.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// currentUserID 從 context 取得目前登入的使用者 ID
+func (h *PortalPageHandler) currentUserID(c *gin.Context) (int, error) {
+	userID, err := auth.GetUserIDFromContext(c)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(userID)
+}
@@ -1 +1,41 @@
 package domain
+
+import "portal_link/pkg/validation"
+
+// Theme Portal Page 的主題風格
+type Theme string
+
+const (
+	// ThemeLight 淺色主題（預設值）
+	ThemeLight Theme = "light"
+	// ThemeDark 深色主題
+	ThemeDark Theme = "dark"
+)
+
+// DefaultTheme 未指定主題時使用的預設值
+const DefaultTheme = ThemeLight
+
+// IsValid 判斷是否為支援的主題
+func (t Theme) IsValid() bool {
+	switch t {
+	case ThemeLight, ThemeDark:
+		return true
+	default:
+		return false
+	}
+}
+
+// ParseTheme 將字串轉換為 Theme，空字串視為預設主題
+func ParseTheme(value string) (Theme, error) {
+	if value == "" {
+		return DefaultTheme, nil
+	}
+
+	theme := Theme(value)
+	var v validation.Errors
+	validateTheme(&v, "theme", theme)
+	if err := v.Err(ErrInvalidParams); err != nil {
+		return "", err
+	}
+	return theme, nil
+}
@@ -1 +1,20 @@
 package domain
+
+import "github.com/cockroachdb/errors"
+
+var (
+	// ErrInvalidParams 參數驗證失敗（格式錯誤、長度不符、必填欄位為空等）
+	ErrInvalidParams = errors.New("invalid parameters")
+
+	// ErrSlugExists Slug 已被使用，無法建立或更新
+	ErrSlugExists = errors.New("slug already exists")
+
+	// ErrPortalPageNotFound 找不到指定的 Portal Page
+	ErrPortalPageNotFound = errors.New("portal page not found")
+
+	// ErrLinkNotFound 找不到指定的 Link
+	ErrLinkNotFound = errors.New("link not found")
+
+	// ErrForbidden 使用者不是 Portal Page 的擁有者
+	ErrForbidden = errors.New("you do not have permission")
+)
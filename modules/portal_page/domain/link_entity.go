@@ -1,14 +1,81 @@
 package domain
 
+import (
+	"portal_link/pkg/validation"
+	"time"
+)
+
 // Link 實體代表使用者在 Portal Page 中展示的個別連結項目
 // Link 是 Portal Page 聚合內的實體，必須透過 Portal Page（聚合根）來管理
 type Link struct {
+	ID           int
+	PortalPageID int
+	Title        string
+	URL          string
+	Description  string
+	IconURL      string
+	DisplayOrder int
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
 }
 
 // LinkParams 用於建立或更新 Link 的參數
 type LinkParams Link
 
-// NewLink 建立新的 Link 實體（私有方法，只能透過 PortalPage 聚合根調用）
-func NewLink(params LinkParams) *Link {
-	return &Link{}
+// newLink 建立新的 Link 實體（私有方法，只能透過 PortalPage 聚合根調用）
+func newLink(params LinkParams) (*Link, error) {
+	now := time.Now().UTC()
+
+	if params.CreatedAt.IsZero() {
+		params.CreatedAt = now
+	}
+
+	if params.UpdatedAt.IsZero() {
+		params.UpdatedAt = now
+	}
+
+	link := &Link{
+		ID:           params.ID,
+		PortalPageID: params.PortalPageID,
+		Title:        params.Title,
+		URL:          params.URL,
+		Description:  params.Description,
+		IconURL:      params.IconURL,
+		DisplayOrder: params.DisplayOrder,
+		CreatedAt:    params.CreatedAt,
+		UpdatedAt:    params.UpdatedAt,
+	}
+	if err := link.validate(); err != nil {
+		return nil, err
+	}
+
+	return link, nil
+}
+
+// update 更新 Link 的內容，display_order 由 PortalPage 負責維護
+func (l *Link) update(params LinkParams, now time.Time) error {
+	updated := *l
+	updated.Title = params.Title
+	updated.URL = params.URL
+	updated.Description = params.Description
+	updated.IconURL = params.IconURL
+	if err := updated.validate(); err != nil {
+		return err
+	}
+
+	if updated != *l {
+		updated.UpdatedAt = now
+		*l = updated
+	}
+	return nil
+}
+
+// validate 驗證 Link 的欄位，返回所有不合法的欄位
+func (l *Link) validate() error {
+	var v validation.Errors
+	validateTitle(&v, "title", l.Title)
+	validateURL(&v, "url", l.URL, false)
+	validateMaxLength(&v, "description", l.Description, descriptionMaxLength)
+	validateURL(&v, "icon_url", l.IconURL, true)
+	return v.Err(ErrInvalidParams)
 }
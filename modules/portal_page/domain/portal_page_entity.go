@@ -1,13 +1,466 @@
 package domain
 
-type PortalPageParams PortalPage
+import (
+	"fmt"
+	"portal_link/pkg/validation"
+	"sort"
+	"time"
+)
+
+// PortalPageParams 用於建立 PortalPage 的參數
+// Links 的 display_order 必須從 1 開始連續編號
+type PortalPageParams struct {
+	ID              int
+	UserID          int
+	Slug            string
+	Title           string
+	Bio             string
+	ProfileImageURL string
+	Theme           Theme
+	Links           []LinkParams
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
 
 // PortalPage 實體代表使用者的個人化連結整合頁面
 // PortalPage 是聚合根（Aggregate Root），負責管理其內部的所有 Link 實體
 type PortalPage struct {
+	ID              int
+	UserID          int
+	Slug            string
+	Title           string
+	Bio             string
+	ProfileImageURL string
+	Theme           Theme
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+
+	// links 依 display_order 升冪排列，display_order 恆為 1..n
+	links []*Link
 }
 
 // NewPortalPage 建立新的 PortalPage 實體
-func NewPortalPage(params PortalPageParams) *PortalPage {
-	return &PortalPage{}
+func NewPortalPage(params PortalPageParams) (*PortalPage, error) {
+	now := time.Now().UTC()
+
+	if params.CreatedAt.IsZero() {
+		params.CreatedAt = now
+	}
+
+	if params.UpdatedAt.IsZero() {
+		params.UpdatedAt = now
+	}
+
+	if params.Theme == "" {
+		params.Theme = DefaultTheme
+	}
+
+	var v validation.Errors
+	if params.UserID <= 0 {
+		v.Add("user_id", validation.RuleOutOfRange, validation.Params{"min": 1})
+	}
+
+	portalPage := &PortalPage{
+		ID:              params.ID,
+		UserID:          params.UserID,
+		Slug:            NormalizeSlug(params.Slug),
+		Title:           params.Title,
+		Bio:             params.Bio,
+		ProfileImageURL: params.ProfileImageURL,
+		Theme:           params.Theme,
+		CreatedAt:       params.CreatedAt,
+		UpdatedAt:       params.UpdatedAt,
+	}
+	if err := v.Merge("", portalPage.validate()); err != nil {
+		return nil, err
+	}
+
+	links, err := portalPage.buildLinks(params.Links, nil)
+	if err = v.Merge("", err); err != nil {
+		return nil, err
+	}
+	if err := v.Err(ErrInvalidParams); err != nil {
+		return nil, err
+	}
+	portalPage.links = links
+
+	return portalPage, nil
+}
+
+// ReconstitutePortalPage 以已保存的資料重建 PortalPage，不套用預設值也不驗證
+// 只用於 repository 讀取資料，驗證規則變嚴格後，先前保存的資料仍可讀取；Links 依 display_order 排序
+func ReconstitutePortalPage(params PortalPageParams) *PortalPage {
+	portalPage := &PortalPage{
+		ID:              params.ID,
+		UserID:          params.UserID,
+		Slug:            params.Slug,
+		Title:           params.Title,
+		Bio:             params.Bio,
+		ProfileImageURL: params.ProfileImageURL,
+		Theme:           params.Theme,
+		CreatedAt:       params.CreatedAt,
+		UpdatedAt:       params.UpdatedAt,
+		links:           make([]*Link, 0, len(params.Links)),
+	}
+	for _, linkParams := range params.Links {
+		link := Link(linkParams)
+		portalPage.links = append(portalPage.links, &link)
+	}
+	sort.SliceStable(portalPage.links, func(i, j int) bool {
+		return portalPage.links[i].DisplayOrder < portalPage.links[j].DisplayOrder
+	})
+
+	return portalPage
+}
+
+// Links 依 display_order 升冪排序返回所有 Link 的複本
+func (p *PortalPage) Links() []*Link {
+	links := make([]*Link, len(p.links))
+	for i, link := range p.links {
+		copied := *link
+		links[i] = &copied
+	}
+	return links
+}
+
+// Link 根據 ID 返回 Link 的複本
+func (p *PortalPage) Link(id int) (*Link, error) {
+	index := p.indexOfLink(id)
+	if index < 0 {
+		return nil, ErrLinkNotFound
+	}
+	copied := *p.links[index]
+	return &copied, nil
+}
+
+// ChangeSlug 更新 slug，slug 是否已被使用由 Repository 檢查
+func (p *PortalPage) ChangeSlug(slug string) error {
+	slug = NormalizeSlug(slug)
+	var v validation.Errors
+	validateSlug(&v, "slug", slug)
+	if err := v.Err(ErrInvalidParams); err != nil {
+		return err
+	}
+	if slug != p.Slug {
+		p.Slug = slug
+		p.touch()
+	}
+	return nil
+}
+
+// ChangeTitle 更新標題
+func (p *PortalPage) ChangeTitle(title string) error {
+	var v validation.Errors
+	validateTitle(&v, "title", title)
+	if err := v.Err(ErrInvalidParams); err != nil {
+		return err
+	}
+	if title != p.Title {
+		p.Title = title
+		p.touch()
+	}
+	return nil
+}
+
+// ChangeBio 更新個人簡介
+func (p *PortalPage) ChangeBio(bio string) error {
+	var v validation.Errors
+	validateMaxLength(&v, "bio", bio, bioMaxLength)
+	if err := v.Err(ErrInvalidParams); err != nil {
+		return err
+	}
+	if bio != p.Bio {
+		p.Bio = bio
+		p.touch()
+	}
+	return nil
+}
+
+// ChangeProfileImageURL 更新個人頭像圖片網址
+func (p *PortalPage) ChangeProfileImageURL(profileImageURL string) error {
+	var v validation.Errors
+	validateURL(&v, "profile_image_url", profileImageURL, true)
+	if err := v.Err(ErrInvalidParams); err != nil {
+		return err
+	}
+	if profileImageURL != p.ProfileImageURL {
+		p.ProfileImageURL = profileImageURL
+		p.touch()
+	}
+	return nil
+}
+
+// ChangeTheme 更新主題
+func (p *PortalPage) ChangeTheme(theme Theme) error {
+	var v validation.Errors
+	validateTheme(&v, "theme", theme)
+	if err := v.Err(ErrInvalidParams); err != nil {
+		return err
+	}
+	if theme != p.Theme {
+		p.Theme = theme
+		p.touch()
+	}
+	return nil
+}
+
+// AddLink 新增 Link 並返回其複本
+// DisplayOrder 為 0 時加在最後，否則插入到指定位置，其後的 Link 依序往後移
+func (p *PortalPage) AddLink(params LinkParams) (*Link, error) {
+	position := params.DisplayOrder
+	if position == 0 {
+		position = len(p.links) + 1
+	}
+	if position < 1 || position > len(p.links)+1 {
+		return nil, validation.NewError(ErrInvalidParams, "display_order", validation.RuleOutOfRange, validation.Params{"min": 1, "max": len(p.links) + 1})
+	}
+
+	now := time.Now().UTC()
+	link, err := newLink(LinkParams{
+		PortalPageID: p.ID,
+		Title:        params.Title,
+		URL:          params.URL,
+		Description:  params.Description,
+		IconURL:      params.IconURL,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	index := position - 1
+	p.links = append(p.links, nil)
+	copy(p.links[index+1:], p.links[index:])
+	p.links[index] = link
+	p.renumberLinks(now)
+	p.touch()
+
+	copied := *link
+	return &copied, nil
+}
+
+// UpdateLink 更新 Link 的標題、網址、描述與圖示
+// DisplayOrder 不為 0 時同時移動到指定位置
+func (p *PortalPage) UpdateLink(id int, params LinkParams) error {
+	index := p.indexOfLink(id)
+	if index < 0 {
+		return ErrLinkNotFound
+	}
+	if params.DisplayOrder < 0 || params.DisplayOrder > len(p.links) {
+		return validation.NewError(ErrInvalidParams, "display_order", validation.RuleOutOfRange, validation.Params{"min": 0, "max": len(p.links)})
+	}
+
+	now := time.Now().UTC()
+	if err := p.links[index].update(params, now); err != nil {
+		return err
+	}
+	if params.DisplayOrder != 0 {
+		link := p.links[index]
+		p.links = append(p.links[:index], p.links[index+1:]...)
+		target := params.DisplayOrder - 1
+		p.links = append(p.links, nil)
+		copy(p.links[target+1:], p.links[target:])
+		p.links[target] = link
+		p.renumberLinks(now)
+	}
+	p.touch()
+	return nil
+}
+
+// RemoveLink 移除 Link，其後的 Link 依序往前移
+func (p *PortalPage) RemoveLink(id int) error {
+	index := p.indexOfLink(id)
+	if index < 0 {
+		return ErrLinkNotFound
+	}
+
+	p.links = append(p.links[:index], p.links[index+1:]...)
+	p.renumberLinks(time.Now().UTC())
+	p.touch()
+	return nil
+}
+
+// ReorderLinks 依 ids 的順序重新排列所有 Link，ids 必須恰好包含所有 Link 的 ID
+func (p *PortalPage) ReorderLinks(ids []int) error {
+	if len(ids) != len(p.links) {
+		return validation.NewError(ErrInvalidParams, "ids", validation.RuleOutOfRange, validation.Params{"min": len(p.links), "max": len(p.links)})
+	}
+
+	reordered := make([]*Link, 0, len(ids))
+	seen := make(map[int]struct{}, len(ids))
+	for _, id := range ids {
+		if _, duplicated := seen[id]; duplicated {
+			return validation.NewError(ErrInvalidParams, "ids", validation.RuleNotUnique, nil)
+		}
+		seen[id] = struct{}{}
+
+		index := p.indexOfLink(id)
+		if index < 0 {
+			return ErrLinkNotFound
+		}
+		reordered = append(reordered, p.links[index])
+	}
+
+	p.links = reordered
+	p.renumberLinks(time.Now().UTC())
+	p.touch()
+	return nil
+}
+
+// ReplaceLinks 以 params 取代所有 Link
+// 有 ID 的項目更新既有的 Link（ID 不存在時返回 ErrLinkNotFound），沒有 ID 的項目新增 Link，
+// 未出現在 params 中的 Link 會被移除；display_order 必須從 1 開始連續編號
+func (p *PortalPage) ReplaceLinks(params []LinkParams) error {
+	existing := make(map[int]*Link, len(p.links))
+	for _, link := range p.links {
+		existing[link.ID] = link
+	}
+
+	links, err := p.buildLinks(params, existing)
+	if err != nil {
+		return err
+	}
+
+	p.links = links
+	p.touch()
+	return nil
+}
+
+// AssignLinkIDs 由 Repository 在保存 Portal Page 時呼叫，為尚未有 ID 的 Link 回填 ID
+// assign 負責保存該 Link 並返回新的 ID
+func (p *PortalPage) AssignLinkIDs(assign func(link Link) (int, error)) error {
+	for _, link := range p.links {
+		link.PortalPageID = p.ID
+		if link.ID != 0 {
+			continue
+		}
+
+		id, err := assign(*link)
+		if err != nil {
+			return err
+		}
+		link.ID = id
+	}
+	return nil
+}
+
+// validate 驗證 Portal Page 的欄位，返回所有不合法的欄位
+func (p *PortalPage) validate() error {
+	var v validation.Errors
+	validateSlug(&v, "slug", p.Slug)
+	validateTitle(&v, "title", p.Title)
+	validateMaxLength(&v, "bio", p.Bio, bioMaxLength)
+	validateURL(&v, "profile_image_url", p.ProfileImageURL, true)
+	validateTheme(&v, "theme", p.Theme)
+	return v.Err(ErrInvalidParams)
+}
+
+// buildLinks 依 params 建立排序後的 Link 清單，欄位錯誤以 links[i]. 加上 params 中的索引表示
+// existing 不為 nil 時，有 ID 的項目必須存在於 existing 中並更新其內容；為 nil 時直接以 params 重建
+func (p *PortalPage) buildLinks(params []LinkParams, existing map[int]*Link) ([]*Link, error) {
+	order := make([]int, len(params))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return params[order[i]].DisplayOrder < params[order[j]].DisplayOrder
+	})
+
+	var v validation.Errors
+	now := time.Now().UTC()
+	links := make([]*Link, 0, len(params))
+	seenIDs := make(map[int]struct{}, len(params))
+	// expected 為下一個 display_order，不連續只回報第一個位置，避免其後的 Link 全部重複回報
+	expected := 1
+	contiguous := true
+	for _, index := range order {
+		linkParams := params[index]
+		prefix := fmt.Sprintf("links[%d].", index)
+
+		switch {
+		case linkParams.DisplayOrder < 1:
+			v.Add(prefix+"display_order", validation.RuleOutOfRange, validation.Params{"min": 1})
+		case linkParams.DisplayOrder == expected-1:
+			v.Add(prefix+"display_order", validation.RuleNotUnique, nil)
+		case linkParams.DisplayOrder != expected:
+			if contiguous {
+				v.Add(prefix+"display_order", validation.RuleNotContiguous, validation.Params{"expected": expected})
+				contiguous = false
+			}
+			expected = linkParams.DisplayOrder + 1
+		default:
+			expected++
+		}
+
+		if linkParams.ID != 0 {
+			if _, duplicated := seenIDs[linkParams.ID]; duplicated {
+				v.Add(prefix+"id", validation.RuleNotUnique, nil)
+				continue
+			}
+			seenIDs[linkParams.ID] = struct{}{}
+		}
+
+		if existing != nil && linkParams.ID != 0 {
+			current, exists := existing[linkParams.ID]
+			if !exists {
+				return nil, ErrLinkNotFound
+			}
+			updated := *current
+			if err := v.Merge(prefix, updated.update(linkParams, now)); err != nil {
+				return nil, err
+			}
+			if updated.DisplayOrder != linkParams.DisplayOrder {
+				updated.DisplayOrder = linkParams.DisplayOrder
+				updated.UpdatedAt = now
+			}
+			links = append(links, &updated)
+			continue
+		}
+
+		if existing != nil {
+			// 新增的 Link 使用目前時間，忽略傳入的 ID 以外的時間欄位
+			linkParams.CreatedAt = now
+			linkParams.UpdatedAt = now
+		}
+		linkParams.PortalPageID = p.ID
+		link, err := newLink(linkParams)
+		if err = v.Merge(prefix, err); err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+
+	if err := v.Err(ErrInvalidParams); err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// renumberLinks 依目前順序將 display_order 重新編號為 1..n
+func (p *PortalPage) renumberLinks(now time.Time) {
+	for i, link := range p.links {
+		if link.DisplayOrder != i+1 {
+			link.DisplayOrder = i + 1
+			link.UpdatedAt = now
+		}
+	}
+}
+
+// indexOfLink 返回指定 ID 的 Link 在清單中的位置，不存在時返回 -1
+func (p *PortalPage) indexOfLink(id int) int {
+	if id == 0 {
+		return -1
+	}
+	for i, link := range p.links {
+		if link.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// touch 更新 UpdatedAt
+func (p *PortalPage) touch() {
+	p.UpdatedAt = time.Now().UTC()
 }
@@ -0,0 +1,312 @@
+package domain
+
+import (
+	"portal_link/pkg/validation"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPortalPage(t *testing.T, links ...LinkParams) *PortalPage {
+	t.Helper()
+
+	portalPage, err := NewPortalPage(PortalPageParams{
+		ID:     1,
+		UserID: 1,
+		Slug:   "john-doe",
+		Title:  "John's Page",
+		Links:  links,
+	})
+	require.NoError(t, err)
+	return portalPage
+}
+
+func linkIDs(portalPage *PortalPage) []int {
+	var ids []int
+	for _, link := range portalPage.Links() {
+		ids = append(ids, link.ID)
+	}
+	return ids
+}
+
+func assertContiguous(t *testing.T, portalPage *PortalPage) {
+	t.Helper()
+	for i, link := range portalPage.Links() {
+		assert.Equal(t, i+1, link.DisplayOrder)
+	}
+}
+
+func TestNewPortalPage(t *testing.T) {
+	tests := []struct {
+		name        string
+		params      PortalPageParams
+		expectedErr error
+		check       func(t *testing.T, portalPage *PortalPage)
+	}{
+		{
+			name:   "正規化 slug 並使用預設主題",
+			params: PortalPageParams{UserID: 1, Slug: "  John-Doe ", Title: "John's Page"},
+			check: func(t *testing.T, portalPage *PortalPage) {
+				assert.Equal(t, "john-doe", portalPage.Slug)
+				assert.Equal(t, ThemeLight, portalPage.Theme)
+				assert.Empty(t, portalPage.Links())
+			},
+		},
+		{
+			name:        "slug 太短",
+			params:      PortalPageParams{UserID: 1, Slug: "ab", Title: "Title"},
+			expectedErr: ErrInvalidParams,
+		},
+		{
+			name:        "slug 含有連續連字號",
+			params:      PortalPageParams{UserID: 1, Slug: "john--doe", Title: "Title"},
+			expectedErr: ErrInvalidParams,
+		},
+		{
+			name:        "slug 為保留字",
+			params:      PortalPageParams{UserID: 1, Slug: "admin", Title: "Title"},
+			expectedErr: ErrInvalidParams,
+		},
+		{
+			name:        "標題為空",
+			params:      PortalPageParams{UserID: 1, Slug: "john-doe", Title: "  "},
+			expectedErr: ErrInvalidParams,
+		},
+		{
+			name:        "簡介超過 500 字元",
+			params:      PortalPageParams{UserID: 1, Slug: "john-doe", Title: "Title", Bio: strings.Repeat("字", 501)},
+			expectedErr: ErrInvalidParams,
+		},
+		{
+			name:        "頭像網址格式錯誤",
+			params:      PortalPageParams{UserID: 1, Slug: "john-doe", Title: "Title", ProfileImageURL: "javascript:alert(1)"},
+			expectedErr: ErrInvalidParams,
+		},
+		{
+			name:        "不支援的主題",
+			params:      PortalPageParams{UserID: 1, Slug: "john-doe", Title: "Title", Theme: Theme("blue")},
+			expectedErr: ErrInvalidParams,
+		},
+		{
+			name: "Link 依 display_order 排序",
+			params: PortalPageParams{UserID: 1, Slug: "john-doe", Title: "Title", Links: []LinkParams{
+				{ID: 2, Title: "Second", URL: "https://b.example.com", DisplayOrder: 2},
+				{ID: 1, Title: "First", URL: "https://a.example.com", DisplayOrder: 1},
+			}},
+			check: func(t *testing.T, portalPage *PortalPage) {
+				assert.Equal(t, []int{1, 2}, linkIDs(portalPage))
+			},
+		},
+		{
+			name: "display_order 不連續",
+			params: PortalPageParams{UserID: 1, Slug: "john-doe", Title: "Title", Links: []LinkParams{
+				{Title: "First", URL: "https://a.example.com", DisplayOrder: 1},
+				{Title: "Third", URL: "https://c.example.com", DisplayOrder: 3},
+			}},
+			expectedErr: ErrInvalidParams,
+		},
+		{
+			name: "Link 網址格式錯誤",
+			params: PortalPageParams{UserID: 1, Slug: "john-doe", Title: "Title", Links: []LinkParams{
+				{Title: "First", URL: "not a url", DisplayOrder: 1},
+			}},
+			expectedErr: ErrInvalidParams,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			portalPage, err := NewPortalPage(tt.params)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			if tt.check != nil {
+				tt.check(t, portalPage)
+			}
+		})
+	}
+}
+
+func TestPortalPage_LinkManagement(t *testing.T) {
+	seed := []LinkParams{
+		{ID: 1, Title: "One", URL: "https://one.example.com", DisplayOrder: 1},
+		{ID: 2, Title: "Two", URL: "https://two.example.com", DisplayOrder: 2},
+		{ID: 3, Title: "Three", URL: "https://three.example.com", DisplayOrder: 3},
+	}
+
+	t.Run("AddLink 插入到指定位置", func(t *testing.T) {
+		portalPage := newTestPortalPage(t, seed...)
+
+		link, err := portalPage.AddLink(LinkParams{Title: "New", URL: "https://new.example.com", DisplayOrder: 2})
+		require.NoError(t, err)
+		assert.Equal(t, 2, link.DisplayOrder)
+		assert.Equal(t, []int{1, 0, 2, 3}, linkIDs(portalPage))
+		assertContiguous(t, portalPage)
+
+		_, err = portalPage.AddLink(LinkParams{Title: "Last", URL: "https://last.example.com"})
+		require.NoError(t, err)
+		assert.Equal(t, 5, portalPage.Links()[4].DisplayOrder)
+
+		_, err = portalPage.AddLink(LinkParams{Title: "Far", URL: "https://far.example.com", DisplayOrder: 10})
+		assert.ErrorIs(t, err, ErrInvalidParams)
+	})
+
+	t.Run("UpdateLink 更新內容並移動位置", func(t *testing.T) {
+		portalPage := newTestPortalPage(t, seed...)
+
+		err := portalPage.UpdateLink(3, LinkParams{Title: "Three!", URL: "https://three.example.com", DisplayOrder: 1})
+		require.NoError(t, err)
+		assert.Equal(t, []int{3, 1, 2}, linkIDs(portalPage))
+		assertContiguous(t, portalPage)
+
+		link, err := portalPage.Link(3)
+		require.NoError(t, err)
+		assert.Equal(t, "Three!", link.Title)
+
+		err = portalPage.UpdateLink(99, LinkParams{Title: "X", URL: "https://x.example.com"})
+		assert.ErrorIs(t, err, ErrLinkNotFound)
+
+		err = portalPage.UpdateLink(1, LinkParams{Title: "X", URL: "ftp://x.example.com"})
+		assert.ErrorIs(t, err, ErrInvalidParams)
+	})
+
+	t.Run("RemoveLink 重新編號", func(t *testing.T) {
+		portalPage := newTestPortalPage(t, seed...)
+
+		require.NoError(t, portalPage.RemoveLink(1))
+		assert.Equal(t, []int{2, 3}, linkIDs(portalPage))
+		assertContiguous(t, portalPage)
+
+		assert.ErrorIs(t, portalPage.RemoveLink(1), ErrLinkNotFound)
+	})
+
+	t.Run("ReorderLinks 必須包含所有 Link", func(t *testing.T) {
+		portalPage := newTestPortalPage(t, seed...)
+
+		require.NoError(t, portalPage.ReorderLinks([]int{2, 3, 1}))
+		assert.Equal(t, []int{2, 3, 1}, linkIDs(portalPage))
+		assertContiguous(t, portalPage)
+
+		assert.ErrorIs(t, portalPage.ReorderLinks([]int{1, 2}), ErrInvalidParams)
+		assert.ErrorIs(t, portalPage.ReorderLinks([]int{1, 1, 2}), ErrInvalidParams)
+		assert.ErrorIs(t, portalPage.ReorderLinks([]int{1, 2, 99}), ErrLinkNotFound)
+	})
+
+	t.Run("ReplaceLinks 更新、新增並移除未列出的 Link", func(t *testing.T) {
+		portalPage := newTestPortalPage(t, seed...)
+
+		err := portalPage.ReplaceLinks([]LinkParams{
+			{ID: 3, Title: "Three", URL: "https://three.example.com", DisplayOrder: 1},
+			{Title: "New", URL: "https://new.example.com", DisplayOrder: 2},
+			{ID: 1, Title: "One (edited)", URL: "https://one.example.com", DisplayOrder: 3},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []int{3, 0, 1}, linkIDs(portalPage))
+		assertContiguous(t, portalPage)
+
+		_, err = portalPage.Link(2)
+		assert.ErrorIs(t, err, ErrLinkNotFound)
+
+		err = portalPage.ReplaceLinks([]LinkParams{
+			{ID: 99, Title: "Missing", URL: "https://missing.example.com", DisplayOrder: 1},
+		})
+		assert.ErrorIs(t, err, ErrLinkNotFound)
+	})
+
+	t.Run("Links 返回複本", func(t *testing.T) {
+		portalPage := newTestPortalPage(t, seed...)
+
+		portalPage.Links()[0].Title = "Changed"
+		link, err := portalPage.Link(1)
+		require.NoError(t, err)
+		assert.Equal(t, "One", link.Title)
+	})
+
+	t.Run("AssignLinkIDs 只為新的 Link 回填 ID", func(t *testing.T) {
+		portalPage := newTestPortalPage(t, seed...)
+		_, err := portalPage.AddLink(LinkParams{Title: "New", URL: "https://new.example.com"})
+		require.NoError(t, err)
+
+		var assigned []string
+		err = portalPage.AssignLinkIDs(func(link Link) (int, error) {
+			assigned = append(assigned, link.Title)
+			return 10, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"New"}, assigned)
+		assert.Equal(t, []int{1, 2, 3, 10}, linkIDs(portalPage))
+	})
+}
+
+func TestPortalPage_Change(t *testing.T) {
+	portalPage := newTestPortalPage(t)
+
+	require.NoError(t, portalPage.ChangeSlug("Jane-Doe"))
+	assert.Equal(t, "jane-doe", portalPage.Slug)
+	assert.ErrorIs(t, portalPage.ChangeSlug("-bad"), ErrInvalidParams)
+
+	require.NoError(t, portalPage.ChangeTheme(ThemeDark))
+	assert.ErrorIs(t, portalPage.ChangeTheme(Theme("neon")), ErrInvalidParams)
+
+	require.NoError(t, portalPage.ChangeProfileImageURL(""))
+	assert.ErrorIs(t, portalPage.ChangeProfileImageURL("example.com/a.png"), ErrInvalidParams)
+
+	assert.ErrorIs(t, portalPage.ChangeTitle(strings.Repeat("a", 101)), ErrInvalidParams)
+	assert.Equal(t, "John's Page", portalPage.Title)
+}
+
+func TestNewPortalPage_FieldErrors(t *testing.T) {
+	_, err := NewPortalPage(PortalPageParams{
+		UserID: 1,
+		Slug:   "ab",
+		Title:  " ",
+		Theme:  Theme("blue"),
+		Links: []LinkParams{
+			{Title: "Third", URL: "https://c.example.com", DisplayOrder: 3},
+			{Title: "", URL: "ftp://a.example.com", DisplayOrder: 1},
+			{Title: "Again", URL: "https://d.example.com", DisplayOrder: 1},
+		},
+	})
+	require.ErrorIs(t, err, ErrInvalidParams)
+
+	// 所有錯誤一起返回，Link 欄位以傳入時的索引表示
+	assert.Equal(t, []validation.FieldError{
+		{Field: "slug", Rule: validation.RuleTooShort, Params: validation.Params{"min": 3}},
+		{Field: "title", Rule: validation.RuleRequired},
+		{Field: "theme", Rule: validation.RuleOneOf, Params: validation.Params{"values": []string{"light", "dark"}}},
+		{Field: "links[1].title", Rule: validation.RuleRequired},
+		{Field: "links[1].url", Rule: validation.RuleInvalidFormat, Params: validation.Params{"schemes": []string{"http", "https"}}},
+		{Field: "links[2].display_order", Rule: validation.RuleNotUnique},
+		{Field: "links[0].display_order", Rule: validation.RuleNotContiguous, Params: validation.Params{"expected": 2}},
+	}, validation.FieldErrors(err))
+}
+
+func TestReconstitutePortalPage(t *testing.T) {
+	// 不符合目前驗證規則的資料也能重建
+	portalPage := ReconstitutePortalPage(PortalPageParams{
+		ID:     1,
+		UserID: 1,
+		Slug:   "Ab",
+		Title:  " ",
+		Theme:  Theme("blue"),
+		Links: []LinkParams{
+			{ID: 2, Title: "Second", URL: "ftp://b.example.com", DisplayOrder: 2},
+			{ID: 1, Title: "", URL: "https://a.example.com", DisplayOrder: 1},
+		},
+	})
+
+	assert.Equal(t, "Ab", portalPage.Slug)
+	assert.Equal(t, " ", portalPage.Title)
+	assert.Equal(t, Theme("blue"), portalPage.Theme)
+	assert.True(t, portalPage.CreatedAt.IsZero())
+	assert.Equal(t, []int{1, 2}, linkIDs(portalPage))
+	assertContiguous(t, portalPage)
+
+	// 重建後的操作仍套用驗證規則
+	err := portalPage.ChangeTitle(" ")
+	assert.ErrorIs(t, err, ErrInvalidParams)
+}
@@ -4,7 +4,8 @@ import "context"
 
 // PortalPageRepository Portal Page Repository
 type PortalPageRepository interface {
-	// Create 建立 Portal Page
+	// Create 建立 Portal Page 與其 Links，並回填 Portal Page 與 Links 的 ID
+	// Slug 已被使用時返回 ErrSlugExists
 	Create(ctx context.Context, portalPage *PortalPage) error
 
 	// Update 更新 Portal Page
@@ -13,9 +14,10 @@ type PortalPageRepository interface {
 	// 2. 更新 Portal Page 的欄位
 	// 3. 更新 Portal Page 的 Links
 	// 4. 刪除不存在於新的 Links 中的舊 Links
+	// Portal Page 不存在時返回 sql.ErrNoRows，Slug 已被其他 Portal Page 使用時返回 ErrSlugExists
 	Update(ctx context.Context, portalPage *PortalPage) error
 
-	// FindBySlug 根據 Slug 查找 Portal Page
+	// FindBySlug 根據 Slug 查找 Portal Page，不存在時返回 sql.ErrNoRows
 	// 依照 display_order 升冪排序
 	FindBySlug(ctx context.Context, slug string) (*PortalPage, error)
 
@@ -23,7 +25,7 @@ type PortalPageRepository interface {
 	// 不包含 Links
 	ListByUserID(ctx context.Context, userID int) ([]*PortalPage, error)
 
-	// FindByID 根據 ID 查找 Portal Page
+	// FindByID 根據 ID 查找 Portal Page，不存在時返回 sql.ErrNoRows
 	// 依照 display_order 升冪排序
 	FindByID(ctx context.Context, id int) (*PortalPage, error)
 }
@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"net/url"
+	"portal_link/pkg/validation"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	slugMinLength        = 3
+	slugMaxLength        = 50
+	titleMaxLength       = 100
+	bioMaxLength         = 500
+	descriptionMaxLength = 500
+	urlMaxLength         = 500
+)
+
+var slugRegex = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// reservedSlugs 系統保留的 slug，避免與前端或 API 路由衝突
+var reservedSlugs = map[string]struct{}{
+	"about":        {},
+	"admin":        {},
+	"api":          {},
+	"assets":       {},
+	"help":         {},
+	"login":        {},
+	"logout":       {},
+	"me":           {},
+	"portal-pages": {},
+	"settings":     {},
+	"signin":       {},
+	"signout":      {},
+	"signup":       {},
+	"static":       {},
+	"user":         {},
+	"users":        {},
+	"www":          {},
+}
+
+// NormalizeSlug 去除前後空白並轉換為小寫
+func NormalizeSlug(slug string) string {
+	return strings.ToLower(strings.TrimSpace(slug))
+}
+
+// validateSlug 驗證已正規化的 slug
+func validateSlug(v *validation.Errors, field string, slug string) {
+	switch {
+	case len(slug) < slugMinLength:
+		v.Add(field, validation.RuleTooShort, validation.Params{"min": slugMinLength})
+	case len(slug) > slugMaxLength:
+		v.Add(field, validation.RuleTooLong, validation.Params{"max": slugMaxLength})
+	case !slugRegex.MatchString(slug):
+		// 只能包含小寫英文、數字與連字號，連字號不能在開頭、結尾或連續出現
+		v.Add(field, validation.RuleInvalidFormat, nil)
+	default:
+		if _, reserved := reservedSlugs[slug]; reserved {
+			v.Add(field, validation.RuleReserved, nil)
+		}
+	}
+}
+
+// validateTitle 驗證標題為 1 到 100 個字元
+func validateTitle(v *validation.Errors, field string, title string) {
+	if strings.TrimSpace(title) == "" {
+		v.Add(field, validation.RuleRequired, nil)
+		return
+	}
+	validateMaxLength(v, field, title, titleMaxLength)
+}
+
+// validateMaxLength 驗證選填文字欄位的長度
+func validateMaxLength(v *validation.Errors, field string, value string, max int) {
+	if utf8.RuneCountInString(value) > max {
+		v.Add(field, validation.RuleTooLong, validation.Params{"max": max})
+	}
+}
+
+// validateURL 驗證 URL 必須是 http 或 https 的絕對網址；optional 為 true 時允許空字串
+func validateURL(v *validation.Errors, field string, rawURL string, optional bool) {
+	if rawURL == "" {
+		if !optional {
+			v.Add(field, validation.RuleRequired, nil)
+		}
+		return
+	}
+	if len(rawURL) > urlMaxLength {
+		v.Add(field, validation.RuleTooLong, validation.Params{"max": urlMaxLength})
+		return
+	}
+
+	parsed, err := url.ParseRequestURI(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		v.Add(field, validation.RuleInvalidFormat, validation.Params{"schemes": []string{"http", "https"}})
+	}
+}
+
+// validateTheme 驗證主題為支援的值
+func validateTheme(v *validation.Errors, field string, theme Theme) {
+	if !theme.IsValid() {
+		v.Add(field, validation.RuleOneOf, validation.Params{"values": []string{string(ThemeLight), string(ThemeDark)}})
+	}
+}
@@ -1,13 +1,256 @@
 package repository
 
 import (
+	"context"
+	"database/sql"
 	"portal_link/modules/portal_page/domain"
+	"portal_link/pkg/transaction"
+	"sort"
+	"sync"
 )
 
+var _ domain.PortalPageRepository = (*InMemoryPortalPageRepository)(nil)
+
+// InMemoryPortalPageRepository is an in-memory implementation of PortalPageRepository for testing.
+// Stored portal pages are deep copies; every read returns a fresh copy so callers cannot
+// mutate the stored state. Writes made inside a transaction.InMemoryTxManager transaction record
+// an undo of the touched portal page, so a rollback never discards writes made outside the transaction.
 type InMemoryPortalPageRepository struct {
+	mu          sync.RWMutex
 	portalPages map[int]*domain.PortalPage
+	slugs       map[string]int // slug -> portal page ID mapping
+	linkOwners  map[int]int    // link ID -> portal page ID mapping
+	nextID      int
+	nextLinkID  int
 }
 
+// NewInMemoryPortalPageRepository creates a new in-memory portal page repository
 func NewInMemoryPortalPageRepository() *InMemoryPortalPageRepository {
-	return &InMemoryPortalPageRepository{portalPages: make(map[int]*domain.PortalPage)}
+	return &InMemoryPortalPageRepository{
+		portalPages: make(map[int]*domain.PortalPage),
+		slugs:       make(map[string]int),
+		linkOwners:  make(map[int]int),
+		nextID:      1,
+		nextLinkID:  1,
+	}
+}
+
+// Create creates a new portal page with its links, assigning IDs to the page and every link
+func (r *InMemoryPortalPageRepository) Create(ctx context.Context, portalPage *domain.PortalPage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Check if slug already exists
+	if _, exists := r.slugs[portalPage.Slug]; exists {
+		return domain.ErrSlugExists
+	}
+
+	// A new portal page cannot reference links that are already stored
+	for _, link := range portalPage.Links() {
+		if link.ID != 0 {
+			return domain.ErrLinkNotFound
+		}
+	}
+
+	portalPage.ID = r.nextID
+	r.nextID++
+	if err := portalPage.AssignLinkIDs(r.assignLinkID); err != nil {
+		return err
+	}
+
+	stored := copyPortalPage(portalPage, true)
+	r.store(stored)
+
+	transaction.RecordUndo(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.restore(stored.ID, stored, nil)
+	})
+
+	return nil
+}
+
+// Update updates an existing portal page. Links with an ID are updated, links without an
+// ID are inserted, and stored links missing from the portal page are deleted.
+func (r *InMemoryPortalPageRepository) Update(ctx context.Context, portalPage *domain.PortalPage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, exists := r.portalPages[portalPage.ID]
+	if !exists {
+		return sql.ErrNoRows
+	}
+
+	// Check if the new slug belongs to another portal page
+	if ownerID, taken := r.slugs[portalPage.Slug]; taken && ownerID != portalPage.ID {
+		return domain.ErrSlugExists
+	}
+
+	// Links with an ID must already belong to this portal page
+	for _, link := range portalPage.Links() {
+		if link.ID == 0 {
+			continue
+		}
+		if ownerID, exists := r.linkOwners[link.ID]; !exists || ownerID != portalPage.ID {
+			return domain.ErrLinkNotFound
+		}
+	}
+
+	if err := portalPage.AssignLinkIDs(r.assignLinkID); err != nil {
+		return err
+	}
+
+	stored := copyPortalPage(portalPage, true)
+
+	// Delete links that are missing from the update
+	keep := make(map[int]struct{}, len(stored.Links()))
+	for _, link := range stored.Links() {
+		keep[link.ID] = struct{}{}
+	}
+	for _, link := range existing.Links() {
+		if _, kept := keep[link.ID]; !kept {
+			delete(r.linkOwners, link.ID)
+		}
+	}
+
+	delete(r.slugs, existing.Slug)
+	r.store(stored)
+
+	transaction.RecordUndo(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.restore(stored.ID, stored, existing)
+	})
+
+	return nil
+}
+
+// FindBySlug retrieves a portal page with its links by slug
+func (r *InMemoryPortalPageRepository) FindBySlug(ctx context.Context, slug string) (*domain.PortalPage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	portalPageID, exists := r.slugs[slug]
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+
+	return copyPortalPage(r.portalPages[portalPageID], true), nil
+}
+
+// ListByUserID retrieves every portal page owned by the user, without links, ordered by creation time
+func (r *InMemoryPortalPageRepository) ListByUserID(ctx context.Context, userID int) ([]*domain.PortalPage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	portalPages := []*domain.PortalPage{}
+	for _, portalPage := range r.portalPages {
+		if portalPage.UserID != userID {
+			continue
+		}
+		portalPages = append(portalPages, copyPortalPage(portalPage, false))
+	}
+
+	sort.Slice(portalPages, func(i, j int) bool {
+		if !portalPages[i].CreatedAt.Equal(portalPages[j].CreatedAt) {
+			return portalPages[i].CreatedAt.Before(portalPages[j].CreatedAt)
+		}
+		return portalPages[i].ID < portalPages[j].ID
+	})
+	return portalPages, nil
+}
+
+// FindByID retrieves a portal page with its links by ID
+func (r *InMemoryPortalPageRepository) FindByID(ctx context.Context, id int) (*domain.PortalPage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	portalPage, exists := r.portalPages[id]
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+
+	return copyPortalPage(portalPage, true), nil
+}
+
+// Reset clears all data (useful for testing)
+func (r *InMemoryPortalPageRepository) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.portalPages = make(map[int]*domain.PortalPage)
+	r.slugs = make(map[string]int)
+	r.linkOwners = make(map[int]int)
+	r.nextID = 1
+	r.nextLinkID = 1
+}
+
+// store saves the copy and updates the slug and link indexes; the caller must hold the lock
+func (r *InMemoryPortalPageRepository) store(portalPage *domain.PortalPage) {
+	r.portalPages[portalPage.ID] = portalPage
+	r.slugs[portalPage.Slug] = portalPage.ID
+	for _, link := range portalPage.Links() {
+		r.linkOwners[link.ID] = portalPage.ID
+	}
+}
+
+// restore puts back previous (removing the portal page when nil) if the portal page is still the
+// version written by the transaction; a later write made outside the transaction wins.
+// Link IDs are not handed out again. The caller must hold the lock.
+func (r *InMemoryPortalPageRepository) restore(id int, written, previous *domain.PortalPage) {
+	if r.portalPages[id] != written {
+		return
+	}
+
+	if r.slugs[written.Slug] == id {
+		delete(r.slugs, written.Slug)
+	}
+	for _, link := range written.Links() {
+		if r.linkOwners[link.ID] == id {
+			delete(r.linkOwners, link.ID)
+		}
+	}
+	if previous == nil {
+		delete(r.portalPages, id)
+		return
+	}
+
+	r.portalPages[id] = previous
+	if _, taken := r.slugs[previous.Slug]; !taken {
+		r.slugs[previous.Slug] = id
+	}
+	for _, link := range previous.Links() {
+		if _, owned := r.linkOwners[link.ID]; !owned {
+			r.linkOwners[link.ID] = id
+		}
+	}
+}
+
+// assignLinkID hands out the next link ID; the caller must hold the lock
+func (r *InMemoryPortalPageRepository) assignLinkID(link domain.Link) (int, error) {
+	id := r.nextLinkID
+	r.nextLinkID++
+	return id, nil
+}
+
+// copyPortalPage rebuilds the aggregate so callers cannot mutate the stored state.
+// The stored state was validated when it was saved, so it is reconstituted without validation.
+func copyPortalPage(portalPage *domain.PortalPage, withLinks bool) *domain.PortalPage {
+	params := domain.PortalPageParams{
+		ID:              portalPage.ID,
+		UserID:          portalPage.UserID,
+		Slug:            portalPage.Slug,
+		Title:           portalPage.Title,
+		Bio:             portalPage.Bio,
+		ProfileImageURL: portalPage.ProfileImageURL,
+		Theme:           portalPage.Theme,
+		CreatedAt:       portalPage.CreatedAt,
+		UpdatedAt:       portalPage.UpdatedAt,
+	}
+	if withLinks {
+		for _, link := range portalPage.Links() {
+			params.Links = append(params.Links, domain.LinkParams(*link))
+		}
+	}
+	return domain.ReconstitutePortalPage(params)
 }
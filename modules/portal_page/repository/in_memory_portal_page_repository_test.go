@@ -0,0 +1,338 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"portal_link/modules/portal_page/domain"
+	"portal_link/modules/portal_page/repository/repositorytest"
+	"portal_link/pkg/transaction"
+	"sync"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPortalPage(t *testing.T, userID int, slug string, links ...domain.LinkParams) *domain.PortalPage {
+	t.Helper()
+	portalPage, err := domain.NewPortalPage(domain.PortalPageParams{
+		UserID: userID,
+		Slug:   slug,
+		Title:  "Title of " + slug,
+		Links:  links,
+	})
+	require.NoError(t, err)
+	return portalPage
+}
+
+func TestInMemoryPortalPageRepository_Conformance(t *testing.T) {
+	repositorytest.RunPortalPageRepositorySuite(t, func(t *testing.T) repositorytest.Fixture {
+		nextUserID := 0
+		return repositorytest.Fixture{
+			Repository: NewInMemoryPortalPageRepository(),
+			NewUserID: func(t *testing.T) int {
+				nextUserID++
+				return nextUserID
+			},
+		}
+	})
+}
+
+func TestInMemoryPortalPageRepository_Create(t *testing.T) {
+	repo := NewInMemoryPortalPageRepository()
+	ctx := context.Background()
+
+	t.Run("assigns page and link IDs", func(t *testing.T) {
+		repo.Reset()
+
+		portalPage := newTestPortalPage(t, 1, "john-doe",
+			domain.LinkParams{Title: "Blog", URL: "https://blog.example.com", DisplayOrder: 1},
+			domain.LinkParams{Title: "Shop", URL: "https://shop.example.com", DisplayOrder: 2},
+		)
+		require.NoError(t, repo.Create(ctx, portalPage))
+		assert.Equal(t, 1, portalPage.ID)
+
+		links := portalPage.Links()
+		require.Len(t, links, 2)
+		assert.Equal(t, 1, links[0].ID)
+		assert.Equal(t, 2, links[1].ID)
+		assert.Equal(t, portalPage.ID, links[0].PortalPageID)
+	})
+
+	t.Run("returns error when slug exists", func(t *testing.T) {
+		repo.Reset()
+
+		require.NoError(t, repo.Create(ctx, newTestPortalPage(t, 1, "john-doe")))
+		err := repo.Create(ctx, newTestPortalPage(t, 2, "john-doe"))
+		assert.True(t, errors.Is(err, domain.ErrSlugExists))
+	})
+
+	t.Run("rejects links that already have an ID", func(t *testing.T) {
+		repo.Reset()
+
+		portalPage := newTestPortalPage(t, 1, "john-doe",
+			domain.LinkParams{ID: 42, Title: "Blog", URL: "https://blog.example.com", DisplayOrder: 1},
+		)
+		err := repo.Create(ctx, portalPage)
+		assert.True(t, errors.Is(err, domain.ErrLinkNotFound))
+
+		_, err = repo.FindBySlug(ctx, "john-doe")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
+
+func TestInMemoryPortalPageRepository_Update(t *testing.T) {
+	repo := NewInMemoryPortalPageRepository()
+	ctx := context.Background()
+
+	t.Run("upserts links and deletes missing links", func(t *testing.T) {
+		repo.Reset()
+
+		portalPage := newTestPortalPage(t, 1, "john-doe",
+			domain.LinkParams{Title: "Blog", URL: "https://blog.example.com", DisplayOrder: 1},
+			domain.LinkParams{Title: "Shop", URL: "https://shop.example.com", DisplayOrder: 2},
+		)
+		require.NoError(t, repo.Create(ctx, portalPage))
+		blog := portalPage.Links()[0]
+
+		require.NoError(t, portalPage.ReplaceLinks([]domain.LinkParams{
+			{Title: "Video", URL: "https://video.example.com", DisplayOrder: 1},
+			{ID: blog.ID, Title: "My Blog", URL: blog.URL, DisplayOrder: 2},
+		}))
+		require.NoError(t, repo.Update(ctx, portalPage))
+
+		stored, err := repo.FindByID(ctx, portalPage.ID)
+		require.NoError(t, err)
+		links := stored.Links()
+		require.Len(t, links, 2)
+		assert.Equal(t, "Video", links[0].Title)
+		assert.Equal(t, 3, links[0].ID)
+		assert.Equal(t, blog.ID, links[1].ID)
+		assert.Equal(t, "My Blog", links[1].Title)
+	})
+
+	t.Run("rejects links of another portal page", func(t *testing.T) {
+		repo.Reset()
+
+		other := newTestPortalPage(t, 2, "jane-doe",
+			domain.LinkParams{Title: "Blog", URL: "https://blog.example.com", DisplayOrder: 1},
+		)
+		require.NoError(t, repo.Create(ctx, other))
+		otherLinkID := other.Links()[0].ID
+
+		portalPage := newTestPortalPage(t, 1, "john-doe")
+		require.NoError(t, repo.Create(ctx, portalPage))
+
+		// Rebuild the aggregate with a link ID taken from the other page
+		forged := newTestPortalPage(t, 1, "john-doe",
+			domain.LinkParams{ID: otherLinkID, Title: "Stolen", URL: "https://blog.example.com", DisplayOrder: 1},
+		)
+		forged.ID = portalPage.ID
+		err := repo.Update(ctx, forged)
+		assert.True(t, errors.Is(err, domain.ErrLinkNotFound))
+
+		stored, err := repo.FindByID(ctx, other.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Blog", stored.Links()[0].Title)
+	})
+
+	t.Run("changes slug and frees the old one", func(t *testing.T) {
+		repo.Reset()
+
+		portalPage := newTestPortalPage(t, 1, "john-doe")
+		require.NoError(t, repo.Create(ctx, portalPage))
+		require.NoError(t, repo.Create(ctx, newTestPortalPage(t, 2, "jane-doe")))
+
+		require.NoError(t, portalPage.ChangeSlug("jane-doe"))
+		assert.True(t, errors.Is(repo.Update(ctx, portalPage), domain.ErrSlugExists))
+
+		require.NoError(t, portalPage.ChangeSlug("johnny"))
+		require.NoError(t, repo.Update(ctx, portalPage))
+
+		_, err := repo.FindBySlug(ctx, "john-doe")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		require.NoError(t, repo.Create(ctx, newTestPortalPage(t, 3, "john-doe")))
+	})
+
+	t.Run("returns error when portal page not found", func(t *testing.T) {
+		repo.Reset()
+
+		portalPage := newTestPortalPage(t, 1, "john-doe")
+		portalPage.ID = 999
+		assert.ErrorIs(t, repo.Update(ctx, portalPage), sql.ErrNoRows)
+	})
+}
+
+func TestInMemoryPortalPageRepository_ReturnsCopies(t *testing.T) {
+	repo := NewInMemoryPortalPageRepository()
+	ctx := context.Background()
+
+	portalPage := newTestPortalPage(t, 1, "john-doe",
+		domain.LinkParams{Title: "Blog", URL: "https://blog.example.com", DisplayOrder: 1},
+	)
+	require.NoError(t, repo.Create(ctx, portalPage))
+
+	// Mutating the created aggregate must not change the stored state
+	require.NoError(t, portalPage.ChangeTitle("Changed"))
+
+	retrieved, err := repo.FindByID(ctx, portalPage.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Title of john-doe", retrieved.Title)
+
+	// Mutating a retrieved aggregate must not change the stored state either
+	retrieved.Title = "Changed again"
+	require.NoError(t, retrieved.RemoveLink(retrieved.Links()[0].ID))
+
+	again, err := repo.FindBySlug(ctx, "john-doe")
+	require.NoError(t, err)
+	assert.Equal(t, "Title of john-doe", again.Title)
+	assert.Len(t, again.Links(), 1)
+}
+
+func TestInMemoryPortalPageRepository_ReadsDataFailingCurrentValidation(t *testing.T) {
+	repo := NewInMemoryPortalPageRepository()
+	ctx := context.Background()
+
+	// Data saved before a validation rule was tightened must stay readable
+	legacy := domain.ReconstitutePortalPage(domain.PortalPageParams{
+		UserID: 1,
+		Slug:   "ab",
+		Title:  "Legacy",
+		Links: []domain.LinkParams{
+			{Title: "Old", URL: "ftp://old.example.com", DisplayOrder: 1},
+		},
+	})
+	require.NoError(t, repo.Create(ctx, legacy))
+
+	bySlug, err := repo.FindBySlug(ctx, "ab")
+	require.NoError(t, err)
+	require.Len(t, bySlug.Links(), 1)
+	assert.Equal(t, "ftp://old.example.com", bySlug.Links()[0].URL)
+
+	byID, err := repo.FindByID(ctx, legacy.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "ab", byID.Slug)
+
+	portalPages, err := repo.ListByUserID(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, portalPages, 1)
+	assert.Equal(t, "ab", portalPages[0].Slug)
+}
+
+func TestInMemoryPortalPageRepository_ListByUserID(t *testing.T) {
+	repo := NewInMemoryPortalPageRepository()
+	ctx := context.Background()
+
+	require.NoError(t, repo.Create(ctx, newTestPortalPage(t, 1, "first-page",
+		domain.LinkParams{Title: "Blog", URL: "https://blog.example.com", DisplayOrder: 1},
+	)))
+	require.NoError(t, repo.Create(ctx, newTestPortalPage(t, 2, "other-page")))
+	require.NoError(t, repo.Create(ctx, newTestPortalPage(t, 1, "second-page")))
+
+	portalPages, err := repo.ListByUserID(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, portalPages, 2)
+	assert.Equal(t, "first-page", portalPages[0].Slug)
+	assert.Equal(t, "second-page", portalPages[1].Slug)
+	assert.Empty(t, portalPages[0].Links())
+
+	none, err := repo.ListByUserID(ctx, 3)
+	require.NoError(t, err)
+	assert.NotNil(t, none)
+	assert.Empty(t, none)
+}
+
+func TestInMemoryPortalPageRepository_ConcurrentCreate(t *testing.T) {
+	repo := NewInMemoryPortalPageRepository()
+	ctx := context.Background()
+
+	const workers = 20
+	portalPages := make([]*domain.PortalPage, workers)
+	for i := range portalPages {
+		// Every other worker races for the same slug
+		slug := "shared-slug"
+		if i%2 == 0 {
+			slug = fmt.Sprintf("page-%d", i)
+		}
+		portalPages[i] = newTestPortalPage(t, i+1, slug)
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		created int
+	)
+	for _, portalPage := range portalPages {
+		wg.Add(1)
+		go func(portalPage *domain.PortalPage) {
+			defer wg.Done()
+			if err := repo.Create(ctx, portalPage); err == nil {
+				mu.Lock()
+				created++
+				mu.Unlock()
+			}
+		}(portalPage)
+	}
+	wg.Wait()
+
+	assert.Equal(t, workers/2+1, created)
+}
+
+func TestInMemoryPortalPageRepository_Rollback(t *testing.T) {
+	repo := NewInMemoryPortalPageRepository()
+	txManager := transaction.NewInMemoryTxManager()
+	ctx := context.Background()
+	boom := errors.New("boom")
+
+	existing := newTestPortalPage(t, 1, "john-doe",
+		domain.LinkParams{Title: "Blog", URL: "https://blog.example.com", DisplayOrder: 1},
+	)
+	require.NoError(t, repo.Create(ctx, existing))
+
+	var outside *domain.PortalPage
+	err := txManager.WithinTx(ctx, func(txCtx context.Context) error {
+		require.NoError(t, repo.Create(txCtx, newTestPortalPage(t, 1, "inside")))
+
+		updated, err := repo.FindByID(txCtx, existing.ID)
+		require.NoError(t, err)
+		updated.Slug = "jane-doe"
+		require.NoError(t, updated.ReplaceLinks([]domain.LinkParams{
+			{Title: "Shop", URL: "https://shop.example.com", DisplayOrder: 1},
+		}))
+		require.NoError(t, repo.Update(txCtx, updated))
+
+		// 交易期間在交易外建立的頁面
+		outside = newTestPortalPage(t, 2, "outside",
+			domain.LinkParams{Title: "Video", URL: "https://video.example.com", DisplayOrder: 1},
+		)
+		require.NoError(t, repo.Create(ctx, outside))
+		return boom
+	})
+	assert.ErrorIs(t, err, boom)
+
+	_, err = repo.FindBySlug(ctx, "inside")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = repo.FindBySlug(ctx, "jane-doe")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	restored, err := repo.FindBySlug(ctx, "john-doe")
+	require.NoError(t, err)
+	require.Len(t, restored.Links(), 1)
+	assert.Equal(t, existing.Links()[0].ID, restored.Links()[0].ID)
+
+	// 恢復後的連結仍可更新
+	require.NoError(t, repo.Update(ctx, restored))
+
+	// 交易外的寫入不受回滾影響
+	stored, err := repo.FindBySlug(ctx, "outside")
+	require.NoError(t, err)
+	assert.Equal(t, outside.ID, stored.ID)
+	require.Len(t, stored.Links(), 1)
+	assert.Equal(t, outside.Links()[0].ID, stored.Links()[0].ID)
+
+	// 回滾不會重複使用已分配的 ID
+	next := newTestPortalPage(t, 2, "next")
+	require.NoError(t, repo.Create(ctx, next))
+	assert.Greater(t, next.ID, outside.ID)
+}
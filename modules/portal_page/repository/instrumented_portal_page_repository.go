@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"portal_link/modules/portal_page/domain"
+	"portal_link/pkg/metrics"
+	"portal_link/pkg/tracing"
+	"time"
+)
+
+var _ domain.PortalPageRepository = (*InstrumentedPortalPageRepository)(nil)
+
+// InstrumentedPortalPageRepository wraps any PortalPageRepository and reports the latency
+// and result of every call to a metrics.RepositoryObserver, tracing each call as a span.
+type InstrumentedPortalPageRepository struct {
+	next     domain.PortalPageRepository
+	observer metrics.RepositoryObserver
+}
+
+// NewInstrumentedPortalPageRepository creates a decorator reporting the calls of next to observer
+func NewInstrumentedPortalPageRepository(next domain.PortalPageRepository, observer metrics.RepositoryObserver) *InstrumentedPortalPageRepository {
+	return &InstrumentedPortalPageRepository{next: next, observer: observer}
+}
+
+// Create creates a portal page with its links
+func (r *InstrumentedPortalPageRepository) Create(ctx context.Context, portalPage *domain.PortalPage) error {
+	ctx, finish := r.start(ctx, "Create")
+	err := r.next.Create(ctx, portalPage)
+	finish(err)
+	return err
+}
+
+// Update updates a portal page and replaces its links
+func (r *InstrumentedPortalPageRepository) Update(ctx context.Context, portalPage *domain.PortalPage) error {
+	ctx, finish := r.start(ctx, "Update")
+	err := r.next.Update(ctx, portalPage)
+	finish(err)
+	return err
+}
+
+// FindBySlug finds a portal page with its links by slug
+func (r *InstrumentedPortalPageRepository) FindBySlug(ctx context.Context, slug string) (*domain.PortalPage, error) {
+	ctx, finish := r.start(ctx, "FindBySlug")
+	portalPage, err := r.next.FindBySlug(ctx, slug)
+	finish(err)
+	return portalPage, err
+}
+
+// ListByUserID lists the portal pages of a user without links
+func (r *InstrumentedPortalPageRepository) ListByUserID(ctx context.Context, userID int) ([]*domain.PortalPage, error) {
+	ctx, finish := r.start(ctx, "ListByUserID")
+	portalPages, err := r.next.ListByUserID(ctx, userID)
+	finish(err)
+	return portalPages, err
+}
+
+// FindByID finds a portal page with its links by ID
+func (r *InstrumentedPortalPageRepository) FindByID(ctx context.Context, id int) (*domain.PortalPage, error) {
+	ctx, finish := r.start(ctx, "FindByID")
+	portalPage, err := r.next.FindByID(ctx, id)
+	finish(err)
+	return portalPage, err
+}
+
+// start starts a PortalPageRepository.<method> span; the returned finish ends it and reports the call
+func (r *InstrumentedPortalPageRepository) start(ctx context.Context, method string) (context.Context, func(err error)) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "PortalPageRepository."+method)
+	return ctx, func(err error) {
+		r.observer.ObserveRepositoryCall("portal_page", method, time.Since(start), err)
+		// a missing row is a regular lookup result, not a span error
+		if errors.Is(err, sql.ErrNoRows) {
+			err = nil
+		}
+		tracing.End(span, err)
+	}
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"portal_link/modules/portal_page/repository/repositorytest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeObserver records the methods reported by an instrumented repository
+type fakeObserver struct {
+	mu      sync.Mutex
+	methods []string
+	errs    []error
+}
+
+func (o *fakeObserver) ObserveRepositoryCall(repository, method string, duration time.Duration, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.methods = append(o.methods, repository+"."+method)
+	o.errs = append(o.errs, err)
+}
+
+func TestInstrumentedPortalPageRepository_Conformance(t *testing.T) {
+	repositorytest.RunPortalPageRepositorySuite(t, func(t *testing.T) repositorytest.Fixture {
+		nextUserID := 0
+		return repositorytest.Fixture{
+			Repository: NewInstrumentedPortalPageRepository(NewInMemoryPortalPageRepository(), &fakeObserver{}),
+			NewUserID: func(t *testing.T) int {
+				nextUserID++
+				return nextUserID
+			},
+		}
+	})
+}
+
+func TestInstrumentedPortalPageRepository_ReportsCalls(t *testing.T) {
+	ctx := context.Background()
+	observer := &fakeObserver{}
+	repo := NewInstrumentedPortalPageRepository(NewInMemoryPortalPageRepository(), observer)
+
+	portalPage := newTestPortalPage(t, 1, "my-page")
+	require.NoError(t, repo.Create(ctx, portalPage))
+	require.NoError(t, repo.Update(ctx, portalPage))
+	_, err := repo.FindByID(ctx, portalPage.ID)
+	require.NoError(t, err)
+	_, err = repo.FindBySlug(ctx, "missing")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = repo.ListByUserID(ctx, 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"portal_page.Create",
+		"portal_page.Update",
+		"portal_page.FindByID",
+		"portal_page.FindBySlug",
+		"portal_page.ListByUserID",
+	}, observer.methods)
+	assert.Equal(t, []error{nil, nil, nil, sql.ErrNoRows, nil}, observer.errs)
+}
@@ -0,0 +1,342 @@
+// Package repositorytest 提供 PortalPageRepository 的共用一致性測試，每一種實作都應執行
+package repositorytest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"portal_link/modules/portal_page/domain"
+	"sync"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Fixture 一個空的 PortalPageRepository 與建立 Portal Page 擁有者的方法
+type Fixture struct {
+	Repository domain.PortalPageRepository
+	// NewUserID 建立一個使用者並返回其 ID；有外鍵限制的實作需要實際寫入使用者
+	NewUserID func(t *testing.T) int
+}
+
+// PortalPageRepositoryFactory 為每個子測試建立一個新的 Fixture
+type PortalPageRepositoryFactory func(t *testing.T) Fixture
+
+// RunPortalPageRepositorySuite 對 newFixture 建立的 repository 執行 PortalPageRepository 的介面契約測試
+func RunPortalPageRepositorySuite(t *testing.T, newFixture PortalPageRepositoryFactory) {
+	ctx := context.Background()
+
+	t.Run("creates portal page and assigns page and link IDs", func(t *testing.T) {
+		f := newFixture(t)
+		userID := f.NewUserID(t)
+
+		portalPage := newPortalPage(t, userID, "john-doe",
+			domain.LinkParams{Title: "Blog", URL: "https://blog.example.com", DisplayOrder: 1},
+			domain.LinkParams{Title: "Shop", URL: "https://shop.example.com", DisplayOrder: 2},
+		)
+		require.NoError(t, f.Repository.Create(ctx, portalPage))
+		assert.Positive(t, portalPage.ID)
+
+		links := portalPage.Links()
+		require.Len(t, links, 2)
+		assert.Positive(t, links[0].ID)
+		assert.Positive(t, links[1].ID)
+		assert.NotEqual(t, links[0].ID, links[1].ID)
+		assert.Equal(t, portalPage.ID, links[0].PortalPageID)
+
+		stored, err := f.Repository.FindByID(ctx, portalPage.ID)
+		require.NoError(t, err)
+		assert.Equal(t, userID, stored.UserID)
+		assert.Equal(t, "john-doe", stored.Slug)
+		assert.Equal(t, "Title of john-doe", stored.Title)
+		assert.Equal(t, domain.ThemeLight, stored.Theme)
+		assert.Equal(t, linkIDs(portalPage), linkIDs(stored))
+	})
+
+	t.Run("rejects links that already have an ID on create", func(t *testing.T) {
+		f := newFixture(t)
+
+		portalPage := newPortalPage(t, f.NewUserID(t), "john-doe",
+			domain.LinkParams{ID: 42, Title: "Blog", URL: "https://blog.example.com", DisplayOrder: 1},
+		)
+		assert.True(t, errors.Is(f.Repository.Create(ctx, portalPage), domain.ErrLinkNotFound))
+
+		_, err := f.Repository.FindBySlug(ctx, "john-doe")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("orders links by display order", func(t *testing.T) {
+		f := newFixture(t)
+
+		portalPage := newPortalPage(t, f.NewUserID(t), "john-doe",
+			domain.LinkParams{Title: "Third", URL: "https://third.example.com", DisplayOrder: 3},
+			domain.LinkParams{Title: "First", URL: "https://first.example.com", DisplayOrder: 1},
+			domain.LinkParams{Title: "Second", URL: "https://second.example.com", DisplayOrder: 2},
+		)
+		require.NoError(t, f.Repository.Create(ctx, portalPage))
+
+		byID, err := f.Repository.FindByID(ctx, portalPage.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"First", "Second", "Third"}, linkTitles(byID))
+
+		bySlug, err := f.Repository.FindBySlug(ctx, "john-doe")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"First", "Second", "Third"}, linkTitles(bySlug))
+	})
+
+	t.Run("returns ErrSlugExists for duplicate slug", func(t *testing.T) {
+		f := newFixture(t)
+		userID := f.NewUserID(t)
+
+		require.NoError(t, f.Repository.Create(ctx, newPortalPage(t, userID, "john-doe")))
+		err := f.Repository.Create(ctx, newPortalPage(t, f.NewUserID(t), "john-doe"))
+		assert.True(t, errors.Is(err, domain.ErrSlugExists))
+
+		other := newPortalPage(t, userID, "other-page")
+		require.NoError(t, f.Repository.Create(ctx, other))
+		require.NoError(t, other.ChangeSlug("john-doe"))
+		assert.True(t, errors.Is(f.Repository.Update(ctx, other), domain.ErrSlugExists))
+
+		stored, err := f.Repository.FindByID(ctx, other.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "other-page", stored.Slug)
+	})
+
+	t.Run("returns sql.ErrNoRows when not found", func(t *testing.T) {
+		f := newFixture(t)
+
+		_, err := f.Repository.FindByID(ctx, 999999)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+
+		_, err = f.Repository.FindBySlug(ctx, "nobody")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+
+		missing := newPortalPage(t, f.NewUserID(t), "missing-page")
+		missing.ID = 999999
+		assert.ErrorIs(t, f.Repository.Update(ctx, missing), sql.ErrNoRows)
+	})
+
+	t.Run("updates fields, upserts links and deletes stale links", func(t *testing.T) {
+		f := newFixture(t)
+
+		created := newPortalPage(t, f.NewUserID(t), "john-doe",
+			domain.LinkParams{Title: "Blog", URL: "https://blog.example.com", DisplayOrder: 1},
+			domain.LinkParams{Title: "Shop", URL: "https://shop.example.com", DisplayOrder: 2},
+		)
+		require.NoError(t, f.Repository.Create(ctx, created))
+
+		portalPage, err := f.Repository.FindByID(ctx, created.ID)
+		require.NoError(t, err)
+		blog, shop := portalPage.Links()[0], portalPage.Links()[1]
+
+		require.NoError(t, portalPage.ChangeTitle("John's links"))
+		require.NoError(t, portalPage.ReplaceLinks([]domain.LinkParams{
+			{Title: "Video", URL: "https://video.example.com", DisplayOrder: 1},
+			{ID: blog.ID, Title: "My Blog", URL: blog.URL, DisplayOrder: 2},
+		}))
+		require.NoError(t, f.Repository.Update(ctx, portalPage))
+
+		stored, err := f.Repository.FindByID(ctx, portalPage.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "John's links", stored.Title)
+		assert.Equal(t, []string{"Video", "My Blog"}, linkTitles(stored))
+
+		links := stored.Links()
+		assert.Positive(t, links[0].ID)
+		assert.NotEqual(t, shop.ID, links[0].ID)
+		assert.Equal(t, blog.ID, links[1].ID)
+
+		// 已刪除的 link 不能再被引用
+		forged := rebuildWithLinks(t, stored,
+			domain.LinkParams{ID: shop.ID, Title: "Shop", URL: shop.URL, DisplayOrder: 1},
+		)
+		assert.True(t, errors.Is(f.Repository.Update(ctx, forged), domain.ErrLinkNotFound))
+	})
+
+	t.Run("rejects links of another portal page", func(t *testing.T) {
+		f := newFixture(t)
+
+		other := newPortalPage(t, f.NewUserID(t), "jane-doe",
+			domain.LinkParams{Title: "Blog", URL: "https://blog.example.com", DisplayOrder: 1},
+		)
+		require.NoError(t, f.Repository.Create(ctx, other))
+
+		portalPage := newPortalPage(t, f.NewUserID(t), "john-doe")
+		require.NoError(t, f.Repository.Create(ctx, portalPage))
+		forged := rebuildWithLinks(t, portalPage,
+			domain.LinkParams{ID: other.Links()[0].ID, Title: "Stolen", URL: "https://blog.example.com", DisplayOrder: 1},
+		)
+		assert.True(t, errors.Is(f.Repository.Update(ctx, forged), domain.ErrLinkNotFound))
+
+		stored, err := f.Repository.FindByID(ctx, other.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Blog"}, linkTitles(stored))
+	})
+
+	t.Run("changes slug and frees the old one", func(t *testing.T) {
+		f := newFixture(t)
+		userID := f.NewUserID(t)
+
+		portalPage := newPortalPage(t, userID, "john-doe")
+		require.NoError(t, f.Repository.Create(ctx, portalPage))
+		require.NoError(t, portalPage.ChangeSlug("johnny"))
+		require.NoError(t, f.Repository.Update(ctx, portalPage))
+
+		_, err := f.Repository.FindBySlug(ctx, "john-doe")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		stored, err := f.Repository.FindBySlug(ctx, "johnny")
+		require.NoError(t, err)
+		assert.Equal(t, portalPage.ID, stored.ID)
+
+		require.NoError(t, f.Repository.Create(ctx, newPortalPage(t, userID, "john-doe")))
+	})
+
+	t.Run("returns copies that do not change the stored state", func(t *testing.T) {
+		f := newFixture(t)
+
+		portalPage := newPortalPage(t, f.NewUserID(t), "john-doe",
+			domain.LinkParams{Title: "Blog", URL: "https://blog.example.com", DisplayOrder: 1},
+		)
+		require.NoError(t, f.Repository.Create(ctx, portalPage))
+		require.NoError(t, portalPage.ChangeTitle("Changed"))
+
+		retrieved, err := f.Repository.FindByID(ctx, portalPage.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Title of john-doe", retrieved.Title)
+
+		require.NoError(t, retrieved.RemoveLink(retrieved.Links()[0].ID))
+		again, err := f.Repository.FindBySlug(ctx, "john-doe")
+		require.NoError(t, err)
+		assert.Len(t, again.Links(), 1)
+	})
+
+	t.Run("lists the user's portal pages without links", func(t *testing.T) {
+		f := newFixture(t)
+		userID, otherUserID := f.NewUserID(t), f.NewUserID(t)
+
+		require.NoError(t, f.Repository.Create(ctx, newPortalPage(t, userID, "first-page",
+			domain.LinkParams{Title: "Blog", URL: "https://blog.example.com", DisplayOrder: 1},
+		)))
+		require.NoError(t, f.Repository.Create(ctx, newPortalPage(t, otherUserID, "other-page")))
+		require.NoError(t, f.Repository.Create(ctx, newPortalPage(t, userID, "second-page")))
+
+		portalPages, err := f.Repository.ListByUserID(ctx, userID)
+		require.NoError(t, err)
+		require.Len(t, portalPages, 2)
+		assert.Equal(t, "first-page", portalPages[0].Slug)
+		assert.Equal(t, "second-page", portalPages[1].Slug)
+		assert.Empty(t, portalPages[0].Links())
+
+		none, err := f.Repository.ListByUserID(ctx, 999999)
+		require.NoError(t, err)
+		assert.NotNil(t, none)
+		assert.Empty(t, none)
+	})
+
+	t.Run("handles concurrent writers", func(t *testing.T) {
+		f := newFixture(t)
+
+		const workers = 20
+		portalPages := make([]*domain.PortalPage, workers)
+		for i := range portalPages {
+			// 一半的 worker 競爭同一個 slug
+			slug := "shared-slug"
+			if i%2 == 0 {
+				slug = fmt.Sprintf("page-%d", i)
+			}
+			portalPages[i] = newPortalPage(t, f.NewUserID(t), slug,
+				domain.LinkParams{Title: "Blog", URL: "https://blog.example.com", DisplayOrder: 1},
+			)
+		}
+
+		var (
+			wg      sync.WaitGroup
+			mu      sync.Mutex
+			created []*domain.PortalPage
+		)
+		for _, portalPage := range portalPages {
+			wg.Add(1)
+			go func(portalPage *domain.PortalPage) {
+				defer wg.Done()
+				err := f.Repository.Create(ctx, portalPage)
+				if err != nil {
+					assert.True(t, errors.Is(err, domain.ErrSlugExists), "unexpected error: %v", err)
+					return
+				}
+				mu.Lock()
+				created = append(created, portalPage)
+				mu.Unlock()
+			}(portalPage)
+		}
+		wg.Wait()
+		require.Len(t, created, workers/2+1)
+
+		// 同時更新不同的 Portal Page 不會互相影響
+		for _, portalPage := range created {
+			wg.Add(1)
+			go func(portalPage *domain.PortalPage) {
+				defer wg.Done()
+				if !assert.NoError(t, portalPage.ReplaceLinks([]domain.LinkParams{
+					{Title: "Video", URL: "https://video.example.com", DisplayOrder: 1},
+					{Title: "Shop", URL: "https://shop.example.com", DisplayOrder: 2},
+				})) {
+					return
+				}
+				assert.NoError(t, f.Repository.Update(ctx, portalPage))
+			}(portalPage)
+		}
+		wg.Wait()
+
+		for _, portalPage := range created {
+			stored, err := f.Repository.FindByID(ctx, portalPage.ID)
+			require.NoError(t, err)
+			assert.Equal(t, portalPage.Slug, stored.Slug)
+			assert.Equal(t, []string{"Video", "Shop"}, linkTitles(stored))
+		}
+	})
+}
+
+func newPortalPage(t *testing.T, userID int, slug string, links ...domain.LinkParams) *domain.PortalPage {
+	t.Helper()
+	portalPage, err := domain.NewPortalPage(domain.PortalPageParams{
+		UserID: userID,
+		Slug:   slug,
+		Title:  "Title of " + slug,
+		Links:  links,
+	})
+	require.NoError(t, err)
+	return portalPage
+}
+
+// rebuildWithLinks 以任意 link ID 重建聚合，模擬引用不屬於此 Portal Page 的 link
+// ReplaceLinks 會在領域層拒絕這種 link，這裡用來確認 repository 本身也會拒絕
+func rebuildWithLinks(t *testing.T, portalPage *domain.PortalPage, links ...domain.LinkParams) *domain.PortalPage {
+	t.Helper()
+	rebuilt, err := domain.NewPortalPage(domain.PortalPageParams{
+		ID:     portalPage.ID,
+		UserID: portalPage.UserID,
+		Slug:   portalPage.Slug,
+		Title:  portalPage.Title,
+		Links:  links,
+	})
+	require.NoError(t, err)
+	return rebuilt
+}
+
+func linkTitles(portalPage *domain.PortalPage) []string {
+	titles := []string{}
+	for _, link := range portalPage.Links() {
+		titles = append(titles, link.Title)
+	}
+	return titles
+}
+
+func linkIDs(portalPage *domain.PortalPage) []int {
+	ids := []int{}
+	for _, link := range portalPage.Links() {
+		ids = append(ids, link.ID)
+	}
+	return ids
+}
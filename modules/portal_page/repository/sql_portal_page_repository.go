@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"portal_link/modules/portal_page/domain"
+	"portal_link/pkg/database"
+)
+
+var _ domain.PortalPageRepository = (*SQLPortalPageRepository)(nil)
+
+const selectPortalPageColumns = `
+	SELECT id, user_id, slug, title, COALESCE(bio, ''), COALESCE(profile_image_url, ''), COALESCE(theme, ''), created_at, updated_at
+	FROM portal_pages`
+
+// SQLPortalPageRepository is a database/sql implementation of PortalPageRepository shared by
+// every driver supported by pkg/database (PostgreSQL and SQLite).
+// A portal page and its links are always written in a single transaction, which joins the
+// use case transaction carried by ctx, if any.
+type SQLPortalPageRepository struct {
+	db *sql.DB
+}
+
+// NewSQLPortalPageRepository creates a new SQL portal page repository
+func NewSQLPortalPageRepository(db *sql.DB) *SQLPortalPageRepository {
+	return &SQLPortalPageRepository{db: db}
+}
+
+// Create creates a new portal page with its links, assigning IDs to the page and every link
+func (r *SQLPortalPageRepository) Create(ctx context.Context, portalPage *domain.PortalPage) error {
+	// A new portal page cannot reference links that are already stored
+	for _, link := range portalPage.Links() {
+		if link.ID != 0 {
+			return domain.ErrLinkNotFound
+		}
+	}
+
+	return database.WithTx(ctx, r.db, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO portal_pages (user_id, slug, title, bio, profile_image_url, theme, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING id`,
+			portalPage.UserID, portalPage.Slug, portalPage.Title, portalPage.Bio, portalPage.ProfileImageURL,
+			string(portalPage.Theme), portalPage.CreatedAt.UTC(), portalPage.UpdatedAt.UTC(),
+		).Scan(&portalPage.ID)
+		if err != nil {
+			return mapPortalPageError(err)
+		}
+
+		return portalPage.AssignLinkIDs(func(link domain.Link) (int, error) {
+			return insertLink(ctx, tx, link)
+		})
+	})
+}
+
+// Update updates an existing portal page. Links with an ID are updated, links without an
+// ID are inserted, and stored links missing from the portal page are deleted.
+func (r *SQLPortalPageRepository) Update(ctx context.Context, portalPage *domain.PortalPage) error {
+	return database.WithTx(ctx, r.db, func(tx *sql.Tx) error {
+		// 1. 更新 Portal Page 的欄位
+		result, err := tx.ExecContext(ctx, `
+			UPDATE portal_pages
+			SET slug = $2, title = $3, bio = $4, profile_image_url = $5, theme = $6, updated_at = $7
+			WHERE id = $1`,
+			portalPage.ID, portalPage.Slug, portalPage.Title, portalPage.Bio, portalPage.ProfileImageURL,
+			string(portalPage.Theme), portalPage.UpdatedAt.UTC(),
+		)
+		if err != nil {
+			return mapPortalPageError(err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return sql.ErrNoRows
+		}
+
+		// 2. 查詢現有的 Link ID
+		existing, err := linkIDs(ctx, tx, portalPage.ID)
+		if err != nil {
+			return err
+		}
+
+		// 3. 更新既有的 Links，Link 必須屬於此 Portal Page
+		for _, link := range portalPage.Links() {
+			if link.ID == 0 {
+				continue
+			}
+			if _, exists := existing[link.ID]; !exists {
+				return domain.ErrLinkNotFound
+			}
+			delete(existing, link.ID)
+
+			_, err := tx.ExecContext(ctx, `
+				UPDATE links
+				SET title = $2, url = $3, description = $4, icon_url = $5, display_order = $6, updated_at = $7
+				WHERE id = $1`,
+				link.ID, link.Title, link.URL, link.Description, link.IconURL, link.DisplayOrder, link.UpdatedAt.UTC(),
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		// 4. 刪除不存在於新的 Links 中的舊 Links
+		for id := range existing {
+			if _, err := tx.ExecContext(ctx, `DELETE FROM links WHERE id = $1`, id); err != nil {
+				return err
+			}
+		}
+
+		// 5. 新增沒有 ID 的 Links
+		return portalPage.AssignLinkIDs(func(link domain.Link) (int, error) {
+			return insertLink(ctx, tx, link)
+		})
+	})
+}
+
+// FindBySlug retrieves a portal page with its links by slug
+func (r *SQLPortalPageRepository) FindBySlug(ctx context.Context, slug string) (*domain.PortalPage, error) {
+	return r.findOne(ctx, selectPortalPageColumns+` WHERE slug = $1`, slug)
+}
+
+// ListByUserID retrieves every portal page owned by the user, without links, ordered by creation time
+func (r *SQLPortalPageRepository) ListByUserID(ctx context.Context, userID int) ([]*domain.PortalPage, error) {
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, selectPortalPageColumns+` WHERE user_id = $1 ORDER BY created_at, id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	portalPages := []*domain.PortalPage{}
+	for rows.Next() {
+		params, err := scanPortalPageParams(rows)
+		if err != nil {
+			return nil, err
+		}
+		portalPages = append(portalPages, domain.ReconstitutePortalPage(params))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return portalPages, nil
+}
+
+// FindByID retrieves a portal page with its links by ID
+func (r *SQLPortalPageRepository) FindByID(ctx context.Context, id int) (*domain.PortalPage, error) {
+	return r.findOne(ctx, selectPortalPageColumns+` WHERE id = $1`, id)
+}
+
+// findOne loads a single portal page and its links ordered by display_order.
+// Stored rows are reconstituted without validation, so rows saved under older rules stay readable.
+func (r *SQLPortalPageRepository) findOne(ctx context.Context, query string, arg any) (*domain.PortalPage, error) {
+	params, err := scanPortalPageParams(database.Conn(ctx, r.db).QueryRowContext(ctx, query, arg))
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, `
+		SELECT id, portal_page_id, title, url, COALESCE(description, ''), COALESCE(icon_url, ''), display_order, created_at, updated_at
+		FROM links
+		WHERE portal_page_id = $1
+		ORDER BY display_order, id`, params.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var link domain.LinkParams
+		err := rows.Scan(&link.ID, &link.PortalPageID, &link.Title, &link.URL, &link.Description, &link.IconURL,
+			&link.DisplayOrder, &link.CreatedAt, &link.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		link.CreatedAt = link.CreatedAt.UTC()
+		link.UpdatedAt = link.UpdatedAt.UTC()
+		params.Links = append(params.Links, link)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return domain.ReconstitutePortalPage(params), nil
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanPortalPageParams scans a portal_pages row; returns sql.ErrNoRows when there is no row
+func scanPortalPageParams(row rowScanner) (domain.PortalPageParams, error) {
+	var (
+		params domain.PortalPageParams
+		theme  string
+	)
+	err := row.Scan(&params.ID, &params.UserID, &params.Slug, &params.Title, &params.Bio, &params.ProfileImageURL,
+		&theme, &params.CreatedAt, &params.UpdatedAt)
+	if err != nil {
+		return domain.PortalPageParams{}, err
+	}
+
+	// Rows without a theme use the default theme
+	params.Theme = domain.Theme(theme)
+	if params.Theme == "" {
+		params.Theme = domain.DefaultTheme
+	}
+	params.CreatedAt = params.CreatedAt.UTC()
+	params.UpdatedAt = params.UpdatedAt.UTC()
+	return params, nil
+}
+
+// insertLink inserts a link and returns its new ID
+func insertLink(ctx context.Context, tx *sql.Tx, link domain.Link) (int, error) {
+	var id int
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO links (portal_page_id, title, url, description, icon_url, display_order, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id`,
+		link.PortalPageID, link.Title, link.URL, link.Description, link.IconURL, link.DisplayOrder,
+		link.CreatedAt.UTC(), link.UpdatedAt.UTC(),
+	).Scan(&id)
+	return id, err
+}
+
+// linkIDs returns the IDs of the links currently stored for the portal page
+func linkIDs(ctx context.Context, tx *sql.Tx, portalPageID int) (map[int]struct{}, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM links WHERE portal_page_id = $1`, portalPageID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[int]struct{})
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids[id] = struct{}{}
+	}
+	return ids, rows.Err()
+}
+
+// mapPortalPageError maps a unique violation to ErrSlugExists; slug is the only unique column besides the ID
+func mapPortalPageError(err error) error {
+	if database.IsUniqueViolation(err) {
+		return domain.ErrSlugExists
+	}
+	return err
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"portal_link/modules/portal_page/domain"
+	"portal_link/modules/portal_page/repository/repositorytest"
+	"portal_link/pkg/database/databasetest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLPortalPageRepository(t *testing.T) {
+	for _, driver := range databasetest.Drivers {
+		t.Run(string(driver), func(t *testing.T) {
+			repositorytest.RunPortalPageRepositorySuite(t, func(t *testing.T) repositorytest.Fixture {
+				db := databasetest.Open(t, driver)
+				var users atomic.Int64
+				return repositorytest.Fixture{
+					Repository: NewSQLPortalPageRepository(db),
+					NewUserID: func(t *testing.T) int {
+						return databasetest.InsertUser(t, db, fmt.Sprintf("user%d@example.com", users.Add(1)))
+					},
+				}
+			})
+
+			t.Run("deleting a user cascades to portal pages and links", func(t *testing.T) {
+				testSQLPortalPageCascade(t, databasetest.Open(t, driver))
+			})
+
+			t.Run("reads rows failing current validation", func(t *testing.T) {
+				testSQLPortalPageLegacyRows(t, databasetest.Open(t, driver))
+			})
+		})
+	}
+}
+
+func testSQLPortalPageCascade(t *testing.T, db *sql.DB) {
+	repo := NewSQLPortalPageRepository(db)
+	ctx := context.Background()
+
+	ownerID := databasetest.InsertUser(t, db, "cascade@example.com")
+	portalPage := newTestPortalPage(t, ownerID, "cascade-page",
+		domain.LinkParams{Title: "Blog", URL: "https://blog.example.com", DisplayOrder: 1},
+	)
+	require.NoError(t, repo.Create(ctx, portalPage))
+
+	_, err := db.Exec(`DELETE FROM users WHERE id = $1`, ownerID)
+	require.NoError(t, err)
+
+	_, err = repo.FindByID(ctx, portalPage.ID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM links WHERE portal_page_id = $1`, portalPage.ID).Scan(&count))
+	assert.Zero(t, count)
+}
+
+func testSQLPortalPageLegacyRows(t *testing.T, db *sql.DB) {
+	repo := NewSQLPortalPageRepository(db)
+	ctx := context.Background()
+
+	// Rows saved before a validation rule was tightened must stay readable
+	ownerID := databasetest.InsertUser(t, db, "legacy@example.com")
+	var portalPageID int
+	require.NoError(t, db.QueryRow(`
+		INSERT INTO portal_pages (user_id, slug, title) VALUES ($1, 'ab', 'Legacy') RETURNING id`, ownerID,
+	).Scan(&portalPageID))
+	_, err := db.Exec(`
+		INSERT INTO links (portal_page_id, title, url, display_order) VALUES ($1, 'Old', 'ftp://old.example.com', 1)`, portalPageID)
+	require.NoError(t, err)
+
+	bySlug, err := repo.FindBySlug(ctx, "ab")
+	require.NoError(t, err)
+	assert.Equal(t, domain.DefaultTheme, bySlug.Theme)
+	require.Len(t, bySlug.Links(), 1)
+	assert.Equal(t, "ftp://old.example.com", bySlug.Links()[0].URL)
+
+	byID, err := repo.FindByID(ctx, portalPageID)
+	require.NoError(t, err)
+	assert.Equal(t, "ab", byID.Slug)
+
+	portalPages, err := repo.ListByUserID(ctx, ownerID)
+	require.NoError(t, err)
+	require.Len(t, portalPages, 1)
+	assert.Equal(t, "ab", portalPages[0].Slug)
+}
@@ -2,24 +2,69 @@ package usecase
 
 import (
 	"context"
+	"portal_link/modules/portal_page/domain"
+	"portal_link/pkg/tracing"
+	"portal_link/pkg/validation"
 )
 
 // CreatePortalPageParams 建立 Portal Page 用例的輸入參數
 type CreatePortalPageParams struct {
+	UserID          int    `json:"-"`
+	Slug            string `json:"slug"`
+	Title           string `json:"title"`
+	Bio             string `json:"bio"`
+	ProfileImageURL string `json:"profile_image_url"`
+	Theme           string `json:"theme"`
 }
 
 // CreatePortalPageResult 建立 Portal Page 用例的輸出結果
 type CreatePortalPageResult struct {
+	ID int `json:"id"`
 }
 
 // CreatePortalPageUC 建立 Portal Page 用例
 type CreatePortalPageUC struct {
+	portalPageRepository domain.PortalPageRepository
 }
 
-func NewCreatePortalPageUC() *CreatePortalPageUC {
-	return &CreatePortalPageUC{}
+func NewCreatePortalPageUC(portalPageRepository domain.PortalPageRepository) *CreatePortalPageUC {
+	return &CreatePortalPageUC{
+		portalPageRepository: portalPageRepository,
+	}
 }
 
-func (c *CreatePortalPageUC) Execute(ctx context.Context, params *CreatePortalPageParams) (*CreatePortalPageResult, error) {
-	return nil, nil
+func (c *CreatePortalPageUC) Execute(ctx context.Context, params *CreatePortalPageParams) (_ *CreatePortalPageResult, err error) {
+	ctx, span := tracing.Start(ctx, "CreatePortalPageUC.Execute")
+	defer func() { tracing.End(span, err) }()
+
+	// 1~2. 驗證主題並建立 PortalPage 實體（由聚合根驗證各欄位），一起返回所有不合法的欄位
+	var v validation.Errors
+	theme, err := domain.ParseTheme(params.Theme)
+	if err = v.Merge("", err); err != nil {
+		return nil, err
+	}
+	portalPage, err := domain.NewPortalPage(domain.PortalPageParams{
+		UserID:          params.UserID,
+		Slug:            params.Slug,
+		Title:           params.Title,
+		Bio:             params.Bio,
+		ProfileImageURL: params.ProfileImageURL,
+		Theme:           theme,
+	})
+	if err = v.Merge("", err); err != nil {
+		return nil, err
+	}
+	if err := v.Err(domain.ErrInvalidParams); err != nil {
+		return nil, err
+	}
+
+	// 3. 存入資料庫，slug 已被使用時返回 ErrSlugExists
+	if err := c.portalPageRepository.Create(ctx, portalPage); err != nil {
+		return nil, err
+	}
+
+	// 4. 返回新建立的 Portal Page ID
+	return &CreatePortalPageResult{
+		ID: portalPage.ID,
+	}, nil
 }
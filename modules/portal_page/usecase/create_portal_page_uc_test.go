@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"context"
+	"portal_link/modules/portal_page/domain"
+	"portal_link/modules/portal_page/repository"
+	"portal_link/pkg/validation"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreatePortalPageUC_Execute(t *testing.T) {
+	ctx := context.Background()
+	portalPageRepo := repository.NewInMemoryPortalPageRepository()
+	uc := NewCreatePortalPageUC(portalPageRepo)
+
+	t.Run("成功建立 Portal Page", func(t *testing.T) {
+		result, err := uc.Execute(ctx, &CreatePortalPageParams{
+			UserID: 1,
+			Slug:   "John-Doe",
+			Title:  "John's Page",
+			Bio:    "Welcome to my personal page!",
+		})
+		require.NoError(t, err)
+		assert.Positive(t, result.ID)
+
+		portalPage, err := portalPageRepo.FindByID(ctx, result.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "john-doe", portalPage.Slug)
+		assert.Equal(t, domain.ThemeLight, portalPage.Theme)
+		assert.Equal(t, 1, portalPage.UserID)
+	})
+
+	t.Run("slug 已被使用", func(t *testing.T) {
+		_, err := uc.Execute(ctx, &CreatePortalPageParams{
+			UserID: 2,
+			Slug:   "john-doe",
+			Title:  "Another Page",
+		})
+		assert.True(t, errors.Is(err, domain.ErrSlugExists))
+	})
+
+	t.Run("無效的參數", func(t *testing.T) {
+		cases := map[string]*CreatePortalPageParams{
+			"缺少使用者":    {Slug: "valid-slug", Title: "Title"},
+			"保留字 slug": {UserID: 1, Slug: "admin", Title: "Title"},
+			"空白標題":     {UserID: 1, Slug: "valid-slug", Title: ""},
+			"無效的主題":    {UserID: 1, Slug: "valid-slug", Title: "Title", Theme: "blue"},
+			"無效的頭像網址":  {UserID: 1, Slug: "valid-slug", Title: "Title", ProfileImageURL: "ftp://example.com/a.png"},
+		}
+		for name, params := range cases {
+			t.Run(name, func(t *testing.T) {
+				_, err := uc.Execute(ctx, params)
+				assert.True(t, errors.Is(err, domain.ErrInvalidParams))
+			})
+		}
+	})
+}
+
+func TestListPortalPagesUC_Execute(t *testing.T) {
+	ctx := context.Background()
+	portalPageRepo := repository.NewInMemoryPortalPageRepository()
+	createUC := NewCreatePortalPageUC(portalPageRepo)
+	uc := NewListPortalPagesUC(portalPageRepo)
+
+	t.Run("沒有 Portal Page 時返回空陣列", func(t *testing.T) {
+		result, err := uc.Execute(ctx, &ListPortalPagesParams{UserID: 1})
+		require.NoError(t, err)
+		assert.NotNil(t, result.PortalPages)
+		assert.Empty(t, result.PortalPages)
+	})
+
+	t.Run("只列出自己的 Portal Page", func(t *testing.T) {
+		for _, params := range []*CreatePortalPageParams{
+			{UserID: 1, Slug: "john-doe", Title: "John's Page"},
+			{UserID: 2, Slug: "jane-doe", Title: "Jane's Page"},
+			{UserID: 1, Slug: "my-links", Title: "My Links"},
+		} {
+			_, err := createUC.Execute(ctx, params)
+			require.NoError(t, err)
+		}
+
+		result, err := uc.Execute(ctx, &ListPortalPagesParams{UserID: 1})
+		require.NoError(t, err)
+		require.Len(t, result.PortalPages, 2)
+		assert.Equal(t, "john-doe", result.PortalPages[0].Slug)
+		assert.Equal(t, "my-links", result.PortalPages[1].Slug)
+	})
+
+	t.Run("user_id 不合法", func(t *testing.T) {
+		_, err := uc.Execute(ctx, &ListPortalPagesParams{UserID: 0})
+		require.ErrorIs(t, err, domain.ErrInvalidParams)
+		assert.Equal(t, []validation.FieldError{
+			{Field: "user_id", Rule: validation.RuleOutOfRange, Params: validation.Params{"min": 1}},
+		}, validation.FieldErrors(err))
+	})
+}
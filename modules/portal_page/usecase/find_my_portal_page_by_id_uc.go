@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"context"
+	"portal_link/modules/portal_page/domain"
+	"portal_link/pkg/tracing"
+	"portal_link/pkg/validation"
+)
+
+// FindMyPortalPageByIDParams 查詢自己的 Portal Page 用例的輸入參數
+type FindMyPortalPageByIDParams struct {
+	UserID int `json:"-"`
+	ID     int `json:"-"`
+}
+
+// FindMyPortalPageByIDUC 查詢自己的 Portal Page 用例
+type FindMyPortalPageByIDUC struct {
+	portalPageRepository domain.PortalPageRepository
+}
+
+func NewFindMyPortalPageByIDUC(portalPageRepository domain.PortalPageRepository) *FindMyPortalPageByIDUC {
+	return &FindMyPortalPageByIDUC{
+		portalPageRepository: portalPageRepository,
+	}
+}
+
+func (f *FindMyPortalPageByIDUC) Execute(ctx context.Context, params *FindMyPortalPageByIDParams) (_ *PortalPageDetail, err error) {
+	ctx, span := tracing.Start(ctx, "FindMyPortalPageByIDUC.Execute")
+	defer func() { tracing.End(span, err) }()
+
+	// 1. 驗證輸入參數
+	var v validation.Errors
+	if params.UserID <= 0 {
+		v.Add("user_id", validation.RuleOutOfRange, validation.Params{"min": 1})
+	}
+	if params.ID <= 0 {
+		v.Add("id", validation.RuleOutOfRange, validation.Params{"min": 1})
+	}
+	if err := v.Err(domain.ErrInvalidParams); err != nil {
+		return nil, err
+	}
+
+	// 2. 查詢 Portal Page 並確認擁有者
+	portalPage, err := findOwnedPortalPage(ctx, f.portalPageRepository, params.ID, params.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 返回包含 Links 的 Portal Page
+	return newPortalPageDetail(portalPage), nil
+}
@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"portal_link/modules/portal_page/domain"
+	"portal_link/pkg/tracing"
+	"portal_link/pkg/validation"
+
+	"github.com/cockroachdb/errors"
+)
+
+// FindPortalPageBySlugParams 以 slug 查詢 Portal Page 用例的輸入參數
+type FindPortalPageBySlugParams struct {
+	Slug string `json:"-"`
+}
+
+// FindPortalPageBySlugUC 以 slug 查詢公開 Portal Page 的用例
+type FindPortalPageBySlugUC struct {
+	portalPageRepository domain.PortalPageRepository
+}
+
+func NewFindPortalPageBySlugUC(portalPageRepository domain.PortalPageRepository) *FindPortalPageBySlugUC {
+	return &FindPortalPageBySlugUC{
+		portalPageRepository: portalPageRepository,
+	}
+}
+
+func (f *FindPortalPageBySlugUC) Execute(ctx context.Context, params *FindPortalPageBySlugParams) (_ *PortalPageDetail, err error) {
+	ctx, span := tracing.Start(ctx, "FindPortalPageBySlugUC.Execute")
+	defer func() { tracing.End(span, err) }()
+
+	// 1. 驗證輸入參數
+	slug := domain.NormalizeSlug(params.Slug)
+	if slug == "" {
+		return nil, validation.NewError(domain.ErrInvalidParams, "slug", validation.RuleRequired, nil)
+	}
+
+	// 2. 以 slug 查詢 Portal Page
+	portalPage, err := f.portalPageRepository.FindBySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrPortalPageNotFound
+		}
+		return nil, err
+	}
+
+	// 3. 返回包含 Links 的 Portal Page
+	return newPortalPageDetail(portalPage), nil
+}
@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"context"
+	"portal_link/modules/portal_page/domain"
+	"portal_link/pkg/tracing"
+	"portal_link/pkg/validation"
+)
+
+// ListPortalPagesParams 列出 Portal Page 用例的輸入參數
+type ListPortalPagesParams struct {
+	UserID int `json:"-"`
+}
+
+// ListPortalPagesResult 列出 Portal Page 用例的輸出結果
+type ListPortalPagesResult struct {
+	PortalPages []PortalPageSummary `json:"portal_pages"`
+}
+
+// PortalPageSummary 不包含 Links 的 Portal Page 摘要
+type PortalPageSummary struct {
+	ID    int    `json:"id"`
+	Slug  string `json:"slug"`
+	Title string `json:"title"`
+}
+
+// ListPortalPagesUC 列出使用者所有 Portal Page 的用例
+type ListPortalPagesUC struct {
+	portalPageRepository domain.PortalPageRepository
+}
+
+func NewListPortalPagesUC(portalPageRepository domain.PortalPageRepository) *ListPortalPagesUC {
+	return &ListPortalPagesUC{
+		portalPageRepository: portalPageRepository,
+	}
+}
+
+func (l *ListPortalPagesUC) Execute(ctx context.Context, params *ListPortalPagesParams) (_ *ListPortalPagesResult, err error) {
+	ctx, span := tracing.Start(ctx, "ListPortalPagesUC.Execute")
+	defer func() { tracing.End(span, err) }()
+
+	// 1. 驗證輸入參數
+	if params.UserID <= 0 {
+		return nil, validation.NewError(domain.ErrInvalidParams, "user_id", validation.RuleOutOfRange, validation.Params{"min": 1})
+	}
+
+	// 2. 查詢使用者的 Portal Page（依建立時間升冪排序）
+	portalPages, err := l.portalPageRepository.ListByUserID(ctx, params.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 返回摘要清單，沒有 Portal Page 時返回空陣列
+	result := &ListPortalPagesResult{
+		PortalPages: make([]PortalPageSummary, 0, len(portalPages)),
+	}
+	for _, portalPage := range portalPages {
+		result.PortalPages = append(result.PortalPages, PortalPageSummary{
+			ID:    portalPage.ID,
+			Slug:  portalPage.Slug,
+			Title: portalPage.Title,
+		})
+	}
+	return result, nil
+}
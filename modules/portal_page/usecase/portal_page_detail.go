@@ -0,0 +1,49 @@
+package usecase
+
+import "portal_link/modules/portal_page/domain"
+
+// PortalPageDetail 包含 Links 的 Portal Page 輸出結果
+type PortalPageDetail struct {
+	ID              int          `json:"id"`
+	Slug            string       `json:"slug"`
+	Title           string       `json:"title"`
+	Bio             string       `json:"bio"`
+	ProfileImageURL string       `json:"profile_image_url"`
+	Theme           string       `json:"theme"`
+	Links           []LinkDetail `json:"links"`
+}
+
+// LinkDetail Portal Page 中的 Link 輸出結果
+type LinkDetail struct {
+	ID           int    `json:"id"`
+	Title        string `json:"title"`
+	URL          string `json:"url"`
+	Description  string `json:"description"`
+	IconURL      string `json:"icon_url"`
+	DisplayOrder int    `json:"display_order"`
+}
+
+// newPortalPageDetail 將 PortalPage 聚合轉換為輸出結果，Links 依 display_order 升冪排序
+func newPortalPageDetail(portalPage *domain.PortalPage) *PortalPageDetail {
+	links := portalPage.Links()
+	detail := &PortalPageDetail{
+		ID:              portalPage.ID,
+		Slug:            portalPage.Slug,
+		Title:           portalPage.Title,
+		Bio:             portalPage.Bio,
+		ProfileImageURL: portalPage.ProfileImageURL,
+		Theme:           string(portalPage.Theme),
+		Links:           make([]LinkDetail, 0, len(links)),
+	}
+	for _, link := range links {
+		detail.Links = append(detail.Links, LinkDetail{
+			ID:           link.ID,
+			Title:        link.Title,
+			URL:          link.URL,
+			Description:  link.Description,
+			IconURL:      link.IconURL,
+			DisplayOrder: link.DisplayOrder,
+		})
+	}
+	return detail
+}
@@ -0,0 +1,25 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"portal_link/modules/portal_page/domain"
+
+	"github.com/cockroachdb/errors"
+)
+
+// findOwnedPortalPage 查詢 Portal Page 並確認屬於指定的使用者
+// 不存在時返回 ErrPortalPageNotFound，不屬於該使用者時返回 ErrForbidden
+func findOwnedPortalPage(ctx context.Context, portalPageRepository domain.PortalPageRepository, id int, userID int) (*domain.PortalPage, error) {
+	portalPage, err := portalPageRepository.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrPortalPageNotFound
+		}
+		return nil, err
+	}
+	if portalPage.UserID != userID {
+		return nil, domain.ErrForbidden
+	}
+	return portalPage, nil
+}
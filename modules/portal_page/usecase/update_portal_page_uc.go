@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"portal_link/modules/portal_page/domain"
+	"portal_link/pkg/tracing"
+	"portal_link/pkg/transaction"
+	"portal_link/pkg/validation"
+
+	"github.com/cockroachdb/errors"
+)
+
+// UpdatePortalPageParams 更新 Portal Page 用例的輸入參數
+// 除了 Links 以外的欄位皆為選填，nil 表示不更新
+type UpdatePortalPageParams struct {
+	UserID          int                          `json:"-"`
+	ID              int                          `json:"-"`
+	Slug            *string                      `json:"slug"`
+	Title           *string                      `json:"title"`
+	Bio             *string                      `json:"bio"`
+	ProfileImageURL *string                      `json:"profile_image_url"`
+	Theme           *string                      `json:"theme"`
+	Links           []UpdatePortalPageLinkParams `json:"links"`
+}
+
+// UpdatePortalPageLinkParams 更新 Portal Page 時的 Link 參數
+// ID 為 0 時新增 Link，否則更新既有的 Link
+type UpdatePortalPageLinkParams struct {
+	ID           int    `json:"id"`
+	Title        string `json:"title"`
+	URL          string `json:"url"`
+	Description  string `json:"description"`
+	IconURL      string `json:"icon_url"`
+	DisplayOrder int    `json:"display_order"`
+}
+
+// UpdatePortalPageResult 更新 Portal Page 用例的輸出結果
+type UpdatePortalPageResult struct {
+	ID int `json:"id"`
+}
+
+// UpdatePortalPageUC 更新 Portal Page 用例
+type UpdatePortalPageUC struct {
+	txManager            transaction.TxManager
+	portalPageRepository domain.PortalPageRepository
+}
+
+func NewUpdatePortalPageUC(txManager transaction.TxManager, portalPageRepository domain.PortalPageRepository) *UpdatePortalPageUC {
+	return &UpdatePortalPageUC{
+		txManager:            txManager,
+		portalPageRepository: portalPageRepository,
+	}
+}
+
+func (u *UpdatePortalPageUC) Execute(ctx context.Context, params *UpdatePortalPageParams) (_ *UpdatePortalPageResult, err error) {
+	ctx, span := tracing.Start(ctx, "UpdatePortalPageUC.Execute")
+	defer func() { tracing.End(span, err) }()
+
+	// 1. 驗證輸入參數
+	var v validation.Errors
+	if params.UserID <= 0 {
+		v.Add("user_id", validation.RuleOutOfRange, validation.Params{"min": 1})
+	}
+	if params.ID <= 0 {
+		v.Add("id", validation.RuleOutOfRange, validation.Params{"min": 1})
+	}
+	if params.Links == nil {
+		v.Add("links", validation.RuleRequired, nil)
+	}
+	if err := v.Err(domain.ErrInvalidParams); err != nil {
+		return nil, err
+	}
+
+	// 2~4 在同一個交易中執行，避免查詢後到寫入前被其他請求修改
+	var portalPage *domain.PortalPage
+	err = u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// 2. 查詢 Portal Page 並確認擁有者
+		var err error
+		portalPage, err = findOwnedPortalPage(ctx, u.portalPageRepository, params.ID, params.UserID)
+		if err != nil {
+			return err
+		}
+
+		// 3. 透過聚合根更新欄位與 Links
+		if err := u.apply(portalPage, params); err != nil {
+			return err
+		}
+
+		// 4. 存入資料庫，slug 已被其他 Portal Page 使用時返回 ErrSlugExists
+		if err := u.portalPageRepository.Update(ctx, portalPage); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrPortalPageNotFound
+			}
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &UpdatePortalPageResult{
+		ID: portalPage.ID,
+	}, nil
+}
+
+// apply 將有提供的欄位套用到 Portal Page，一起返回所有不合法的欄位
+func (u *UpdatePortalPageUC) apply(portalPage *domain.PortalPage, params *UpdatePortalPageParams) error {
+	var v validation.Errors
+	if params.Slug != nil {
+		if err := v.Merge("", portalPage.ChangeSlug(*params.Slug)); err != nil {
+			return err
+		}
+	}
+	if params.Title != nil {
+		if err := v.Merge("", portalPage.ChangeTitle(*params.Title)); err != nil {
+			return err
+		}
+	}
+	if params.Bio != nil {
+		if err := v.Merge("", portalPage.ChangeBio(*params.Bio)); err != nil {
+			return err
+		}
+	}
+	if params.ProfileImageURL != nil {
+		if err := v.Merge("", portalPage.ChangeProfileImageURL(*params.ProfileImageURL)); err != nil {
+			return err
+		}
+	}
+	if params.Theme != nil {
+		if err := v.Merge("", portalPage.ChangeTheme(domain.Theme(*params.Theme))); err != nil {
+			return err
+		}
+	}
+
+	// display_order 由聚合根驗證，Link 的欄位錯誤以 links[i]. 加上請求中的索引表示
+	links := make([]domain.LinkParams, 0, len(params.Links))
+	for _, link := range params.Links {
+		links = append(links, domain.LinkParams{
+			ID:           link.ID,
+			Title:        link.Title,
+			URL:          link.URL,
+			Description:  link.Description,
+			IconURL:      link.IconURL,
+			DisplayOrder: link.DisplayOrder,
+		})
+	}
+	if err := v.Merge("", portalPage.ReplaceLinks(links)); err != nil {
+		return err
+	}
+	return v.Err(domain.ErrInvalidParams)
+}
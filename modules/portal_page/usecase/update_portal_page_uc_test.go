@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"context"
+	"portal_link/modules/portal_page/domain"
+	"portal_link/modules/portal_page/repository"
+	"portal_link/pkg/transaction"
+	"portal_link/pkg/validation"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdatePortalPageUC_Execute(t *testing.T) {
+	ctx := context.Background()
+	portalPageRepo := repository.NewInMemoryPortalPageRepository()
+	createUC := NewCreatePortalPageUC(portalPageRepo)
+	findUC := NewFindMyPortalPageByIDUC(portalPageRepo)
+	uc := NewUpdatePortalPageUC(transaction.NewInMemoryTxManager(), portalPageRepo)
+
+	created, err := createUC.Execute(ctx, &CreatePortalPageParams{UserID: 1, Slug: "john-doe", Title: "John's Page"})
+	require.NoError(t, err)
+	_, err = createUC.Execute(ctx, &CreatePortalPageParams{UserID: 2, Slug: "jane-doe", Title: "Jane's Page"})
+	require.NoError(t, err)
+
+	title := "John's Updated Page"
+	theme := "dark"
+
+	t.Run("更新欄位並新增 Links", func(t *testing.T) {
+		result, err := uc.Execute(ctx, &UpdatePortalPageParams{
+			UserID: 1,
+			ID:     created.ID,
+			Title:  &title,
+			Theme:  &theme,
+			Links: []UpdatePortalPageLinkParams{
+				{Title: "Second", URL: "https://second.example.com", DisplayOrder: 2},
+				{Title: "First", URL: "https://first.example.com", DisplayOrder: 1},
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, created.ID, result.ID)
+
+		detail, err := findUC.Execute(ctx, &FindMyPortalPageByIDParams{UserID: 1, ID: created.ID})
+		require.NoError(t, err)
+		assert.Equal(t, title, detail.Title)
+		assert.Equal(t, "dark", detail.Theme)
+		assert.Equal(t, "john-doe", detail.Slug)
+		require.Len(t, detail.Links, 2)
+		assert.Equal(t, "First", detail.Links[0].Title)
+		assert.Equal(t, "Second", detail.Links[1].Title)
+		assert.Positive(t, detail.Links[0].ID)
+	})
+
+	t.Run("更新既有 Link 並移除未列出的 Link", func(t *testing.T) {
+		before, err := findUC.Execute(ctx, &FindMyPortalPageByIDParams{UserID: 1, ID: created.ID})
+		require.NoError(t, err)
+		kept := before.Links[1]
+
+		_, err = uc.Execute(ctx, &UpdatePortalPageParams{
+			UserID: 1,
+			ID:     created.ID,
+			Links: []UpdatePortalPageLinkParams{
+				{ID: kept.ID, Title: "Renamed", URL: kept.URL, DisplayOrder: 1},
+			},
+		})
+		require.NoError(t, err)
+
+		detail, err := findUC.Execute(ctx, &FindMyPortalPageByIDParams{UserID: 1, ID: created.ID})
+		require.NoError(t, err)
+		require.Len(t, detail.Links, 1)
+		assert.Equal(t, kept.ID, detail.Links[0].ID)
+		assert.Equal(t, "Renamed", detail.Links[0].Title)
+	})
+
+	t.Run("slug 已被其他 Portal Page 使用", func(t *testing.T) {
+		slug := "jane-doe"
+		_, err := uc.Execute(ctx, &UpdatePortalPageParams{UserID: 1, ID: created.ID, Slug: &slug, Links: []UpdatePortalPageLinkParams{}})
+		assert.True(t, errors.Is(err, domain.ErrSlugExists))
+	})
+
+	t.Run("Link ID 不屬於此 Portal Page", func(t *testing.T) {
+		_, err := uc.Execute(ctx, &UpdatePortalPageParams{
+			UserID: 1,
+			ID:     created.ID,
+			Links:  []UpdatePortalPageLinkParams{{ID: 999, Title: "Unknown", URL: "https://example.com", DisplayOrder: 1}},
+		})
+		assert.True(t, errors.Is(err, domain.ErrLinkNotFound))
+	})
+
+	t.Run("缺少 links", func(t *testing.T) {
+		_, err := uc.Execute(ctx, &UpdatePortalPageParams{UserID: 1, ID: created.ID, Title: &title})
+		assert.True(t, errors.Is(err, domain.ErrInvalidParams))
+	})
+
+	t.Run("同時返回欄位與 Link 的錯誤", func(t *testing.T) {
+		badSlug := "-bad"
+		badTheme := "neon"
+		_, err := uc.Execute(ctx, &UpdatePortalPageParams{
+			UserID: 1,
+			ID:     created.ID,
+			Slug:   &badSlug,
+			Theme:  &badTheme,
+			Links: []UpdatePortalPageLinkParams{
+				{Title: "First", URL: "https://a.example.com", DisplayOrder: 1},
+				{Title: "Second", URL: "not a url", DisplayOrder: 0},
+			},
+		})
+		require.ErrorIs(t, err, domain.ErrInvalidParams)
+		assert.Equal(t, []validation.FieldError{
+			{Field: "slug", Rule: validation.RuleInvalidFormat},
+			{Field: "theme", Rule: validation.RuleOneOf, Params: validation.Params{"values": []string{"light", "dark"}}},
+			{Field: "links[1].display_order", Rule: validation.RuleOutOfRange, Params: validation.Params{"min": 1}},
+			{Field: "links[1].url", Rule: validation.RuleInvalidFormat, Params: validation.Params{"schemes": []string{"http", "https"}}},
+		}, validation.FieldErrors(err))
+	})
+
+	t.Run("非擁有者無法查詢或更新", func(t *testing.T) {
+		_, err := findUC.Execute(ctx, &FindMyPortalPageByIDParams{UserID: 2, ID: created.ID})
+		assert.True(t, errors.Is(err, domain.ErrForbidden))
+
+		_, err = uc.Execute(ctx, &UpdatePortalPageParams{UserID: 2, ID: created.ID, Title: &title, Links: []UpdatePortalPageLinkParams{}})
+		assert.True(t, errors.Is(err, domain.ErrForbidden))
+	})
+
+	t.Run("查詢參數不合法時返回所有不合法的欄位", func(t *testing.T) {
+		_, err := findUC.Execute(ctx, &FindMyPortalPageByIDParams{UserID: 0, ID: -1})
+		require.ErrorIs(t, err, domain.ErrInvalidParams)
+		assert.Equal(t, []validation.FieldError{
+			{Field: "user_id", Rule: validation.RuleOutOfRange, Params: validation.Params{"min": 1}},
+			{Field: "id", Rule: validation.RuleOutOfRange, Params: validation.Params{"min": 1}},
+		}, validation.FieldErrors(err))
+	})
+
+	t.Run("Portal Page 不存在", func(t *testing.T) {
+		_, err := findUC.Execute(ctx, &FindMyPortalPageByIDParams{UserID: 1, ID: 999})
+		assert.True(t, errors.Is(err, domain.ErrPortalPageNotFound))
+
+		_, err = uc.Execute(ctx, &UpdatePortalPageParams{UserID: 1, ID: 999, Links: []UpdatePortalPageLinkParams{}})
+		assert.True(t, errors.Is(err, domain.ErrPortalPageNotFound))
+	})
+}
+
+func TestFindPortalPageBySlugUC_Execute(t *testing.T) {
+	ctx := context.Background()
+	portalPageRepo := repository.NewInMemoryPortalPageRepository()
+	uc := NewFindPortalPageBySlugUC(portalPageRepo)
+
+	_, err := NewCreatePortalPageUC(portalPageRepo).Execute(ctx, &CreatePortalPageParams{UserID: 1, Slug: "john-doe", Title: "John's Page"})
+	require.NoError(t, err)
+
+	t.Run("以 slug 查詢（不分大小寫）", func(t *testing.T) {
+		detail, err := uc.Execute(ctx, &FindPortalPageBySlugParams{Slug: "John-Doe"})
+		require.NoError(t, err)
+		assert.Equal(t, "john-doe", detail.Slug)
+		assert.NotNil(t, detail.Links)
+	})
+
+	t.Run("slug 為空白", func(t *testing.T) {
+		_, err := uc.Execute(ctx, &FindPortalPageBySlugParams{Slug: "  "})
+		require.ErrorIs(t, err, domain.ErrInvalidParams)
+		assert.Equal(t, []validation.FieldError{
+			{Field: "slug", Rule: validation.RuleRequired},
+		}, validation.FieldErrors(err))
+	})
+
+	t.Run("slug 不存在", func(t *testing.T) {
+		_, err := uc.Execute(ctx, &FindPortalPageBySlugParams{Slug: "nobody"})
+		assert.True(t, errors.Is(err, domain.ErrPortalPageNotFound))
+	})
+}
@@ -0,0 +1,56 @@
+package restapi
+
+import (
+	"net/http"
+	"portal_link/modules/user/domain"
+	"portal_link/pkg/http_error"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// errorMappings 用戶模組的 domain error 與 HTTP 回應的對應（狀態碼與代碼依 api.yaml）
+// 與其他錯誤共用 Code 的項目以 MessageKey 指定 pkg/i18n 訊息目錄中的訊息
+var errorMappings = []http_error.Mapping{
+	{Err: domain.ErrInvalidParams, Status: http.StatusBadRequest, Code: http_error.ErrInvalidParams},
+	{Err: domain.ErrEmailExists, Status: http.StatusBadRequest, Code: "ErrEmailExists"},
+	{Err: domain.ErrInvalidCredentials, Status: http.StatusUnauthorized, Code: "ErrInvalidCredentials"},
+	{Err: domain.ErrTooManyLoginAttempts, Status: http.StatusTooManyRequests, Code: http_error.ErrTooManyRequests, MessageKey: "ErrTooManyLoginAttempts", RetryAfter: loginRetryAfter},
+
+	{Err: domain.ErrInvalidRefreshToken, Status: http.StatusUnauthorized, Code: http_error.ErrUnauthorized, MessageKey: "ErrInvalidRefreshToken"},
+	{Err: domain.ErrRefreshTokenExpired, Status: http.StatusUnauthorized, Code: http_error.ErrUnauthorized, MessageKey: "ErrRefreshTokenExpired"},
+	{Err: domain.ErrRefreshTokenReused, Status: http.StatusUnauthorized, Code: http_error.ErrUnauthorized, MessageKey: "ErrRefreshTokenReused"},
+
+	{Err: domain.ErrInvalidPasswordResetToken, Status: http.StatusBadRequest, Code: http_error.ErrInvalidParams, MessageKey: "ErrInvalidPasswordResetToken"},
+	{Err: domain.ErrPasswordResetTokenExpired, Status: http.StatusBadRequest, Code: http_error.ErrInvalidParams, MessageKey: "ErrPasswordResetTokenExpired"},
+
+	{Err: domain.ErrInvalidEmailVerificationToken, Status: http.StatusBadRequest, Code: http_error.ErrInvalidParams, MessageKey: "ErrInvalidEmailVerificationToken"},
+	{Err: domain.ErrEmailVerificationTokenExpired, Status: http.StatusBadRequest, Code: http_error.ErrInvalidParams, MessageKey: "ErrEmailVerificationTokenExpired"},
+	{Err: domain.ErrEmailAlreadyVerified, Status: http.StatusBadRequest, Code: http_error.ErrInvalidParams, MessageKey: "ErrEmailAlreadyVerified"},
+	{Err: domain.ErrEmailNotVerified, Status: http.StatusForbidden, Code: "ErrEmailNotVerified"},
+	{Err: domain.ErrVerificationEmailThrottled, Status: http.StatusTooManyRequests, Code: http_error.ErrTooManyRequests, MessageKey: "ErrVerificationEmailThrottled", RetryAfter: verificationEmailRetryAfter},
+
+	{Err: domain.ErrPersonalAccessTokenNotFound, Status: http.StatusNotFound, Code: http_error.ErrNotFound, MessageKey: "ErrPersonalAccessTokenNotFound"},
+}
+
+func init() {
+	http_error.Register(errorMappings...)
+}
+
+// loginRetryAfter 登入鎖定解除前的等待時間
+func loginRetryAfter(err error) time.Duration {
+	var lockedErr *domain.LoginLockedError
+	if errors.As(err, &lockedErr) {
+		return lockedErr.RetryAfter
+	}
+	return 0
+}
+
+// verificationEmailRetryAfter 可再次寄送驗證信前的等待時間
+func verificationEmailRetryAfter(err error) time.Duration {
+	var throttledErr *domain.VerificationEmailThrottledError
+	if errors.As(err, &throttledErr) {
+		return throttledErr.RetryAfter
+	}
+	return 0
+}
@@ -0,0 +1,47 @@
+package restapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"portal_link/modules/user/domain"
+	"portal_link/pkg/http_error"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorMappings(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		err        error
+		status     int
+		code       string
+		retryAfter string
+	}{
+		{name: "invalid params", err: errors.Wrap(domain.ErrInvalidParams, "name is invalid"), status: http.StatusBadRequest, code: http_error.ErrInvalidParams},
+		{name: "invalid credentials", err: domain.ErrInvalidCredentials, status: http.StatusUnauthorized, code: "ErrInvalidCredentials"},
+		{name: "login locked", err: &domain.LoginLockedError{RetryAfter: 30 * time.Second}, status: http.StatusTooManyRequests, code: http_error.ErrTooManyRequests, retryAfter: "30"},
+		{name: "refresh token reused", err: domain.ErrRefreshTokenReused, status: http.StatusUnauthorized, code: http_error.ErrUnauthorized},
+		{name: "verification email throttled", err: &domain.VerificationEmailThrottledError{RetryAfter: 45 * time.Second}, status: http.StatusTooManyRequests, code: http_error.ErrTooManyRequests, retryAfter: "45"},
+		{name: "internal", err: errors.New("connection refused"), status: http.StatusInternalServerError, code: http_error.ErrInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+
+			http_error.Respond(c, tt.err)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Contains(t, w.Body.String(), `"code":"`+tt.code+`"`)
+			assert.Equal(t, tt.retryAfter, w.Header().Get("Retry-After"))
+		})
+	}
+}
@@ -3,48 +3,105 @@ package restapi
 import (
 	"database/sql"
 	"errors"
+	"io"
 	"net/http"
 	"portal_link/modules/user/domain"
 	"portal_link/modules/user/repository"
 	"portal_link/modules/user/usecase"
+	"portal_link/pkg/auth"
 	"portal_link/pkg/http_error"
+	"portal_link/pkg/metrics"
+	"portal_link/pkg/transaction"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
 // UserHandler 用戶處理器
 type UserHandler struct {
-	signUpUC *usecase.SignUpUC
-	signInUC *usecase.SignInUC
+	signUpUC       *usecase.SignUpUC
+	signInUC       *usecase.SignInUC
+	refreshTokenUC *usecase.RefreshTokenUC
+	signOutUC      *usecase.SignOutUC
+	signOutAllUC   *usecase.SignOutAllUC
+
+	requestPasswordResetUC *usecase.RequestPasswordResetUC
+	resetPasswordUC        *usecase.ResetPasswordUC
+
+	verifyEmailUC             *usecase.VerifyEmailUC
+	resendVerificationEmailUC *usecase.ResendVerificationEmailUC
+
+	createPersonalAccessTokenUC *usecase.CreatePersonalAccessTokenUC
+	listPersonalAccessTokensUC  *usecase.ListPersonalAccessTokensUC
+	revokePersonalAccessTokenUC *usecase.RevokePersonalAccessTokenUC
+}
+
+// EmailLinks 寄給使用者的信件中使用的網址，token 會附加在 query string
+type EmailLinks struct {
+	// PasswordResetURL 前端重設密碼頁面的網址
+	PasswordResetURL string
+	// VerifyEmailURL 驗證電子郵件的網址（GET /api/v1/user/verify-email）
+	VerifyEmailURL string
 }
 
 // NewInMemUserHandler 建立新的用戶處理器 (in-memory version)
-func NewInMemUserHandler(e *gin.Engine, userRepo domain.UserRepository) error {
-	handler := &UserHandler{
-		signUpUC: usecase.NewSignUpUC(userRepo),
-		signInUC: usecase.NewSignInUC(userRepo),
-	}
+// txManager 通常為 transaction.NewInMemoryTxManager()，回滾時還原交易中寫入記憶體 repository 的資料
+func NewInMemUserHandler(e *gin.Engine, txManager transaction.TxManager, userRepo domain.UserRepository, refreshTokenRepo domain.RefreshTokenRepository, loginAttemptRepo domain.LoginAttemptRepository, passwordResetTokenRepo domain.PasswordResetTokenRepository, emailVerificationTokenRepo domain.EmailVerificationTokenRepository, personalAccessTokenRepo domain.PersonalAccessTokenRepository, passwordHasher domain.PasswordHasher, tokenManager *auth.TokenManager, mailer domain.Mailer, links EmailLinks, m *metrics.Metrics) error {
+	return registerUserHandler(e, txManager, userRepo, refreshTokenRepo, loginAttemptRepo, passwordResetTokenRepo, emailVerificationTokenRepo, personalAccessTokenRepo, passwordHasher, tokenManager, mailer, links, m)
+}
 
-	router := e.Group("/api/v1/user")
-	{
-		router.POST("/signup", handler.SignUp)
-		router.POST("/signin", handler.SignIn)
-	}
-	return nil
+// NewUserHandler 建立新的用戶處理器，使用者、refresh token、登入失敗紀錄、密碼重設與電子郵件驗證 token、personal access token 保存在 SQL 資料庫（PostgreSQL 或 SQLite）
+// personalAccessTokenRepo 通常為 repository.NewSQLPersonalAccessTokenRepository(db)，需與 tokenManager 驗證 personal access token 時使用的 repository 相同
+func NewUserHandler(e *gin.Engine, db *sql.DB, txManager transaction.TxManager, personalAccessTokenRepo domain.PersonalAccessTokenRepository, passwordHasher domain.PasswordHasher, tokenManager *auth.TokenManager, mailer domain.Mailer, links EmailLinks, m *metrics.Metrics) error {
+	return registerUserHandler(e, txManager,
+		repository.NewSQLUserRepository(db),
+		repository.NewSQLRefreshTokenRepository(db),
+		repository.NewSQLLoginAttemptRepository(db),
+		repository.NewSQLPasswordResetTokenRepository(db),
+		repository.NewSQLEmailVerificationTokenRepository(db),
+		personalAccessTokenRepo,
+		passwordHasher, tokenManager, mailer, links, m)
 }
 
-// NewUserHandler 建立新的用戶處理器
-func NewUserHandler(e *gin.Engine, db *sql.DB) error {
-	userRepo := repository.NewInMemoryUserRepository()
+// registerUserHandler 建立用戶處理器並註冊路由，使用者 repository 的呼叫與登入結果記錄到 m
+func registerUserHandler(e *gin.Engine, txManager transaction.TxManager, userRepo domain.UserRepository, refreshTokenRepo domain.RefreshTokenRepository, loginAttemptRepo domain.LoginAttemptRepository, passwordResetTokenRepo domain.PasswordResetTokenRepository, emailVerificationTokenRepo domain.EmailVerificationTokenRepository, personalAccessTokenRepo domain.PersonalAccessTokenRepository, passwordHasher domain.PasswordHasher, tokenManager *auth.TokenManager, mailer domain.Mailer, links EmailLinks, m *metrics.Metrics) error {
+	userRepo = repository.NewInstrumentedUserRepository(userRepo, m)
+
 	handler := &UserHandler{
-		signUpUC: usecase.NewSignUpUC(userRepo),
-		// signInUC: usecase.NewSignInUC(userRepo),
+		signUpUC:       usecase.NewSignUpUC(txManager, userRepo, refreshTokenRepo, emailVerificationTokenRepo, passwordHasher, tokenManager, mailer, links.VerifyEmailURL),
+		signInUC:       usecase.NewSignInUC(userRepo, refreshTokenRepo, loginAttemptRepo, passwordHasher, tokenManager, m),
+		refreshTokenUC: usecase.NewRefreshTokenUC(userRepo, refreshTokenRepo, tokenManager),
+		signOutUC:      usecase.NewSignOutUC(refreshTokenRepo, tokenManager),
+		signOutAllUC:   usecase.NewSignOutAllUC(refreshTokenRepo, personalAccessTokenRepo, tokenManager),
+
+		requestPasswordResetUC: usecase.NewRequestPasswordResetUC(userRepo, passwordResetTokenRepo, mailer, links.PasswordResetURL),
+		resetPasswordUC:        usecase.NewResetPasswordUC(txManager, userRepo, passwordResetTokenRepo, refreshTokenRepo, personalAccessTokenRepo, passwordHasher, tokenManager),
+
+		verifyEmailUC:             usecase.NewVerifyEmailUC(userRepo, emailVerificationTokenRepo),
+		resendVerificationEmailUC: usecase.NewResendVerificationEmailUC(txManager, userRepo, emailVerificationTokenRepo, mailer, links.VerifyEmailURL),
+
+		createPersonalAccessTokenUC: usecase.NewCreatePersonalAccessTokenUC(personalAccessTokenRepo),
+		listPersonalAccessTokensUC:  usecase.NewListPersonalAccessTokensUC(personalAccessTokenRepo),
+		revokePersonalAccessTokenUC: usecase.NewRevokePersonalAccessTokenUC(personalAccessTokenRepo),
 	}
 
+	// 未指定 scope，只接受登入取得的 access token，personal access token 無法管理帳號與其他 token
+	authMiddleware := auth.AuthMiddleware(tokenManager, userRepo)
+
 	router := e.Group("/api/v1/user")
 	{
 		router.POST("/signup", handler.SignUp)
 		router.POST("/signin", handler.SignIn)
+		router.POST("/token/refresh", handler.RefreshToken)
+		router.POST("/signout", authMiddleware, handler.SignOut)
+		router.POST("/signout-all", authMiddleware, handler.SignOutAll)
+		router.POST("/password/forgot", handler.RequestPasswordReset)
+		router.POST("/password/reset", handler.ResetPassword)
+		router.GET("/verify-email", handler.VerifyEmail)
+		router.POST("/verify-email/resend", authMiddleware, handler.ResendVerificationEmail)
+		router.POST("/tokens", authMiddleware, handler.CreatePersonalAccessToken)
+		router.GET("/tokens", authMiddleware, handler.ListPersonalAccessTokens)
+		router.DELETE("/tokens/:id", authMiddleware, handler.RevokePersonalAccessToken)
 	}
 	return nil
 }
@@ -66,21 +123,15 @@ func (h *UserHandler) SignUp(c *gin.Context) {
 		Password: req.Password,
 	})
 	if err != nil {
-		if errors.Is(err, domain.ErrInvalidParams) || errors.Is(err, domain.ErrEmailExists) {
-			http_error.ResponseBadRequest(c, &http_error.ErrorResponse{
-				Message: err.Error(),
-			})
-			return
-		}
-		http_error.ResponseInternalServerError(c, &http_error.ErrorResponse{
-			Message: err.Error(),
-		})
+		http_error.Respond(c, err)
 		return
 	}
 
 	// 返回成功響應
 	c.JSON(http.StatusOK, &usecase.SignUpResult{
-		AccessToken: result.AccessToken,
+		AccessToken:  result.AccessToken,
+		RefreshToken: result.RefreshToken,
+		ExpiresIn:    result.ExpiresIn,
 	})
 }
 
@@ -98,23 +149,275 @@ func (h *UserHandler) SignIn(c *gin.Context) {
 	result, err := h.signInUC.Execute(c.Request.Context(), &usecase.SignInParams{
 		Email:    req.Email,
 		Password: req.Password,
+		IP:       c.ClientIP(),
 	})
 
 	if err != nil {
-		if errors.Is(err, domain.ErrInvalidParams) || errors.Is(err, domain.ErrInvalidCredentials) {
-			http_error.ResponseBadRequest(c, &http_error.ErrorResponse{
-				Message: err.Error(),
-			})
-			return
-		}
-		http_error.ResponseInternalServerError(c, &http_error.ErrorResponse{
-			Message: err.Error(),
-		})
+		http_error.Respond(c, err)
 		return
 	}
 
 	// 返回成功響應
 	c.JSON(http.StatusOK, &usecase.SignInResult{
-		AccessToken: result.AccessToken,
+		AccessToken:  result.AccessToken,
+		RefreshToken: result.RefreshToken,
+		ExpiresIn:    result.ExpiresIn,
+	})
+}
+
+// RefreshToken 處理刷新 token 請求
+func (h *UserHandler) RefreshToken(c *gin.Context) {
+	var req usecase.RefreshTokenParams
+
+	// 綁定並驗證請求體
+	if err := c.ShouldBindJSON(&req); err != nil {
+		http_error.ResponseBadRequest(c, nil)
+		return
+	}
+
+	// 執行刷新 token 用例
+	result, err := h.refreshTokenUC.Execute(c.Request.Context(), &usecase.RefreshTokenParams{
+		RefreshToken: req.RefreshToken,
+	})
+	if err != nil {
+		http_error.Respond(c, err)
+		return
+	}
+
+	// 返回成功響應
+	c.JSON(http.StatusOK, &usecase.RefreshTokenResult{
+		AccessToken:  result.AccessToken,
+		RefreshToken: result.RefreshToken,
+		ExpiresIn:    result.ExpiresIn,
+	})
+}
+
+// SignOut 處理登出請求，撤銷目前的 access token（以及選填的 refresh token）
+func (h *UserHandler) SignOut(c *gin.Context) {
+	var req usecase.SignOutParams
+
+	// 綁定請求體（refresh_token 為選填，允許空的請求體）
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		http_error.ResponseBadRequest(c, nil)
+		return
+	}
+
+	userID, err := h.currentUserID(c)
+	if err != nil {
+		http_error.Respond(c, err)
+		return
+	}
+	accessToken, err := auth.GetAccessTokenFromContext(c)
+	if err != nil {
+		http_error.Respond(c, err)
+		return
+	}
+
+	// 執行登出用例
+	err = h.signOutUC.Execute(c.Request.Context(), &usecase.SignOutParams{
+		UserID:       userID,
+		AccessToken:  accessToken,
+		RefreshToken: req.RefreshToken,
+	})
+	if err != nil {
+		http_error.Respond(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// SignOutAll 處理登出所有裝置請求，撤銷使用者所有的 access token、refresh token 與 personal access token
+func (h *UserHandler) SignOutAll(c *gin.Context) {
+	userID, err := h.currentUserID(c)
+	if err != nil {
+		http_error.Respond(c, err)
+		return
+	}
+
+	// 執行登出所有裝置用例
+	err = h.signOutAllUC.Execute(c.Request.Context(), &usecase.SignOutAllParams{
+		UserID: userID,
+	})
+	if err != nil {
+		http_error.Respond(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RequestPasswordReset 處理申請重設密碼請求，無論帳號是否存在都返回相同結果
+func (h *UserHandler) RequestPasswordReset(c *gin.Context) {
+	var req usecase.RequestPasswordResetParams
+
+	// 綁定並驗證請求體
+	if err := c.ShouldBindJSON(&req); err != nil {
+		http_error.ResponseBadRequest(c, nil)
+		return
+	}
+
+	// 執行申請重設密碼用例
+	err := h.requestPasswordResetUC.Execute(c.Request.Context(), &usecase.RequestPasswordResetParams{
+		Email: req.Email,
+	})
+	if err != nil {
+		http_error.Respond(c, err)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// ResetPassword 處理重設密碼請求
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	var req usecase.ResetPasswordParams
+
+	// 綁定並驗證請求體
+	if err := c.ShouldBindJSON(&req); err != nil {
+		http_error.ResponseBadRequest(c, nil)
+		return
+	}
+
+	// 執行重設密碼用例
+	err := h.resetPasswordUC.Execute(c.Request.Context(), &usecase.ResetPasswordParams{
+		Token:       req.Token,
+		NewPassword: req.NewPassword,
+	})
+	if err != nil {
+		http_error.Respond(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// VerifyEmail 處理驗證電子郵件請求，token 由驗證信中的連結帶入 query string
+func (h *UserHandler) VerifyEmail(c *gin.Context) {
+	var req usecase.VerifyEmailParams
+
+	// 綁定 query string
+	if err := c.ShouldBindQuery(&req); err != nil {
+		http_error.ResponseBadRequest(c, nil)
+		return
+	}
+
+	// 執行驗證電子郵件用例
+	err := h.verifyEmailUC.Execute(c.Request.Context(), &usecase.VerifyEmailParams{
+		Token: req.Token,
+	})
+	if err != nil {
+		http_error.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"email_verified": true,
+	})
+}
+
+// ResendVerificationEmail 處理重新寄送驗證信請求
+func (h *UserHandler) ResendVerificationEmail(c *gin.Context) {
+	userID, err := h.currentUserID(c)
+	if err != nil {
+		http_error.Respond(c, err)
+		return
+	}
+
+	// 執行重新寄送驗證信用例
+	err = h.resendVerificationEmailUC.Execute(c.Request.Context(), &usecase.ResendVerificationEmailParams{
+		UserID: userID,
+	})
+	if err != nil {
+		http_error.Respond(c, err)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// CreatePersonalAccessToken 處理建立 personal access token 請求，token 只在此回應中返回一次
+func (h *UserHandler) CreatePersonalAccessToken(c *gin.Context) {
+	var req usecase.CreatePersonalAccessTokenParams
+
+	// 綁定並驗證請求體
+	if err := c.ShouldBindJSON(&req); err != nil {
+		http_error.ResponseBadRequest(c, nil)
+		return
+	}
+
+	userID, err := h.currentUserID(c)
+	if err != nil {
+		http_error.Respond(c, err)
+		return
+	}
+
+	// 執行建立 personal access token 用例
+	result, err := h.createPersonalAccessTokenUC.Execute(c.Request.Context(), &usecase.CreatePersonalAccessTokenParams{
+		UserID:    userID,
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
 	})
+	if err != nil {
+		http_error.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// ListPersonalAccessTokens 處理列出自己尚未撤銷的 personal access token 請求
+func (h *UserHandler) ListPersonalAccessTokens(c *gin.Context) {
+	userID, err := h.currentUserID(c)
+	if err != nil {
+		http_error.Respond(c, err)
+		return
+	}
+
+	// 執行列出 personal access token 用例
+	result, err := h.listPersonalAccessTokensUC.Execute(c.Request.Context(), &usecase.ListPersonalAccessTokensParams{
+		UserID: userID,
+	})
+	if err != nil {
+		http_error.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// RevokePersonalAccessToken 處理撤銷 personal access token 請求
+func (h *UserHandler) RevokePersonalAccessToken(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		http_error.ResponseBadRequest(c, nil)
+		return
+	}
+
+	userID, err := h.currentUserID(c)
+	if err != nil {
+		http_error.Respond(c, err)
+		return
+	}
+
+	// 執行撤銷 personal access token 用例
+	if err := h.revokePersonalAccessTokenUC.Execute(c.Request.Context(), &usecase.RevokePersonalAccessTokenParams{
+		UserID: userID,
+		ID:     id,
+	}); err != nil {
+		http_error.Respond(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// currentUserID 從 context 取得目前登入的使用者 ID
+func (h *UserHandler) currentUserID(c *gin.Context) (int, error) {
+	userID, err := auth.GetUserIDFromContext(c)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(userID)
 }
@@ -0,0 +1,63 @@
+package domain
+
+import "time"
+
+const (
+	// EmailVerificationTokenExpiration 電子郵件驗證 token 的有效期限
+	EmailVerificationTokenExpiration = 24 * time.Hour
+	// EmailVerificationResendInterval 重新寄送驗證信的最短間隔
+	EmailVerificationResendInterval = 1 * time.Minute
+)
+
+type EmailVerificationTokenParams EmailVerificationToken
+
+// EmailVerificationToken 實體代表寄送給使用者的電子郵件驗證 token
+// 資料庫只保存 token 的雜湊值；token 只能使用一次
+type EmailVerificationToken struct {
+	ID        int
+	UserID    int
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// NewEmailVerificationToken 建立新的 EmailVerificationToken 實體
+func NewEmailVerificationToken(params EmailVerificationTokenParams) (*EmailVerificationToken, error) {
+	if params.CreatedAt.IsZero() {
+		params.CreatedAt = time.Now().UTC()
+	}
+	if params.ExpiresAt.IsZero() {
+		params.ExpiresAt = params.CreatedAt.Add(EmailVerificationTokenExpiration)
+	}
+
+	emailVerificationToken := &EmailVerificationToken{
+		ID:        params.ID,
+		UserID:    params.UserID,
+		TokenHash: params.TokenHash,
+		ExpiresAt: params.ExpiresAt,
+		UsedAt:    params.UsedAt,
+		CreatedAt: params.CreatedAt,
+	}
+
+	return emailVerificationToken, nil
+}
+
+// IsExpired 判斷電子郵件驗證 token 是否已過期
+func (t *EmailVerificationToken) IsExpired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
+
+// IsUsed 判斷電子郵件驗證 token 是否已被使用
+func (t *EmailVerificationToken) IsUsed() bool {
+	return t.UsedAt != nil
+}
+
+// ResendAvailableIn 距離可以重新寄送驗證信的時間，可立即寄送時返回 0
+func (t *EmailVerificationToken) ResendAvailableIn(now time.Time) time.Duration {
+	wait := t.CreatedAt.Add(EmailVerificationResendInterval).Sub(now)
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
@@ -1,6 +1,11 @@
 package domain
 
-import "github.com/cockroachdb/errors"
+import (
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
 
 var (
 	// ErrInvalidParams 參數錯誤
@@ -11,4 +16,76 @@ var (
 
 	// ErrInvalidCredentials 登入憑證錯誤（帳號或密碼錯誤）
 	ErrInvalidCredentials = errors.New("invalid credentials")
+
+	// ErrTooManyLoginAttempts 登入失敗次數過多，暫時鎖定
+	ErrTooManyLoginAttempts = errors.New("too many failed sign-in attempts")
+)
+
+// LoginLockedError 登入被暫時鎖定，RetryAfter 為距離解除鎖定的時間
+// 可透過 errors.Is(err, ErrTooManyLoginAttempts) 判斷
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrTooManyLoginAttempts, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LoginLockedError) Unwrap() error {
+	return ErrTooManyLoginAttempts
+}
+
+var (
+	// ErrInvalidRefreshToken refresh token 不存在或已被撤銷
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+
+	// ErrRefreshTokenExpired refresh token 已過期
+	ErrRefreshTokenExpired = errors.New("refresh token has expired")
+
+	// ErrRefreshTokenReused 已輪替過的 refresh token 被再次使用，整個 token family 已被撤銷
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+)
+
+var (
+	// ErrInvalidPasswordResetToken 密碼重設 token 不存在或已被使用
+	ErrInvalidPasswordResetToken = errors.New("invalid password reset token")
+
+	// ErrPasswordResetTokenExpired 密碼重設 token 已過期
+	ErrPasswordResetTokenExpired = errors.New("password reset token has expired")
+)
+
+var (
+	// ErrInvalidEmailVerificationToken 電子郵件驗證 token 不存在或已被使用
+	ErrInvalidEmailVerificationToken = errors.New("invalid email verification token")
+
+	// ErrEmailVerificationTokenExpired 電子郵件驗證 token 已過期
+	ErrEmailVerificationTokenExpired = errors.New("email verification token has expired")
+
+	// ErrEmailAlreadyVerified 電子郵件已完成驗證
+	ErrEmailAlreadyVerified = errors.New("email already verified")
+
+	// ErrEmailNotVerified 電子郵件尚未驗證，無法執行需要驗證的操作
+	ErrEmailNotVerified = errors.New("email not verified")
+
+	// ErrVerificationEmailThrottled 重新寄送驗證信過於頻繁
+	ErrVerificationEmailThrottled = errors.New("verification email was sent recently")
+)
+
+// VerificationEmailThrottledError 重新寄送驗證信過於頻繁，RetryAfter 為距離可再次寄送的時間
+// 可透過 errors.Is(err, ErrVerificationEmailThrottled) 判斷
+type VerificationEmailThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *VerificationEmailThrottledError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrVerificationEmailThrottled, e.RetryAfter.Round(time.Second))
+}
+
+func (e *VerificationEmailThrottledError) Unwrap() error {
+	return ErrVerificationEmailThrottled
+}
+
+var (
+	// ErrPersonalAccessTokenNotFound personal access token 不存在、已被撤銷或不屬於目前使用者
+	ErrPersonalAccessTokenNotFound = errors.New("personal access token not found")
 )
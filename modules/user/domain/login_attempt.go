@@ -0,0 +1,90 @@
+package domain
+
+import "time"
+
+// LoginAttempt 記錄某個 key（email 或 IP）連續登入失敗的次數與鎖定時間
+type LoginAttempt struct {
+	Key           string
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   time.Time
+}
+
+// LockoutPolicy 登入失敗的鎖定策略
+// 失敗次數達到 MaxFailures 後開始鎖定，之後每次失敗鎖定時間加倍，最長為 MaxLockout
+type LockoutPolicy struct {
+	// MaxFailures 開始鎖定前允許的失敗次數
+	MaxFailures int
+	// BaseLockout 第一次鎖定的時間
+	BaseLockout time.Duration
+	// MaxLockout 鎖定時間上限
+	MaxLockout time.Duration
+	// ResetAfter 最後一次失敗超過此時間後，失敗次數歸零
+	ResetAfter time.Duration
+}
+
+var (
+	// DefaultEmailLockoutPolicy 以 email 計算的預設鎖定策略
+	DefaultEmailLockoutPolicy = LockoutPolicy{
+		MaxFailures: 5,
+		BaseLockout: 30 * time.Second,
+		MaxLockout:  15 * time.Minute,
+		ResetAfter:  time.Hour,
+	}
+
+	// DefaultIPLockoutPolicy 以 IP 計算的預設鎖定策略
+	// 同一個 IP 可能有多位使用者（如 NAT），因此允許較多的失敗次數
+	DefaultIPLockoutPolicy = LockoutPolicy{
+		MaxFailures: 20,
+		BaseLockout: 30 * time.Second,
+		MaxLockout:  15 * time.Minute,
+		ResetAfter:  time.Hour,
+	}
+)
+
+// LockoutDuration 計算第 failures 次失敗後的鎖定時間
+func (p LockoutPolicy) LockoutDuration(failures int) time.Duration {
+	if failures < p.MaxFailures {
+		return 0
+	}
+
+	lockout := p.BaseLockout
+	for i := p.MaxFailures; i < failures; i++ {
+		lockout *= 2
+		if lockout >= p.MaxLockout {
+			return p.MaxLockout
+		}
+	}
+	return lockout
+}
+
+// IsStale 判斷失敗紀錄是否已超過 ResetAfter 且不在鎖定中，可視為歸零
+func (a *LoginAttempt) IsStale(policy LockoutPolicy, now time.Time) bool {
+	return !a.IsLocked(now) && now.Sub(a.LastFailureAt) >= policy.ResetAfter
+}
+
+// IsLocked 判斷目前是否在鎖定中
+func (a *LoginAttempt) IsLocked(now time.Time) bool {
+	return now.Before(a.LockedUntil)
+}
+
+// RetryAfter 計算距離解除鎖定的時間
+func (a *LoginAttempt) RetryAfter(now time.Time) time.Duration {
+	if !a.IsLocked(now) {
+		return 0
+	}
+	return a.LockedUntil.Sub(now)
+}
+
+// RegisterFailure 累加失敗次數，並依策略更新鎖定時間
+func (a *LoginAttempt) RegisterFailure(policy LockoutPolicy, now time.Time) {
+	if a.IsStale(policy, now) {
+		a.Failures = 0
+	}
+
+	a.Failures++
+	a.LastFailureAt = now
+	if lockout := policy.LockoutDuration(a.Failures); lockout > 0 {
+		a.LockedUntil = now.Add(lockout)
+	}
+}
@@ -0,0 +1,10 @@
+package domain
+
+import "context"
+
+// Mailer 寄送電子郵件給使用者
+// 正式環境可替換為 SMTP 或第三方寄信服務，開發與測試時使用 outbox 實作
+type Mailer interface {
+	// Send 寄送純文字電子郵件
+	Send(ctx context.Context, to string, subject string, body string) error
+}
@@ -0,0 +1,14 @@
+package domain
+
+// PasswordHasher 密碼雜湊器
+// 雜湊值需自帶演算法與參數（如 $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>），以便日後調整參數
+type PasswordHasher interface {
+	// Hash 以目前設定的演算法與參數產生密碼雜湊值
+	Hash(password string) (string, error)
+
+	// Verify 以固定時間比對密碼與雜湊值是否相符
+	Verify(password string, encodedHash string) (bool, error)
+
+	// NeedsRehash 判斷雜湊值是否為明文或使用了過時的演算法與參數，需要重新雜湊
+	NeedsRehash(encodedHash string) bool
+}
@@ -0,0 +1,50 @@
+package domain
+
+import "time"
+
+// PasswordResetTokenExpiration 密碼重設 token 的有效期限
+const PasswordResetTokenExpiration = 1 * time.Hour
+
+type PasswordResetTokenParams PasswordResetToken
+
+// PasswordResetToken 實體代表寄送給使用者的密碼重設 token
+// 資料庫只保存 token 的雜湊值；token 只能使用一次
+type PasswordResetToken struct {
+	ID        int
+	UserID    int
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// NewPasswordResetToken 建立新的 PasswordResetToken 實體
+func NewPasswordResetToken(params PasswordResetTokenParams) (*PasswordResetToken, error) {
+	if params.CreatedAt.IsZero() {
+		params.CreatedAt = time.Now().UTC()
+	}
+	if params.ExpiresAt.IsZero() {
+		params.ExpiresAt = params.CreatedAt.Add(PasswordResetTokenExpiration)
+	}
+
+	passwordResetToken := &PasswordResetToken{
+		ID:        params.ID,
+		UserID:    params.UserID,
+		TokenHash: params.TokenHash,
+		ExpiresAt: params.ExpiresAt,
+		UsedAt:    params.UsedAt,
+		CreatedAt: params.CreatedAt,
+	}
+
+	return passwordResetToken, nil
+}
+
+// IsExpired 判斷密碼重設 token 是否已過期
+func (t *PasswordResetToken) IsExpired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
+
+// IsUsed 判斷密碼重設 token 是否已被使用
+func (t *PasswordResetToken) IsUsed() bool {
+	return t.UsedAt != nil
+}
@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// Scope personal access token 可以存取的權限
+type Scope string
+
+const (
+	// ScopePortalPagesRead 讀取自己的 Portal Page
+	ScopePortalPagesRead Scope = "portal_pages:read"
+	// ScopePortalPagesWrite 建立與更新自己的 Portal Page
+	ScopePortalPagesWrite Scope = "portal_pages:write"
+)
+
+// Scopes 所有可指定給 personal access token 的權限
+var Scopes = []Scope{ScopePortalPagesRead, ScopePortalPagesWrite}
+
+// IsValid 判斷是否為支援的權限
+func (s Scope) IsValid() bool {
+	return slices.Contains(Scopes, s)
+}
+
+type PersonalAccessTokenParams PersonalAccessToken
+
+// PersonalAccessToken 實體代表使用者為腳本或 CI 建立的 personal access token
+// 資料庫只保存 token 的雜湊值；token 只能存取 Scopes 中的權限，撤銷或過期後即失效
+type PersonalAccessToken struct {
+	ID        int
+	UserID    int
+	Name      string
+	TokenHash string
+	Scopes    []Scope
+	// ExpiresAt 為 nil 時不會過期
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
+
+// NewPersonalAccessToken 建立新的 PersonalAccessToken 實體
+func NewPersonalAccessToken(params PersonalAccessTokenParams) (*PersonalAccessToken, error) {
+	if params.CreatedAt.IsZero() {
+		params.CreatedAt = time.Now().UTC()
+	}
+
+	personalAccessToken := &PersonalAccessToken{
+		ID:         params.ID,
+		UserID:     params.UserID,
+		Name:       params.Name,
+		TokenHash:  params.TokenHash,
+		Scopes:     slices.Clone(params.Scopes),
+		ExpiresAt:  params.ExpiresAt,
+		LastUsedAt: params.LastUsedAt,
+		RevokedAt:  params.RevokedAt,
+		CreatedAt:  params.CreatedAt,
+	}
+
+	return personalAccessToken, nil
+}
+
+// IsExpired 判斷 personal access token 是否已過期
+func (t *PersonalAccessToken) IsExpired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
+
+// IsRevoked 判斷 personal access token 是否已被撤銷
+func (t *PersonalAccessToken) IsRevoked() bool {
+	return t.RevokedAt != nil
+}
+
+// HasScopes 判斷 personal access token 是否具有所有指定的權限
+func (t *PersonalAccessToken) HasScopes(scopes ...Scope) bool {
+	for _, scope := range scopes {
+		if !slices.Contains(t.Scopes, scope) {
+			return false
+		}
+	}
+	return true
+}
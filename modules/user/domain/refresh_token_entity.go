@@ -0,0 +1,53 @@
+package domain
+
+import "time"
+
+type RefreshTokenParams RefreshToken
+
+// RefreshToken 實體代表簽發給使用者的 refresh token
+// 資料庫只保存 token 的雜湊值；每次使用後都會輪替成同一個 family 的新 token
+type RefreshToken struct {
+	ID        int
+	UserID    int
+	FamilyID  string
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+// NewRefreshToken 建立新的 RefreshToken 實體
+func NewRefreshToken(params RefreshTokenParams) (*RefreshToken, error) {
+	if params.CreatedAt.IsZero() {
+		params.CreatedAt = time.Now().UTC()
+	}
+
+	refreshToken := &RefreshToken{
+		ID:        params.ID,
+		UserID:    params.UserID,
+		FamilyID:  params.FamilyID,
+		TokenHash: params.TokenHash,
+		ExpiresAt: params.ExpiresAt,
+		UsedAt:    params.UsedAt,
+		RevokedAt: params.RevokedAt,
+		CreatedAt: params.CreatedAt,
+	}
+
+	return refreshToken, nil
+}
+
+// IsExpired 判斷 refresh token 是否已過期
+func (t *RefreshToken) IsExpired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
+
+// IsUsed 判斷 refresh token 是否已被輪替過
+func (t *RefreshToken) IsUsed() bool {
+	return t.UsedAt != nil
+}
+
+// IsRevoked 判斷 refresh token 是否已被撤銷
+func (t *RefreshToken) IsRevoked() bool {
+	return t.RevokedAt != nil
+}
@@ -1,6 +1,9 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // UserRepository 使用者 Repository
 type UserRepository interface {
@@ -12,4 +15,98 @@ type UserRepository interface {
 
 	// Find 根據 ID 獲取使用者
 	Find(ctx context.Context, id int) (*User, error)
+
+	// Update 更新使用者
+	Update(ctx context.Context, user *User) error
+}
+
+// RefreshTokenRepository Refresh Token Repository
+type RefreshTokenRepository interface {
+	// Create 建立 refresh token
+	Create(ctx context.Context, refreshToken *RefreshToken) error
+
+	// GetByTokenHash 根據 token 雜湊值獲取 refresh token
+	GetByTokenHash(ctx context.Context, tokenHash string) (*RefreshToken, error)
+
+	// Rotate 將 current 標記為已使用並建立 next
+	// 若 current 已被使用或撤銷，返回 ErrRefreshTokenReused 且不建立 next
+	Rotate(ctx context.Context, current *RefreshToken, next *RefreshToken) error
+
+	// RevokeFamily 撤銷同一個 family 中所有尚未撤銷的 refresh token
+	RevokeFamily(ctx context.Context, familyID string) error
+
+	// RevokeByUserID 撤銷使用者所有尚未撤銷的 refresh token
+	RevokeByUserID(ctx context.Context, userID int) error
+}
+
+// LoginAttemptRepository 登入失敗紀錄 Repository
+type LoginAttemptRepository interface {
+	// Get 根據 key 獲取登入失敗紀錄
+	Get(ctx context.Context, key string) (*LoginAttempt, error)
+
+	// RecordFailure 原子地累加 key 的失敗次數，並依 policy 更新鎖定時間
+	RecordFailure(ctx context.Context, key string, policy LockoutPolicy, now time.Time) (*LoginAttempt, error)
+
+	// Reset 清除 key 的失敗紀錄
+	Reset(ctx context.Context, key string) error
+}
+
+// PasswordResetTokenRepository 密碼重設 Token Repository
+type PasswordResetTokenRepository interface {
+	// Create 建立密碼重設 token
+	Create(ctx context.Context, passwordResetToken *PasswordResetToken) error
+
+	// GetByTokenHash 根據 token 雜湊值獲取密碼重設 token
+	GetByTokenHash(ctx context.Context, tokenHash string) (*PasswordResetToken, error)
+
+	// MarkUsed 將密碼重設 token 標記為已使用
+	// 若 token 已被使用，返回 ErrInvalidPasswordResetToken
+	MarkUsed(ctx context.Context, passwordResetToken *PasswordResetToken) error
+
+	// InvalidateByUserID 將使用者所有尚未使用的密碼重設 token 標記為已使用
+	InvalidateByUserID(ctx context.Context, userID int) error
+}
+
+// EmailVerificationTokenRepository 電子郵件驗證 Token Repository
+type EmailVerificationTokenRepository interface {
+	// Create 建立電子郵件驗證 token
+	Create(ctx context.Context, emailVerificationToken *EmailVerificationToken) error
+
+	// GetByTokenHash 根據 token 雜湊值獲取電子郵件驗證 token
+	GetByTokenHash(ctx context.Context, tokenHash string) (*EmailVerificationToken, error)
+
+	// GetLatestByUserID 獲取使用者最近建立的電子郵件驗證 token
+	GetLatestByUserID(ctx context.Context, userID int) (*EmailVerificationToken, error)
+
+	// MarkUsed 將電子郵件驗證 token 標記為已使用
+	// 若 token 已被使用，返回 ErrInvalidEmailVerificationToken
+	MarkUsed(ctx context.Context, emailVerificationToken *EmailVerificationToken) error
+
+	// InvalidateByUserID 將使用者所有尚未使用的電子郵件驗證 token 標記為已使用
+	InvalidateByUserID(ctx context.Context, userID int) error
+}
+
+// PersonalAccessTokenRepository Personal Access Token Repository
+type PersonalAccessTokenRepository interface {
+	// Create 建立 personal access token
+	Create(ctx context.Context, personalAccessToken *PersonalAccessToken) error
+
+	// Find 根據 ID 獲取 personal access token
+	Find(ctx context.Context, id int) (*PersonalAccessToken, error)
+
+	// GetByTokenHash 根據 token 雜湊值獲取 personal access token
+	GetByTokenHash(ctx context.Context, tokenHash string) (*PersonalAccessToken, error)
+
+	// ListByUserID 依建立順序列出使用者尚未撤銷的 personal access token
+	ListByUserID(ctx context.Context, userID int) ([]*PersonalAccessToken, error)
+
+	// Revoke 撤銷 personal access token
+	// 若 token 已被撤銷，返回 ErrPersonalAccessTokenNotFound
+	Revoke(ctx context.Context, personalAccessToken *PersonalAccessToken) error
+
+	// MarkUsed 更新 personal access token 的最後使用時間
+	MarkUsed(ctx context.Context, id int, usedAt time.Time) error
+
+	// RevokeByUserID 撤銷使用者所有尚未撤銷的 personal access token
+	RevokeByUserID(ctx context.Context, userID int) error
 }
@@ -0,0 +1,31 @@
+package domain
+
+// 登入結果，用於 SignInRecorder
+const (
+	SignInOutcomeSuccess            = "success"
+	SignInOutcomeInvalidCredentials = "invalid_credentials"
+	SignInOutcomeLocked             = "locked"
+	SignInOutcomeInvalidParams      = "invalid_params"
+	SignInOutcomeError              = "error"
+)
+
+// 鎖定範圍，用於 SignInRecorder
+const (
+	LockoutScopeEmail = "email"
+	LockoutScopeIP    = "ip"
+)
+
+// SignInRecorder 記錄登入結果與鎖定次數，用於監控（例如 Prometheus counter）
+type SignInRecorder interface {
+	// RecordSignIn 記錄一次登入的結果，outcome 為 SignInOutcome* 之一
+	RecordSignIn(outcome string)
+	// RecordLockout 記錄一次因失敗次數過多而開始的鎖定，scope 為 LockoutScope* 之一
+	RecordLockout(scope string)
+}
+
+// NopSignInRecorder 不記錄任何資料的 SignInRecorder
+type NopSignInRecorder struct{}
+
+func (NopSignInRecorder) RecordSignIn(outcome string) {}
+
+func (NopSignInRecorder) RecordLockout(scope string) {}
@@ -6,12 +6,13 @@ type UserParams User
 
 // User 實體代表使用 Portal Link 的使用者
 type User struct {
-	ID        int
-	Name      string
-	Email     string
-	Password  string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID              int
+	Name            string
+	Email           string
+	Password        string
+	EmailVerifiedAt *time.Time // 電子郵件驗證完成的時間，尚未驗證時為空
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
 }
 
 // NewUser 建立新的 User 實體
@@ -27,13 +28,28 @@ func NewUser(params UserParams) (*User, error) {
 	}
 
 	user := &User{
-		ID:        params.ID,
-		Name:      params.Name,
-		Email:     params.Email,
-		Password:  params.Password,
-		CreatedAt: params.CreatedAt,
-		UpdatedAt: params.UpdatedAt,
+		ID:              params.ID,
+		Name:            params.Name,
+		Email:           params.Email,
+		Password:        params.Password,
+		EmailVerifiedAt: params.EmailVerifiedAt,
+		CreatedAt:       params.CreatedAt,
+		UpdatedAt:       params.UpdatedAt,
 	}
 
 	return user, nil
 }
+
+// IsEmailVerified 判斷使用者是否已完成電子郵件驗證
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// VerifyEmail 將電子郵件標記為已驗證，已驗證過時保留原本的驗證時間
+func (u *User) VerifyEmail(now time.Time) {
+	if u.IsEmailVerified() {
+		return
+	}
+	u.EmailVerifiedAt = &now
+	u.UpdatedAt = now
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"portal_link/modules/user/domain"
+	"sync"
+	"time"
+)
+
+var _ domain.EmailVerificationTokenRepository = (*InMemoryEmailVerificationTokenRepository)(nil)
+
+// InMemoryEmailVerificationTokenRepository is an in-memory implementation of EmailVerificationTokenRepository for testing
+type InMemoryEmailVerificationTokenRepository struct {
+	mu     sync.RWMutex
+	tokens map[int]*domain.EmailVerificationToken
+	hashes map[string]int // token hash -> email verification token ID mapping
+	nextID int
+}
+
+// NewInMemoryEmailVerificationTokenRepository creates a new in-memory email verification token repository
+func NewInMemoryEmailVerificationTokenRepository() *InMemoryEmailVerificationTokenRepository {
+	return &InMemoryEmailVerificationTokenRepository{
+		tokens: make(map[int]*domain.EmailVerificationToken),
+		hashes: make(map[string]int),
+		nextID: 1,
+	}
+}
+
+// Create creates a new email verification token
+func (r *InMemoryEmailVerificationTokenRepository) Create(ctx context.Context, emailVerificationToken *domain.EmailVerificationToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	emailVerificationToken.ID = r.nextID
+	r.nextID++
+
+	r.tokens[emailVerificationToken.ID] = copyEmailVerificationToken(emailVerificationToken)
+	r.hashes[emailVerificationToken.TokenHash] = emailVerificationToken.ID
+	return nil
+}
+
+// GetByTokenHash retrieves an email verification token by its hash
+func (r *InMemoryEmailVerificationTokenRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*domain.EmailVerificationToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, exists := r.hashes[tokenHash]
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+
+	return copyEmailVerificationToken(r.tokens[id]), nil
+}
+
+// GetLatestByUserID retrieves the most recently created email verification token of the given user
+func (r *InMemoryEmailVerificationTokenRepository) GetLatestByUserID(ctx context.Context, userID int) (*domain.EmailVerificationToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var latest *domain.EmailVerificationToken
+	for _, token := range r.tokens {
+		if token.UserID != userID {
+			continue
+		}
+		// IDs are assigned in creation order, so the highest ID is the latest token
+		if latest == nil || token.ID > latest.ID {
+			latest = token
+		}
+	}
+	if latest == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	return copyEmailVerificationToken(latest), nil
+}
+
+// MarkUsed marks the email verification token as used, failing if it has already been used
+func (r *InMemoryEmailVerificationTokenRepository) MarkUsed(ctx context.Context, emailVerificationToken *domain.EmailVerificationToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, exists := r.tokens[emailVerificationToken.ID]
+	if !exists {
+		return sql.ErrNoRows
+	}
+	if stored.IsUsed() {
+		return domain.ErrInvalidEmailVerificationToken
+	}
+
+	usedAt := time.Now().UTC()
+	stored.UsedAt = &usedAt
+	emailVerificationToken.UsedAt = &usedAt
+	return nil
+}
+
+// InvalidateByUserID marks every unused email verification token owned by the given user as used
+func (r *InMemoryEmailVerificationTokenRepository) InvalidateByUserID(ctx context.Context, userID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	usedAt := time.Now().UTC()
+	for _, token := range r.tokens {
+		if token.UserID == userID && !token.IsUsed() {
+			token.UsedAt = &usedAt
+		}
+	}
+	return nil
+}
+
+// Reset clears all data (useful for testing)
+func (r *InMemoryEmailVerificationTokenRepository) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tokens = make(map[int]*domain.EmailVerificationToken)
+	r.hashes = make(map[string]int)
+	r.nextID = 1
+}
+
+// copyEmailVerificationToken returns a copy so callers cannot mutate the stored state
+func copyEmailVerificationToken(emailVerificationToken *domain.EmailVerificationToken) *domain.EmailVerificationToken {
+	copied := *emailVerificationToken
+	if emailVerificationToken.UsedAt != nil {
+		usedAt := *emailVerificationToken.UsedAt
+		copied.UsedAt = &usedAt
+	}
+	return &copied
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"portal_link/modules/user/domain"
+	"portal_link/modules/user/repository/repositorytest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEmailVerificationToken(t *testing.T, userID int, tokenHash string) *domain.EmailVerificationToken {
+	t.Helper()
+
+	emailVerificationToken, err := domain.NewEmailVerificationToken(domain.EmailVerificationTokenParams{
+		UserID:    userID,
+		TokenHash: tokenHash,
+	})
+	require.NoError(t, err)
+	return emailVerificationToken
+}
+
+func TestInMemoryEmailVerificationTokenRepository_Conformance(t *testing.T) {
+	repositorytest.RunEmailVerificationTokenRepositorySuite(t, func(t *testing.T) repositorytest.EmailVerificationTokenFixture {
+		var nextUserID int
+		return repositorytest.EmailVerificationTokenFixture{
+			Repository: NewInMemoryEmailVerificationTokenRepository(),
+			NewUserID: func(t *testing.T) int {
+				nextUserID++
+				return nextUserID
+			},
+		}
+	})
+}
+
+func TestInMemoryEmailVerificationTokenRepository_GetLatestByUserID(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryEmailVerificationTokenRepository()
+
+	_, err := repo.GetLatestByUserID(ctx, 1)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	require.NoError(t, repo.Create(ctx, newTestEmailVerificationToken(t, 1, "hash-1")))
+	require.NoError(t, repo.Create(ctx, newTestEmailVerificationToken(t, 2, "hash-2")))
+	require.NoError(t, repo.Create(ctx, newTestEmailVerificationToken(t, 1, "hash-3")))
+
+	latest, err := repo.GetLatestByUserID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "hash-3", latest.TokenHash)
+
+	latest, err = repo.GetLatestByUserID(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "hash-2", latest.TokenHash)
+}
+
+func TestInMemoryEmailVerificationTokenRepository_MarkUsed(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryEmailVerificationTokenRepository()
+
+	token := newTestEmailVerificationToken(t, 1, "hash-1")
+	require.NoError(t, repo.Create(ctx, token))
+
+	found, err := repo.GetByTokenHash(ctx, "hash-1")
+	require.NoError(t, err)
+	require.NoError(t, repo.MarkUsed(ctx, found))
+	assert.True(t, found.IsUsed())
+
+	// 同一個 token 不能使用第二次
+	err = repo.MarkUsed(ctx, token)
+	assert.ErrorIs(t, err, domain.ErrInvalidEmailVerificationToken)
+
+	_, err = repo.GetByTokenHash(ctx, "missing")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestInMemoryEmailVerificationTokenRepository_InvalidateByUserID(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryEmailVerificationTokenRepository()
+
+	require.NoError(t, repo.Create(ctx, newTestEmailVerificationToken(t, 1, "hash-1")))
+	require.NoError(t, repo.Create(ctx, newTestEmailVerificationToken(t, 2, "hash-2")))
+
+	require.NoError(t, repo.InvalidateByUserID(ctx, 1))
+
+	for hash, used := range map[string]bool{"hash-1": true, "hash-2": false} {
+		found, err := repo.GetByTokenHash(ctx, hash)
+		require.NoError(t, err)
+		assert.Equal(t, used, found.IsUsed(), hash)
+	}
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"portal_link/modules/user/domain"
+	"portal_link/pkg/database"
+	"time"
+)
+
+var _ domain.EmailVerificationTokenRepository = (*SQLEmailVerificationTokenRepository)(nil)
+
+// SQLEmailVerificationTokenRepository is a database/sql implementation of EmailVerificationTokenRepository shared by
+// every driver supported by pkg/database (PostgreSQL and SQLite).
+// Only the token hash is stored; queries join the use case transaction carried by ctx, if any.
+type SQLEmailVerificationTokenRepository struct {
+	db *sql.DB
+}
+
+// NewSQLEmailVerificationTokenRepository creates a new SQL email verification token repository
+func NewSQLEmailVerificationTokenRepository(db *sql.DB) *SQLEmailVerificationTokenRepository {
+	return &SQLEmailVerificationTokenRepository{db: db}
+}
+
+// emailVerificationTokenColumns are the columns scanned by scanEmailVerificationToken
+const emailVerificationTokenColumns = `id, user_id, token_hash, expires_at, used_at, created_at`
+
+// Create creates a new email verification token and assigns its ID
+func (r *SQLEmailVerificationTokenRepository) Create(ctx context.Context, emailVerificationToken *domain.EmailVerificationToken) error {
+	return database.Conn(ctx, r.db).QueryRowContext(ctx, `
+		INSERT INTO email_verification_tokens (user_id, token_hash, expires_at, used_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`,
+		emailVerificationToken.UserID, emailVerificationToken.TokenHash, emailVerificationToken.ExpiresAt.UTC(),
+		nullTime(emailVerificationToken.UsedAt), emailVerificationToken.CreatedAt.UTC(),
+	).Scan(&emailVerificationToken.ID)
+}
+
+// GetByTokenHash retrieves an email verification token by its hash
+func (r *SQLEmailVerificationTokenRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*domain.EmailVerificationToken, error) {
+	return scanEmailVerificationToken(database.Conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT `+emailVerificationTokenColumns+`
+		FROM email_verification_tokens
+		WHERE token_hash = $1`, tokenHash,
+	))
+}
+
+// GetLatestByUserID retrieves the most recently created email verification token of the given user
+func (r *SQLEmailVerificationTokenRepository) GetLatestByUserID(ctx context.Context, userID int) (*domain.EmailVerificationToken, error) {
+	// IDs are assigned in creation order, so the highest ID is the latest token
+	return scanEmailVerificationToken(database.Conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT `+emailVerificationTokenColumns+`
+		FROM email_verification_tokens
+		WHERE user_id = $1
+		ORDER BY id DESC
+		LIMIT 1`, userID,
+	))
+}
+
+// MarkUsed marks the email verification token as used, failing if it has already been used.
+// The conditional update lets only one of several concurrent uses of the same token succeed.
+func (r *SQLEmailVerificationTokenRepository) MarkUsed(ctx context.Context, emailVerificationToken *domain.EmailVerificationToken) error {
+	conn := database.Conn(ctx, r.db)
+	usedAt := time.Now().UTC()
+	result, err := conn.ExecContext(ctx, `
+		UPDATE email_verification_tokens
+		SET used_at = $2
+		WHERE id = $1 AND used_at IS NULL`,
+		emailVerificationToken.ID, usedAt,
+	)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		// Distinguish a missing token from one that was already used
+		var id int
+		if err := conn.QueryRowContext(ctx, `SELECT id FROM email_verification_tokens WHERE id = $1`, emailVerificationToken.ID).Scan(&id); err != nil {
+			return err
+		}
+		return domain.ErrInvalidEmailVerificationToken
+	}
+
+	emailVerificationToken.UsedAt = &usedAt
+	return nil
+}
+
+// InvalidateByUserID marks every unused email verification token owned by the given user as used
+func (r *SQLEmailVerificationTokenRepository) InvalidateByUserID(ctx context.Context, userID int) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE email_verification_tokens
+		SET used_at = $2
+		WHERE user_id = $1 AND used_at IS NULL`,
+		userID, time.Now().UTC(),
+	)
+	return err
+}
+
+// scanEmailVerificationToken scans a row selected with emailVerificationTokenColumns
+func scanEmailVerificationToken(row rowScanner) (*domain.EmailVerificationToken, error) {
+	var (
+		emailVerificationToken domain.EmailVerificationToken
+		usedAt                 sql.NullTime
+	)
+	err := row.Scan(&emailVerificationToken.ID, &emailVerificationToken.UserID, &emailVerificationToken.TokenHash,
+		&emailVerificationToken.ExpiresAt, &usedAt, &emailVerificationToken.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	emailVerificationToken.ExpiresAt = emailVerificationToken.ExpiresAt.UTC()
+	emailVerificationToken.CreatedAt = emailVerificationToken.CreatedAt.UTC()
+	emailVerificationToken.UsedAt = timePtr(usedAt)
+	return &emailVerificationToken, nil
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"portal_link/modules/user/domain"
+	"sync"
+	"time"
+)
+
+var _ domain.LoginAttemptRepository = (*InMemoryLoginAttemptRepository)(nil)
+
+// InMemoryLoginAttemptRepository is an in-memory implementation of LoginAttemptRepository
+type InMemoryLoginAttemptRepository struct {
+	mu       sync.Mutex
+	attempts map[string]*domain.LoginAttempt
+}
+
+// NewInMemoryLoginAttemptRepository creates a new in-memory login attempt repository
+func NewInMemoryLoginAttemptRepository() *InMemoryLoginAttemptRepository {
+	return &InMemoryLoginAttemptRepository{
+		attempts: make(map[string]*domain.LoginAttempt),
+	}
+}
+
+// Get retrieves the login attempt record for a key
+func (r *InMemoryLoginAttemptRepository) Get(ctx context.Context, key string) (*domain.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempt, exists := r.attempts[key]
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+
+	copied := *attempt
+	return &copied, nil
+}
+
+// RecordFailure increments the failure counter for a key and applies the lockout policy
+func (r *InMemoryLoginAttemptRepository) RecordFailure(ctx context.Context, key string, policy domain.LockoutPolicy, now time.Time) (*domain.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Drop stale records so the map does not grow without bound
+	for k, attempt := range r.attempts {
+		if k != key && attempt.IsStale(policy, now) {
+			delete(r.attempts, k)
+		}
+	}
+
+	attempt, exists := r.attempts[key]
+	if !exists {
+		attempt = &domain.LoginAttempt{Key: key}
+		r.attempts[key] = attempt
+	}
+	attempt.RegisterFailure(policy, now)
+
+	copied := *attempt
+	return &copied, nil
+}
+
+// Reset clears the failure counter for a key
+func (r *InMemoryLoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.attempts, key)
+	return nil
+}
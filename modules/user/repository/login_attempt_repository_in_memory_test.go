@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"portal_link/modules/user/domain"
+	"portal_link/modules/user/repository/repositorytest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testLockoutPolicy = domain.LockoutPolicy{
+	MaxFailures: 3,
+	BaseLockout: time.Minute,
+	MaxLockout:  5 * time.Minute,
+	ResetAfter:  time.Hour,
+}
+
+func TestInMemoryLoginAttemptRepository_Conformance(t *testing.T) {
+	repositorytest.RunLoginAttemptRepositorySuite(t, func(t *testing.T) domain.LoginAttemptRepository {
+		return NewInMemoryLoginAttemptRepository()
+	})
+}
+
+func TestInMemoryLoginAttemptRepository_RecordFailure(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("locks after max failures with exponential backoff", func(t *testing.T) {
+		repo := NewInMemoryLoginAttemptRepository()
+
+		expected := []time.Duration{0, 0, time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute}
+		for i, lockout := range expected {
+			attempt, err := repo.RecordFailure(ctx, "email:a@example.com", testLockoutPolicy, now)
+			require.NoError(t, err)
+			assert.Equal(t, i+1, attempt.Failures)
+			assert.Equal(t, lockout, attempt.RetryAfter(now), "failure %d", i+1)
+		}
+	})
+
+	t.Run("resets failures after the reset window", func(t *testing.T) {
+		repo := NewInMemoryLoginAttemptRepository()
+
+		_, err := repo.RecordFailure(ctx, "ip:127.0.0.1", testLockoutPolicy, now)
+		require.NoError(t, err)
+		_, err = repo.RecordFailure(ctx, "ip:127.0.0.1", testLockoutPolicy, now)
+		require.NoError(t, err)
+
+		attempt, err := repo.RecordFailure(ctx, "ip:127.0.0.1", testLockoutPolicy, now.Add(2*time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 1, attempt.Failures)
+		assert.False(t, attempt.IsLocked(now.Add(2*time.Hour)))
+	})
+
+	t.Run("keys are counted independently", func(t *testing.T) {
+		repo := NewInMemoryLoginAttemptRepository()
+
+		for i := 0; i < 3; i++ {
+			_, err := repo.RecordFailure(ctx, "email:a@example.com", testLockoutPolicy, now)
+			require.NoError(t, err)
+		}
+		_, err := repo.RecordFailure(ctx, "email:b@example.com", testLockoutPolicy, now)
+		require.NoError(t, err)
+
+		a, err := repo.Get(ctx, "email:a@example.com")
+		require.NoError(t, err)
+		assert.True(t, a.IsLocked(now))
+
+		b, err := repo.Get(ctx, "email:b@example.com")
+		require.NoError(t, err)
+		assert.False(t, b.IsLocked(now))
+	})
+}
+
+func TestInMemoryLoginAttemptRepository_Reset(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryLoginAttemptRepository()
+
+	_, err := repo.RecordFailure(ctx, "email:a@example.com", testLockoutPolicy, time.Now().UTC())
+	require.NoError(t, err)
+
+	require.NoError(t, repo.Reset(ctx, "email:a@example.com"))
+
+	_, err = repo.Get(ctx, "email:a@example.com")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
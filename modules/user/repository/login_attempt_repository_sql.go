@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"portal_link/modules/user/domain"
+	"portal_link/pkg/database"
+	"time"
+)
+
+var _ domain.LoginAttemptRepository = (*SQLLoginAttemptRepository)(nil)
+
+// SQLLoginAttemptRepository is a database/sql implementation of LoginAttemptRepository shared by
+// every driver supported by pkg/database (PostgreSQL and SQLite).
+// Every instance reads and updates the same counters, so lockouts apply across instances.
+type SQLLoginAttemptRepository struct {
+	db *sql.DB
+}
+
+// NewSQLLoginAttemptRepository creates a new SQL login attempt repository
+func NewSQLLoginAttemptRepository(db *sql.DB) *SQLLoginAttemptRepository {
+	return &SQLLoginAttemptRepository{db: db}
+}
+
+// Get retrieves the login attempt record for a key
+func (r *SQLLoginAttemptRepository) Get(ctx context.Context, key string) (*domain.LoginAttempt, error) {
+	return getLoginAttempt(ctx, database.Conn(ctx, r.db), key)
+}
+
+// RecordFailure increments the failure counter for a key and applies the lockout policy.
+// The row is inserted or locked before it is read, so concurrent failures of the same key are all counted.
+func (r *SQLLoginAttemptRepository) RecordFailure(ctx context.Context, key string, policy domain.LockoutPolicy, now time.Time) (*domain.LoginAttempt, error) {
+	now = now.UTC()
+
+	// Drop stale records so the table does not grow without bound
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, `
+		DELETE FROM login_attempts
+		WHERE key <> $1 AND last_failure_at <= $2 AND (locked_until IS NULL OR locked_until <= $3)`,
+		key, now.Add(-policy.ResetAfter), now,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var attempt *domain.LoginAttempt
+	err = database.WithTx(ctx, r.db, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO login_attempts (key, failures, last_failure_at)
+			VALUES ($1, 0, $2)
+			ON CONFLICT (key) DO UPDATE SET failures = login_attempts.failures`,
+			key, now,
+		)
+		if err != nil {
+			return err
+		}
+
+		attempt, err = getLoginAttempt(ctx, tx, key)
+		if err != nil {
+			return err
+		}
+		attempt.RegisterFailure(policy, now)
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE login_attempts
+			SET failures = $2, last_failure_at = $3, locked_until = $4
+			WHERE key = $1`,
+			key, attempt.Failures, attempt.LastFailureAt.UTC(), nullTime(lockedUntil(attempt)),
+		)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return attempt, nil
+}
+
+// Reset clears the failure counter for a key
+func (r *SQLLoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, `DELETE FROM login_attempts WHERE key = $1`, key)
+	return err
+}
+
+// getLoginAttempt reads the login attempt record for a key
+func getLoginAttempt(ctx context.Context, conn database.Executor, key string) (*domain.LoginAttempt, error) {
+	var (
+		attempt     domain.LoginAttempt
+		lockedUntil sql.NullTime
+	)
+	err := conn.QueryRowContext(ctx, `
+		SELECT key, failures, last_failure_at, locked_until
+		FROM login_attempts
+		WHERE key = $1`, key,
+	).Scan(&attempt.Key, &attempt.Failures, &attempt.LastFailureAt, &lockedUntil)
+	if err != nil {
+		return nil, err
+	}
+
+	attempt.LastFailureAt = attempt.LastFailureAt.UTC()
+	if lockedUntil.Valid {
+		attempt.LockedUntil = lockedUntil.Time.UTC()
+	}
+	return &attempt, nil
+}
+
+// lockedUntil returns the lockout expiry of the attempt, or nil if it has never been locked
+func lockedUntil(attempt *domain.LoginAttempt) *time.Time {
+	if attempt.LockedUntil.IsZero() {
+		return nil
+	}
+	return &attempt.LockedUntil
+}
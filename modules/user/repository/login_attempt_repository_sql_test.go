@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"portal_link/modules/user/domain"
+	"portal_link/modules/user/repository/repositorytest"
+	"portal_link/pkg/database/databasetest"
+	"testing"
+)
+
+func TestSQLLoginAttemptRepository(t *testing.T) {
+	for _, driver := range databasetest.Drivers {
+		t.Run(string(driver), func(t *testing.T) {
+			repositorytest.RunLoginAttemptRepositorySuite(t, func(t *testing.T) domain.LoginAttemptRepository {
+				return NewSQLLoginAttemptRepository(databasetest.Open(t, driver))
+			})
+		})
+	}
+}
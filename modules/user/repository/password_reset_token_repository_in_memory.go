@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"portal_link/modules/user/domain"
+	"portal_link/pkg/transaction"
+	"sync"
+	"time"
+)
+
+var _ domain.PasswordResetTokenRepository = (*InMemoryPasswordResetTokenRepository)(nil)
+
+// InMemoryPasswordResetTokenRepository is an in-memory implementation of PasswordResetTokenRepository for testing
+type InMemoryPasswordResetTokenRepository struct {
+	mu     sync.RWMutex
+	tokens map[int]*domain.PasswordResetToken
+	hashes map[string]int // token hash -> password reset token ID mapping
+	nextID int
+}
+
+// NewInMemoryPasswordResetTokenRepository creates a new in-memory password reset token repository
+func NewInMemoryPasswordResetTokenRepository() *InMemoryPasswordResetTokenRepository {
+	return &InMemoryPasswordResetTokenRepository{
+		tokens: make(map[int]*domain.PasswordResetToken),
+		hashes: make(map[string]int),
+		nextID: 1,
+	}
+}
+
+// Create creates a new password reset token
+func (r *InMemoryPasswordResetTokenRepository) Create(ctx context.Context, passwordResetToken *domain.PasswordResetToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	passwordResetToken.ID = r.nextID
+	r.nextID++
+
+	r.tokens[passwordResetToken.ID] = copyPasswordResetToken(passwordResetToken)
+	r.hashes[passwordResetToken.TokenHash] = passwordResetToken.ID
+	return nil
+}
+
+// GetByTokenHash retrieves a password reset token by its hash
+func (r *InMemoryPasswordResetTokenRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*domain.PasswordResetToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, exists := r.hashes[tokenHash]
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+
+	return copyPasswordResetToken(r.tokens[id]), nil
+}
+
+// MarkUsed marks the password reset token as used, failing if it has already been used
+func (r *InMemoryPasswordResetTokenRepository) MarkUsed(ctx context.Context, passwordResetToken *domain.PasswordResetToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, exists := r.tokens[passwordResetToken.ID]
+	if !exists {
+		return sql.ErrNoRows
+	}
+	if stored.IsUsed() {
+		return domain.ErrInvalidPasswordResetToken
+	}
+
+	usedAt := time.Now().UTC()
+	stored.UsedAt = &usedAt
+	passwordResetToken.UsedAt = &usedAt
+
+	transaction.RecordUndo(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		// Only clear the mark written by the transaction
+		if stored.UsedAt == &usedAt {
+			stored.UsedAt = nil
+		}
+	})
+
+	return nil
+}
+
+// InvalidateByUserID marks every unused password reset token owned by the given user as used
+func (r *InMemoryPasswordResetTokenRepository) InvalidateByUserID(ctx context.Context, userID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	usedAt := time.Now().UTC()
+	for _, token := range r.tokens {
+		if token.UserID == userID && !token.IsUsed() {
+			token.UsedAt = &usedAt
+		}
+	}
+	return nil
+}
+
+// Reset clears all data (useful for testing)
+func (r *InMemoryPasswordResetTokenRepository) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tokens = make(map[int]*domain.PasswordResetToken)
+	r.hashes = make(map[string]int)
+	r.nextID = 1
+}
+
+// copyPasswordResetToken returns a copy so callers cannot mutate the stored state
+func copyPasswordResetToken(passwordResetToken *domain.PasswordResetToken) *domain.PasswordResetToken {
+	copied := *passwordResetToken
+	if passwordResetToken.UsedAt != nil {
+		usedAt := *passwordResetToken.UsedAt
+		copied.UsedAt = &usedAt
+	}
+	return &copied
+}
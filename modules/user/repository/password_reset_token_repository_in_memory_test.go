@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"portal_link/modules/user/domain"
+	"portal_link/modules/user/repository/repositorytest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPasswordResetToken(t *testing.T, userID int, tokenHash string) *domain.PasswordResetToken {
+	t.Helper()
+
+	passwordResetToken, err := domain.NewPasswordResetToken(domain.PasswordResetTokenParams{
+		UserID:    userID,
+		TokenHash: tokenHash,
+	})
+	require.NoError(t, err)
+	return passwordResetToken
+}
+
+func TestInMemoryPasswordResetTokenRepository_Conformance(t *testing.T) {
+	repositorytest.RunPasswordResetTokenRepositorySuite(t, func(t *testing.T) repositorytest.PasswordResetTokenFixture {
+		var nextUserID int
+		return repositorytest.PasswordResetTokenFixture{
+			Repository: NewInMemoryPasswordResetTokenRepository(),
+			NewUserID: func(t *testing.T) int {
+				nextUserID++
+				return nextUserID
+			},
+		}
+	})
+}
+
+func TestInMemoryPasswordResetTokenRepository_GetByTokenHash(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryPasswordResetTokenRepository()
+
+	token := newTestPasswordResetToken(t, 1, "hash-1")
+	require.NoError(t, repo.Create(ctx, token))
+	assert.Equal(t, 1, token.ID)
+
+	found, err := repo.GetByTokenHash(ctx, "hash-1")
+	require.NoError(t, err)
+	assert.Equal(t, token.UserID, found.UserID)
+
+	// 修改回傳值不影響保存的資料
+	found.UserID = 2
+	found, err = repo.GetByTokenHash(ctx, "hash-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, found.UserID)
+
+	_, err = repo.GetByTokenHash(ctx, "missing")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestInMemoryPasswordResetTokenRepository_MarkUsed(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryPasswordResetTokenRepository()
+
+	token := newTestPasswordResetToken(t, 1, "hash-1")
+	require.NoError(t, repo.Create(ctx, token))
+
+	require.NoError(t, repo.MarkUsed(ctx, token))
+	assert.True(t, token.IsUsed())
+
+	found, err := repo.GetByTokenHash(ctx, "hash-1")
+	require.NoError(t, err)
+	assert.True(t, found.IsUsed())
+
+	// 同一個 token 不能使用第二次
+	err = repo.MarkUsed(ctx, found)
+	assert.ErrorIs(t, err, domain.ErrInvalidPasswordResetToken)
+
+	err = repo.MarkUsed(ctx, &domain.PasswordResetToken{ID: 99})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestInMemoryPasswordResetTokenRepository_InvalidateByUserID(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryPasswordResetTokenRepository()
+
+	require.NoError(t, repo.Create(ctx, newTestPasswordResetToken(t, 1, "hash-1")))
+	require.NoError(t, repo.Create(ctx, newTestPasswordResetToken(t, 1, "hash-2")))
+	require.NoError(t, repo.Create(ctx, newTestPasswordResetToken(t, 2, "hash-3")))
+
+	require.NoError(t, repo.InvalidateByUserID(ctx, 1))
+
+	for hash, used := range map[string]bool{"hash-1": true, "hash-2": true, "hash-3": false} {
+		found, err := repo.GetByTokenHash(ctx, hash)
+		require.NoError(t, err)
+		assert.Equal(t, used, found.IsUsed(), hash)
+	}
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"portal_link/modules/user/domain"
+	"portal_link/pkg/database"
+	"time"
+)
+
+var _ domain.PasswordResetTokenRepository = (*SQLPasswordResetTokenRepository)(nil)
+
+// SQLPasswordResetTokenRepository is a database/sql implementation of PasswordResetTokenRepository shared by
+// every driver supported by pkg/database (PostgreSQL and SQLite).
+// Only the token hash is stored; queries join the use case transaction carried by ctx, if any.
+type SQLPasswordResetTokenRepository struct {
+	db *sql.DB
+}
+
+// NewSQLPasswordResetTokenRepository creates a new SQL password reset token repository
+func NewSQLPasswordResetTokenRepository(db *sql.DB) *SQLPasswordResetTokenRepository {
+	return &SQLPasswordResetTokenRepository{db: db}
+}
+
+// Create creates a new password reset token and assigns its ID
+func (r *SQLPasswordResetTokenRepository) Create(ctx context.Context, passwordResetToken *domain.PasswordResetToken) error {
+	return database.Conn(ctx, r.db).QueryRowContext(ctx, `
+		INSERT INTO password_reset_tokens (user_id, token_hash, expires_at, used_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`,
+		passwordResetToken.UserID, passwordResetToken.TokenHash, passwordResetToken.ExpiresAt.UTC(),
+		nullTime(passwordResetToken.UsedAt), passwordResetToken.CreatedAt.UTC(),
+	).Scan(&passwordResetToken.ID)
+}
+
+// GetByTokenHash retrieves a password reset token by its hash
+func (r *SQLPasswordResetTokenRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*domain.PasswordResetToken, error) {
+	var (
+		passwordResetToken domain.PasswordResetToken
+		usedAt             sql.NullTime
+	)
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT id, user_id, token_hash, expires_at, used_at, created_at
+		FROM password_reset_tokens
+		WHERE token_hash = $1`, tokenHash,
+	).Scan(&passwordResetToken.ID, &passwordResetToken.UserID, &passwordResetToken.TokenHash,
+		&passwordResetToken.ExpiresAt, &usedAt, &passwordResetToken.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	passwordResetToken.ExpiresAt = passwordResetToken.ExpiresAt.UTC()
+	passwordResetToken.CreatedAt = passwordResetToken.CreatedAt.UTC()
+	passwordResetToken.UsedAt = timePtr(usedAt)
+	return &passwordResetToken, nil
+}
+
+// MarkUsed marks the password reset token as used, failing if it has already been used.
+// The conditional update lets only one of several concurrent uses of the same token succeed.
+func (r *SQLPasswordResetTokenRepository) MarkUsed(ctx context.Context, passwordResetToken *domain.PasswordResetToken) error {
+	conn := database.Conn(ctx, r.db)
+	usedAt := time.Now().UTC()
+	result, err := conn.ExecContext(ctx, `
+		UPDATE password_reset_tokens
+		SET used_at = $2
+		WHERE id = $1 AND used_at IS NULL`,
+		passwordResetToken.ID, usedAt,
+	)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		// Distinguish a missing token from one that was already used
+		var id int
+		if err := conn.QueryRowContext(ctx, `SELECT id FROM password_reset_tokens WHERE id = $1`, passwordResetToken.ID).Scan(&id); err != nil {
+			return err
+		}
+		return domain.ErrInvalidPasswordResetToken
+	}
+
+	passwordResetToken.UsedAt = &usedAt
+	return nil
+}
+
+// InvalidateByUserID marks every unused password reset token owned by the given user as used
+func (r *SQLPasswordResetTokenRepository) InvalidateByUserID(ctx context.Context, userID int) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE password_reset_tokens
+		SET used_at = $2
+		WHERE user_id = $1 AND used_at IS NULL`,
+		userID, time.Now().UTC(),
+	)
+	return err
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"portal_link/modules/user/domain"
+	"portal_link/pkg/transaction"
+	"slices"
+	"sync"
+	"time"
+)
+
+var _ domain.PersonalAccessTokenRepository = (*InMemoryPersonalAccessTokenRepository)(nil)
+
+// InMemoryPersonalAccessTokenRepository is an in-memory implementation of PersonalAccessTokenRepository
+type InMemoryPersonalAccessTokenRepository struct {
+	mu     sync.RWMutex
+	tokens map[int]*domain.PersonalAccessToken
+	hashes map[string]int // token hash -> personal access token ID mapping
+	nextID int
+}
+
+// NewInMemoryPersonalAccessTokenRepository creates a new in-memory personal access token repository
+func NewInMemoryPersonalAccessTokenRepository() *InMemoryPersonalAccessTokenRepository {
+	return &InMemoryPersonalAccessTokenRepository{
+		tokens: make(map[int]*domain.PersonalAccessToken),
+		hashes: make(map[string]int),
+		nextID: 1,
+	}
+}
+
+// Create creates a new personal access token
+func (r *InMemoryPersonalAccessTokenRepository) Create(ctx context.Context, personalAccessToken *domain.PersonalAccessToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	personalAccessToken.ID = r.nextID
+	r.nextID++
+
+	r.tokens[personalAccessToken.ID] = copyPersonalAccessToken(personalAccessToken)
+	r.hashes[personalAccessToken.TokenHash] = personalAccessToken.ID
+	return nil
+}
+
+// Find retrieves a personal access token by ID
+func (r *InMemoryPersonalAccessTokenRepository) Find(ctx context.Context, id int) (*domain.PersonalAccessToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	token, exists := r.tokens[id]
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+
+	return copyPersonalAccessToken(token), nil
+}
+
+// GetByTokenHash retrieves a personal access token by its hash
+func (r *InMemoryPersonalAccessTokenRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*domain.PersonalAccessToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, exists := r.hashes[tokenHash]
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+
+	return copyPersonalAccessToken(r.tokens[id]), nil
+}
+
+// ListByUserID lists the user's personal access tokens that are not revoked, ordered by ID
+func (r *InMemoryPersonalAccessTokenRepository) ListByUserID(ctx context.Context, userID int) ([]*domain.PersonalAccessToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tokens := make([]*domain.PersonalAccessToken, 0)
+	for _, token := range r.tokens {
+		if token.UserID == userID && !token.IsRevoked() {
+			tokens = append(tokens, copyPersonalAccessToken(token))
+		}
+	}
+	slices.SortFunc(tokens, func(a, b *domain.PersonalAccessToken) int {
+		return a.ID - b.ID
+	})
+	return tokens, nil
+}
+
+// Revoke marks a personal access token as revoked
+func (r *InMemoryPersonalAccessTokenRepository) Revoke(ctx context.Context, personalAccessToken *domain.PersonalAccessToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, exists := r.tokens[personalAccessToken.ID]
+	if !exists {
+		return sql.ErrNoRows
+	}
+	if stored.IsRevoked() {
+		return domain.ErrPersonalAccessTokenNotFound
+	}
+
+	revokedAt := time.Now().UTC()
+	stored.RevokedAt = &revokedAt
+	personalAccessToken.RevokedAt = &revokedAt
+	return nil
+}
+
+// MarkUsed updates the last used time of a personal access token
+func (r *InMemoryPersonalAccessTokenRepository) MarkUsed(ctx context.Context, id int, usedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, exists := r.tokens[id]
+	if !exists {
+		return sql.ErrNoRows
+	}
+
+	stored.LastUsedAt = &usedAt
+	return nil
+}
+
+// RevokeByUserID revokes every personal access token owned by the given user
+func (r *InMemoryPersonalAccessTokenRepository) RevokeByUserID(ctx context.Context, userID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	revokedAt := time.Now().UTC()
+	for _, token := range r.tokens {
+		if token.UserID == userID && !token.IsRevoked() {
+			token.RevokedAt = &revokedAt
+		}
+	}
+
+	transaction.RecordUndo(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		// Only clear the revocations written by the transaction
+		for _, token := range r.tokens {
+			if token.RevokedAt == &revokedAt {
+				token.RevokedAt = nil
+			}
+		}
+	})
+
+	return nil
+}
+
+// Reset clears all data (useful for testing)
+func (r *InMemoryPersonalAccessTokenRepository) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tokens = make(map[int]*domain.PersonalAccessToken)
+	r.hashes = make(map[string]int)
+	r.nextID = 1
+}
+
+// copyPersonalAccessToken returns a copy so callers cannot mutate the stored state
+func copyPersonalAccessToken(personalAccessToken *domain.PersonalAccessToken) *domain.PersonalAccessToken {
+	copied := *personalAccessToken
+	copied.Scopes = slices.Clone(personalAccessToken.Scopes)
+	if personalAccessToken.ExpiresAt != nil {
+		expiresAt := *personalAccessToken.ExpiresAt
+		copied.ExpiresAt = &expiresAt
+	}
+	if personalAccessToken.LastUsedAt != nil {
+		lastUsedAt := *personalAccessToken.LastUsedAt
+		copied.LastUsedAt = &lastUsedAt
+	}
+	if personalAccessToken.RevokedAt != nil {
+		revokedAt := *personalAccessToken.RevokedAt
+		copied.RevokedAt = &revokedAt
+	}
+	return &copied
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"portal_link/modules/user/domain"
+	"portal_link/modules/user/repository/repositorytest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryPersonalAccessTokenRepository_Conformance(t *testing.T) {
+	repositorytest.RunPersonalAccessTokenRepositorySuite(t, func(t *testing.T) repositorytest.PersonalAccessTokenFixture {
+		var nextUserID int
+		return repositorytest.PersonalAccessTokenFixture{
+			Repository: NewInMemoryPersonalAccessTokenRepository(),
+			NewUserID: func(t *testing.T) int {
+				nextUserID++
+				return nextUserID
+			},
+		}
+	})
+}
+
+func newTestPersonalAccessToken(userID int, tokenHash string) *domain.PersonalAccessToken {
+	return &domain.PersonalAccessToken{
+		UserID:    userID,
+		Name:      "ci",
+		TokenHash: tokenHash,
+		Scopes:    []domain.Scope{domain.ScopePortalPagesRead},
+		CreatedAt: time.Now().UTC(),
+	}
+}
+
+func TestInMemoryPersonalAccessTokenRepository_GetByTokenHash(t *testing.T) {
+	repo := NewInMemoryPersonalAccessTokenRepository()
+	ctx := context.Background()
+
+	t.Run("successfully retrieves personal access token by hash", func(t *testing.T) {
+		repo.Reset()
+
+		token := newTestPersonalAccessToken(1, "hash-1")
+		require.NoError(t, repo.Create(ctx, token))
+		assert.Equal(t, 1, token.ID)
+
+		retrieved, err := repo.GetByTokenHash(ctx, "hash-1")
+		require.NoError(t, err)
+		assert.Equal(t, token.ID, retrieved.ID)
+		assert.Equal(t, []domain.Scope{domain.ScopePortalPagesRead}, retrieved.Scopes)
+
+		// Mutating the returned value must not change the stored state
+		retrieved.Scopes[0] = domain.ScopePortalPagesWrite
+		again, err := repo.GetByTokenHash(ctx, "hash-1")
+		require.NoError(t, err)
+		assert.Equal(t, []domain.Scope{domain.ScopePortalPagesRead}, again.Scopes)
+	})
+
+	t.Run("returns error when hash not found", func(t *testing.T) {
+		repo.Reset()
+
+		_, err := repo.GetByTokenHash(ctx, "missing")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
+
+func TestInMemoryPersonalAccessTokenRepository_ListByUserID(t *testing.T) {
+	repo := NewInMemoryPersonalAccessTokenRepository()
+	ctx := context.Background()
+
+	first := newTestPersonalAccessToken(1, "hash-1")
+	require.NoError(t, repo.Create(ctx, first))
+	revoked := newTestPersonalAccessToken(1, "hash-2")
+	require.NoError(t, repo.Create(ctx, revoked))
+	require.NoError(t, repo.Create(ctx, newTestPersonalAccessToken(2, "hash-3")))
+	last := newTestPersonalAccessToken(1, "hash-4")
+	require.NoError(t, repo.Create(ctx, last))
+	require.NoError(t, repo.Revoke(ctx, revoked))
+
+	tokens, err := repo.ListByUserID(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, tokens, 2)
+	assert.Equal(t, first.ID, tokens[0].ID)
+	assert.Equal(t, last.ID, tokens[1].ID)
+
+	tokens, err = repo.ListByUserID(ctx, 3)
+	require.NoError(t, err)
+	assert.Empty(t, tokens)
+}
+
+func TestInMemoryPersonalAccessTokenRepository_Revoke(t *testing.T) {
+	repo := NewInMemoryPersonalAccessTokenRepository()
+	ctx := context.Background()
+
+	t.Run("marks token as revoked", func(t *testing.T) {
+		repo.Reset()
+
+		token := newTestPersonalAccessToken(1, "hash-1")
+		require.NoError(t, repo.Create(ctx, token))
+		require.NoError(t, repo.Revoke(ctx, token))
+		assert.True(t, token.IsRevoked())
+
+		stored, err := repo.Find(ctx, token.ID)
+		require.NoError(t, err)
+		assert.True(t, stored.IsRevoked())
+	})
+
+	t.Run("returns ErrPersonalAccessTokenNotFound when already revoked", func(t *testing.T) {
+		repo.Reset()
+
+		token := newTestPersonalAccessToken(1, "hash-1")
+		require.NoError(t, repo.Create(ctx, token))
+		require.NoError(t, repo.Revoke(ctx, token))
+		assert.ErrorIs(t, repo.Revoke(ctx, token), domain.ErrPersonalAccessTokenNotFound)
+	})
+
+	t.Run("returns error when token not found", func(t *testing.T) {
+		repo.Reset()
+
+		assert.ErrorIs(t, repo.Revoke(ctx, &domain.PersonalAccessToken{ID: 99}), sql.ErrNoRows)
+	})
+}
+
+func TestInMemoryPersonalAccessTokenRepository_MarkUsed(t *testing.T) {
+	repo := NewInMemoryPersonalAccessTokenRepository()
+	ctx := context.Background()
+
+	token := newTestPersonalAccessToken(1, "hash-1")
+	require.NoError(t, repo.Create(ctx, token))
+
+	usedAt := time.Now().UTC()
+	require.NoError(t, repo.MarkUsed(ctx, token.ID, usedAt))
+
+	stored, err := repo.Find(ctx, token.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored.LastUsedAt)
+	assert.True(t, usedAt.Equal(*stored.LastUsedAt))
+
+	assert.ErrorIs(t, repo.MarkUsed(ctx, 99, usedAt), sql.ErrNoRows)
+}
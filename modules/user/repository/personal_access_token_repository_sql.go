@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"portal_link/modules/user/domain"
+	"portal_link/pkg/database"
+	"strings"
+	"time"
+)
+
+var _ domain.PersonalAccessTokenRepository = (*SQLPersonalAccessTokenRepository)(nil)
+
+// SQLPersonalAccessTokenRepository is a database/sql implementation of PersonalAccessTokenRepository shared by
+// every driver supported by pkg/database (PostgreSQL and SQLite).
+// Only the token hash is stored and scopes are kept as a space separated list;
+// queries join the use case transaction carried by ctx, if any.
+type SQLPersonalAccessTokenRepository struct {
+	db *sql.DB
+}
+
+// NewSQLPersonalAccessTokenRepository creates a new SQL personal access token repository
+func NewSQLPersonalAccessTokenRepository(db *sql.DB) *SQLPersonalAccessTokenRepository {
+	return &SQLPersonalAccessTokenRepository{db: db}
+}
+
+// personalAccessTokenColumns are the columns scanned by scanPersonalAccessToken
+const personalAccessTokenColumns = `id, user_id, name, token_hash, scopes, expires_at, last_used_at, revoked_at, created_at`
+
+// Create creates a new personal access token and assigns its ID
+func (r *SQLPersonalAccessTokenRepository) Create(ctx context.Context, personalAccessToken *domain.PersonalAccessToken) error {
+	return database.Conn(ctx, r.db).QueryRowContext(ctx, `
+		INSERT INTO personal_access_tokens (user_id, name, token_hash, scopes, expires_at, last_used_at, revoked_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id`,
+		personalAccessToken.UserID, personalAccessToken.Name, personalAccessToken.TokenHash, joinScopes(personalAccessToken.Scopes),
+		nullTime(personalAccessToken.ExpiresAt), nullTime(personalAccessToken.LastUsedAt), nullTime(personalAccessToken.RevokedAt),
+		personalAccessToken.CreatedAt.UTC(),
+	).Scan(&personalAccessToken.ID)
+}
+
+// Find retrieves a personal access token by ID
+func (r *SQLPersonalAccessTokenRepository) Find(ctx context.Context, id int) (*domain.PersonalAccessToken, error) {
+	return scanPersonalAccessToken(database.Conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT `+personalAccessTokenColumns+`
+		FROM personal_access_tokens
+		WHERE id = $1`, id,
+	))
+}
+
+// GetByTokenHash retrieves a personal access token by its hash
+func (r *SQLPersonalAccessTokenRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*domain.PersonalAccessToken, error) {
+	return scanPersonalAccessToken(database.Conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT `+personalAccessTokenColumns+`
+		FROM personal_access_tokens
+		WHERE token_hash = $1`, tokenHash,
+	))
+}
+
+// ListByUserID lists the user's personal access tokens that are not revoked, ordered by ID
+func (r *SQLPersonalAccessTokenRepository) ListByUserID(ctx context.Context, userID int) ([]*domain.PersonalAccessToken, error) {
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, `
+		SELECT `+personalAccessTokenColumns+`
+		FROM personal_access_tokens
+		WHERE user_id = $1 AND revoked_at IS NULL
+		ORDER BY id`, userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := make([]*domain.PersonalAccessToken, 0)
+	for rows.Next() {
+		token, err := scanPersonalAccessToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, rows.Err()
+}
+
+// Revoke marks a personal access token as revoked.
+// The conditional update returns ErrPersonalAccessTokenNotFound when the token was already revoked.
+func (r *SQLPersonalAccessTokenRepository) Revoke(ctx context.Context, personalAccessToken *domain.PersonalAccessToken) error {
+	revokedAt := time.Now().UTC()
+	err := database.WithTx(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, `
+			UPDATE personal_access_tokens
+			SET revoked_at = $2
+			WHERE id = $1 AND revoked_at IS NULL`,
+			personalAccessToken.ID, revokedAt,
+		)
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			// Distinguish a missing token from one that was already revoked
+			var id int
+			if err := tx.QueryRowContext(ctx, `SELECT id FROM personal_access_tokens WHERE id = $1`, personalAccessToken.ID).Scan(&id); err != nil {
+				return err
+			}
+			return domain.ErrPersonalAccessTokenNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	personalAccessToken.RevokedAt = &revokedAt
+	return nil
+}
+
+// MarkUsed updates the last used time of a personal access token
+func (r *SQLPersonalAccessTokenRepository) MarkUsed(ctx context.Context, id int, usedAt time.Time) error {
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE personal_access_tokens
+		SET last_used_at = $2
+		WHERE id = $1`,
+		id, usedAt.UTC(),
+	)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// RevokeByUserID revokes every personal access token owned by the given user
+func (r *SQLPersonalAccessTokenRepository) RevokeByUserID(ctx context.Context, userID int) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE personal_access_tokens
+		SET revoked_at = $2
+		WHERE user_id = $1 AND revoked_at IS NULL`,
+		userID, time.Now().UTC(),
+	)
+	return err
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanPersonalAccessToken scans a row selected with personalAccessTokenColumns
+func scanPersonalAccessToken(row rowScanner) (*domain.PersonalAccessToken, error) {
+	var (
+		personalAccessToken              domain.PersonalAccessToken
+		scopes                           string
+		expiresAt, lastUsedAt, revokedAt sql.NullTime
+	)
+	err := row.Scan(&personalAccessToken.ID, &personalAccessToken.UserID, &personalAccessToken.Name, &personalAccessToken.TokenHash,
+		&scopes, &expiresAt, &lastUsedAt, &revokedAt, &personalAccessToken.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	personalAccessToken.Scopes = splitScopes(scopes)
+	personalAccessToken.ExpiresAt = timePtr(expiresAt)
+	personalAccessToken.LastUsedAt = timePtr(lastUsedAt)
+	personalAccessToken.RevokedAt = timePtr(revokedAt)
+	personalAccessToken.CreatedAt = personalAccessToken.CreatedAt.UTC()
+	return &personalAccessToken, nil
+}
+
+// joinScopes stores scopes as a space separated list
+func joinScopes(scopes []domain.Scope) string {
+	values := make([]string, len(scopes))
+	for i, scope := range scopes {
+		values[i] = string(scope)
+	}
+	return strings.Join(values, " ")
+}
+
+// splitScopes parses a space separated list of scopes
+func splitScopes(value string) []domain.Scope {
+	scopes := []domain.Scope{}
+	for _, scope := range strings.Fields(value) {
+		scopes = append(scopes, domain.Scope(scope))
+	}
+	return scopes
+}
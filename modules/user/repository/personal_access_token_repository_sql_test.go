@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"fmt"
+	"portal_link/modules/user/repository/repositorytest"
+	"portal_link/pkg/database/databasetest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSQLPersonalAccessTokenRepository(t *testing.T) {
+	for _, driver := range databasetest.Drivers {
+		t.Run(string(driver), func(t *testing.T) {
+			repositorytest.RunPersonalAccessTokenRepositorySuite(t, func(t *testing.T) repositorytest.PersonalAccessTokenFixture {
+				db := databasetest.Open(t, driver)
+				var users atomic.Int64
+				return repositorytest.PersonalAccessTokenFixture{
+					Repository: NewSQLPersonalAccessTokenRepository(db),
+					NewUserID: func(t *testing.T) int {
+						return databasetest.InsertUser(t, db, fmt.Sprintf("user%d@example.com", users.Add(1)))
+					},
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"portal_link/modules/user/domain"
+	"portal_link/pkg/transaction"
+	"sync"
+	"time"
+)
+
+var _ domain.RefreshTokenRepository = (*InMemoryRefreshTokenRepository)(nil)
+
+// InMemoryRefreshTokenRepository is an in-memory implementation of RefreshTokenRepository for testing
+type InMemoryRefreshTokenRepository struct {
+	mu     sync.RWMutex
+	tokens map[int]*domain.RefreshToken
+	hashes map[string]int // token hash -> refresh token ID mapping
+	nextID int
+}
+
+// NewInMemoryRefreshTokenRepository creates a new in-memory refresh token repository
+func NewInMemoryRefreshTokenRepository() *InMemoryRefreshTokenRepository {
+	return &InMemoryRefreshTokenRepository{
+		tokens: make(map[int]*domain.RefreshToken),
+		hashes: make(map[string]int),
+		nextID: 1,
+	}
+}
+
+// Create creates a new refresh token
+func (r *InMemoryRefreshTokenRepository) Create(ctx context.Context, refreshToken *domain.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.create(refreshToken)
+	return nil
+}
+
+// GetByTokenHash retrieves a refresh token by its hash
+func (r *InMemoryRefreshTokenRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, exists := r.hashes[tokenHash]
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+
+	return copyRefreshToken(r.tokens[id]), nil
+}
+
+// Rotate marks current as used and stores next atomically
+func (r *InMemoryRefreshTokenRepository) Rotate(ctx context.Context, current *domain.RefreshToken, next *domain.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, exists := r.tokens[current.ID]
+	if !exists {
+		return sql.ErrNoRows
+	}
+	if stored.IsUsed() || stored.IsRevoked() {
+		return domain.ErrRefreshTokenReused
+	}
+
+	usedAt := time.Now().UTC()
+	stored.UsedAt = &usedAt
+	current.UsedAt = &usedAt
+
+	r.create(next)
+	return nil
+}
+
+// RevokeFamily revokes every refresh token in the given family
+func (r *InMemoryRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	revokedAt := time.Now().UTC()
+	for _, token := range r.tokens {
+		if token.FamilyID == familyID && !token.IsRevoked() {
+			token.RevokedAt = &revokedAt
+		}
+	}
+	return nil
+}
+
+// RevokeByUserID revokes every refresh token owned by the given user
+func (r *InMemoryRefreshTokenRepository) RevokeByUserID(ctx context.Context, userID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	revokedAt := time.Now().UTC()
+	for _, token := range r.tokens {
+		if token.UserID == userID && !token.IsRevoked() {
+			token.RevokedAt = &revokedAt
+		}
+	}
+
+	transaction.RecordUndo(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		// Only clear the revocations written by the transaction
+		for _, token := range r.tokens {
+			if token.RevokedAt == &revokedAt {
+				token.RevokedAt = nil
+			}
+		}
+	})
+
+	return nil
+}
+
+// Reset clears all data (useful for testing)
+func (r *InMemoryRefreshTokenRepository) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tokens = make(map[int]*domain.RefreshToken)
+	r.hashes = make(map[string]int)
+	r.nextID = 1
+}
+
+// create stores a refresh token, the caller must hold the write lock
+func (r *InMemoryRefreshTokenRepository) create(refreshToken *domain.RefreshToken) {
+	refreshToken.ID = r.nextID
+	r.nextID++
+
+	r.tokens[refreshToken.ID] = copyRefreshToken(refreshToken)
+	r.hashes[refreshToken.TokenHash] = refreshToken.ID
+}
+
+// copyRefreshToken returns a copy so callers cannot mutate the stored state
+func copyRefreshToken(refreshToken *domain.RefreshToken) *domain.RefreshToken {
+	copied := *refreshToken
+	if refreshToken.UsedAt != nil {
+		usedAt := *refreshToken.UsedAt
+		copied.UsedAt = &usedAt
+	}
+	if refreshToken.RevokedAt != nil {
+		revokedAt := *refreshToken.RevokedAt
+		copied.RevokedAt = &revokedAt
+	}
+	return &copied
+}
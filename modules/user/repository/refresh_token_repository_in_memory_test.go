@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"portal_link/modules/user/domain"
+	"portal_link/modules/user/repository/repositorytest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryRefreshTokenRepository_Conformance(t *testing.T) {
+	repositorytest.RunRefreshTokenRepositorySuite(t, func(t *testing.T) repositorytest.RefreshTokenFixture {
+		var nextUserID int
+		return repositorytest.RefreshTokenFixture{
+			Repository: NewInMemoryRefreshTokenRepository(),
+			NewUserID: func(t *testing.T) int {
+				nextUserID++
+				return nextUserID
+			},
+		}
+	})
+}
+
+func newTestRefreshToken(familyID, tokenHash string) *domain.RefreshToken {
+	return &domain.RefreshToken{
+		UserID:    1,
+		FamilyID:  familyID,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().UTC().Add(time.Hour),
+		CreatedAt: time.Now().UTC(),
+	}
+}
+
+func TestInMemoryRefreshTokenRepository_GetByTokenHash(t *testing.T) {
+	repo := NewInMemoryRefreshTokenRepository()
+	ctx := context.Background()
+
+	t.Run("successfully retrieves refresh token by hash", func(t *testing.T) {
+		repo.Reset()
+
+		token := newTestRefreshToken("family", "hash-1")
+		require.NoError(t, repo.Create(ctx, token))
+		assert.Equal(t, 1, token.ID)
+
+		retrieved, err := repo.GetByTokenHash(ctx, "hash-1")
+		require.NoError(t, err)
+		assert.Equal(t, token.ID, retrieved.ID)
+		assert.Equal(t, "family", retrieved.FamilyID)
+
+		// Mutating the returned value must not change the stored state
+		now := time.Now().UTC()
+		retrieved.RevokedAt = &now
+		again, err := repo.GetByTokenHash(ctx, "hash-1")
+		require.NoError(t, err)
+		assert.False(t, again.IsRevoked())
+	})
+
+	t.Run("returns error when hash not found", func(t *testing.T) {
+		repo.Reset()
+
+		_, err := repo.GetByTokenHash(ctx, "missing")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
+
+func TestInMemoryRefreshTokenRepository_Rotate(t *testing.T) {
+	repo := NewInMemoryRefreshTokenRepository()
+	ctx := context.Background()
+
+	t.Run("marks current as used and creates next", func(t *testing.T) {
+		repo.Reset()
+
+		current := newTestRefreshToken("family", "hash-1")
+		require.NoError(t, repo.Create(ctx, current))
+
+		next := newTestRefreshToken("family", "hash-2")
+		require.NoError(t, repo.Rotate(ctx, current, next))
+		assert.NotZero(t, next.ID)
+
+		stored, err := repo.GetByTokenHash(ctx, "hash-1")
+		require.NoError(t, err)
+		assert.True(t, stored.IsUsed())
+
+		_, err = repo.GetByTokenHash(ctx, "hash-2")
+		assert.NoError(t, err)
+	})
+
+	t.Run("returns ErrRefreshTokenReused when current was already used", func(t *testing.T) {
+		repo.Reset()
+
+		current := newTestRefreshToken("family", "hash-1")
+		require.NoError(t, repo.Create(ctx, current))
+		require.NoError(t, repo.Rotate(ctx, current, newTestRefreshToken("family", "hash-2")))
+
+		stale, err := repo.GetByTokenHash(ctx, "hash-1")
+		require.NoError(t, err)
+		stale.UsedAt = nil
+
+		err = repo.Rotate(ctx, stale, newTestRefreshToken("family", "hash-3"))
+		assert.ErrorIs(t, err, domain.ErrRefreshTokenReused)
+
+		_, err = repo.GetByTokenHash(ctx, "hash-3")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
+
+func TestInMemoryRefreshTokenRepository_RevokeFamily(t *testing.T) {
+	repo := NewInMemoryRefreshTokenRepository()
+	ctx := context.Background()
+
+	t.Run("revokes only tokens in the family", func(t *testing.T) {
+		repo.Reset()
+
+		require.NoError(t, repo.Create(ctx, newTestRefreshToken("a", "hash-a1")))
+		require.NoError(t, repo.Create(ctx, newTestRefreshToken("a", "hash-a2")))
+		require.NoError(t, repo.Create(ctx, newTestRefreshToken("b", "hash-b1")))
+
+		require.NoError(t, repo.RevokeFamily(ctx, "a"))
+
+		for _, hash := range []string{"hash-a1", "hash-a2"} {
+			token, err := repo.GetByTokenHash(ctx, hash)
+			require.NoError(t, err)
+			assert.True(t, token.IsRevoked())
+		}
+
+		token, err := repo.GetByTokenHash(ctx, "hash-b1")
+		require.NoError(t, err)
+		assert.False(t, token.IsRevoked())
+	})
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"portal_link/modules/user/domain"
+	"portal_link/pkg/database"
+	"time"
+)
+
+var _ domain.RefreshTokenRepository = (*SQLRefreshTokenRepository)(nil)
+
+// SQLRefreshTokenRepository is a database/sql implementation of RefreshTokenRepository shared by
+// every driver supported by pkg/database (PostgreSQL and SQLite).
+// Only the token hash is stored; queries join the use case transaction carried by ctx, if any.
+type SQLRefreshTokenRepository struct {
+	db *sql.DB
+}
+
+// NewSQLRefreshTokenRepository creates a new SQL refresh token repository
+func NewSQLRefreshTokenRepository(db *sql.DB) *SQLRefreshTokenRepository {
+	return &SQLRefreshTokenRepository{db: db}
+}
+
+// Create creates a new refresh token and assigns its ID
+func (r *SQLRefreshTokenRepository) Create(ctx context.Context, refreshToken *domain.RefreshToken) error {
+	return insertRefreshToken(ctx, database.Conn(ctx, r.db), refreshToken)
+}
+
+// GetByTokenHash retrieves a refresh token by its hash
+func (r *SQLRefreshTokenRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	var (
+		refreshToken      domain.RefreshToken
+		usedAt, revokedAt sql.NullTime
+	)
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT id, user_id, family_id, token_hash, expires_at, used_at, revoked_at, created_at
+		FROM refresh_tokens
+		WHERE token_hash = $1`, tokenHash,
+	).Scan(&refreshToken.ID, &refreshToken.UserID, &refreshToken.FamilyID, &refreshToken.TokenHash,
+		&refreshToken.ExpiresAt, &usedAt, &revokedAt, &refreshToken.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken.ExpiresAt = refreshToken.ExpiresAt.UTC()
+	refreshToken.CreatedAt = refreshToken.CreatedAt.UTC()
+	refreshToken.UsedAt = timePtr(usedAt)
+	refreshToken.RevokedAt = timePtr(revokedAt)
+	return &refreshToken, nil
+}
+
+// Rotate marks current as used and stores next in a single transaction.
+// The conditional update lets only one of several concurrent rotations of the same token succeed.
+func (r *SQLRefreshTokenRepository) Rotate(ctx context.Context, current *domain.RefreshToken, next *domain.RefreshToken) error {
+	usedAt := time.Now().UTC()
+	err := database.WithTx(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, `
+			UPDATE refresh_tokens
+			SET used_at = $2
+			WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL`,
+			current.ID, usedAt,
+		)
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			// Distinguish a missing token from one that was already used or revoked
+			var id int
+			if err := tx.QueryRowContext(ctx, `SELECT id FROM refresh_tokens WHERE id = $1`, current.ID).Scan(&id); err != nil {
+				return err
+			}
+			return domain.ErrRefreshTokenReused
+		}
+
+		return insertRefreshToken(ctx, tx, next)
+	})
+	if err != nil {
+		return err
+	}
+
+	current.UsedAt = &usedAt
+	return nil
+}
+
+// RevokeFamily revokes every refresh token in the given family
+func (r *SQLRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE refresh_tokens
+		SET revoked_at = $2
+		WHERE family_id = $1 AND revoked_at IS NULL`,
+		familyID, time.Now().UTC(),
+	)
+	return err
+}
+
+// RevokeByUserID revokes every refresh token owned by the given user
+func (r *SQLRefreshTokenRepository) RevokeByUserID(ctx context.Context, userID int) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE refresh_tokens
+		SET revoked_at = $2
+		WHERE user_id = $1 AND revoked_at IS NULL`,
+		userID, time.Now().UTC(),
+	)
+	return err
+}
+
+// insertRefreshToken inserts a refresh token row and assigns its ID
+func insertRefreshToken(ctx context.Context, conn database.Executor, refreshToken *domain.RefreshToken) error {
+	return conn.QueryRowContext(ctx, `
+		INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at, used_at, revoked_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id`,
+		refreshToken.UserID, refreshToken.FamilyID, refreshToken.TokenHash, refreshToken.ExpiresAt.UTC(),
+		nullTime(refreshToken.UsedAt), nullTime(refreshToken.RevokedAt), refreshToken.CreatedAt.UTC(),
+	).Scan(&refreshToken.ID)
+}
+
+// timePtr converts a nullable column value to an optional UTC time
+func timePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	utc := t.Time.UTC()
+	return &utc
+}
@@ -0,0 +1,125 @@
+package repositorytest
+
+import (
+	"context"
+	"database/sql"
+	"portal_link/modules/user/domain"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// LoginAttemptRepositoryFactory 為每個子測試建立一個空的 LoginAttemptRepository
+type LoginAttemptRepositoryFactory func(t *testing.T) domain.LoginAttemptRepository
+
+// testLockoutPolicy 第 3 次失敗開始鎖定一分鐘，之後加倍，最長五分鐘
+var testLockoutPolicy = domain.LockoutPolicy{
+	MaxFailures: 3,
+	BaseLockout: time.Minute,
+	MaxLockout:  5 * time.Minute,
+	ResetAfter:  time.Hour,
+}
+
+// RunLoginAttemptRepositorySuite 對 newRepo 建立的 repository 執行 LoginAttemptRepository 的介面契約測試
+func RunLoginAttemptRepositorySuite(t *testing.T, newRepo LoginAttemptRepositoryFactory) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("locks after max failures with exponential backoff", func(t *testing.T) {
+		repo := newRepo(t)
+
+		expected := []time.Duration{0, 0, time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute}
+		for i, lockout := range expected {
+			attempt, err := repo.RecordFailure(ctx, "email:a@example.com", testLockoutPolicy, now)
+			require.NoError(t, err)
+			assert.Equal(t, i+1, attempt.Failures)
+			assert.Equal(t, lockout, attempt.RetryAfter(now), "failure %d", i+1)
+		}
+
+		stored, err := repo.Get(ctx, "email:a@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "email:a@example.com", stored.Key)
+		assert.Equal(t, len(expected), stored.Failures)
+		assert.True(t, now.Equal(stored.LastFailureAt))
+		assert.Equal(t, 5*time.Minute, stored.RetryAfter(now))
+
+		_, err = repo.Get(ctx, "email:unknown@example.com")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("resets failures after the reset window", func(t *testing.T) {
+		repo := newRepo(t)
+
+		for i := 0; i < 2; i++ {
+			_, err := repo.RecordFailure(ctx, "ip:127.0.0.1", testLockoutPolicy, now)
+			require.NoError(t, err)
+		}
+
+		attempt, err := repo.RecordFailure(ctx, "ip:127.0.0.1", testLockoutPolicy, now.Add(2*time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 1, attempt.Failures)
+		assert.False(t, attempt.IsLocked(now.Add(2*time.Hour)))
+	})
+
+	t.Run("counts keys independently and drops stale records", func(t *testing.T) {
+		repo := newRepo(t)
+
+		for i := 0; i < 3; i++ {
+			_, err := repo.RecordFailure(ctx, "email:a@example.com", testLockoutPolicy, now)
+			require.NoError(t, err)
+		}
+		_, err := repo.RecordFailure(ctx, "email:b@example.com", testLockoutPolicy, now)
+		require.NoError(t, err)
+
+		a, err := repo.Get(ctx, "email:a@example.com")
+		require.NoError(t, err)
+		assert.True(t, a.IsLocked(now))
+		b, err := repo.Get(ctx, "email:b@example.com")
+		require.NoError(t, err)
+		assert.False(t, b.IsLocked(now))
+
+		// 超過 ResetAfter 且未鎖定的紀錄在記錄其他 key 的失敗時清除
+		_, err = repo.RecordFailure(ctx, "email:c@example.com", testLockoutPolicy, now.Add(2*time.Hour))
+		require.NoError(t, err)
+		_, err = repo.Get(ctx, "email:a@example.com")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		_, err = repo.Get(ctx, "email:b@example.com")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("reset clears the failure counter", func(t *testing.T) {
+		repo := newRepo(t)
+
+		_, err := repo.RecordFailure(ctx, "email:a@example.com", testLockoutPolicy, now)
+		require.NoError(t, err)
+		require.NoError(t, repo.Reset(ctx, "email:a@example.com"))
+
+		_, err = repo.Get(ctx, "email:a@example.com")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		// 不存在的 key 也可以重置
+		assert.NoError(t, repo.Reset(ctx, "email:unknown@example.com"))
+	})
+
+	t.Run("counts every concurrent failure", func(t *testing.T) {
+		repo := newRepo(t)
+
+		const workers = 10
+		var wg sync.WaitGroup
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := repo.RecordFailure(ctx, "ip:10.0.0.1", testLockoutPolicy, now)
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		stored, err := repo.Get(ctx, "ip:10.0.0.1")
+		require.NoError(t, err)
+		assert.Equal(t, workers, stored.Failures)
+	})
+}
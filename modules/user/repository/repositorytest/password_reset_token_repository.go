@@ -0,0 +1,125 @@
+package repositorytest
+
+import (
+	"context"
+	"database/sql"
+	"portal_link/modules/user/domain"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// PasswordResetTokenFixture 一個空的 PasswordResetTokenRepository 與建立 token 擁有者的方法
+type PasswordResetTokenFixture struct {
+	Repository domain.PasswordResetTokenRepository
+	// NewUserID 建立一個使用者並返回其 ID；有外鍵限制的實作需要實際寫入使用者
+	NewUserID func(t *testing.T) int
+}
+
+// PasswordResetTokenRepositoryFactory 為每個子測試建立一個新的 PasswordResetTokenFixture
+type PasswordResetTokenRepositoryFactory func(t *testing.T) PasswordResetTokenFixture
+
+// RunPasswordResetTokenRepositorySuite 對 newFixture 建立的 repository 執行 PasswordResetTokenRepository 的介面契約測試
+func RunPasswordResetTokenRepositorySuite(t *testing.T, newFixture PasswordResetTokenRepositoryFactory) {
+	ctx := context.Background()
+
+	t.Run("creates password reset token and retrieves it by hash", func(t *testing.T) {
+		f := newFixture(t)
+		userID := f.NewUserID(t)
+
+		token := newPasswordResetToken(userID, "hash-1")
+		require.NoError(t, f.Repository.Create(ctx, token))
+		assert.Positive(t, token.ID)
+
+		stored, err := f.Repository.GetByTokenHash(ctx, "hash-1")
+		require.NoError(t, err)
+		assert.Equal(t, token.ID, stored.ID)
+		assert.Equal(t, userID, stored.UserID)
+		assert.Equal(t, "hash-1", stored.TokenHash)
+		assert.WithinDuration(t, token.ExpiresAt, stored.ExpiresAt, time.Millisecond)
+		assert.WithinDuration(t, token.CreatedAt, stored.CreatedAt, time.Millisecond)
+		assert.False(t, stored.IsUsed())
+
+		_, err = f.Repository.GetByTokenHash(ctx, "unknown")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("marks a password reset token used only once", func(t *testing.T) {
+		f := newFixture(t)
+		userID := f.NewUserID(t)
+
+		token := newPasswordResetToken(userID, "hash-1")
+		require.NoError(t, f.Repository.Create(ctx, token))
+
+		require.NoError(t, f.Repository.MarkUsed(ctx, token))
+		assert.True(t, token.IsUsed())
+
+		stored, err := f.Repository.GetByTokenHash(ctx, "hash-1")
+		require.NoError(t, err)
+		assert.True(t, stored.IsUsed())
+
+		assert.ErrorIs(t, f.Repository.MarkUsed(ctx, stored), domain.ErrInvalidPasswordResetToken)
+		assert.ErrorIs(t, f.Repository.MarkUsed(ctx, &domain.PasswordResetToken{ID: 999999}), sql.ErrNoRows)
+	})
+
+	t.Run("invalidates every unused token of a user", func(t *testing.T) {
+		f := newFixture(t)
+		alice := f.NewUserID(t)
+		bob := f.NewUserID(t)
+
+		require.NoError(t, f.Repository.Create(ctx, newPasswordResetToken(alice, "hash-a1")))
+		require.NoError(t, f.Repository.Create(ctx, newPasswordResetToken(alice, "hash-a2")))
+		require.NoError(t, f.Repository.Create(ctx, newPasswordResetToken(bob, "hash-b1")))
+
+		require.NoError(t, f.Repository.InvalidateByUserID(ctx, alice))
+
+		for tokenHash, used := range map[string]bool{"hash-a1": true, "hash-a2": true, "hash-b1": false} {
+			stored, err := f.Repository.GetByTokenHash(ctx, tokenHash)
+			require.NoError(t, err)
+			assert.Equal(t, used, stored.IsUsed(), tokenHash)
+		}
+	})
+
+	t.Run("lets only one concurrent use succeed", func(t *testing.T) {
+		f := newFixture(t)
+		userID := f.NewUserID(t)
+
+		token := newPasswordResetToken(userID, "hash")
+		require.NoError(t, f.Repository.Create(ctx, token))
+
+		const workers = 10
+		var (
+			wg        sync.WaitGroup
+			succeeded atomic.Int32
+		)
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				attempt := *token
+				if err := f.Repository.MarkUsed(ctx, &attempt); err != nil {
+					assert.ErrorIs(t, err, domain.ErrInvalidPasswordResetToken)
+					return
+				}
+				succeeded.Add(1)
+			}()
+		}
+		wg.Wait()
+
+		assert.EqualValues(t, 1, succeeded.Load())
+	})
+}
+
+func newPasswordResetToken(userID int, tokenHash string) *domain.PasswordResetToken {
+	now := time.Now().UTC()
+	return &domain.PasswordResetToken{
+		UserID:    userID,
+		TokenHash: tokenHash,
+		ExpiresAt: now.Add(time.Hour),
+		CreatedAt: now,
+	}
+}
@@ -0,0 +1,149 @@
+package repositorytest
+
+import (
+	"context"
+	"database/sql"
+	"portal_link/modules/user/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// PersonalAccessTokenFixture 一個空的 PersonalAccessTokenRepository 與建立 token 擁有者的方法
+type PersonalAccessTokenFixture struct {
+	Repository domain.PersonalAccessTokenRepository
+	// NewUserID 建立一個使用者並返回其 ID；有外鍵限制的實作需要實際寫入使用者
+	NewUserID func(t *testing.T) int
+}
+
+// PersonalAccessTokenRepositoryFactory 為每個子測試建立一個新的 PersonalAccessTokenFixture
+type PersonalAccessTokenRepositoryFactory func(t *testing.T) PersonalAccessTokenFixture
+
+// RunPersonalAccessTokenRepositorySuite 對 newFixture 建立的 repository 執行 PersonalAccessTokenRepository 的介面契約測試
+func RunPersonalAccessTokenRepositorySuite(t *testing.T, newFixture PersonalAccessTokenRepositoryFactory) {
+	ctx := context.Background()
+
+	t.Run("creates personal access token and retrieves it by ID and hash", func(t *testing.T) {
+		f := newFixture(t)
+		userID := f.NewUserID(t)
+
+		expiresAt := time.Now().UTC().Add(24 * time.Hour)
+		token := newPersonalAccessToken(userID, "hash-1", domain.ScopePortalPagesRead, domain.ScopePortalPagesWrite)
+		token.ExpiresAt = &expiresAt
+		require.NoError(t, f.Repository.Create(ctx, token))
+		assert.Positive(t, token.ID)
+
+		for _, find := range []func() (*domain.PersonalAccessToken, error){
+			func() (*domain.PersonalAccessToken, error) { return f.Repository.Find(ctx, token.ID) },
+			func() (*domain.PersonalAccessToken, error) { return f.Repository.GetByTokenHash(ctx, "hash-1") },
+		} {
+			stored, err := find()
+			require.NoError(t, err)
+			assert.Equal(t, token.ID, stored.ID)
+			assert.Equal(t, userID, stored.UserID)
+			assert.Equal(t, "ci", stored.Name)
+			assert.Equal(t, "hash-1", stored.TokenHash)
+			assert.Equal(t, []domain.Scope{domain.ScopePortalPagesRead, domain.ScopePortalPagesWrite}, stored.Scopes)
+			require.NotNil(t, stored.ExpiresAt)
+			assert.WithinDuration(t, expiresAt, *stored.ExpiresAt, time.Millisecond)
+			assert.WithinDuration(t, token.CreatedAt, stored.CreatedAt, time.Millisecond)
+			assert.Nil(t, stored.LastUsedAt)
+			assert.False(t, stored.IsRevoked())
+		}
+
+		_, err := f.Repository.Find(ctx, 999999)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		_, err = f.Repository.GetByTokenHash(ctx, "unknown")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("lists the user's tokens that are not revoked in creation order", func(t *testing.T) {
+		f := newFixture(t)
+		alice := f.NewUserID(t)
+		bob := f.NewUserID(t)
+
+		first := newPersonalAccessToken(alice, "hash-1", domain.ScopePortalPagesRead)
+		require.NoError(t, f.Repository.Create(ctx, first))
+		revoked := newPersonalAccessToken(alice, "hash-2", domain.ScopePortalPagesRead)
+		require.NoError(t, f.Repository.Create(ctx, revoked))
+		require.NoError(t, f.Repository.Create(ctx, newPersonalAccessToken(bob, "hash-3", domain.ScopePortalPagesRead)))
+		last := newPersonalAccessToken(alice, "hash-4", domain.ScopePortalPagesWrite)
+		require.NoError(t, f.Repository.Create(ctx, last))
+		require.NoError(t, f.Repository.Revoke(ctx, revoked))
+
+		tokens, err := f.Repository.ListByUserID(ctx, alice)
+		require.NoError(t, err)
+		require.Len(t, tokens, 2)
+		assert.Equal(t, first.ID, tokens[0].ID)
+		assert.Equal(t, last.ID, tokens[1].ID)
+		assert.Equal(t, []domain.Scope{domain.ScopePortalPagesWrite}, tokens[1].Scopes)
+
+		tokens, err = f.Repository.ListByUserID(ctx, f.NewUserID(t))
+		require.NoError(t, err)
+		assert.Empty(t, tokens)
+	})
+
+	t.Run("revokes a personal access token only once", func(t *testing.T) {
+		f := newFixture(t)
+		token := newPersonalAccessToken(f.NewUserID(t), "hash-1", domain.ScopePortalPagesRead)
+		require.NoError(t, f.Repository.Create(ctx, token))
+
+		require.NoError(t, f.Repository.Revoke(ctx, token))
+		assert.True(t, token.IsRevoked())
+		stored, err := f.Repository.GetByTokenHash(ctx, "hash-1")
+		require.NoError(t, err)
+		assert.True(t, stored.IsRevoked())
+
+		assert.ErrorIs(t, f.Repository.Revoke(ctx, stored), domain.ErrPersonalAccessTokenNotFound)
+		assert.ErrorIs(t, f.Repository.Revoke(ctx, &domain.PersonalAccessToken{ID: 999999}), sql.ErrNoRows)
+	})
+
+	t.Run("revokes every token of a user", func(t *testing.T) {
+		f := newFixture(t)
+		alice := f.NewUserID(t)
+		bob := f.NewUserID(t)
+
+		require.NoError(t, f.Repository.Create(ctx, newPersonalAccessToken(alice, "hash-a1", domain.ScopePortalPagesRead)))
+		require.NoError(t, f.Repository.Create(ctx, newPersonalAccessToken(alice, "hash-a2", domain.ScopePortalPagesWrite)))
+		require.NoError(t, f.Repository.Create(ctx, newPersonalAccessToken(bob, "hash-b1", domain.ScopePortalPagesRead)))
+
+		require.NoError(t, f.Repository.RevokeByUserID(ctx, alice))
+		for tokenHash, revoked := range map[string]bool{"hash-a1": true, "hash-a2": true, "hash-b1": false} {
+			stored, err := f.Repository.GetByTokenHash(ctx, tokenHash)
+			require.NoError(t, err)
+			assert.Equal(t, revoked, stored.IsRevoked(), tokenHash)
+		}
+
+		tokens, err := f.Repository.ListByUserID(ctx, alice)
+		require.NoError(t, err)
+		assert.Empty(t, tokens)
+	})
+
+	t.Run("records the last use", func(t *testing.T) {
+		f := newFixture(t)
+		token := newPersonalAccessToken(f.NewUserID(t), "hash-1", domain.ScopePortalPagesRead)
+		require.NoError(t, f.Repository.Create(ctx, token))
+
+		usedAt := time.Now().UTC()
+		require.NoError(t, f.Repository.MarkUsed(ctx, token.ID, usedAt))
+
+		stored, err := f.Repository.Find(ctx, token.ID)
+		require.NoError(t, err)
+		require.NotNil(t, stored.LastUsedAt)
+		assert.WithinDuration(t, usedAt, *stored.LastUsedAt, time.Millisecond)
+
+		assert.ErrorIs(t, f.Repository.MarkUsed(ctx, 999999, usedAt), sql.ErrNoRows)
+	})
+}
+
+func newPersonalAccessToken(userID int, tokenHash string, scopes ...domain.Scope) *domain.PersonalAccessToken {
+	return &domain.PersonalAccessToken{
+		UserID:    userID,
+		Name:      "ci",
+		TokenHash: tokenHash,
+		Scopes:    scopes,
+		CreatedAt: time.Now().UTC(),
+	}
+}
@@ -0,0 +1,159 @@
+package repositorytest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"portal_link/modules/user/domain"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RefreshTokenFixture 一個空的 RefreshTokenRepository 與建立 token 擁有者的方法
+type RefreshTokenFixture struct {
+	Repository domain.RefreshTokenRepository
+	// NewUserID 建立一個使用者並返回其 ID；有外鍵限制的實作需要實際寫入使用者
+	NewUserID func(t *testing.T) int
+}
+
+// RefreshTokenRepositoryFactory 為每個子測試建立一個新的 RefreshTokenFixture
+type RefreshTokenRepositoryFactory func(t *testing.T) RefreshTokenFixture
+
+// RunRefreshTokenRepositorySuite 對 newFixture 建立的 repository 執行 RefreshTokenRepository 的介面契約測試
+func RunRefreshTokenRepositorySuite(t *testing.T, newFixture RefreshTokenRepositoryFactory) {
+	ctx := context.Background()
+
+	t.Run("creates refresh token and retrieves it by hash", func(t *testing.T) {
+		f := newFixture(t)
+		userID := f.NewUserID(t)
+
+		token := newRefreshToken(userID, "family", "hash-1")
+		require.NoError(t, f.Repository.Create(ctx, token))
+		assert.Positive(t, token.ID)
+
+		stored, err := f.Repository.GetByTokenHash(ctx, "hash-1")
+		require.NoError(t, err)
+		assert.Equal(t, token.ID, stored.ID)
+		assert.Equal(t, userID, stored.UserID)
+		assert.Equal(t, "family", stored.FamilyID)
+		assert.Equal(t, "hash-1", stored.TokenHash)
+		assert.WithinDuration(t, token.ExpiresAt, stored.ExpiresAt, time.Millisecond)
+		assert.WithinDuration(t, token.CreatedAt, stored.CreatedAt, time.Millisecond)
+		assert.False(t, stored.IsUsed())
+		assert.False(t, stored.IsRevoked())
+
+		_, err = f.Repository.GetByTokenHash(ctx, "unknown")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("rotates a refresh token only once", func(t *testing.T) {
+		f := newFixture(t)
+		userID := f.NewUserID(t)
+
+		current := newRefreshToken(userID, "family", "hash-1")
+		require.NoError(t, f.Repository.Create(ctx, current))
+
+		next := newRefreshToken(userID, "family", "hash-2")
+		require.NoError(t, f.Repository.Rotate(ctx, current, next))
+		assert.True(t, current.IsUsed())
+		assert.Positive(t, next.ID)
+
+		stored, err := f.Repository.GetByTokenHash(ctx, "hash-1")
+		require.NoError(t, err)
+		assert.True(t, stored.IsUsed())
+		_, err = f.Repository.GetByTokenHash(ctx, "hash-2")
+		require.NoError(t, err)
+
+		// 再次輪替同一個 token 不會建立新的 token
+		assert.ErrorIs(t, f.Repository.Rotate(ctx, stored, newRefreshToken(userID, "family", "hash-3")), domain.ErrRefreshTokenReused)
+		_, err = f.Repository.GetByTokenHash(ctx, "hash-3")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("does not rotate revoked or missing refresh tokens", func(t *testing.T) {
+		f := newFixture(t)
+		userID := f.NewUserID(t)
+
+		revoked := newRefreshToken(userID, "family", "hash-1")
+		require.NoError(t, f.Repository.Create(ctx, revoked))
+		require.NoError(t, f.Repository.RevokeFamily(ctx, "family"))
+		assert.ErrorIs(t, f.Repository.Rotate(ctx, revoked, newRefreshToken(userID, "family", "hash-2")), domain.ErrRefreshTokenReused)
+
+		missing := newRefreshToken(userID, "family", "hash-3")
+		missing.ID = 999999
+		assert.ErrorIs(t, f.Repository.Rotate(ctx, missing, newRefreshToken(userID, "family", "hash-4")), sql.ErrNoRows)
+	})
+
+	t.Run("revokes a family and every token of a user", func(t *testing.T) {
+		f := newFixture(t)
+		alice := f.NewUserID(t)
+		bob := f.NewUserID(t)
+
+		require.NoError(t, f.Repository.Create(ctx, newRefreshToken(alice, "family-a", "hash-a1")))
+		require.NoError(t, f.Repository.Create(ctx, newRefreshToken(alice, "family-a", "hash-a2")))
+		require.NoError(t, f.Repository.Create(ctx, newRefreshToken(alice, "family-b", "hash-b1")))
+		require.NoError(t, f.Repository.Create(ctx, newRefreshToken(bob, "family-c", "hash-c1")))
+
+		require.NoError(t, f.Repository.RevokeFamily(ctx, "family-a"))
+		assertRevoked(t, f.Repository, map[string]bool{"hash-a1": true, "hash-a2": true, "hash-b1": false, "hash-c1": false})
+
+		require.NoError(t, f.Repository.RevokeByUserID(ctx, alice))
+		assertRevoked(t, f.Repository, map[string]bool{"hash-a1": true, "hash-a2": true, "hash-b1": true, "hash-c1": false})
+	})
+
+	t.Run("lets only one concurrent rotation succeed", func(t *testing.T) {
+		f := newFixture(t)
+		userID := f.NewUserID(t)
+
+		current := newRefreshToken(userID, "family", "hash")
+		require.NoError(t, f.Repository.Create(ctx, current))
+
+		const workers = 10
+		var (
+			wg        sync.WaitGroup
+			succeeded atomic.Int32
+		)
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				attempt := *current
+				err := f.Repository.Rotate(ctx, &attempt, newRefreshToken(userID, "family", fmt.Sprintf("next-%d", i)))
+				if err != nil {
+					assert.ErrorIs(t, err, domain.ErrRefreshTokenReused)
+					return
+				}
+				succeeded.Add(1)
+			}(i)
+		}
+		wg.Wait()
+
+		assert.EqualValues(t, 1, succeeded.Load())
+	})
+}
+
+func newRefreshToken(userID int, familyID, tokenHash string) *domain.RefreshToken {
+	now := time.Now().UTC()
+	return &domain.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: tokenHash,
+		ExpiresAt: now.Add(time.Hour),
+		CreatedAt: now,
+	}
+}
+
+// assertRevoked 檢查每個 token 雜湊值對應的 refresh token 是否已撤銷
+func assertRevoked(t *testing.T, repo domain.RefreshTokenRepository, expected map[string]bool) {
+	t.Helper()
+	for tokenHash, revoked := range expected {
+		stored, err := repo.GetByTokenHash(context.Background(), tokenHash)
+		require.NoError(t, err)
+		assert.Equal(t, revoked, stored.IsRevoked(), tokenHash)
+	}
+}
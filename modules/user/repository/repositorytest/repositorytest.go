@@ -0,0 +1,171 @@
+// Package repositorytest 提供用戶模組 repository 的共用一致性測試，每一種實作都應執行
+package repositorytest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"portal_link/modules/user/domain"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// UserRepositoryFactory 為每個子測試建立一個空的 UserRepository
+type UserRepositoryFactory func(t *testing.T) domain.UserRepository
+
+// RunUserRepositorySuite 對 newRepo 建立的 repository 執行 UserRepository 的介面契約測試
+func RunUserRepositorySuite(t *testing.T, newRepo UserRepositoryFactory) {
+	ctx := context.Background()
+
+	t.Run("creates user and assigns ID", func(t *testing.T) {
+		repo := newRepo(t)
+
+		user := newUser(t, "john@example.com")
+		require.NoError(t, repo.Create(ctx, user))
+		assert.Positive(t, user.ID)
+
+		other := newUser(t, "jane@example.com")
+		require.NoError(t, repo.Create(ctx, other))
+		assert.NotEqual(t, user.ID, other.ID)
+	})
+
+	t.Run("retrieves user by ID and email", func(t *testing.T) {
+		repo := newRepo(t)
+
+		user := newUser(t, "john@example.com")
+		require.NoError(t, repo.Create(ctx, user))
+
+		byID, err := repo.Find(ctx, user.ID)
+		require.NoError(t, err)
+		assertSameUser(t, user, byID)
+
+		byEmail, err := repo.GetByEmail(ctx, "john@example.com")
+		require.NoError(t, err)
+		assertSameUser(t, user, byEmail)
+	})
+
+	t.Run("returns ErrEmailExists for duplicate email", func(t *testing.T) {
+		repo := newRepo(t)
+
+		require.NoError(t, repo.Create(ctx, newUser(t, "john@example.com")))
+		assert.ErrorIs(t, repo.Create(ctx, newUser(t, "john@example.com")), domain.ErrEmailExists)
+
+		other := newUser(t, "jane@example.com")
+		require.NoError(t, repo.Create(ctx, other))
+		other.Email = "john@example.com"
+		assert.ErrorIs(t, repo.Update(ctx, other), domain.ErrEmailExists)
+
+		stored, err := repo.GetByEmail(ctx, "john@example.com")
+		require.NoError(t, err)
+		assert.NotEqual(t, other.ID, stored.ID)
+	})
+
+	t.Run("returns sql.ErrNoRows when not found", func(t *testing.T) {
+		repo := newRepo(t)
+
+		_, err := repo.Find(ctx, 999999)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+
+		_, err = repo.GetByEmail(ctx, "nobody@example.com")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+
+		missing := newUser(t, "missing@example.com")
+		missing.ID = 999999
+		assert.ErrorIs(t, repo.Update(ctx, missing), sql.ErrNoRows)
+	})
+
+	t.Run("updates a retrieved user", func(t *testing.T) {
+		repo := newRepo(t)
+
+		created := newUser(t, "john@example.com")
+		require.NoError(t, repo.Create(ctx, created))
+
+		user, err := repo.Find(ctx, created.ID)
+		require.NoError(t, err)
+		user.Name = "Johnny"
+		user.Email = "johnny@example.com"
+		user.Password = "new-hash"
+		user.VerifyEmail(time.Now().UTC())
+		require.NoError(t, repo.Update(ctx, user))
+
+		updated, err := repo.GetByEmail(ctx, "johnny@example.com")
+		require.NoError(t, err)
+		assertSameUser(t, user, updated)
+
+		// 舊的 email 不再指向此使用者，且可以重新使用
+		_, err = repo.GetByEmail(ctx, "john@example.com")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		require.NoError(t, repo.Create(ctx, newUser(t, "john@example.com")))
+	})
+
+	t.Run("handles concurrent writers", func(t *testing.T) {
+		repo := newRepo(t)
+
+		const workers = 20
+		users := make([]*domain.User, workers)
+		for i := range users {
+			// 一半的 worker 競爭同一個 email
+			email := "shared@example.com"
+			if i%2 == 0 {
+				email = fmt.Sprintf("user%d@example.com", i)
+			}
+			users[i] = newUser(t, email)
+		}
+
+		var (
+			wg      sync.WaitGroup
+			mu      sync.Mutex
+			created int
+		)
+		for _, user := range users {
+			wg.Add(1)
+			go func(user *domain.User) {
+				defer wg.Done()
+				err := repo.Create(ctx, user)
+				if err != nil {
+					assert.ErrorIs(t, err, domain.ErrEmailExists)
+					return
+				}
+				mu.Lock()
+				created++
+				mu.Unlock()
+			}(user)
+		}
+		wg.Wait()
+
+		assert.Equal(t, workers/2+1, created)
+		for _, user := range users {
+			if user.Email == "shared@example.com" {
+				continue
+			}
+			stored, err := repo.GetByEmail(ctx, user.Email)
+			require.NoError(t, err)
+			assert.Equal(t, user.ID, stored.ID)
+		}
+	})
+}
+
+func newUser(t *testing.T, email string) *domain.User {
+	t.Helper()
+	user, err := domain.NewUser(domain.UserParams{Name: "John Doe", Email: email, Password: "hash"})
+	require.NoError(t, err)
+	return user
+}
+
+// assertSameUser 比較保存前後的使用者；時間欄位允許資料庫精度造成的誤差
+func assertSameUser(t *testing.T, expected, actual *domain.User) {
+	t.Helper()
+	assert.Equal(t, expected.ID, actual.ID)
+	assert.Equal(t, expected.Name, actual.Name)
+	assert.Equal(t, expected.Email, actual.Email)
+	assert.Equal(t, expected.Password, actual.Password)
+	assert.Equal(t, expected.IsEmailVerified(), actual.IsEmailVerified())
+	if expected.EmailVerifiedAt != nil && actual.EmailVerifiedAt != nil {
+		assert.WithinDuration(t, *expected.EmailVerifiedAt, *actual.EmailVerifiedAt, time.Millisecond)
+	}
+	assert.WithinDuration(t, expected.CreatedAt, actual.CreatedAt, time.Millisecond)
+}
@@ -4,17 +4,21 @@ import (
 	"context"
 	"database/sql"
 	"portal_link/modules/user/domain"
+	"portal_link/pkg/transaction"
 	"sync"
 )
 
 var _ domain.UserRepository = (*InMemoryUserRepository)(nil)
 
-// InMemoryUserRepository is an in-memory implementation of UserRepository for testing
+// InMemoryUserRepository is an in-memory implementation of UserRepository for testing.
+// Stored users are copies; every read returns a fresh copy so callers cannot mutate the stored state.
+// Writes made inside a transaction.InMemoryTxManager transaction record an undo of the touched user,
+// so a rollback never discards writes made outside the transaction.
 type InMemoryUserRepository struct {
-	mu      sync.RWMutex
-	users   map[int]*domain.User
-	emails  map[string]int // email -> user ID mapping
-	nextID  int
+	mu     sync.RWMutex
+	users  map[int]*domain.User
+	emails map[string]int // email -> user ID mapping
+	nextID int
 }
 
 // NewInMemoryUserRepository creates a new in-memory user repository
@@ -48,9 +52,16 @@ func (r *InMemoryUserRepository) Create(ctx context.Context, user *domain.User)
 	}
 
 	// Store user
-	r.users[user.ID] = user
+	stored := copyUser(user)
+	r.users[user.ID] = stored
 	r.emails[user.Email] = user.ID
 
+	transaction.RecordUndo(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.restore(stored.ID, stored, nil)
+	})
+
 	return nil
 }
 
@@ -64,8 +75,7 @@ func (r *InMemoryUserRepository) GetByEmail(ctx context.Context, email string) (
 		return nil, sql.ErrNoRows
 	}
 
-	user := r.users[userID]
-	return user, nil
+	return copyUser(r.users[userID]), nil
 }
 
 // Find retrieves a user by ID
@@ -78,7 +88,60 @@ func (r *InMemoryUserRepository) Find(ctx context.Context, id int) (*domain.User
 		return nil, sql.ErrNoRows
 	}
 
-	return user, nil
+	return copyUser(user), nil
+}
+
+// Update updates an existing user
+func (r *InMemoryUserRepository) Update(ctx context.Context, user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, exists := r.users[user.ID]
+	if !exists {
+		return sql.ErrNoRows
+	}
+
+	// Check if the new email belongs to another user
+	if user.Email != existing.Email {
+		if _, taken := r.emails[user.Email]; taken {
+			return domain.ErrEmailExists
+		}
+		delete(r.emails, existing.Email)
+		r.emails[user.Email] = user.ID
+	}
+
+	stored := copyUser(user)
+	r.users[user.ID] = stored
+
+	transaction.RecordUndo(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.restore(stored.ID, stored, existing)
+	})
+
+	return nil
+}
+
+// restore puts back previous (removing the user when nil) if the user is still the version
+// written by the transaction; a later write made outside the transaction wins.
+// Stored users are never mutated in place, so comparing pointers is enough. The caller must hold the lock.
+func (r *InMemoryUserRepository) restore(id int, written, previous *domain.User) {
+	if r.users[id] != written {
+		return
+	}
+
+	if r.emails[written.Email] == id {
+		delete(r.emails, written.Email)
+	}
+	if previous == nil {
+		delete(r.users, id)
+		return
+	}
+
+	r.users[id] = previous
+	if _, taken := r.emails[previous.Email]; !taken {
+		r.emails[previous.Email] = id
+	}
 }
 
 // Reset clears all data (useful for testing)
@@ -90,3 +153,13 @@ func (r *InMemoryUserRepository) Reset() {
 	r.emails = make(map[string]int)
 	r.nextID = 1
 }
+
+// copyUser copies the user so callers cannot mutate the stored state
+func copyUser(user *domain.User) *domain.User {
+	copied := *user
+	if user.EmailVerifiedAt != nil {
+		verifiedAt := *user.EmailVerifiedAt
+		copied.EmailVerifiedAt = &verifiedAt
+	}
+	return &copied
+}
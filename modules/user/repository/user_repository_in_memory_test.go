@@ -3,7 +3,10 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"portal_link/modules/user/domain"
+	"portal_link/modules/user/repository/repositorytest"
+	"portal_link/pkg/transaction"
 	"testing"
 	"time"
 
@@ -11,6 +14,12 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+func TestInMemoryUserRepository_Conformance(t *testing.T) {
+	repositorytest.RunUserRepositorySuite(t, func(t *testing.T) domain.UserRepository {
+		return NewInMemoryUserRepository()
+	})
+}
+
 func TestInMemoryUserRepository_Create(t *testing.T) {
 	repo := NewInMemoryUserRepository()
 	ctx := context.Background()
@@ -239,3 +248,99 @@ func TestInMemoryUserRepository_Concurrency(t *testing.T) {
 		assert.Equal(t, 10, len(repo.emails))
 	})
 }
+
+func TestInMemoryUserRepository_Update(t *testing.T) {
+	repo := NewInMemoryUserRepository()
+	ctx := context.Background()
+
+	t.Run("successfully updates user", func(t *testing.T) {
+		repo.Reset()
+
+		user := &domain.User{
+			Name:      "Test User",
+			Email:     "test@example.com",
+			Password:  "hashedpassword",
+			CreatedAt: time.Now().UTC(),
+			UpdatedAt: time.Now().UTC(),
+		}
+		err := repo.Create(ctx, user)
+		require.NoError(t, err)
+
+		updated := *user
+		updated.Password = "newhashedpassword"
+		updated.Email = "new@example.com"
+		err = repo.Update(ctx, &updated)
+		require.NoError(t, err)
+
+		retrieved, err := repo.GetByEmail(ctx, "new@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "newhashedpassword", retrieved.Password)
+
+		_, err = repo.GetByEmail(ctx, "test@example.com")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("returns error when email belongs to another user", func(t *testing.T) {
+		repo.Reset()
+
+		user1 := &domain.User{Name: "User 1", Email: "user1@example.com", Password: "password1"}
+		user2 := &domain.User{Name: "User 2", Email: "user2@example.com", Password: "password2"}
+		require.NoError(t, repo.Create(ctx, user1))
+		require.NoError(t, repo.Create(ctx, user2))
+
+		updated := *user2
+		updated.Email = "user1@example.com"
+		err := repo.Update(ctx, &updated)
+		assert.ErrorIs(t, err, domain.ErrEmailExists)
+	})
+
+	t.Run("returns error when ID not found", func(t *testing.T) {
+		repo.Reset()
+
+		err := repo.Update(ctx, &domain.User{ID: 9999, Email: "missing@example.com"})
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
+
+func TestInMemoryUserRepository_Rollback(t *testing.T) {
+	repo := NewInMemoryUserRepository()
+	txManager := transaction.NewInMemoryTxManager()
+	ctx := context.Background()
+	boom := errors.New("boom")
+
+	existing := &domain.User{Name: "Existing", Email: "existing@example.com", Password: "password"}
+	require.NoError(t, repo.Create(ctx, existing))
+
+	var outside *domain.User
+	err := txManager.WithinTx(ctx, func(txCtx context.Context) error {
+		require.NoError(t, repo.Create(txCtx, &domain.User{Name: "Inside", Email: "inside@example.com", Password: "password"}))
+
+		updated := *existing
+		updated.Email = "renamed@example.com"
+		require.NoError(t, repo.Update(txCtx, &updated))
+
+		// 交易期間在交易外建立的使用者
+		outside = &domain.User{Name: "Outside", Email: "outside@example.com", Password: "password"}
+		require.NoError(t, repo.Create(ctx, outside))
+		return boom
+	})
+	assert.ErrorIs(t, err, boom)
+
+	_, err = repo.GetByEmail(ctx, "inside@example.com")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = repo.GetByEmail(ctx, "renamed@example.com")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	restored, err := repo.GetByEmail(ctx, "existing@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, existing.ID, restored.ID)
+
+	// 交易外的寫入不受回滾影響
+	stored, err := repo.GetByEmail(ctx, "outside@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, outside.ID, stored.ID)
+
+	// 回滾不會重複使用已分配的 ID
+	next := &domain.User{Name: "Next", Email: "next@example.com", Password: "password"}
+	require.NoError(t, repo.Create(ctx, next))
+	assert.Greater(t, next.ID, outside.ID)
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"portal_link/modules/user/domain"
+	"portal_link/pkg/metrics"
+	"portal_link/pkg/tracing"
+	"time"
+)
+
+var _ domain.UserRepository = (*InstrumentedUserRepository)(nil)
+
+// InstrumentedUserRepository wraps any UserRepository and reports the latency and
+// result of every call to a metrics.RepositoryObserver, tracing each call as a span.
+type InstrumentedUserRepository struct {
+	next     domain.UserRepository
+	observer metrics.RepositoryObserver
+}
+
+// NewInstrumentedUserRepository creates a decorator reporting the calls of next to observer
+func NewInstrumentedUserRepository(next domain.UserRepository, observer metrics.RepositoryObserver) *InstrumentedUserRepository {
+	return &InstrumentedUserRepository{next: next, observer: observer}
+}
+
+// Create creates a new user
+func (r *InstrumentedUserRepository) Create(ctx context.Context, user *domain.User) error {
+	ctx, finish := r.start(ctx, "Create")
+	err := r.next.Create(ctx, user)
+	finish(err)
+	return err
+}
+
+// GetByEmail retrieves a user by email
+func (r *InstrumentedUserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	ctx, finish := r.start(ctx, "GetByEmail")
+	user, err := r.next.GetByEmail(ctx, email)
+	finish(err)
+	return user, err
+}
+
+// Find retrieves a user by ID
+func (r *InstrumentedUserRepository) Find(ctx context.Context, id int) (*domain.User, error) {
+	ctx, finish := r.start(ctx, "Find")
+	user, err := r.next.Find(ctx, id)
+	finish(err)
+	return user, err
+}
+
+// Update updates an existing user
+func (r *InstrumentedUserRepository) Update(ctx context.Context, user *domain.User) error {
+	ctx, finish := r.start(ctx, "Update")
+	err := r.next.Update(ctx, user)
+	finish(err)
+	return err
+}
+
+// start starts a UserRepository.<method> span; the returned finish ends it and reports the call
+func (r *InstrumentedUserRepository) start(ctx context.Context, method string) (context.Context, func(err error)) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "UserRepository."+method)
+	return ctx, func(err error) {
+		r.observer.ObserveRepositoryCall("user", method, time.Since(start), err)
+		// a missing row is a regular lookup result, not a span error
+		if errors.Is(err, sql.ErrNoRows) {
+			err = nil
+		}
+		tracing.End(span, err)
+	}
+}
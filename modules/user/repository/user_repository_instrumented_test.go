@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"portal_link/modules/user/domain"
+	"portal_link/modules/user/repository/repositorytest"
+	"portal_link/pkg/tracing/tracingtest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// repositoryCall a call reported to fakeObserver
+type repositoryCall struct {
+	repository string
+	method     string
+	err        error
+}
+
+// fakeObserver records the calls reported by an instrumented repository
+type fakeObserver struct {
+	mu    sync.Mutex
+	calls []repositoryCall
+}
+
+func (o *fakeObserver) ObserveRepositoryCall(repository, method string, duration time.Duration, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.calls = append(o.calls, repositoryCall{repository: repository, method: method, err: err})
+}
+
+func TestInstrumentedUserRepository_Conformance(t *testing.T) {
+	repositorytest.RunUserRepositorySuite(t, func(t *testing.T) domain.UserRepository {
+		return NewInstrumentedUserRepository(NewInMemoryUserRepository(), &fakeObserver{})
+	})
+}
+
+func TestInstrumentedUserRepository_ReportsCalls(t *testing.T) {
+	ctx := context.Background()
+	observer := &fakeObserver{}
+	repo := NewInstrumentedUserRepository(NewInMemoryUserRepository(), observer)
+
+	user := &domain.User{Name: "Test User", Email: "test@example.com", Password: "hashed"}
+	require.NoError(t, repo.Create(ctx, user))
+	_, err := repo.Find(ctx, user.ID)
+	require.NoError(t, err)
+	_, err = repo.GetByEmail(ctx, "missing@example.com")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	require.NoError(t, repo.Update(ctx, user))
+
+	assert.Equal(t, []repositoryCall{
+		{repository: "user", method: "Create"},
+		{repository: "user", method: "Find"},
+		{repository: "user", method: "GetByEmail", err: sql.ErrNoRows},
+		{repository: "user", method: "Update"},
+	}, observer.calls)
+}
+
+func TestInstrumentedUserRepository_Spans(t *testing.T) {
+	ctx := context.Background()
+	exporter := tracingtest.NewExporter(t)
+	repo := NewInstrumentedUserRepository(NewInMemoryUserRepository(), &fakeObserver{})
+
+	user := &domain.User{Name: "Test User", Email: "test@example.com", Password: "hashed"}
+	require.NoError(t, repo.Create(ctx, user))
+	_, err := repo.GetByEmail(ctx, "missing@example.com")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	assert.Equal(t, []string{"UserRepository.Create", "UserRepository.GetByEmail"}, tracingtest.SpanNames(exporter))
+	// a missing row is not a span error
+	assert.Equal(t, codes.Unset, exporter.GetSpans()[1].Status.Code)
+}
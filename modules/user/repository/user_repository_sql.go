@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"portal_link/modules/user/domain"
+	"portal_link/pkg/database"
+	"time"
+)
+
+var _ domain.UserRepository = (*SQLUserRepository)(nil)
+
+const selectUserColumns = `SELECT id, name, email, password, email_verified_at, created_at, updated_at FROM users`
+
+// SQLUserRepository is a database/sql implementation of UserRepository.
+// The queries are shared by every driver supported by pkg/database (PostgreSQL and SQLite)
+// and join the use case transaction carried by ctx, if any.
+type SQLUserRepository struct {
+	db *sql.DB
+}
+
+// NewSQLUserRepository creates a new SQL user repository
+func NewSQLUserRepository(db *sql.DB) *SQLUserRepository {
+	return &SQLUserRepository{db: db}
+}
+
+// Create creates a new user and assigns its ID
+func (r *SQLUserRepository) Create(ctx context.Context, user *domain.User) error {
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, `
+		INSERT INTO users (name, email, password, email_verified_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`,
+		user.Name, user.Email, user.Password, nullTime(user.EmailVerifiedAt), user.CreatedAt.UTC(), user.UpdatedAt.UTC(),
+	).Scan(&user.ID)
+	return mapUserError(err)
+}
+
+// GetByEmail retrieves a user by email
+func (r *SQLUserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	return scanUser(database.Conn(ctx, r.db).QueryRowContext(ctx, selectUserColumns+` WHERE email = $1`, email))
+}
+
+// Find retrieves a user by ID
+func (r *SQLUserRepository) Find(ctx context.Context, id int) (*domain.User, error) {
+	return scanUser(database.Conn(ctx, r.db).QueryRowContext(ctx, selectUserColumns+` WHERE id = $1`, id))
+}
+
+// Update updates an existing user
+func (r *SQLUserRepository) Update(ctx context.Context, user *domain.User) error {
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE users
+		SET name = $2, email = $3, password = $4, email_verified_at = $5, updated_at = $6
+		WHERE id = $1`,
+		user.ID, user.Name, user.Email, user.Password, nullTime(user.EmailVerifiedAt), user.UpdatedAt.UTC(),
+	)
+	if err != nil {
+		return mapUserError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// scanUser scans a single users row; returns sql.ErrNoRows when there is no row
+func scanUser(row *sql.Row) (*domain.User, error) {
+	var (
+		user            domain.User
+		emailVerifiedAt sql.NullTime
+	)
+	err := row.Scan(&user.ID, &user.Name, &user.Email, &user.Password, &emailVerifiedAt, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	user.CreatedAt = user.CreatedAt.UTC()
+	user.UpdatedAt = user.UpdatedAt.UTC()
+	if emailVerifiedAt.Valid {
+		verifiedAt := emailVerifiedAt.Time.UTC()
+		user.EmailVerifiedAt = &verifiedAt
+	}
+	return &user, nil
+}
+
+// mapUserError maps a unique violation to ErrEmailExists; email is the only unique column besides the ID
+func mapUserError(err error) error {
+	if database.IsUniqueViolation(err) {
+		return domain.ErrEmailExists
+	}
+	return err
+}
+
+// nullTime converts an optional time to a nullable UTC column value
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"portal_link/modules/user/domain"
+	"portal_link/modules/user/repository/repositorytest"
+	"portal_link/pkg/database/databasetest"
+	"testing"
+)
+
+func TestSQLUserRepository(t *testing.T) {
+	for _, driver := range databasetest.Drivers {
+		t.Run(string(driver), func(t *testing.T) {
+			repositorytest.RunUserRepositorySuite(t, func(t *testing.T) domain.UserRepository {
+				return NewSQLUserRepository(databasetest.Open(t, driver))
+			})
+		})
+	}
+}
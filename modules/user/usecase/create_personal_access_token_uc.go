@@ -0,0 +1,159 @@
+package usecase
+
+import (
+	"context"
+	"portal_link/modules/user/domain"
+	"portal_link/pkg/auth"
+	"portal_link/pkg/tracing"
+	"portal_link/pkg/validation"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// CreatePersonalAccessTokenParams 建立 personal access token 用例的輸入參數
+type CreatePersonalAccessTokenParams struct {
+	UserID int    `json:"-"`
+	Name   string `json:"name"`
+	// Scopes token 可以存取的權限，例如 portal_pages:read
+	Scopes []string `json:"scopes"`
+	// ExpiresAt 選填，未提供時 token 不會過期
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// PersonalAccessTokenResult personal access token 的資訊，不包含 token 本身
+type PersonalAccessTokenResult struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreatePersonalAccessTokenResult 建立 personal access token 用例的輸出結果
+// Token 只在建立時返回，之後無法再取得
+type CreatePersonalAccessTokenResult struct {
+	PersonalAccessTokenResult
+	Token string `json:"token"`
+}
+
+// CreatePersonalAccessTokenUC 建立 personal access token 用例
+type CreatePersonalAccessTokenUC struct {
+	personalAccessTokenRepository domain.PersonalAccessTokenRepository
+}
+
+func NewCreatePersonalAccessTokenUC(personalAccessTokenRepository domain.PersonalAccessTokenRepository) *CreatePersonalAccessTokenUC {
+	return &CreatePersonalAccessTokenUC{
+		personalAccessTokenRepository: personalAccessTokenRepository,
+	}
+}
+
+func (s *CreatePersonalAccessTokenUC) Execute(ctx context.Context, params *CreatePersonalAccessTokenParams) (_ *CreatePersonalAccessTokenResult, err error) {
+	ctx, span := tracing.Start(ctx, "CreatePersonalAccessTokenUC.Execute")
+	defer func() { tracing.End(span, err) }()
+
+	// 1. 驗證輸入參數
+	now := time.Now().UTC()
+	if err := s.validateParams(params, now); err != nil {
+		return nil, err
+	}
+
+	// 2. 產生 token，只保存雜湊值
+	token, tokenHash, err := auth.GeneratePersonalAccessToken()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate personal access token")
+	}
+	scopes := make([]domain.Scope, 0, len(params.Scopes))
+	for _, scope := range params.Scopes {
+		scopes = append(scopes, domain.Scope(scope))
+	}
+	var expiresAt *time.Time
+	if params.ExpiresAt != nil {
+		utc := params.ExpiresAt.UTC()
+		expiresAt = &utc
+	}
+
+	personalAccessToken, err := domain.NewPersonalAccessToken(domain.PersonalAccessTokenParams{
+		UserID:    params.UserID,
+		Name:      strings.TrimSpace(params.Name),
+		TokenHash: tokenHash,
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 存入資料庫
+	if err := s.personalAccessTokenRepository.Create(ctx, personalAccessToken); err != nil {
+		return nil, err
+	}
+
+	return &CreatePersonalAccessTokenResult{
+		PersonalAccessTokenResult: newPersonalAccessTokenResult(personalAccessToken),
+		Token:                     token,
+	}, nil
+}
+
+// validateParams 驗證輸入參數，一起返回所有不合法的欄位
+func (s *CreatePersonalAccessTokenUC) validateParams(params *CreatePersonalAccessTokenParams, now time.Time) error {
+	var v validation.Errors
+
+	if params.UserID <= 0 {
+		v.Add("user_id", validation.RuleOutOfRange, validation.Params{"min": 1})
+	}
+
+	// 驗證 name：必填，最多 100 字元
+	name := strings.TrimSpace(params.Name)
+	if name == "" {
+		v.Add("name", validation.RuleRequired, nil)
+	} else if len(name) > personalAccessTokenNameMaxLength {
+		v.Add("name", validation.RuleTooLong, validation.Params{"max": personalAccessTokenNameMaxLength})
+	}
+
+	// 驗證 scopes：至少一個，且不能重複
+	if len(params.Scopes) == 0 {
+		v.Add("scopes", validation.RuleRequired, nil)
+	}
+	allowed := make([]string, 0, len(domain.Scopes))
+	for _, scope := range domain.Scopes {
+		allowed = append(allowed, string(scope))
+	}
+	for i, scope := range params.Scopes {
+		field := "scopes[" + strconv.Itoa(i) + "]"
+		switch {
+		case !domain.Scope(scope).IsValid():
+			v.Add(field, validation.RuleOneOf, validation.Params{"values": allowed})
+		case slices.Contains(params.Scopes[:i], scope):
+			v.Add(field, validation.RuleNotUnique, nil)
+		}
+	}
+
+	// 驗證 expires_at：必須晚於現在
+	if params.ExpiresAt != nil && !params.ExpiresAt.After(now) {
+		v.Add("expires_at", validation.RuleOutOfRange, nil)
+	}
+
+	return v.Err(domain.ErrInvalidParams)
+}
+
+// newPersonalAccessTokenResult 將 personal access token 轉為輸出結果
+func newPersonalAccessTokenResult(personalAccessToken *domain.PersonalAccessToken) PersonalAccessTokenResult {
+	scopes := make([]string, 0, len(personalAccessToken.Scopes))
+	for _, scope := range personalAccessToken.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+	return PersonalAccessTokenResult{
+		ID:         personalAccessToken.ID,
+		Name:       personalAccessToken.Name,
+		Scopes:     scopes,
+		ExpiresAt:  personalAccessToken.ExpiresAt,
+		LastUsedAt: personalAccessToken.LastUsedAt,
+		CreatedAt:  personalAccessToken.CreatedAt,
+	}
+}
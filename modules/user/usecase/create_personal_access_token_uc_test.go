@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"context"
+	"portal_link/modules/user/domain"
+	"portal_link/modules/user/repository"
+	"portal_link/pkg/auth"
+	"portal_link/pkg/validation"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreatePersonalAccessTokenUC_Execute(t *testing.T) {
+	ctx := context.Background()
+	past := time.Now().UTC().Add(-time.Hour)
+	future := time.Now().UTC().Add(24 * time.Hour)
+
+	tests := []struct {
+		name              string
+		params            *CreatePersonalAccessTokenParams
+		wantErr           bool
+		expectedFieldErrs []validation.FieldError
+		checkResult       func(t *testing.T, repo *repository.InMemoryPersonalAccessTokenRepository, result *CreatePersonalAccessTokenResult)
+	}{
+		{
+			name: "成功建立，只保存 token 的雜湊值",
+			params: &CreatePersonalAccessTokenParams{
+				UserID:    1,
+				Name:      " deploy ",
+				Scopes:    []string{"portal_pages:read", "portal_pages:write"},
+				ExpiresAt: &future,
+			},
+			checkResult: func(t *testing.T, repo *repository.InMemoryPersonalAccessTokenRepository, result *CreatePersonalAccessTokenResult) {
+				assert.True(t, strings.HasPrefix(result.Token, auth.PersonalAccessTokenPrefix))
+				assert.Equal(t, "deploy", result.Name)
+				assert.Equal(t, []string{"portal_pages:read", "portal_pages:write"}, result.Scopes)
+				require.NotNil(t, result.ExpiresAt)
+				assert.True(t, future.Equal(*result.ExpiresAt))
+				assert.Nil(t, result.LastUsedAt)
+
+				stored, err := repo.GetByTokenHash(ctx, auth.HashOpaqueToken(result.Token))
+				require.NoError(t, err)
+				assert.Equal(t, result.ID, stored.ID)
+				assert.NotEqual(t, result.Token, stored.TokenHash)
+				assert.True(t, stored.HasScopes(domain.ScopePortalPagesRead, domain.ScopePortalPagesWrite))
+			},
+		},
+		{
+			name: "未指定有效期時不會過期",
+			params: &CreatePersonalAccessTokenParams{
+				UserID: 1,
+				Name:   "ci",
+				Scopes: []string{"portal_pages:read"},
+			},
+			checkResult: func(t *testing.T, repo *repository.InMemoryPersonalAccessTokenRepository, result *CreatePersonalAccessTokenResult) {
+				assert.Nil(t, result.ExpiresAt)
+			},
+		},
+		{
+			name: "返回所有不合法的欄位",
+			params: &CreatePersonalAccessTokenParams{
+				UserID:    1,
+				Name:      strings.Repeat("a", 101),
+				Scopes:    []string{"portal_pages:read", "admin", "portal_pages:read"},
+				ExpiresAt: &past,
+			},
+			wantErr: true,
+			expectedFieldErrs: []validation.FieldError{
+				{Field: "name", Rule: validation.RuleTooLong, Params: validation.Params{"max": 100}},
+				{Field: "scopes[1]", Rule: validation.RuleOneOf, Params: validation.Params{"values": []string{"portal_pages:read", "portal_pages:write"}}},
+				{Field: "scopes[2]", Rule: validation.RuleNotUnique},
+				{Field: "expires_at", Rule: validation.RuleOutOfRange},
+			},
+		},
+		{
+			name: "name 與 scopes 為必填",
+			params: &CreatePersonalAccessTokenParams{
+				UserID: 1,
+				Name:   "  ",
+			},
+			wantErr: true,
+			expectedFieldErrs: []validation.FieldError{
+				{Field: "name", Rule: validation.RuleRequired},
+				{Field: "scopes", Rule: validation.RuleRequired},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := repository.NewInMemoryPersonalAccessTokenRepository()
+			uc := NewCreatePersonalAccessTokenUC(repo)
+
+			result, err := uc.Execute(ctx, tt.params)
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, domain.ErrInvalidParams)
+				assert.Equal(t, tt.expectedFieldErrs, validation.FieldErrors(err))
+				return
+			}
+			require.NoError(t, err)
+			if tt.checkResult != nil {
+				tt.checkResult(t, repo, result)
+			}
+		})
+	}
+}
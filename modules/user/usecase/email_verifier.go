@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"portal_link/modules/user/domain"
+	"portal_link/pkg/auth"
+
+	"github.com/cockroachdb/errors"
+)
+
+// emailVerifier 負責產生電子郵件驗證 token 並寄送驗證信，供註冊與重新寄送驗證信用例共用
+type emailVerifier struct {
+	emailVerificationTokenRepository domain.EmailVerificationTokenRepository
+	mailer                           domain.Mailer
+	verifyURL                        string
+}
+
+// send 使先前尚未使用的驗證 token 失效，產生新的 token 並寄送驗證信
+func (v *emailVerifier) send(ctx context.Context, user *domain.User) error {
+	link, err := v.issue(ctx, user)
+	if err != nil {
+		return err
+	}
+	return v.mail(ctx, user, link)
+}
+
+// issue 使先前尚未使用的驗證 token 失效並產生新的 token，返回附帶 token 的驗證網址
+// 只寫入 repository，可以在交易中執行
+func (v *emailVerifier) issue(ctx context.Context, user *domain.User) (string, error) {
+	if err := v.emailVerificationTokenRepository.InvalidateByUserID(ctx, user.ID); err != nil {
+		return "", errors.Wrap(err, "failed to invalidate email verification tokens")
+	}
+
+	// 產生驗證 token，資料庫只保存雜湊值
+	token, tokenHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	emailVerificationToken, err := domain.NewEmailVerificationToken(domain.EmailVerificationTokenParams{
+		UserID:    user.ID,
+		TokenHash: tokenHash,
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := v.emailVerificationTokenRepository.Create(ctx, emailVerificationToken); err != nil {
+		return "", errors.Wrap(err, "failed to create email verification token")
+	}
+
+	return linkWithToken(v.verifyURL, token)
+}
+
+// mail 寄送附帶驗證網址 link 的驗證信
+func (v *emailVerifier) mail(ctx context.Context, user *domain.User, link string) error {
+	body := fmt.Sprintf("您好 %s，\n\n請點擊以下連結驗證您的電子郵件地址，連結將於 %s 後失效：\n\n%s\n\n若您沒有註冊 Portal Link，請忽略此信件。\n",
+		user.Name, domain.EmailVerificationTokenExpiration, link)
+	if err := v.mailer.Send(ctx, user.Email, "驗證您的 Portal Link 電子郵件地址", body); err != nil {
+		return errors.Wrap(err, "failed to send verification email")
+	}
+
+	return nil
+}
+
+// linkWithToken 將 token 附加到網址的 query string
+func linkWithToken(rawURL string, token string) (string, error) {
+	link, err := url.Parse(rawURL)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid link url")
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String(), nil
+}
@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"context"
+	"portal_link/modules/user/domain"
+	"portal_link/pkg/tracing"
+	"portal_link/pkg/validation"
+)
+
+// ListPersonalAccessTokensParams 列出 personal access token 用例的輸入參數
+type ListPersonalAccessTokensParams struct {
+	UserID int
+}
+
+// ListPersonalAccessTokensResult 列出 personal access token 用例的輸出結果
+type ListPersonalAccessTokensResult struct {
+	PersonalAccessTokens []PersonalAccessTokenResult `json:"personal_access_tokens"`
+}
+
+// ListPersonalAccessTokensUC 列出使用者尚未撤銷的 personal access token 用例，包含已過期的 token
+type ListPersonalAccessTokensUC struct {
+	personalAccessTokenRepository domain.PersonalAccessTokenRepository
+}
+
+func NewListPersonalAccessTokensUC(personalAccessTokenRepository domain.PersonalAccessTokenRepository) *ListPersonalAccessTokensUC {
+	return &ListPersonalAccessTokensUC{
+		personalAccessTokenRepository: personalAccessTokenRepository,
+	}
+}
+
+func (s *ListPersonalAccessTokensUC) Execute(ctx context.Context, params *ListPersonalAccessTokensParams) (_ *ListPersonalAccessTokensResult, err error) {
+	ctx, span := tracing.Start(ctx, "ListPersonalAccessTokensUC.Execute")
+	defer func() { tracing.End(span, err) }()
+
+	// 1. 驗證輸入參數
+	if params.UserID <= 0 {
+		return nil, validation.NewError(domain.ErrInvalidParams, "user_id", validation.RuleOutOfRange, validation.Params{"min": 1})
+	}
+
+	// 2. 查詢使用者的 personal access token
+	personalAccessTokens, err := s.personalAccessTokenRepository.ListByUserID(ctx, params.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]PersonalAccessTokenResult, 0, len(personalAccessTokens))
+	for _, personalAccessToken := range personalAccessTokens {
+		results = append(results, newPersonalAccessTokenResult(personalAccessToken))
+	}
+	return &ListPersonalAccessTokensResult{
+		PersonalAccessTokens: results,
+	}, nil
+}
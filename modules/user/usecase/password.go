@@ -0,0 +1,23 @@
+package usecase
+
+import (
+	"context"
+	"portal_link/modules/user/domain"
+	"portal_link/pkg/tracing"
+)
+
+// hashPassword 雜湊密碼，並以 span 記錄雜湊所花的時間
+func hashPassword(ctx context.Context, hasher domain.PasswordHasher, password string) (string, error) {
+	_, span := tracing.Start(ctx, "PasswordHasher.Hash")
+	hashedPassword, err := hasher.Hash(password)
+	tracing.End(span, err)
+	return hashedPassword, err
+}
+
+// verifyPassword 比對密碼與雜湊值，並以 span 記錄比對所花的時間
+func verifyPassword(ctx context.Context, hasher domain.PasswordHasher, password string, encodedHash string) (bool, error) {
+	_, span := tracing.Start(ctx, "PasswordHasher.Verify")
+	matched, err := hasher.Verify(password, encodedHash)
+	tracing.End(span, err)
+	return matched, err
+}
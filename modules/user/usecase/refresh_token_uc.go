@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"portal_link/modules/user/domain"
+	"portal_link/pkg/auth"
+	"portal_link/pkg/tracing"
+	"portal_link/pkg/validation"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// RefreshTokenParams 刷新 token 用例的輸入參數
+type RefreshTokenParams struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RefreshTokenResult 刷新 token 用例的輸出結果
+type RefreshTokenResult struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// RefreshTokenUC 刷新 token 用例
+type RefreshTokenUC struct {
+	userRepository         domain.UserRepository
+	refreshTokenRepository domain.RefreshTokenRepository
+	tokenIssuer            *tokenIssuer
+}
+
+func NewRefreshTokenUC(userRepository domain.UserRepository, refreshTokenRepository domain.RefreshTokenRepository, tokenManager *auth.TokenManager) *RefreshTokenUC {
+	return &RefreshTokenUC{
+		userRepository:         userRepository,
+		refreshTokenRepository: refreshTokenRepository,
+		tokenIssuer: &tokenIssuer{
+			tokenManager:           tokenManager,
+			refreshTokenRepository: refreshTokenRepository,
+		},
+	}
+}
+
+func (r *RefreshTokenUC) Execute(ctx context.Context, params *RefreshTokenParams) (_ *RefreshTokenResult, err error) {
+	ctx, span := tracing.Start(ctx, "RefreshTokenUC.Execute")
+	defer func() { tracing.End(span, err) }()
+
+	// 1. 驗證輸入參數格式
+	if params.RefreshToken == "" {
+		return nil, validation.NewError(domain.ErrInvalidParams, "refresh_token", validation.RuleRequired, nil)
+	}
+
+	// 2. 根據 token 雜湊值查詢 refresh token
+	current, err := r.refreshTokenRepository.GetByTokenHash(ctx, auth.HashRefreshToken(params.RefreshToken))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	// 3. 已撤銷的 refresh token 無法使用
+	if current.IsRevoked() {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+
+	// 4. 已輪替過的 refresh token 被再次使用，視為外洩並撤銷整個 family
+	if current.IsUsed() {
+		return nil, r.revokeFamily(ctx, current)
+	}
+
+	// 5. 檢查是否過期
+	if current.IsExpired(time.Now().UTC()) {
+		return nil, domain.ErrRefreshTokenExpired
+	}
+
+	// 6. 確認使用者仍然存在
+	if _, err := r.userRepository.Find(ctx, current.UserID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	// 7. 輪替 refresh token 並簽發新的 access token
+	tokens, err := r.tokenIssuer.rotate(ctx, current)
+	if err != nil {
+		// 同時有其他請求使用了相同的 refresh token
+		if errors.Is(err, domain.ErrRefreshTokenReused) {
+			return nil, r.revokeFamily(ctx, current)
+		}
+		return nil, err
+	}
+
+	// 8. 返回新的 access_token 與 refresh_token
+	return &RefreshTokenResult{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+	}, nil
+}
+
+// revokeFamily 撤銷 refresh token 所屬的整個 family，並返回 ErrRefreshTokenReused
+func (r *RefreshTokenUC) revokeFamily(ctx context.Context, refreshToken *domain.RefreshToken) error {
+	if err := r.refreshTokenRepository.RevokeFamily(ctx, refreshToken.FamilyID); err != nil {
+		return errors.Wrap(err, "failed to revoke refresh token family")
+	}
+	return domain.ErrRefreshTokenReused
+}
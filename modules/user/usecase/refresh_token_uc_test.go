@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"context"
+	"portal_link/modules/user/domain"
+	"portal_link/modules/user/repository"
+	"portal_link/pkg/auth"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefreshTokenUC_Execute(t *testing.T) {
+	ctx := context.Background()
+	userRepo := repository.NewInMemoryUserRepository()
+	refreshTokenRepo := repository.NewInMemoryRefreshTokenRepository()
+	tokenManager := newTestTokenManager(t)
+
+	user, err := domain.NewUser(domain.UserParams{
+		Name:     "John Doe",
+		Email:    "john@example.com",
+		Password: "password123",
+	})
+	require.NoError(t, err)
+	require.NoError(t, userRepo.Create(ctx, user))
+
+	signIn := func(t *testing.T) *SignInResult {
+		t.Helper()
+		result, err := NewSignInUC(userRepo, refreshTokenRepo, repository.NewInMemoryLoginAttemptRepository(), newTestPasswordHasher(t), tokenManager, nil).Execute(ctx, &SignInParams{
+			Email:    "john@example.com",
+			Password: "password123",
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	uc := NewRefreshTokenUC(userRepo, refreshTokenRepo, tokenManager)
+
+	t.Run("成功刷新並輪替 refresh token", func(t *testing.T) {
+		signInResult := signIn(t)
+
+		result, err := uc.Execute(ctx, &RefreshTokenParams{RefreshToken: signInResult.RefreshToken})
+		require.NoError(t, err)
+		assert.NotEmpty(t, result.AccessToken)
+		assert.NotEqual(t, signInResult.RefreshToken, result.RefreshToken)
+		assert.Equal(t, int(tokenManager.AccessTokenTTL().Seconds()), result.ExpiresIn)
+
+		userID, err := tokenManager.ValidateAccessToken(ctx, result.AccessToken, userRepo)
+		require.NoError(t, err)
+		assert.Equal(t, "1", userID)
+
+		// 新的 refresh token 可以繼續使用
+		_, err = uc.Execute(ctx, &RefreshTokenParams{RefreshToken: result.RefreshToken})
+		assert.NoError(t, err)
+	})
+
+	t.Run("重複使用舊的 refresh token 會撤銷整個 family", func(t *testing.T) {
+		signInResult := signIn(t)
+
+		rotated, err := uc.Execute(ctx, &RefreshTokenParams{RefreshToken: signInResult.RefreshToken})
+		require.NoError(t, err)
+
+		// 攻擊者重送已輪替的 refresh token
+		_, err = uc.Execute(ctx, &RefreshTokenParams{RefreshToken: signInResult.RefreshToken})
+		assert.ErrorIs(t, err, domain.ErrRefreshTokenReused)
+
+		// 同一個 family 中最新的 refresh token 也一併失效
+		_, err = uc.Execute(ctx, &RefreshTokenParams{RefreshToken: rotated.RefreshToken})
+		assert.ErrorIs(t, err, domain.ErrInvalidRefreshToken)
+
+		// 其他 family 不受影響
+		other := signIn(t)
+		_, err = uc.Execute(ctx, &RefreshTokenParams{RefreshToken: other.RefreshToken})
+		assert.NoError(t, err)
+	})
+
+	t.Run("refresh token 已過期", func(t *testing.T) {
+		plaintext, tokenHash, err := auth.GenerateRefreshToken()
+		require.NoError(t, err)
+		require.NoError(t, refreshTokenRepo.Create(ctx, &domain.RefreshToken{
+			UserID:    user.ID,
+			FamilyID:  "expired",
+			TokenHash: tokenHash,
+			ExpiresAt: time.Now().UTC().Add(-time.Minute),
+		}))
+
+		_, err = uc.Execute(ctx, &RefreshTokenParams{RefreshToken: plaintext})
+		assert.ErrorIs(t, err, domain.ErrRefreshTokenExpired)
+	})
+
+	t.Run("refresh token 不存在", func(t *testing.T) {
+		_, err := uc.Execute(ctx, &RefreshTokenParams{RefreshToken: "unknown"})
+		assert.ErrorIs(t, err, domain.ErrInvalidRefreshToken)
+	})
+
+	t.Run("refresh token 為空", func(t *testing.T) {
+		_, err := uc.Execute(ctx, &RefreshTokenParams{RefreshToken: ""})
+		assert.ErrorIs(t, err, domain.ErrInvalidParams)
+	})
+}
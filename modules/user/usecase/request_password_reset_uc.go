@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"portal_link/modules/user/domain"
+	"portal_link/pkg/auth"
+	"portal_link/pkg/tracing"
+	"portal_link/pkg/validation"
+
+	"github.com/cockroachdb/errors"
+)
+
+// RequestPasswordResetParams 申請重設密碼用例的輸入參數
+type RequestPasswordResetParams struct {
+	Email string `json:"email"`
+}
+
+// RequestPasswordResetUC 申請重設密碼用例
+type RequestPasswordResetUC struct {
+	userRepository               domain.UserRepository
+	passwordResetTokenRepository domain.PasswordResetTokenRepository
+	mailer                       domain.Mailer
+	resetURL                     string
+}
+
+// NewRequestPasswordResetUC 建立申請重設密碼用例，resetURL 為前端重設密碼頁面的網址，token 會附加在 query string
+func NewRequestPasswordResetUC(userRepository domain.UserRepository, passwordResetTokenRepository domain.PasswordResetTokenRepository, mailer domain.Mailer, resetURL string) *RequestPasswordResetUC {
+	return &RequestPasswordResetUC{
+		userRepository:               userRepository,
+		passwordResetTokenRepository: passwordResetTokenRepository,
+		mailer:                       mailer,
+		resetURL:                     resetURL,
+	}
+}
+
+func (s *RequestPasswordResetUC) Execute(ctx context.Context, params *RequestPasswordResetParams) (err error) {
+	ctx, span := tracing.Start(ctx, "RequestPasswordResetUC.Execute")
+	defer func() { tracing.End(span, err) }()
+
+	// 1. 驗證輸入參數格式
+	if err := s.validateParams(params); err != nil {
+		return err
+	}
+
+	// 2. 根據電子郵件地址查詢使用者，不存在時直接返回成功，避免洩漏帳號是否存在
+	user, err := s.userRepository.GetByEmail(ctx, params.Email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	// 3. 使先前尚未使用的重設 token 失效，同一時間只有最新的 token 有效
+	if err := s.passwordResetTokenRepository.InvalidateByUserID(ctx, user.ID); err != nil {
+		return errors.Wrap(err, "failed to invalidate password reset tokens")
+	}
+
+	// 4. 產生重設 token，資料庫只保存雜湊值
+	token, tokenHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+	passwordResetToken, err := domain.NewPasswordResetToken(domain.PasswordResetTokenParams{
+		UserID:    user.ID,
+		TokenHash: tokenHash,
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.passwordResetTokenRepository.Create(ctx, passwordResetToken); err != nil {
+		return errors.Wrap(err, "failed to create password reset token")
+	}
+
+	// 5. 寄送重設密碼信件
+	link, err := linkWithToken(s.resetURL, token)
+	if err != nil {
+		return err
+	}
+	body := fmt.Sprintf("您好 %s，\n\n請點擊以下連結重設您的密碼，連結將於 %s 後失效：\n\n%s\n\n若您沒有申請重設密碼，請忽略此信件。\n",
+		user.Name, domain.PasswordResetTokenExpiration, link)
+	if err := s.mailer.Send(ctx, user.Email, "重設您的 Portal Link 密碼", body); err != nil {
+		return errors.Wrap(err, "failed to send password reset email")
+	}
+
+	return nil
+}
+
+// validateParams 驗證輸入參數
+func (s *RequestPasswordResetUC) validateParams(params *RequestPasswordResetParams) error {
+	var v validation.Errors
+	validateEmail(&v, "email", params.Email)
+	return v.Err(domain.ErrInvalidParams)
+}
@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"net/url"
+	"portal_link/modules/user/domain"
+	"portal_link/modules/user/repository"
+	"portal_link/pkg/auth"
+	"portal_link/pkg/mailer"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestPasswordResetUC_Execute(t *testing.T) {
+	ctx := context.Background()
+	userRepo := repository.NewInMemoryUserRepository()
+	passwordResetTokenRepo := repository.NewInMemoryPasswordResetTokenRepository()
+	outbox := mailer.NewInMemoryOutbox()
+	uc := NewRequestPasswordResetUC(userRepo, passwordResetTokenRepo, outbox, "https://portal.example.com/reset-password")
+
+	user, err := domain.NewUser(domain.UserParams{
+		Name:     "John Doe",
+		Email:    "john@example.com",
+		Password: "password123",
+	})
+	require.NoError(t, err)
+	require.NoError(t, userRepo.Create(ctx, user))
+
+	t.Run("寄送含有重設 token 的信件", func(t *testing.T) {
+		outbox.Reset()
+
+		err := uc.Execute(ctx, &RequestPasswordResetParams{Email: "john@example.com"})
+		require.NoError(t, err)
+
+		messages := outbox.MessagesTo("john@example.com")
+		require.Len(t, messages, 1)
+		token := tokenFromEmailBody(t, messages[0].Body)
+
+		// 資料庫只保存雜湊值
+		passwordResetToken, err := passwordResetTokenRepo.GetByTokenHash(ctx, auth.HashOpaqueToken(token))
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, passwordResetToken.UserID)
+		assert.NotEqual(t, token, passwordResetToken.TokenHash)
+		assert.False(t, passwordResetToken.IsUsed())
+		assert.Equal(t, domain.PasswordResetTokenExpiration, passwordResetToken.ExpiresAt.Sub(passwordResetToken.CreatedAt))
+	})
+
+	t.Run("重新申請後先前的 token 失效", func(t *testing.T) {
+		outbox.Reset()
+
+		require.NoError(t, uc.Execute(ctx, &RequestPasswordResetParams{Email: "john@example.com"}))
+		require.NoError(t, uc.Execute(ctx, &RequestPasswordResetParams{Email: "john@example.com"}))
+
+		messages := outbox.MessagesTo("john@example.com")
+		require.Len(t, messages, 2)
+
+		first, err := passwordResetTokenRepo.GetByTokenHash(ctx, auth.HashOpaqueToken(tokenFromEmailBody(t, messages[0].Body)))
+		require.NoError(t, err)
+		assert.True(t, first.IsUsed())
+
+		second, err := passwordResetTokenRepo.GetByTokenHash(ctx, auth.HashOpaqueToken(tokenFromEmailBody(t, messages[1].Body)))
+		require.NoError(t, err)
+		assert.False(t, second.IsUsed())
+	})
+
+	t.Run("使用者不存在時不寄信也不返回錯誤", func(t *testing.T) {
+		outbox.Reset()
+
+		err := uc.Execute(ctx, &RequestPasswordResetParams{Email: "nobody@example.com"})
+		require.NoError(t, err)
+		assert.Empty(t, outbox.Messages())
+	})
+
+	t.Run("email 格式錯誤", func(t *testing.T) {
+		err := uc.Execute(ctx, &RequestPasswordResetParams{Email: "invalid-email"})
+		assert.ErrorIs(t, err, domain.ErrInvalidParams)
+	})
+}
+
+// tokenFromEmailBody 從信件的連結中取出 token
+func tokenFromEmailBody(t *testing.T, body string) string {
+	t.Helper()
+
+	link := regexp.MustCompile(`https://\S+`).FindString(body)
+	require.NotEmpty(t, link)
+	parsed, err := url.Parse(link)
+	require.NoError(t, err)
+	token := parsed.Query().Get("token")
+	require.NotEmpty(t, token)
+	return token
+}
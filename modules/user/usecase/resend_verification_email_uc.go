@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"portal_link/modules/user/domain"
+	"portal_link/pkg/tracing"
+	"portal_link/pkg/transaction"
+	"portal_link/pkg/validation"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// ResendVerificationEmailParams 重新寄送驗證信用例的輸入參數
+type ResendVerificationEmailParams struct {
+	UserID int
+}
+
+// ResendVerificationEmailUC 重新寄送驗證信用例
+type ResendVerificationEmailUC struct {
+	txManager                        transaction.TxManager
+	userRepository                   domain.UserRepository
+	emailVerificationTokenRepository domain.EmailVerificationTokenRepository
+	emailVerifier                    *emailVerifier
+}
+
+// NewResendVerificationEmailUC 建立重新寄送驗證信用例，verifyURL 為驗證電子郵件的網址，token 會附加在 query string
+func NewResendVerificationEmailUC(txManager transaction.TxManager, userRepository domain.UserRepository, emailVerificationTokenRepository domain.EmailVerificationTokenRepository, mailer domain.Mailer, verifyURL string) *ResendVerificationEmailUC {
+	return &ResendVerificationEmailUC{
+		txManager:                        txManager,
+		userRepository:                   userRepository,
+		emailVerificationTokenRepository: emailVerificationTokenRepository,
+		emailVerifier: &emailVerifier{
+			emailVerificationTokenRepository: emailVerificationTokenRepository,
+			mailer:                           mailer,
+			verifyURL:                        verifyURL,
+		},
+	}
+}
+
+func (s *ResendVerificationEmailUC) Execute(ctx context.Context, params *ResendVerificationEmailParams) (err error) {
+	ctx, span := tracing.Start(ctx, "ResendVerificationEmailUC.Execute")
+	defer func() { tracing.End(span, err) }()
+
+	// 1. 驗證輸入參數
+	if params.UserID <= 0 {
+		return validation.NewError(domain.ErrInvalidParams, "user_id", validation.RuleOutOfRange, validation.Params{"min": 1})
+	}
+
+	// 2. 查詢使用者，已驗證時不需重新寄送
+	user, err := s.userRepository.Find(ctx, params.UserID)
+	if err != nil {
+		return err
+	}
+	if user.IsEmailVerified() {
+		return domain.ErrEmailAlreadyVerified
+	}
+
+	// 3~4 在同一個交易中執行，多個實例同時收到重新寄送的請求時只有一個會產生新的 token
+	var link string
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// 3. 距離上一封驗證信太近時拒絕寄送
+		latest, err := s.emailVerificationTokenRepository.GetLatestByUserID(ctx, user.ID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		if latest != nil {
+			if wait := latest.ResendAvailableIn(time.Now().UTC()); wait > 0 {
+				return &domain.VerificationEmailThrottledError{RetryAfter: wait}
+			}
+		}
+
+		// 4. 產生新的驗證 token
+		link, err = s.emailVerifier.issue(ctx, user)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// 5. 交易提交後寄送驗證信，避免交易重試時重複寄送
+	return s.emailVerifier.mail(ctx, user, link)
+}
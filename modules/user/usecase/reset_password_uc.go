@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"portal_link/modules/user/domain"
+	"portal_link/pkg/auth"
+	"portal_link/pkg/tracing"
+	"portal_link/pkg/transaction"
+	"portal_link/pkg/validation"
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// ResetPasswordParams 重設密碼用例的輸入參數
+type ResetPasswordParams struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+// ResetPasswordUC 重設密碼用例
+type ResetPasswordUC struct {
+	txManager                     transaction.TxManager
+	userRepository                domain.UserRepository
+	passwordResetTokenRepository  domain.PasswordResetTokenRepository
+	refreshTokenRepository        domain.RefreshTokenRepository
+	personalAccessTokenRepository domain.PersonalAccessTokenRepository
+	passwordHasher                domain.PasswordHasher
+	tokenManager                  *auth.TokenManager
+}
+
+func NewResetPasswordUC(txManager transaction.TxManager, userRepository domain.UserRepository, passwordResetTokenRepository domain.PasswordResetTokenRepository, refreshTokenRepository domain.RefreshTokenRepository, personalAccessTokenRepository domain.PersonalAccessTokenRepository, passwordHasher domain.PasswordHasher, tokenManager *auth.TokenManager) *ResetPasswordUC {
+	return &ResetPasswordUC{
+		txManager:                     txManager,
+		userRepository:                userRepository,
+		passwordResetTokenRepository:  passwordResetTokenRepository,
+		refreshTokenRepository:        refreshTokenRepository,
+		personalAccessTokenRepository: personalAccessTokenRepository,
+		passwordHasher:                passwordHasher,
+		tokenManager:                  tokenManager,
+	}
+}
+
+func (s *ResetPasswordUC) Execute(ctx context.Context, params *ResetPasswordParams) (err error) {
+	ctx, span := tracing.Start(ctx, "ResetPasswordUC.Execute")
+	defer func() { tracing.End(span, err) }()
+
+	// 1. 驗證輸入參數格式
+	if err := s.validateParams(params); err != nil {
+		return err
+	}
+
+	// 2. 以 token 雜湊值查詢重設 token
+	passwordResetToken, err := s.passwordResetTokenRepository.GetByTokenHash(ctx, auth.HashOpaqueToken(params.Token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrInvalidPasswordResetToken
+		}
+		return err
+	}
+
+	// 3. 檢查 token 是否已使用或過期
+	now := time.Now().UTC()
+	if passwordResetToken.IsUsed() {
+		return domain.ErrInvalidPasswordResetToken
+	}
+	if passwordResetToken.IsExpired(now) {
+		return domain.ErrPasswordResetTokenExpired
+	}
+
+	// 4. 雜湊新密碼（耗時較長，在交易開始前完成）
+	hashedPassword, err := hashPassword(ctx, s.passwordHasher, params.NewPassword)
+	if err != nil {
+		return errors.Wrap(err, "failed to hash password")
+	}
+
+	// 5~7 在同一個交易中執行，任一步驟失敗時 token 不會被消耗，密碼與已登入的 session 也保持不變
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// 5. 將 token 標記為已使用，同時送出的請求只有一個會成功
+		if err := s.passwordResetTokenRepository.MarkUsed(ctx, passwordResetToken); err != nil {
+			return err
+		}
+
+		// 6. 以新密碼的雜湊值更新使用者
+		user, err := s.userRepository.Find(ctx, passwordResetToken.UserID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrInvalidPasswordResetToken
+			}
+			return err
+		}
+		updated := *user
+		updated.Password = hashedPassword
+		updated.UpdatedAt = now
+		if err := s.userRepository.Update(ctx, &updated); err != nil {
+			return errors.Wrap(err, "failed to update password")
+		}
+
+		// 7. 使所有已登入的 session 與 personal access token 失效
+		if err := s.refreshTokenRepository.RevokeByUserID(ctx, user.ID); err != nil {
+			return errors.Wrap(err, "failed to revoke refresh tokens")
+		}
+		if err := s.personalAccessTokenRepository.RevokeByUserID(ctx, user.ID); err != nil {
+			return errors.Wrap(err, "failed to revoke personal access tokens")
+		}
+		if err := s.tokenManager.RevokeAllAccessTokens(ctx, strconv.Itoa(user.ID)); err != nil {
+			return errors.Wrap(err, "failed to revoke access tokens")
+		}
+		return nil
+	})
+}
+
+// validateParams 驗證輸入參數，返回所有不合法的欄位
+func (s *ResetPasswordUC) validateParams(params *ResetPasswordParams) error {
+	var v validation.Errors
+
+	// 驗證 token
+	if params.Token == "" {
+		v.Add("token", validation.RuleRequired, nil)
+	}
+
+	// 驗證 new_password：最少 8 字元，需包含英文和數字
+	validateNewPassword(&v, "new_password", params.NewPassword)
+
+	return v.Err(domain.ErrInvalidParams)
+}
@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"portal_link/modules/user/domain"
+	"portal_link/modules/user/repository"
+	"portal_link/pkg/auth"
+	"portal_link/pkg/mailer"
+	"portal_link/pkg/transaction"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResetPasswordUC_Execute(t *testing.T) {
+	ctx := context.Background()
+	userRepo := repository.NewInMemoryUserRepository()
+	refreshTokenRepo := repository.NewInMemoryRefreshTokenRepository()
+	passwordResetTokenRepo := repository.NewInMemoryPasswordResetTokenRepository()
+	personalAccessTokenRepo := repository.NewInMemoryPersonalAccessTokenRepository()
+	outbox := mailer.NewInMemoryOutbox()
+	passwordHasher := newTestPasswordHasher(t)
+	tokenManager := newTestTokenManager(t)
+
+	requestUC := NewRequestPasswordResetUC(userRepo, passwordResetTokenRepo, outbox, "https://portal.example.com/reset-password")
+	resetUC := NewResetPasswordUC(transaction.NewInMemoryTxManager(), userRepo, passwordResetTokenRepo, refreshTokenRepo, personalAccessTokenRepo, passwordHasher, tokenManager)
+	signInUC := NewSignInUC(userRepo, refreshTokenRepo, repository.NewInMemoryLoginAttemptRepository(), passwordHasher, tokenManager, nil)
+	refreshUC := NewRefreshTokenUC(userRepo, refreshTokenRepo, tokenManager)
+
+	hashedPassword, err := passwordHasher.Hash("password123")
+	require.NoError(t, err)
+	user, err := domain.NewUser(domain.UserParams{
+		Name:     "John Doe",
+		Email:    "john@example.com",
+		Password: hashedPassword,
+	})
+	require.NoError(t, err)
+	require.NoError(t, userRepo.Create(ctx, user))
+
+	requestToken := func(t *testing.T) string {
+		t.Helper()
+		outbox.Reset()
+		require.NoError(t, requestUC.Execute(ctx, &RequestPasswordResetParams{Email: "john@example.com"}))
+		messages := outbox.MessagesTo("john@example.com")
+		require.Len(t, messages, 1)
+		return tokenFromEmailBody(t, messages[0].Body)
+	}
+
+	t.Run("重設密碼後舊密碼失效且所有 session 被登出", func(t *testing.T) {
+		session, err := signInUC.Execute(ctx, &SignInParams{Email: "john@example.com", Password: "password123"})
+		require.NoError(t, err)
+		require.NoError(t, personalAccessTokenRepo.Create(ctx, &domain.PersonalAccessToken{
+			UserID:    user.ID,
+			Name:      "ci",
+			TokenHash: "pat-hash",
+			Scopes:    []domain.Scope{domain.ScopePortalPagesRead},
+		}))
+
+		token := requestToken(t)
+		err = resetUC.Execute(ctx, &ResetPasswordParams{Token: token, NewPassword: "newpassword456"})
+		require.NoError(t, err)
+
+		_, err = signInUC.Execute(ctx, &SignInParams{Email: "john@example.com", Password: "password123"})
+		assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+		_, err = signInUC.Execute(ctx, &SignInParams{Email: "john@example.com", Password: "newpassword456"})
+		assert.NoError(t, err)
+
+		_, err = tokenManager.ValidateAccessToken(ctx, session.AccessToken, userRepo)
+		assert.ErrorIs(t, err, auth.ErrRevokedToken)
+		_, err = refreshUC.Execute(ctx, &RefreshTokenParams{RefreshToken: session.RefreshToken})
+		assert.ErrorIs(t, err, domain.ErrInvalidRefreshToken)
+		pat, err := personalAccessTokenRepo.GetByTokenHash(ctx, "pat-hash")
+		require.NoError(t, err)
+		assert.True(t, pat.IsRevoked())
+
+		// token 只能使用一次
+		err = resetUC.Execute(ctx, &ResetPasswordParams{Token: token, NewPassword: "another789"})
+		assert.ErrorIs(t, err, domain.ErrInvalidPasswordResetToken)
+	})
+
+	t.Run("token 不存在", func(t *testing.T) {
+		err := resetUC.Execute(ctx, &ResetPasswordParams{Token: "unknown", NewPassword: "newpassword456"})
+		assert.ErrorIs(t, err, domain.ErrInvalidPasswordResetToken)
+	})
+
+	t.Run("token 已過期", func(t *testing.T) {
+		token, tokenHash, err := auth.GenerateOpaqueToken()
+		require.NoError(t, err)
+		expired, err := domain.NewPasswordResetToken(domain.PasswordResetTokenParams{
+			UserID:    user.ID,
+			TokenHash: tokenHash,
+			CreatedAt: time.Now().UTC().Add(-2 * domain.PasswordResetTokenExpiration),
+		})
+		require.NoError(t, err)
+		require.NoError(t, passwordResetTokenRepo.Create(ctx, expired))
+
+		err = resetUC.Execute(ctx, &ResetPasswordParams{Token: token, NewPassword: "newpassword456"})
+		assert.ErrorIs(t, err, domain.ErrPasswordResetTokenExpired)
+	})
+
+	t.Run("新密碼不符合規則", func(t *testing.T) {
+		token := requestToken(t)
+
+		for _, newPassword := range []string{"short1", "onlyletters", "12345678"} {
+			err := resetUC.Execute(ctx, &ResetPasswordParams{Token: token, NewPassword: newPassword})
+			assert.ErrorIs(t, err, domain.ErrInvalidParams, newPassword)
+		}
+
+		// 驗證失敗不會消耗 token
+		err := resetUC.Execute(ctx, &ResetPasswordParams{Token: token, NewPassword: "newpassword789"})
+		assert.NoError(t, err)
+	})
+}
+
+func TestResetPasswordUC_Execute_Transaction(t *testing.T) {
+	ctx := context.Background()
+	userRepo := repository.NewInMemoryUserRepository()
+	refreshTokenRepo := repository.NewInMemoryRefreshTokenRepository()
+	passwordResetTokenRepo := repository.NewInMemoryPasswordResetTokenRepository()
+	personalAccessTokenRepo := repository.NewInMemoryPersonalAccessTokenRepository()
+	passwordHasher := newTestPasswordHasher(t)
+	tokenManager := newTestTokenManager(t)
+
+	hashedPassword, err := passwordHasher.Hash("password123")
+	require.NoError(t, err)
+	user, err := domain.NewUser(domain.UserParams{
+		Name:     "John Doe",
+		Email:    "john@example.com",
+		Password: hashedPassword,
+	})
+	require.NoError(t, err)
+	require.NoError(t, userRepo.Create(ctx, user))
+	require.NoError(t, personalAccessTokenRepo.Create(ctx, &domain.PersonalAccessToken{
+		UserID:    user.ID,
+		Name:      "ci",
+		TokenHash: "pat-hash",
+		Scopes:    []domain.Scope{domain.ScopePortalPagesRead},
+	}))
+
+	token, tokenHash, err := auth.GenerateOpaqueToken()
+	require.NoError(t, err)
+	passwordResetToken, err := domain.NewPasswordResetToken(domain.PasswordResetTokenParams{
+		UserID:    user.ID,
+		TokenHash: tokenHash,
+	})
+	require.NoError(t, err)
+	require.NoError(t, passwordResetTokenRepo.Create(ctx, passwordResetToken))
+
+	errCommit := errors.New("commit failed")
+	txManager := &failingCommitTxManager{inner: transaction.NewInMemoryTxManager(), err: errCommit}
+	resetUC := NewResetPasswordUC(txManager, userRepo, passwordResetTokenRepo, refreshTokenRepo, personalAccessTokenRepo, passwordHasher, tokenManager)
+
+	// 交易失敗時 token 不會被消耗，密碼與 personal access token 保持不變
+	err = resetUC.Execute(ctx, &ResetPasswordParams{Token: token, NewPassword: "newpassword456"})
+	assert.ErrorIs(t, err, errCommit)
+
+	stored, err := passwordResetTokenRepo.GetByTokenHash(ctx, tokenHash)
+	require.NoError(t, err)
+	assert.False(t, stored.IsUsed())
+	found, err := userRepo.Find(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, hashedPassword, found.Password)
+	pat, err := personalAccessTokenRepo.GetByTokenHash(ctx, "pat-hash")
+	require.NoError(t, err)
+	assert.False(t, pat.IsRevoked())
+
+	// 之後仍可以使用同一個 token 重設密碼
+	resetUC = NewResetPasswordUC(transaction.NewInMemoryTxManager(), userRepo, passwordResetTokenRepo, refreshTokenRepo, personalAccessTokenRepo, passwordHasher, tokenManager)
+	require.NoError(t, resetUC.Execute(ctx, &ResetPasswordParams{Token: token, NewPassword: "newpassword456"}))
+}
@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"portal_link/modules/user/domain"
+	"portal_link/pkg/tracing"
+	"portal_link/pkg/validation"
+
+	"github.com/cockroachdb/errors"
+)
+
+// RevokePersonalAccessTokenParams 撤銷 personal access token 用例的輸入參數
+type RevokePersonalAccessTokenParams struct {
+	UserID int
+	ID     int
+}
+
+// RevokePersonalAccessTokenUC 撤銷 personal access token 用例，撤銷後 token 立即失效
+type RevokePersonalAccessTokenUC struct {
+	personalAccessTokenRepository domain.PersonalAccessTokenRepository
+}
+
+func NewRevokePersonalAccessTokenUC(personalAccessTokenRepository domain.PersonalAccessTokenRepository) *RevokePersonalAccessTokenUC {
+	return &RevokePersonalAccessTokenUC{
+		personalAccessTokenRepository: personalAccessTokenRepository,
+	}
+}
+
+func (s *RevokePersonalAccessTokenUC) Execute(ctx context.Context, params *RevokePersonalAccessTokenParams) (err error) {
+	ctx, span := tracing.Start(ctx, "RevokePersonalAccessTokenUC.Execute")
+	defer func() { tracing.End(span, err) }()
+
+	// 1. 驗證輸入參數
+	var v validation.Errors
+	if params.UserID <= 0 {
+		v.Add("user_id", validation.RuleOutOfRange, validation.Params{"min": 1})
+	}
+	if params.ID <= 0 {
+		v.Add("id", validation.RuleOutOfRange, validation.Params{"min": 1})
+	}
+	if err := v.Err(domain.ErrInvalidParams); err != nil {
+		return err
+	}
+
+	// 2. 查詢 token 並確認擁有者，不屬於目前使用者時視為不存在，避免洩漏其他使用者的 token ID
+	personalAccessToken, err := s.personalAccessTokenRepository.Find(ctx, params.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrPersonalAccessTokenNotFound
+		}
+		return err
+	}
+	if personalAccessToken.UserID != params.UserID || personalAccessToken.IsRevoked() {
+		return domain.ErrPersonalAccessTokenNotFound
+	}
+
+	// 3. 撤銷 token
+	return s.personalAccessTokenRepository.Revoke(ctx, personalAccessToken)
+}
@@ -0,0 +1,40 @@
+package usecase
+
+import (
+	"context"
+	"portal_link/modules/user/domain"
+	"portal_link/modules/user/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevokePersonalAccessTokenUC_Execute(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewInMemoryPersonalAccessTokenRepository()
+	create := NewCreatePersonalAccessTokenUC(repo)
+	list := NewListPersonalAccessTokensUC(repo)
+	uc := NewRevokePersonalAccessTokenUC(repo)
+
+	created, err := create.Execute(ctx, &CreatePersonalAccessTokenParams{UserID: 1, Name: "ci", Scopes: []string{"portal_pages:read"}})
+	require.NoError(t, err)
+
+	t.Run("其他使用者的 token 視為不存在", func(t *testing.T) {
+		err := uc.Execute(ctx, &RevokePersonalAccessTokenParams{UserID: 2, ID: created.ID})
+		assert.ErrorIs(t, err, domain.ErrPersonalAccessTokenNotFound)
+	})
+
+	t.Run("撤銷後不再列出", func(t *testing.T) {
+		require.NoError(t, uc.Execute(ctx, &RevokePersonalAccessTokenParams{UserID: 1, ID: created.ID}))
+
+		result, err := list.Execute(ctx, &ListPersonalAccessTokensParams{UserID: 1})
+		require.NoError(t, err)
+		assert.Empty(t, result.PersonalAccessTokens)
+	})
+
+	t.Run("已撤銷或不存在的 token", func(t *testing.T) {
+		assert.ErrorIs(t, uc.Execute(ctx, &RevokePersonalAccessTokenParams{UserID: 1, ID: created.ID}), domain.ErrPersonalAccessTokenNotFound)
+		assert.ErrorIs(t, uc.Execute(ctx, &RevokePersonalAccessTokenParams{UserID: 1, ID: 99}), domain.ErrPersonalAccessTokenNotFound)
+	})
+}
@@ -3,10 +3,14 @@ package usecase
 import (
 	"context"
 	"database/sql"
-	"fmt"
 	"portal_link/modules/user/domain"
 	"portal_link/pkg/auth"
-	"regexp"
+	"portal_link/pkg/logger"
+	"portal_link/pkg/tracing"
+	"portal_link/pkg/validation"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/cockroachdb/errors"
 )
@@ -15,72 +19,261 @@ import (
 type SignInParams struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	// IP 用戶端 IP，用於計算同一來源的登入失敗次數
+	IP string `json:"-"`
 }
 
 // SignInResult 登入用例的輸出結果
 type SignInResult struct {
-	AccessToken string `json:"access_token"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
 }
 
 // SignInUC 登入用例
 type SignInUC struct {
-	userRepository domain.UserRepository
+	userRepository         domain.UserRepository
+	loginAttemptRepository domain.LoginAttemptRepository
+	passwordHasher         domain.PasswordHasher
+	// dummyPasswordHash 使用者不存在時用來驗證密碼的雜湊值，以目前的雜湊參數產生
+	dummyPasswordHash  func() (string, error)
+	tokenIssuer        *tokenIssuer
+	emailLockoutPolicy domain.LockoutPolicy
+	ipLockoutPolicy    domain.LockoutPolicy
+	recorder           domain.SignInRecorder
 }
 
-func NewSignInUC(userRepository domain.UserRepository) *SignInUC {
-	return &SignInUC{userRepository: userRepository}
+// dummyPassword 產生 SignInUC 固定比對用雜湊值的密碼，不對應任何使用者
+const dummyPassword = "portal-link-dummy-password"
+
+// NewSignInUC 建立登入用例，recorder 為 nil 時不記錄登入結果
+func NewSignInUC(userRepository domain.UserRepository, refreshTokenRepository domain.RefreshTokenRepository, loginAttemptRepository domain.LoginAttemptRepository, passwordHasher domain.PasswordHasher, tokenManager *auth.TokenManager, recorder domain.SignInRecorder) *SignInUC {
+	if recorder == nil {
+		recorder = domain.NopSignInRecorder{}
+	}
+	return &SignInUC{
+		userRepository:         userRepository,
+		loginAttemptRepository: loginAttemptRepository,
+		passwordHasher:         passwordHasher,
+		dummyPasswordHash: sync.OnceValues(func() (string, error) {
+			return passwordHasher.Hash(dummyPassword)
+		}),
+		tokenIssuer: &tokenIssuer{
+			tokenManager:           tokenManager,
+			refreshTokenRepository: refreshTokenRepository,
+		},
+		emailLockoutPolicy: domain.DefaultEmailLockoutPolicy,
+		ipLockoutPolicy:    domain.DefaultIPLockoutPolicy,
+		recorder:           recorder,
+	}
+}
+
+func (s *SignInUC) Execute(ctx context.Context, signInParams *SignInParams) (_ *SignInResult, err error) {
+	ctx, span := tracing.Start(ctx, "SignInUC.Execute")
+	defer func() { tracing.End(span, err) }()
+
+	result, err := s.execute(ctx, signInParams)
+	s.recorder.RecordSignIn(signInOutcome(err))
+	return result, err
 }
 
-func (s *SignInUC) Execute(ctx context.Context, signInParams *SignInParams) (*SignInResult, error) {
+func (s *SignInUC) execute(ctx context.Context, signInParams *SignInParams) (*SignInResult, error) {
 	// 1. 驗證輸入參數格式
 	if err := s.validateParams(signInParams); err != nil {
 		return nil, err
 	}
 
-	// 2. 根據電子郵件地址查詢使用者
-	user, err := s.userRepository.GetByEmail(ctx, signInParams.Email)
+	// 2. 檢查 email 與 IP 是否因登入失敗次數過多而被鎖定
+	now := time.Now().UTC()
+	if err := s.checkLockout(ctx, signInParams, now); err != nil {
+		return nil, err
+	}
+
+	// 3. 根據電子郵件地址查詢使用者並驗證密碼
+	user, err := s.authenticate(ctx, signInParams)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidCredentials) {
+			if recordErr := s.recordFailure(ctx, signInParams, now); recordErr != nil {
+				return nil, recordErr
+			}
+		}
+		return nil, err
+	}
+
+	// 4. 登入成功，清除失敗紀錄
+	if err := s.resetFailures(ctx, signInParams); err != nil {
+		return nil, err
+	}
+
+	// 若密碼仍為明文或使用過時的雜湊參數，重新雜湊後更新（失敗時不影響登入）
+	if s.passwordHasher.NeedsRehash(user.Password) {
+		if err := s.rehashPassword(ctx, user, signInParams.Password); err != nil {
+			logger.FromContext(ctx).Warn("failed to rehash password", "user_id", user.ID, "error", err)
+		}
+	}
+
+	// 5. 產生該 User 的 access_token 與 refresh_token
+	tokens, err := s.tokenIssuer.issue(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 6. 返回 access_token 與 refresh_token
+	return &SignInResult{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+	}, nil
+}
+
+// authenticate 根據電子郵件地址查詢使用者並驗證密碼
+// 使用者不存在或密碼錯誤時，一律返回 ErrInvalidCredentials（不透露具體原因）
+func (s *SignInUC) authenticate(ctx context.Context, params *SignInParams) (*domain.User, error) {
+	user, err := s.userRepository.GetByEmail(ctx, params.Email)
 	if err != nil {
-		// 使用者不存在時，返回 ErrInvalidCredentials（不透露具體原因）
 		if errors.Is(err, sql.ErrNoRows) {
+			// 使用者不存在時仍驗證一次密碼，避免以回應時間判斷帳號是否存在
+			if err := s.verifyDummyPassword(ctx, params.Password); err != nil {
+				return nil, err
+			}
 			return nil, domain.ErrInvalidCredentials
 		}
 		return nil, err
 	}
 
-	// 3. 驗證密碼是否正確（暫時以明文方式比對）
-	if user.Password != signInParams.Password {
-		// 密碼錯誤時，返回 ErrInvalidCredentials（不透露具體原因）
+	matched, err := verifyPassword(ctx, s.passwordHasher, params.Password, user.Password)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to verify password")
+	}
+	if !matched {
 		return nil, domain.ErrInvalidCredentials
 	}
 
-	// 4. 產生該 User 的 access_token
-	UserID := fmt.Sprintf("%d", user.ID)
-	accessToken, err := auth.GenerateAccessToken(UserID)
+	return user, nil
+}
+
+// verifyDummyPassword 以固定的雜湊值驗證密碼，花費的時間與驗證真實使用者的密碼相同
+func (s *SignInUC) verifyDummyPassword(ctx context.Context, password string) error {
+	dummyHash, err := s.dummyPasswordHash()
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to generate access token")
+		return errors.Wrap(err, "failed to hash dummy password")
 	}
+	if _, err := verifyPassword(ctx, s.passwordHasher, password, dummyHash); err != nil {
+		return errors.Wrap(err, "failed to verify password")
+	}
+	return nil
+}
 
-	// 5. 返回 access_token
-	return &SignInResult{
-		AccessToken: accessToken,
-	}, nil
+// checkLockout 檢查 email 與 IP 是否在鎖定中，鎖定時返回 LoginLockedError
+func (s *SignInUC) checkLockout(ctx context.Context, params *SignInParams, now time.Time) error {
+	var retryAfter time.Duration
+	for _, key := range s.lockoutKeys(params) {
+		attempt, err := s.loginAttemptRepository.Get(ctx, key)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			return err
+		}
+		if wait := attempt.RetryAfter(now); wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+
+	if retryAfter > 0 {
+		return &domain.LoginLockedError{RetryAfter: retryAfter}
+	}
+	return nil
 }
 
-// validateParams 驗證輸入參數
-func (s *SignInUC) validateParams(params *SignInParams) error {
-	// 驗證 email
-	if len(params.Email) < 1 || len(params.Email) > 255 {
-		return errors.Wrap(domain.ErrInvalidParams, "email is invalid")
+// recordFailure 累加 email 與 IP 的失敗次數，因此開始鎖定時記錄鎖定次數
+func (s *SignInUC) recordFailure(ctx context.Context, params *SignInParams, now time.Time) error {
+	attempt, err := s.loginAttemptRepository.RecordFailure(ctx, emailLockoutKey(params.Email), s.emailLockoutPolicy, now)
+	if err != nil {
+		return errors.Wrap(err, "failed to record login failure")
 	}
-	emailRegex := regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
-	if !emailRegex.MatchString(params.Email) {
-		return errors.Wrap(domain.ErrInvalidParams, "email is invalid")
+	if attempt.RetryAfter(now) > 0 {
+		s.recorder.RecordLockout(domain.LockoutScopeEmail)
 	}
+	if params.IP != "" {
+		attempt, err := s.loginAttemptRepository.RecordFailure(ctx, ipLockoutKey(params.IP), s.ipLockoutPolicy, now)
+		if err != nil {
+			return errors.Wrap(err, "failed to record login failure")
+		}
+		if attempt.RetryAfter(now) > 0 {
+			s.recorder.RecordLockout(domain.LockoutScopeIP)
+		}
+	}
+	return nil
+}
 
-	// 驗證 password：最少 8 字元
-	if len(params.Password) < 8 {
-		return errors.Wrap(domain.ErrInvalidParams, "password is invalid")
+// signInOutcome 將登入的錯誤轉換為 SignInRecorder 的結果
+func signInOutcome(err error) string {
+	switch {
+	case err == nil:
+		return domain.SignInOutcomeSuccess
+	case errors.Is(err, domain.ErrInvalidCredentials):
+		return domain.SignInOutcomeInvalidCredentials
+	case errors.Is(err, domain.ErrTooManyLoginAttempts):
+		return domain.SignInOutcomeLocked
+	case errors.Is(err, domain.ErrInvalidParams):
+		return domain.SignInOutcomeInvalidParams
+	default:
+		return domain.SignInOutcomeError
 	}
+}
 
+// resetFailures 清除 email 與 IP 的失敗紀錄
+func (s *SignInUC) resetFailures(ctx context.Context, params *SignInParams) error {
+	for _, key := range s.lockoutKeys(params) {
+		if err := s.loginAttemptRepository.Reset(ctx, key); err != nil {
+			return errors.Wrap(err, "failed to reset login failures")
+		}
+	}
 	return nil
 }
+
+// lockoutKeys 取得計算失敗次數的 key
+func (s *SignInUC) lockoutKeys(params *SignInParams) []string {
+	keys := []string{emailLockoutKey(params.Email)}
+	if params.IP != "" {
+		keys = append(keys, ipLockoutKey(params.IP))
+	}
+	return keys
+}
+
+func emailLockoutKey(email string) string {
+	return "email:" + strings.ToLower(email)
+}
+
+func ipLockoutKey(ip string) string {
+	return "ip:" + ip
+}
+
+// rehashPassword 以目前的雜湊設定重新雜湊使用者的密碼
+func (s *SignInUC) rehashPassword(ctx context.Context, user *domain.User, password string) error {
+	hashedPassword, err := hashPassword(ctx, s.passwordHasher, password)
+	if err != nil {
+		return err
+	}
+
+	updated := *user
+	updated.Password = hashedPassword
+	updated.UpdatedAt = time.Now().UTC()
+	return s.userRepository.Update(ctx, &updated)
+}
+
+// validateParams 驗證輸入參數，返回所有不合法的欄位
+func (s *SignInUC) validateParams(params *SignInParams) error {
+	var v validation.Errors
+
+	// 驗證 email
+	validateEmail(&v, "email", params.Email)
+
+	// 驗證 password：最少 8 字元
+	if len(params.Password) < passwordMinLength {
+		v.Add("password", validation.RuleTooShort, validation.Params{"min": passwordMinLength})
+	}
+
+	return v.Err(domain.ErrInvalidParams)
+}
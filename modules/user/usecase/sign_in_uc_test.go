@@ -2,13 +2,22 @@ package usecase
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 	"portal_link/modules/user/domain"
 	"portal_link/modules/user/repository"
+	"portal_link/pkg/auth"
+	"portal_link/pkg/metrics"
+	"portal_link/pkg/password"
+	"portal_link/pkg/tracing/tracingtest"
+	"portal_link/pkg/validation"
 	"strings"
 	"testing"
 
 	"github.com/cockroachdb/errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
 )
 
 func TestSignInUC_Execute(t *testing.T) {
@@ -16,13 +25,14 @@ func TestSignInUC_Execute(t *testing.T) {
 	ctx := context.Background()
 
 	tests := []struct {
-		name           string
-		params         *SignInParams
-		setupData      func(t *testing.T) // 準備測試數據
-		wantErr        bool
-		expectedErr    error
-		expectedErrMsg string
-		checkResult    func(t *testing.T, result *SignInResult)
+		name              string
+		params            *SignInParams
+		setupData         func(t *testing.T) // 準備測試數據
+		wantErr           bool
+		expectedErr       error
+		expectedErrMsg    string
+		expectedFieldErrs []validation.FieldError
+		checkResult       func(t *testing.T, result *SignInResult)
 	}{
 		{
 			name: "成功登入",
@@ -45,6 +55,70 @@ func TestSignInUC_Execute(t *testing.T) {
 			checkResult: func(t *testing.T, result *SignInResult) {
 				assert.NotNil(t, result)
 				assert.NotEmpty(t, result.AccessToken)
+				assert.NotEmpty(t, result.RefreshToken)
+				assert.Positive(t, result.ExpiresIn)
+			},
+		},
+		{
+			name: "明文密碼登入後自動重新雜湊",
+			params: &SignInParams{
+				Email:    "legacy@example.com",
+				Password: "password123",
+			},
+			setupData: func(t *testing.T) {
+				// 建立尚未遷移、以明文保存密碼的使用者
+				existingUser, err := domain.NewUser(domain.UserParams{
+					Name:     "Legacy User",
+					Email:    "legacy@example.com",
+					Password: "password123",
+				})
+				assert.NoError(t, err)
+				err = repo.Create(ctx, existingUser)
+				assert.NoError(t, err)
+			},
+			wantErr: false,
+			checkResult: func(t *testing.T, result *SignInResult) {
+				assert.NotEmpty(t, result.AccessToken)
+
+				user, err := repo.GetByEmail(ctx, "legacy@example.com")
+				assert.NoError(t, err)
+				assert.True(t, strings.HasPrefix(user.Password, "$argon2id$"))
+				assert.False(t, newTestPasswordHasher(t).NeedsRehash(user.Password))
+
+				// 重新雜湊後仍可使用相同密碼登入
+				_, err = NewSignInUC(repo, repository.NewInMemoryRefreshTokenRepository(), repository.NewInMemoryLoginAttemptRepository(), newTestPasswordHasher(t), newTestTokenManager(t), nil).Execute(ctx, &SignInParams{
+					Email:    "legacy@example.com",
+					Password: "password123",
+				})
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "過時的雜湊參數登入後自動重新雜湊",
+			params: &SignInParams{
+				Email:    "outdated@example.com",
+				Password: "password123",
+			},
+			setupData: func(t *testing.T) {
+				oldHasher, err := password.NewHasher(password.Config{Algorithm: password.AlgorithmBcrypt, BcryptCost: 4})
+				assert.NoError(t, err)
+				hashedPassword, err := oldHasher.Hash("password123")
+				assert.NoError(t, err)
+
+				existingUser, err := domain.NewUser(domain.UserParams{
+					Name:     "Outdated User",
+					Email:    "outdated@example.com",
+					Password: hashedPassword,
+				})
+				assert.NoError(t, err)
+				err = repo.Create(ctx, existingUser)
+				assert.NoError(t, err)
+			},
+			wantErr: false,
+			checkResult: func(t *testing.T, result *SignInResult) {
+				user, err := repo.GetByEmail(ctx, "outdated@example.com")
+				assert.NoError(t, err)
+				assert.True(t, strings.HasPrefix(user.Password, "$argon2id$"))
 			},
 		},
 		{
@@ -53,8 +127,8 @@ func TestSignInUC_Execute(t *testing.T) {
 				Email:    "",
 				Password: "password123",
 			},
-			wantErr:        true,
-			expectedErrMsg: "email is invalid",
+			wantErr:           true,
+			expectedFieldErrs: []validation.FieldError{{Field: "email", Rule: validation.RuleRequired}},
 		},
 		{
 			name: "Email 格式錯誤（缺少 @）",
@@ -62,8 +136,8 @@ func TestSignInUC_Execute(t *testing.T) {
 				Email:    "johnexample.com",
 				Password: "password123",
 			},
-			wantErr:        true,
-			expectedErrMsg: "email is invalid",
+			wantErr:           true,
+			expectedFieldErrs: []validation.FieldError{{Field: "email", Rule: validation.RuleInvalidFormat}},
 		},
 		{
 			name: "Email 格式錯誤（缺少域名）",
@@ -71,8 +145,8 @@ func TestSignInUC_Execute(t *testing.T) {
 				Email:    "john@",
 				Password: "password123",
 			},
-			wantErr:        true,
-			expectedErrMsg: "email is invalid",
+			wantErr:           true,
+			expectedFieldErrs: []validation.FieldError{{Field: "email", Rule: validation.RuleInvalidFormat}},
 		},
 		{
 			name: "Email 太長（超過 255 字元）",
@@ -80,8 +154,8 @@ func TestSignInUC_Execute(t *testing.T) {
 				Email:    strings.Repeat("a", 247) + "@test.com", // 247 + 9 = 256 字元
 				Password: "password123",
 			},
-			wantErr:        true,
-			expectedErrMsg: "email is invalid",
+			wantErr:           true,
+			expectedFieldErrs: []validation.FieldError{{Field: "email", Rule: validation.RuleTooLong, Params: validation.Params{"max": 255}}},
 		},
 		{
 			name: "Password 太短（少於 8 字元）",
@@ -89,8 +163,8 @@ func TestSignInUC_Execute(t *testing.T) {
 				Email:    "test@example.com",
 				Password: "pass123",
 			},
-			wantErr:        true,
-			expectedErrMsg: "password is invalid",
+			wantErr:           true,
+			expectedFieldErrs: []validation.FieldError{{Field: "password", Rule: validation.RuleTooShort, Params: validation.Params{"min": 8}}},
 		},
 		{
 			name: "使用者不存在",
@@ -201,7 +275,7 @@ func TestSignInUC_Execute(t *testing.T) {
 				tt.setupData(t)
 			}
 
-			uc := NewSignInUC(repo)
+			uc := NewSignInUC(repo, repository.NewInMemoryRefreshTokenRepository(), repository.NewInMemoryLoginAttemptRepository(), newTestPasswordHasher(t), newTestTokenManager(t), nil)
 			result, err := uc.Execute(ctx, tt.params)
 
 			if tt.wantErr {
@@ -212,6 +286,10 @@ func TestSignInUC_Execute(t *testing.T) {
 				if tt.expectedErrMsg != "" {
 					assert.Contains(t, err.Error(), tt.expectedErrMsg)
 				}
+				if tt.expectedFieldErrs != nil {
+					assert.ErrorIs(t, err, domain.ErrInvalidParams)
+					assert.Equal(t, tt.expectedFieldErrs, validation.FieldErrors(err))
+				}
 			} else {
 				assert.NoError(t, err)
 				if tt.checkResult != nil {
@@ -221,3 +299,261 @@ func TestSignInUC_Execute(t *testing.T) {
 		})
 	}
 }
+
+func TestSignInUC_Execute_Lockout(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) (*SignInUC, *repository.InMemoryLoginAttemptRepository) {
+		repo := repository.NewInMemoryUserRepository()
+		existingUser, err := domain.NewUser(domain.UserParams{
+			Name:     "John Doe",
+			Email:    "john@example.com",
+			Password: "password123",
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, repo.Create(ctx, existingUser))
+
+		loginAttemptRepo := repository.NewInMemoryLoginAttemptRepository()
+		uc := NewSignInUC(repo, repository.NewInMemoryRefreshTokenRepository(), loginAttemptRepo, newTestPasswordHasher(t), newTestTokenManager(t), nil)
+		return uc, loginAttemptRepo
+	}
+
+	failN := func(t *testing.T, uc *SignInUC, n int, ip string) {
+		for i := 0; i < n; i++ {
+			_, err := uc.Execute(ctx, &SignInParams{Email: "john@example.com", Password: "wrongpassword", IP: ip})
+			assert.True(t, errors.Is(err, domain.ErrInvalidCredentials))
+		}
+	}
+
+	t.Run("連續失敗達上限後鎖定，正確密碼也無法登入", func(t *testing.T) {
+		uc, _ := setup(t)
+		failN(t, uc, domain.DefaultEmailLockoutPolicy.MaxFailures, "10.0.0.1")
+
+		_, err := uc.Execute(ctx, &SignInParams{Email: "john@example.com", Password: "password123", IP: "10.0.0.2"})
+		assert.True(t, errors.Is(err, domain.ErrTooManyLoginAttempts))
+
+		var lockedErr *domain.LoginLockedError
+		assert.True(t, errors.As(err, &lockedErr))
+		assert.Positive(t, lockedErr.RetryAfter)
+		assert.LessOrEqual(t, lockedErr.RetryAfter, domain.DefaultEmailLockoutPolicy.BaseLockout)
+	})
+
+	t.Run("email 大小寫不同視為同一帳號", func(t *testing.T) {
+		uc, _ := setup(t)
+		failN(t, uc, domain.DefaultEmailLockoutPolicy.MaxFailures, "")
+
+		_, err := uc.Execute(ctx, &SignInParams{Email: "JOHN@example.com", Password: "password123"})
+		assert.True(t, errors.Is(err, domain.ErrTooManyLoginAttempts))
+	})
+
+	t.Run("不存在的使用者也累計失敗次數", func(t *testing.T) {
+		uc, loginAttemptRepo := setup(t)
+		_, err := uc.Execute(ctx, &SignInParams{Email: "nobody@example.com", Password: "password123", IP: "10.0.0.1"})
+		assert.True(t, errors.Is(err, domain.ErrInvalidCredentials))
+
+		attempt, err := loginAttemptRepo.Get(ctx, "email:nobody@example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, attempt.Failures)
+		attempt, err = loginAttemptRepo.Get(ctx, "ip:10.0.0.1")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, attempt.Failures)
+	})
+
+	t.Run("同一 IP 嘗試多個帳號達上限後鎖定", func(t *testing.T) {
+		uc, _ := setup(t)
+		for i := 0; i < domain.DefaultIPLockoutPolicy.MaxFailures; i++ {
+			_, err := uc.Execute(ctx, &SignInParams{Email: fmt.Sprintf("user%d@example.com", i), Password: "password123", IP: "10.0.0.9"})
+			assert.True(t, errors.Is(err, domain.ErrInvalidCredentials))
+		}
+
+		_, err := uc.Execute(ctx, &SignInParams{Email: "john@example.com", Password: "password123", IP: "10.0.0.9"})
+		assert.True(t, errors.Is(err, domain.ErrTooManyLoginAttempts))
+
+		_, err = uc.Execute(ctx, &SignInParams{Email: "john@example.com", Password: "password123", IP: "10.0.0.10"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("登入成功後清除失敗紀錄", func(t *testing.T) {
+		uc, loginAttemptRepo := setup(t)
+		failN(t, uc, domain.DefaultEmailLockoutPolicy.MaxFailures-1, "10.0.0.1")
+
+		_, err := uc.Execute(ctx, &SignInParams{Email: "john@example.com", Password: "password123", IP: "10.0.0.1"})
+		assert.NoError(t, err)
+
+		_, err = loginAttemptRepo.Get(ctx, "email:john@example.com")
+		assert.True(t, errors.Is(err, sql.ErrNoRows))
+		_, err = loginAttemptRepo.Get(ctx, "ip:10.0.0.1")
+		assert.True(t, errors.Is(err, sql.ErrNoRows))
+	})
+}
+
+// fakeSignInRecorder 記錄 SignInRecorder 收到的結果與鎖定
+type fakeSignInRecorder struct {
+	outcomes []string
+	lockouts []string
+}
+
+func (r *fakeSignInRecorder) RecordSignIn(outcome string) {
+	r.outcomes = append(r.outcomes, outcome)
+}
+
+func (r *fakeSignInRecorder) RecordLockout(scope string) {
+	r.lockouts = append(r.lockouts, scope)
+}
+
+func TestSignInUC_Execute_Recorder(t *testing.T) {
+	ctx := context.Background()
+
+	repo := repository.NewInMemoryUserRepository()
+	existingUser, err := domain.NewUser(domain.UserParams{
+		Name:     "John Doe",
+		Email:    "john@example.com",
+		Password: "password123",
+	})
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(ctx, existingUser))
+
+	recorder := &fakeSignInRecorder{}
+	uc := NewSignInUC(repo, repository.NewInMemoryRefreshTokenRepository(), repository.NewInMemoryLoginAttemptRepository(), newTestPasswordHasher(t), newTestTokenManager(t), recorder)
+
+	_, err = uc.Execute(ctx, &SignInParams{Email: "john@example.com", Password: "password123"})
+	require.NoError(t, err)
+	_, err = uc.Execute(ctx, &SignInParams{Email: "invalid", Password: "password123"})
+	require.Error(t, err)
+	for i := 0; i < domain.DefaultEmailLockoutPolicy.MaxFailures; i++ {
+		_, err = uc.Execute(ctx, &SignInParams{Email: "john@example.com", Password: "wrongpassword", IP: "10.0.0.1"})
+		require.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	}
+	_, err = uc.Execute(ctx, &SignInParams{Email: "john@example.com", Password: "password123", IP: "10.0.0.1"})
+	require.ErrorIs(t, err, domain.ErrTooManyLoginAttempts)
+
+	expected := []string{domain.SignInOutcomeSuccess, domain.SignInOutcomeInvalidParams}
+	for i := 0; i < domain.DefaultEmailLockoutPolicy.MaxFailures; i++ {
+		expected = append(expected, domain.SignInOutcomeInvalidCredentials)
+	}
+	expected = append(expected, domain.SignInOutcomeLocked)
+	assert.Equal(t, expected, recorder.outcomes)
+
+	// 只在達到上限、開始鎖定的那次失敗記錄一次；IP 尚未達到上限
+	assert.Equal(t, []string{domain.LockoutScopeEmail}, recorder.lockouts)
+}
+
+func TestSignInUC_Execute_Tracing(t *testing.T) {
+	ctx := context.Background()
+	exporter := tracingtest.NewExporter(t)
+
+	repo := repository.NewInstrumentedUserRepository(repository.NewInMemoryUserRepository(), metrics.New())
+	existingUser, err := domain.NewUser(domain.UserParams{
+		Name:     "John Doe",
+		Email:    "john@example.com",
+		Password: "password123",
+	})
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(ctx, existingUser))
+	exporter.Reset()
+
+	uc := NewSignInUC(repo, repository.NewInMemoryRefreshTokenRepository(), repository.NewInMemoryLoginAttemptRepository(), newTestPasswordHasher(t), newTestTokenManager(t), nil)
+	_, err = uc.Execute(ctx, &SignInParams{Email: "john@example.com", Password: "password123"})
+	require.NoError(t, err)
+
+	// 查詢使用者、比對密碼與簽發 token 都是 SignInUC.Execute 的子 span
+	spans := exporter.GetSpans()
+	root := spans[len(spans)-1]
+	assert.Equal(t, "SignInUC.Execute", root.Name)
+	var children []string
+	for _, span := range spans[:len(spans)-1] {
+		if span.Parent.SpanID() == root.SpanContext.SpanID() {
+			children = append(children, span.Name)
+		}
+	}
+	assert.Subset(t, children, []string{"UserRepository.GetByEmail", "PasswordHasher.Verify", "tokenIssuer.issue"})
+
+	t.Run("records the error of a failed sign-in", func(t *testing.T) {
+		exporter.Reset()
+		_, err := uc.Execute(ctx, &SignInParams{Email: "john@example.com", Password: "wrongpassword"})
+		require.ErrorIs(t, err, domain.ErrInvalidCredentials)
+
+		spans := exporter.GetSpans()
+		root := spans[len(spans)-1]
+		assert.Equal(t, "SignInUC.Execute", root.Name)
+		assert.Equal(t, codes.Error, root.Status.Code)
+	})
+}
+
+// countingPasswordHasher 記錄 Verify 比對過的雜湊值
+type countingPasswordHasher struct {
+	domain.PasswordHasher
+	verified []string
+}
+
+func (h *countingPasswordHasher) Verify(password string, encodedHash string) (bool, error) {
+	h.verified = append(h.verified, encodedHash)
+	return h.PasswordHasher.Verify(password, encodedHash)
+}
+
+func TestSignInUC_Execute_VerifiesPasswordForUnknownEmail(t *testing.T) {
+	ctx := context.Background()
+	hasher := &countingPasswordHasher{PasswordHasher: newTestPasswordHasher(t)}
+
+	repo := repository.NewInMemoryUserRepository()
+	hashedPassword, err := hasher.Hash("password123")
+	require.NoError(t, err)
+	existingUser, err := domain.NewUser(domain.UserParams{
+		Name:     "John Doe",
+		Email:    "john@example.com",
+		Password: hashedPassword,
+	})
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(ctx, existingUser))
+
+	uc := NewSignInUC(repo, repository.NewInMemoryRefreshTokenRepository(), repository.NewInMemoryLoginAttemptRepository(), hasher, newTestTokenManager(t), nil)
+
+	// 密碼錯誤與使用者不存在時都會以目前的雜湊參數驗證一次密碼，回應時間不會透露帳號是否存在
+	_, err = uc.Execute(ctx, &SignInParams{Email: "john@example.com", Password: "wrongpassword1"})
+	require.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	require.Len(t, hasher.verified, 1)
+	assert.Equal(t, hashedPassword, hasher.verified[0])
+
+	for i := 0; i < 2; i++ {
+		_, err = uc.Execute(ctx, &SignInParams{Email: "nobody@example.com", Password: "password123"})
+		require.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	}
+	require.Len(t, hasher.verified, 3)
+	assert.NotEqual(t, hashedPassword, hasher.verified[1])
+	assert.Equal(t, hasher.verified[1], hasher.verified[2], "固定的雜湊值只產生一次")
+	assert.False(t, hasher.NeedsRehash(hasher.verified[1]))
+}
+
+// newTestTokenManager 建立測試用的 TokenManager
+func newTestTokenManager(t *testing.T) *auth.TokenManager {
+	t.Helper()
+
+	key, err := auth.NewHMACKey("test", []byte(strings.Repeat("s", 32)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyring, err := auth.NewKeyring(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return auth.NewTokenManager(keyring, auth.TokenManagerConfig{})
+}
+
+// newTestPasswordHasher 建立測試用的 PasswordHasher，使用較低的參數以加快測試
+func newTestPasswordHasher(t *testing.T) domain.PasswordHasher {
+	t.Helper()
+
+	hasher, err := password.NewHasher(password.Config{
+		Argon2id: password.Argon2idParams{
+			Memory:      1024,
+			Iterations:  1,
+			Parallelism: 1,
+			SaltLength:  16,
+			KeyLength:   32,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hasher
+}
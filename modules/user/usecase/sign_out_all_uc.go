@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"context"
+	"portal_link/modules/user/domain"
+	"portal_link/pkg/auth"
+	"portal_link/pkg/tracing"
+	"portal_link/pkg/validation"
+	"strconv"
+
+	"github.com/cockroachdb/errors"
+)
+
+// SignOutAllParams 登出所有裝置用例的輸入參數
+type SignOutAllParams struct {
+	UserID int
+}
+
+// SignOutAllUC 登出所有裝置用例
+type SignOutAllUC struct {
+	refreshTokenRepository        domain.RefreshTokenRepository
+	personalAccessTokenRepository domain.PersonalAccessTokenRepository
+	tokenManager                  *auth.TokenManager
+}
+
+func NewSignOutAllUC(refreshTokenRepository domain.RefreshTokenRepository, personalAccessTokenRepository domain.PersonalAccessTokenRepository, tokenManager *auth.TokenManager) *SignOutAllUC {
+	return &SignOutAllUC{
+		refreshTokenRepository:        refreshTokenRepository,
+		personalAccessTokenRepository: personalAccessTokenRepository,
+		tokenManager:                  tokenManager,
+	}
+}
+
+func (s *SignOutAllUC) Execute(ctx context.Context, params *SignOutAllParams) (err error) {
+	ctx, span := tracing.Start(ctx, "SignOutAllUC.Execute")
+	defer func() { tracing.End(span, err) }()
+
+	// 1. 驗證輸入參數
+	if params.UserID <= 0 {
+		return validation.NewError(domain.ErrInvalidParams, "user_id", validation.RuleOutOfRange, validation.Params{"min": 1})
+	}
+
+	// 2. 遞增 token 版本，使所有已簽發的 access token 失效
+	if err := s.tokenManager.RevokeAllAccessTokens(ctx, strconv.Itoa(params.UserID)); err != nil {
+		return errors.Wrap(err, "failed to revoke access tokens")
+	}
+
+	// 3. 撤銷所有 refresh token，避免以 refresh token 換發新的 access token
+	if err := s.refreshTokenRepository.RevokeByUserID(ctx, params.UserID); err != nil {
+		return errors.Wrap(err, "failed to revoke refresh tokens")
+	}
+
+	// 4. 撤銷所有 personal access token
+	if err := s.personalAccessTokenRepository.RevokeByUserID(ctx, params.UserID); err != nil {
+		return errors.Wrap(err, "failed to revoke personal access tokens")
+	}
+
+	return nil
+}
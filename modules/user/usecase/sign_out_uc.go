@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"portal_link/modules/user/domain"
+	"portal_link/pkg/auth"
+	"portal_link/pkg/tracing"
+	"portal_link/pkg/validation"
+
+	"github.com/cockroachdb/errors"
+)
+
+// SignOutParams 登出用例的輸入參數
+type SignOutParams struct {
+	// UserID 目前登入的使用者 ID
+	UserID int `json:"-"`
+	// AccessToken 目前請求使用的 access token
+	AccessToken *auth.AccessTokenClaims `json:"-"`
+	// RefreshToken 選填，一併撤銷此 refresh token 所屬的 family
+	RefreshToken string `json:"refresh_token"`
+}
+
+// SignOutUC 登出用例（僅登出目前裝置）
+type SignOutUC struct {
+	refreshTokenRepository domain.RefreshTokenRepository
+	tokenManager           *auth.TokenManager
+}
+
+func NewSignOutUC(refreshTokenRepository domain.RefreshTokenRepository, tokenManager *auth.TokenManager) *SignOutUC {
+	return &SignOutUC{
+		refreshTokenRepository: refreshTokenRepository,
+		tokenManager:           tokenManager,
+	}
+}
+
+func (s *SignOutUC) Execute(ctx context.Context, params *SignOutParams) (err error) {
+	ctx, span := tracing.Start(ctx, "SignOutUC.Execute")
+	defer func() { tracing.End(span, err) }()
+
+	// 1. 驗證輸入參數
+	if params.AccessToken == nil {
+		return validation.NewError(domain.ErrInvalidParams, "access_token", validation.RuleRequired, nil)
+	}
+
+	// 2. 撤銷目前的 access token
+	if err := s.tokenManager.RevokeAccessToken(ctx, params.AccessToken); err != nil {
+		return errors.Wrap(err, "failed to revoke access token")
+	}
+
+	// 3. 未提供 refresh token 時，只撤銷 access token
+	if params.RefreshToken == "" {
+		return nil
+	}
+
+	// 4. 撤銷 refresh token 所屬的 family
+	// refresh token 不存在或不屬於目前使用者時直接忽略，讓登出保持冪等
+	refreshToken, err := s.refreshTokenRepository.GetByTokenHash(ctx, auth.HashRefreshToken(params.RefreshToken))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	if refreshToken.UserID != params.UserID {
+		return nil
+	}
+
+	return s.refreshTokenRepository.RevokeFamily(ctx, refreshToken.FamilyID)
+}
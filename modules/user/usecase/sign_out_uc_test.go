@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"portal_link/modules/user/domain"
+	"portal_link/modules/user/repository"
+	"portal_link/pkg/auth"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignOutUC_Execute(t *testing.T) {
+	ctx := context.Background()
+	userRepo := repository.NewInMemoryUserRepository()
+	refreshTokenRepo := repository.NewInMemoryRefreshTokenRepository()
+	personalAccessTokenRepo := repository.NewInMemoryPersonalAccessTokenRepository()
+	tokenManager := newTestTokenManager(t)
+
+	user, err := domain.NewUser(domain.UserParams{
+		Name:     "John Doe",
+		Email:    "john@example.com",
+		Password: "password123",
+	})
+	require.NoError(t, err)
+	require.NoError(t, userRepo.Create(ctx, user))
+
+	signIn := func(t *testing.T) *SignInResult {
+		t.Helper()
+		result, err := NewSignInUC(userRepo, refreshTokenRepo, repository.NewInMemoryLoginAttemptRepository(), newTestPasswordHasher(t), tokenManager, nil).Execute(ctx, &SignInParams{
+			Email:    "john@example.com",
+			Password: "password123",
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	claimsOf := func(t *testing.T, accessToken string) *auth.AccessTokenClaims {
+		t.Helper()
+		claims := &auth.AccessTokenClaims{}
+		_, _, err := jwt.NewParser().ParseUnverified(accessToken, claims)
+		require.NoError(t, err)
+		return claims
+	}
+
+	refreshUC := NewRefreshTokenUC(userRepo, refreshTokenRepo, tokenManager)
+
+	t.Run("登出目前裝置", func(t *testing.T) {
+		current := signIn(t)
+		otherDevice := signIn(t)
+
+		err := NewSignOutUC(refreshTokenRepo, tokenManager).Execute(ctx, &SignOutParams{
+			UserID:       user.ID,
+			AccessToken:  claimsOf(t, current.AccessToken),
+			RefreshToken: current.RefreshToken,
+		})
+		require.NoError(t, err)
+
+		_, err = tokenManager.ValidateAccessToken(ctx, current.AccessToken, userRepo)
+		assert.ErrorIs(t, err, auth.ErrRevokedToken)
+		_, err = refreshUC.Execute(ctx, &RefreshTokenParams{RefreshToken: current.RefreshToken})
+		assert.ErrorIs(t, err, domain.ErrInvalidRefreshToken)
+
+		// 其他裝置不受影響
+		_, err = tokenManager.ValidateAccessToken(ctx, otherDevice.AccessToken, userRepo)
+		assert.NoError(t, err)
+		_, err = refreshUC.Execute(ctx, &RefreshTokenParams{RefreshToken: otherDevice.RefreshToken})
+		assert.NoError(t, err)
+	})
+
+	t.Run("不屬於目前使用者的 refresh token 不會被撤銷", func(t *testing.T) {
+		current := signIn(t)
+
+		err := NewSignOutUC(refreshTokenRepo, tokenManager).Execute(ctx, &SignOutParams{
+			UserID:       user.ID + 1,
+			AccessToken:  claimsOf(t, current.AccessToken),
+			RefreshToken: current.RefreshToken,
+		})
+		require.NoError(t, err)
+
+		_, err = refreshUC.Execute(ctx, &RefreshTokenParams{RefreshToken: current.RefreshToken})
+		assert.NoError(t, err)
+	})
+
+	t.Run("登出所有裝置", func(t *testing.T) {
+		first := signIn(t)
+		second := signIn(t)
+		require.NoError(t, personalAccessTokenRepo.Create(ctx, &domain.PersonalAccessToken{
+			UserID:    user.ID,
+			Name:      "ci",
+			TokenHash: "pat-hash",
+			Scopes:    []domain.Scope{domain.ScopePortalPagesRead},
+		}))
+
+		err := NewSignOutAllUC(refreshTokenRepo, personalAccessTokenRepo, tokenManager).Execute(ctx, &SignOutAllParams{UserID: user.ID})
+		require.NoError(t, err)
+
+		for _, session := range []*SignInResult{first, second} {
+			_, err = tokenManager.ValidateAccessToken(ctx, session.AccessToken, userRepo)
+			assert.ErrorIs(t, err, auth.ErrRevokedToken)
+			_, err = refreshUC.Execute(ctx, &RefreshTokenParams{RefreshToken: session.RefreshToken})
+			assert.ErrorIs(t, err, domain.ErrInvalidRefreshToken)
+		}
+		pat, err := personalAccessTokenRepo.GetByTokenHash(ctx, "pat-hash")
+		require.NoError(t, err)
+		assert.True(t, pat.IsRevoked())
+
+		// 重新登入後可正常使用
+		again := signIn(t)
+		_, err = tokenManager.ValidateAccessToken(ctx, again.AccessToken, userRepo)
+		assert.NoError(t, err)
+	})
+}
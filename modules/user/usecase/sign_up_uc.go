@@ -3,10 +3,12 @@ package usecase
 import (
 	"context"
 	"database/sql"
-	"fmt"
 	"portal_link/modules/user/domain"
 	"portal_link/pkg/auth"
-	"regexp"
+	"portal_link/pkg/logger"
+	"portal_link/pkg/tracing"
+	"portal_link/pkg/transaction"
+	"portal_link/pkg/validation"
 
 	"github.com/cockroachdb/errors"
 )
@@ -20,87 +22,116 @@ type SignUpParams struct {
 
 // SignUpResult 註冊用例的輸出結果
 type SignUpResult struct {
-	AccessToken string `json:"access_token"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
 }
 
 // SignUpUC 註冊用例
 type SignUpUC struct {
+	txManager      transaction.TxManager
 	userRepository domain.UserRepository
+	passwordHasher domain.PasswordHasher
+	tokenIssuer    *tokenIssuer
+	emailVerifier  *emailVerifier
 }
 
-func NewSignUpUC(userRepository domain.UserRepository) *SignUpUC {
-	return &SignUpUC{userRepository: userRepository}
+// NewSignUpUC 建立註冊用例，verifyURL 為驗證電子郵件的網址，token 會附加在 query string
+func NewSignUpUC(txManager transaction.TxManager, userRepository domain.UserRepository, refreshTokenRepository domain.RefreshTokenRepository, emailVerificationTokenRepository domain.EmailVerificationTokenRepository, passwordHasher domain.PasswordHasher, tokenManager *auth.TokenManager, mailer domain.Mailer, verifyURL string) *SignUpUC {
+	return &SignUpUC{
+		txManager:      txManager,
+		userRepository: userRepository,
+		passwordHasher: passwordHasher,
+		tokenIssuer: &tokenIssuer{
+			tokenManager:           tokenManager,
+			refreshTokenRepository: refreshTokenRepository,
+		},
+		emailVerifier: &emailVerifier{
+			emailVerificationTokenRepository: emailVerificationTokenRepository,
+			mailer:                           mailer,
+			verifyURL:                        verifyURL,
+		},
+	}
 }
 
-func (s *SignUpUC) Execute(ctx context.Context, signUpParams *SignUpParams) (*SignUpResult, error) {
+func (s *SignUpUC) Execute(ctx context.Context, signUpParams *SignUpParams) (_ *SignUpResult, err error) {
+	ctx, span := tracing.Start(ctx, "SignUpUC.Execute")
+	defer func() { tracing.End(span, err) }()
+
 	// 1. 驗證輸入參數格式
 	if err := s.validateParams(signUpParams); err != nil {
 		return nil, err
 	}
 
-	// 2. 檢查電子郵件地址是否已被註冊
-	existingUser, err := s.userRepository.GetByEmail(ctx, signUpParams.Email)
-	if err == nil && existingUser != nil {
-		return nil, domain.ErrEmailExists
-	}
-	// 如果 err 不是 sql.ErrNoRows，則返回錯誤
-	if err != nil && !errors.Is(err, sql.ErrNoRows) {
-		return nil, err
+	// 2. 雜湊密碼（耗時較長，在交易開始前完成）
+	hashedPassword, err := hashPassword(ctx, s.passwordHasher, signUpParams.Password)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to hash password")
 	}
 
-	// 3. 建立新的 User 實體
-	user, err := domain.NewUser(domain.UserParams{
-		Name:     signUpParams.Name,
-		Email:    signUpParams.Email,
-		Password: signUpParams.Password, // 暫時以明文存儲
+	// 3~4 在同一個交易中執行，避免檢查後到寫入前被其他請求以相同電子郵件註冊
+	var user *domain.User
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// 3. 檢查電子郵件地址是否已被註冊
+		existingUser, err := s.userRepository.GetByEmail(ctx, signUpParams.Email)
+		if err == nil && existingUser != nil {
+			return domain.ErrEmailExists
+		}
+		// 如果 err 不是 sql.ErrNoRows，則返回錯誤
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		// 4. 建立新的 User 實體並存入資料庫
+		user, err = domain.NewUser(domain.UserParams{
+			Name:     signUpParams.Name,
+			Email:    signUpParams.Email,
+			Password: hashedPassword,
+		})
+		if err != nil {
+			return err
+		}
+		return s.userRepository.Create(ctx, user)
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	// 4. 將使用者資訊存入資料庫
-	if err := s.userRepository.Create(ctx, user); err != nil {
-		return nil, err
+	// 5. 寄送電子郵件驗證信（失敗時不影響註冊，使用者可重新寄送）
+	if err := s.emailVerifier.send(ctx, user); err != nil {
+		logger.FromContext(ctx).Warn("failed to send verification email", "user_id", user.ID, "error", err)
 	}
 
-	// 5. 產生該 User 的 access_token
-	UserID := fmt.Sprintf("%d", user.ID)
-	accessToken, err := auth.GenerateAccessToken(UserID)
+	// 6. 產生該 User 的 access_token 與 refresh_token
+	tokens, err := s.tokenIssuer.issue(ctx, user.ID)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to generate access token")
+		return nil, err
 	}
 
-	// 6. 返回 access_token
+	// 7. 返回 access_token 與 refresh_token
 	return &SignUpResult{
-		AccessToken: accessToken,
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
 	}, nil
 }
 
-// validateParams 驗證輸入參數
+// validateParams 驗證輸入參數，返回所有不合法的欄位
 func (s *SignUpUC) validateParams(params *SignUpParams) error {
+	var v validation.Errors
+
 	// 驗證 name
-	if len(params.Name) < 1 || len(params.Name) > 255 {
-		return errors.Wrap(domain.ErrInvalidParams, "name is invalid")
+	if params.Name == "" {
+		v.Add("name", validation.RuleRequired, nil)
+	} else if len(params.Name) > nameMaxLength {
+		v.Add("name", validation.RuleTooLong, validation.Params{"max": nameMaxLength})
 	}
 
 	// 驗證 email
-	if len(params.Email) < 1 || len(params.Email) > 255 {
-		return errors.Wrap(domain.ErrInvalidParams, "email is invalid")
-	}
-	emailRegex := regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
-	if !emailRegex.MatchString(params.Email) {
-		return errors.Wrap(domain.ErrInvalidParams, "email is invalid")
-	}
+	validateEmail(&v, "email", params.Email)
 
 	// 驗證 password：最少 8 字元，需包含英文和數字
-	if len(params.Password) < 8 {
-		return errors.Wrap(domain.ErrInvalidParams, "password is invalid")
-	}
-	hasLetter := regexp.MustCompile(`[a-zA-Z]`).MatchString(params.Password)
-	hasNumber := regexp.MustCompile(`[0-9]`).MatchString(params.Password)
-	if !hasLetter || !hasNumber {
-		return errors.Wrap(domain.ErrInvalidParams, "password is invalid")
-	}
+	validateNewPassword(&v, "password", params.Password)
 
-	return nil
+	return v.Err(domain.ErrInvalidParams)
 }
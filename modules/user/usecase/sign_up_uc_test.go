@@ -2,12 +2,18 @@ package usecase
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"portal_link/modules/user/domain"
 	"portal_link/modules/user/repository"
+	"portal_link/pkg/mailer"
+	"portal_link/pkg/transaction"
+	"portal_link/pkg/validation"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSignUpUC_Execute(t *testing.T) {
@@ -15,12 +21,13 @@ func TestSignUpUC_Execute(t *testing.T) {
 	ctx := context.Background()
 
 	tests := []struct {
-		name           string
-		params         *SignUpParams
-		setupData      func(t *testing.T) // 準備測試數據
-		wantErr        bool
-		expectedErrMsg string
-		checkResult    func(t *testing.T, result *SignUpResult)
+		name              string
+		params            *SignUpParams
+		setupData         func(t *testing.T) // 準備測試數據
+		wantErr           bool
+		expectedErrMsg    string
+		expectedFieldErrs []validation.FieldError
+		checkResult       func(t *testing.T, result *SignUpResult)
 	}{
 		{
 			name: "成功註冊",
@@ -33,6 +40,7 @@ func TestSignUpUC_Execute(t *testing.T) {
 			checkResult: func(t *testing.T, result *SignUpResult) {
 				assert.NotNil(t, result)
 				assert.NotEmpty(t, result.AccessToken)
+				assert.NotEmpty(t, result.RefreshToken)
 
 				// 驗證用戶已被創建
 				user, err := repo.GetByEmail(ctx, "john@example.com")
@@ -41,6 +49,12 @@ func TestSignUpUC_Execute(t *testing.T) {
 				assert.Equal(t, "John Doe", user.Name)
 				assert.Equal(t, "john@example.com", user.Email)
 				assert.NotZero(t, user.ID)
+
+				// 驗證密碼以雜湊值保存
+				assert.NotEqual(t, "password123", user.Password)
+				matched, err := newTestPasswordHasher(t).Verify("password123", user.Password)
+				assert.NoError(t, err)
+				assert.True(t, matched)
 			},
 		},
 		{
@@ -50,8 +64,8 @@ func TestSignUpUC_Execute(t *testing.T) {
 				Email:    "test1@example.com",
 				Password: "password123",
 			},
-			wantErr:        true,
-			expectedErrMsg: "name is invalid",
+			wantErr:           true,
+			expectedFieldErrs: []validation.FieldError{{Field: "name", Rule: validation.RuleRequired}},
 		},
 		{
 			name: "Name 太長（超過 255 字元）",
@@ -60,8 +74,8 @@ func TestSignUpUC_Execute(t *testing.T) {
 				Email:    "test2@example.com",
 				Password: "password123",
 			},
-			wantErr:        true,
-			expectedErrMsg: "name is invalid",
+			wantErr:           true,
+			expectedFieldErrs: []validation.FieldError{{Field: "name", Rule: validation.RuleTooLong, Params: validation.Params{"max": 255}}},
 		},
 		{
 			name: "Email 為空",
@@ -70,8 +84,8 @@ func TestSignUpUC_Execute(t *testing.T) {
 				Email:    "",
 				Password: "password123",
 			},
-			wantErr:        true,
-			expectedErrMsg: "email is invalid",
+			wantErr:           true,
+			expectedFieldErrs: []validation.FieldError{{Field: "email", Rule: validation.RuleRequired}},
 		},
 		{
 			name: "Email 格式錯誤（缺少 @）",
@@ -80,8 +94,8 @@ func TestSignUpUC_Execute(t *testing.T) {
 				Email:    "johnexample.com",
 				Password: "password123",
 			},
-			wantErr:        true,
-			expectedErrMsg: "email is invalid",
+			wantErr:           true,
+			expectedFieldErrs: []validation.FieldError{{Field: "email", Rule: validation.RuleInvalidFormat}},
 		},
 		{
 			name: "Email 格式錯誤（缺少域名）",
@@ -90,8 +104,8 @@ func TestSignUpUC_Execute(t *testing.T) {
 				Email:    "john@",
 				Password: "password123",
 			},
-			wantErr:        true,
-			expectedErrMsg: "email is invalid",
+			wantErr:           true,
+			expectedFieldErrs: []validation.FieldError{{Field: "email", Rule: validation.RuleInvalidFormat}},
 		},
 		{
 			name: "Email 太長（超過 255 字元）",
@@ -100,8 +114,8 @@ func TestSignUpUC_Execute(t *testing.T) {
 				Email:    strings.Repeat("a", 247) + "@test.com", // 247 + 9 = 256 字元
 				Password: "password123",
 			},
-			wantErr:        true,
-			expectedErrMsg: "email is invalid",
+			wantErr:           true,
+			expectedFieldErrs: []validation.FieldError{{Field: "email", Rule: validation.RuleTooLong, Params: validation.Params{"max": 255}}},
 		},
 		{
 			name: "Password 太短（少於 8 字元）",
@@ -110,8 +124,8 @@ func TestSignUpUC_Execute(t *testing.T) {
 				Email:    "test3@example.com",
 				Password: "pass123",
 			},
-			wantErr:        true,
-			expectedErrMsg: "password is invalid",
+			wantErr:           true,
+			expectedFieldErrs: []validation.FieldError{{Field: "password", Rule: validation.RuleTooShort, Params: validation.Params{"min": 8}}},
 		},
 		{
 			name: "Password 沒有字母",
@@ -120,8 +134,8 @@ func TestSignUpUC_Execute(t *testing.T) {
 				Email:    "test4@example.com",
 				Password: "12345678",
 			},
-			wantErr:        true,
-			expectedErrMsg: "password is invalid",
+			wantErr:           true,
+			expectedFieldErrs: []validation.FieldError{{Field: "password", Rule: validation.RuleMissingLetter}},
 		},
 		{
 			name: "Password 沒有數字",
@@ -130,8 +144,23 @@ func TestSignUpUC_Execute(t *testing.T) {
 				Email:    "test5@example.com",
 				Password: "password",
 			},
-			wantErr:        true,
-			expectedErrMsg: "password is invalid",
+			wantErr:           true,
+			expectedFieldErrs: []validation.FieldError{{Field: "password", Rule: validation.RuleMissingDigit}},
+		},
+		{
+			name: "同時返回所有不合法的欄位",
+			params: &SignUpParams{
+				Name:     "",
+				Email:    "john@",
+				Password: "abc",
+			},
+			wantErr: true,
+			expectedFieldErrs: []validation.FieldError{
+				{Field: "name", Rule: validation.RuleRequired},
+				{Field: "email", Rule: validation.RuleInvalidFormat},
+				{Field: "password", Rule: validation.RuleTooShort, Params: validation.Params{"min": 8}},
+				{Field: "password", Rule: validation.RuleMissingDigit},
+			},
 		},
 		{
 			name: "Email 已存在",
@@ -201,7 +230,7 @@ func TestSignUpUC_Execute(t *testing.T) {
 				tt.setupData(t)
 			}
 
-			uc := NewSignUpUC(repo)
+			uc := NewSignUpUC(transaction.NewInMemoryTxManager(), repo, repository.NewInMemoryRefreshTokenRepository(), repository.NewInMemoryEmailVerificationTokenRepository(), newTestPasswordHasher(t), newTestTokenManager(t), mailer.NewInMemoryOutbox(), testVerifyEmailURL)
 			result, err := uc.Execute(ctx, tt.params)
 
 			if tt.wantErr {
@@ -209,6 +238,10 @@ func TestSignUpUC_Execute(t *testing.T) {
 				if tt.expectedErrMsg != "" {
 					assert.Contains(t, err.Error(), tt.expectedErrMsg)
 				}
+				if tt.expectedFieldErrs != nil {
+					assert.ErrorIs(t, err, domain.ErrInvalidParams)
+					assert.Equal(t, tt.expectedFieldErrs, validation.FieldErrors(err))
+				}
 			} else {
 				assert.NoError(t, err)
 				if tt.checkResult != nil {
@@ -218,3 +251,66 @@ func TestSignUpUC_Execute(t *testing.T) {
 		})
 	}
 }
+
+// failingCommitTxManager 執行 fn 後模擬提交失敗，讓交易回滾
+type failingCommitTxManager struct {
+	inner transaction.TxManager
+	err   error
+}
+
+func (m *failingCommitTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return m.inner.WithinTx(ctx, func(ctx context.Context) error {
+		if err := fn(ctx); err != nil {
+			return err
+		}
+		return m.err
+	})
+}
+
+func TestSignUpUC_Execute_Transaction(t *testing.T) {
+	ctx := context.Background()
+	params := &SignUpParams{Name: "John Doe", Email: "john@example.com", Password: "password123"}
+
+	t.Run("rolls back the user and sends no email when the transaction fails", func(t *testing.T) {
+		repo := repository.NewInMemoryUserRepository()
+		outbox := mailer.NewInMemoryOutbox()
+		errCommit := errors.New("commit failed")
+		txManager := &failingCommitTxManager{inner: transaction.NewInMemoryTxManager(), err: errCommit}
+		uc := NewSignUpUC(txManager, repo, repository.NewInMemoryRefreshTokenRepository(), repository.NewInMemoryEmailVerificationTokenRepository(), newTestPasswordHasher(t), newTestTokenManager(t), outbox, testVerifyEmailURL)
+
+		_, err := uc.Execute(ctx, params)
+		assert.ErrorIs(t, err, errCommit)
+
+		_, err = repo.GetByEmail(ctx, params.Email)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.Empty(t, outbox.Messages())
+	})
+
+	t.Run("concurrent sign ups with the same email create one user", func(t *testing.T) {
+		repo := repository.NewInMemoryUserRepository()
+		uc := NewSignUpUC(transaction.NewInMemoryTxManager(), repo, repository.NewInMemoryRefreshTokenRepository(), repository.NewInMemoryEmailVerificationTokenRepository(), newTestPasswordHasher(t), newTestTokenManager(t), mailer.NewInMemoryOutbox(), testVerifyEmailURL)
+
+		const workers = 5
+		errs := make(chan error, workers)
+		for i := 0; i < workers; i++ {
+			go func() {
+				_, err := uc.Execute(ctx, params)
+				errs <- err
+			}()
+		}
+
+		succeeded := 0
+		for i := 0; i < workers; i++ {
+			if err := <-errs; err == nil {
+				succeeded++
+			} else {
+				assert.ErrorIs(t, err, domain.ErrEmailExists)
+			}
+		}
+		assert.Equal(t, 1, succeeded)
+
+		user, err := repo.GetByEmail(ctx, params.Email)
+		require.NoError(t, err)
+		assert.Equal(t, "John Doe", user.Name)
+	})
+}
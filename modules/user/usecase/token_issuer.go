@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"portal_link/modules/user/domain"
+	"portal_link/pkg/auth"
+	"portal_link/pkg/tracing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// tokenPair 簽發給使用者的 access token 與 refresh token
+type tokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int
+}
+
+// tokenIssuer 負責簽發 access token 與 refresh token，供登入、註冊與刷新用例共用
+type tokenIssuer struct {
+	tokenManager           *auth.TokenManager
+	refreshTokenRepository domain.RefreshTokenRepository
+}
+
+// issue 為使用者簽發一組新的 token，並建立新的 refresh token family
+func (i *tokenIssuer) issue(ctx context.Context, userID int) (_ *tokenPair, err error) {
+	ctx, span := tracing.Start(ctx, "tokenIssuer.issue")
+	defer func() { tracing.End(span, err) }()
+
+	familyID, err := auth.GenerateTokenFamilyID()
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, plaintext, err := i.newRefreshToken(userID, familyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := i.refreshTokenRepository.Create(ctx, refreshToken); err != nil {
+		return nil, err
+	}
+
+	return i.pair(ctx, userID, plaintext)
+}
+
+// rotate 使用 current 換發一組新的 token，新的 refresh token 沿用 current 的 family
+func (i *tokenIssuer) rotate(ctx context.Context, current *domain.RefreshToken) (_ *tokenPair, err error) {
+	ctx, span := tracing.Start(ctx, "tokenIssuer.rotate")
+	defer func() { tracing.End(span, err) }()
+
+	next, plaintext, err := i.newRefreshToken(current.UserID, current.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := i.refreshTokenRepository.Rotate(ctx, current, next); err != nil {
+		return nil, err
+	}
+
+	return i.pair(ctx, current.UserID, plaintext)
+}
+
+// newRefreshToken 產生 refresh token 實體與其明文
+func (i *tokenIssuer) newRefreshToken(userID int, familyID string) (*domain.RefreshToken, string, error) {
+	plaintext, tokenHash, err := auth.GenerateRefreshToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	refreshToken, err := domain.NewRefreshToken(domain.RefreshTokenParams{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().UTC().Add(i.tokenManager.RefreshTokenTTL()),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return refreshToken, plaintext, nil
+}
+
+// pair 產生 access token 並與 refresh token 組合
+func (i *tokenIssuer) pair(ctx context.Context, userID int, refreshToken string) (*tokenPair, error) {
+	accessToken, err := i.tokenManager.GenerateAccessToken(ctx, fmt.Sprintf("%d", userID))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate access token")
+	}
+
+	return &tokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(i.tokenManager.AccessTokenTTL().Seconds()),
+	}, nil
+}
@@ -0,0 +1,46 @@
+package usecase
+
+import (
+	"regexp"
+
+	"portal_link/pkg/validation"
+)
+
+const (
+	nameMaxLength     = 255
+	emailMaxLength    = 255
+	passwordMinLength = 8
+
+	personalAccessTokenNameMaxLength = 100
+)
+
+var (
+	emailRegex  = regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
+	letterRegex = regexp.MustCompile(`[a-zA-Z]`)
+	digitRegex  = regexp.MustCompile(`[0-9]`)
+)
+
+// validateEmail 驗證電子郵件地址的長度與格式
+func validateEmail(v *validation.Errors, field string, email string) {
+	switch {
+	case email == "":
+		v.Add(field, validation.RuleRequired, nil)
+	case len(email) > emailMaxLength:
+		v.Add(field, validation.RuleTooLong, validation.Params{"max": emailMaxLength})
+	case !emailRegex.MatchString(email):
+		v.Add(field, validation.RuleInvalidFormat, nil)
+	}
+}
+
+// validateNewPassword 驗證新設定的密碼：最少 8 字元，需包含英文和數字
+func validateNewPassword(v *validation.Errors, field string, password string) {
+	if len(password) < passwordMinLength {
+		v.Add(field, validation.RuleTooShort, validation.Params{"min": passwordMinLength})
+	}
+	if !letterRegex.MatchString(password) {
+		v.Add(field, validation.RuleMissingLetter, nil)
+	}
+	if !digitRegex.MatchString(password) {
+		v.Add(field, validation.RuleMissingDigit, nil)
+	}
+}
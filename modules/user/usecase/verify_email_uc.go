@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"portal_link/modules/user/domain"
+	"portal_link/pkg/auth"
+	"portal_link/pkg/tracing"
+	"portal_link/pkg/validation"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// VerifyEmailParams 驗證電子郵件用例的輸入參數
+type VerifyEmailParams struct {
+	Token string `form:"token"`
+}
+
+// VerifyEmailUC 驗證電子郵件用例
+type VerifyEmailUC struct {
+	userRepository                   domain.UserRepository
+	emailVerificationTokenRepository domain.EmailVerificationTokenRepository
+}
+
+func NewVerifyEmailUC(userRepository domain.UserRepository, emailVerificationTokenRepository domain.EmailVerificationTokenRepository) *VerifyEmailUC {
+	return &VerifyEmailUC{
+		userRepository:                   userRepository,
+		emailVerificationTokenRepository: emailVerificationTokenRepository,
+	}
+}
+
+func (s *VerifyEmailUC) Execute(ctx context.Context, params *VerifyEmailParams) (err error) {
+	ctx, span := tracing.Start(ctx, "VerifyEmailUC.Execute")
+	defer func() { tracing.End(span, err) }()
+
+	// 1. 驗證輸入參數
+	if params.Token == "" {
+		return validation.NewError(domain.ErrInvalidParams, "token", validation.RuleRequired, nil)
+	}
+
+	// 2. 以 token 雜湊值查詢驗證 token
+	emailVerificationToken, err := s.emailVerificationTokenRepository.GetByTokenHash(ctx, auth.HashOpaqueToken(params.Token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrInvalidEmailVerificationToken
+		}
+		return err
+	}
+
+	// 3. 檢查 token 是否已使用或過期
+	now := time.Now().UTC()
+	if emailVerificationToken.IsUsed() {
+		return domain.ErrInvalidEmailVerificationToken
+	}
+	if emailVerificationToken.IsExpired(now) {
+		return domain.ErrEmailVerificationTokenExpired
+	}
+
+	// 4. 將 token 標記為已使用
+	if err := s.emailVerificationTokenRepository.MarkUsed(ctx, emailVerificationToken); err != nil {
+		return err
+	}
+
+	// 5. 將使用者的電子郵件標記為已驗證
+	user, err := s.userRepository.Find(ctx, emailVerificationToken.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrInvalidEmailVerificationToken
+		}
+		return err
+	}
+	if user.IsEmailVerified() {
+		return nil
+	}
+	updated := *user
+	updated.VerifyEmail(now)
+	if err := s.userRepository.Update(ctx, &updated); err != nil {
+		return errors.Wrap(err, "failed to verify email")
+	}
+
+	return nil
+}
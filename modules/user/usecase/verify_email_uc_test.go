@@ -0,0 +1,160 @@
+package usecase
+
+import (
+	"context"
+	"portal_link/modules/user/domain"
+	"portal_link/modules/user/repository"
+	"portal_link/pkg/auth"
+	"portal_link/pkg/mailer"
+	"portal_link/pkg/transaction"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testVerifyEmailURL = "https://api.example.com/api/v1/user/verify-email"
+
+func TestVerifyEmailUC_Execute(t *testing.T) {
+	ctx := context.Background()
+	userRepo := repository.NewInMemoryUserRepository()
+	emailVerificationTokenRepo := repository.NewInMemoryEmailVerificationTokenRepository()
+	outbox := mailer.NewInMemoryOutbox()
+
+	signUpUC := NewSignUpUC(transaction.NewInMemoryTxManager(), userRepo, repository.NewInMemoryRefreshTokenRepository(), emailVerificationTokenRepo, newTestPasswordHasher(t), newTestTokenManager(t), outbox, testVerifyEmailURL)
+	verifyUC := NewVerifyEmailUC(userRepo, emailVerificationTokenRepo)
+
+	signUp := func(t *testing.T, email string) (*domain.User, string) {
+		t.Helper()
+		_, err := signUpUC.Execute(ctx, &SignUpParams{Name: "John Doe", Email: email, Password: "password123"})
+		require.NoError(t, err)
+
+		user, err := userRepo.GetByEmail(ctx, email)
+		require.NoError(t, err)
+		messages := outbox.MessagesTo(email)
+		require.Len(t, messages, 1)
+		return user, tokenFromEmailBody(t, messages[0].Body)
+	}
+
+	t.Run("註冊後寄送驗證信，驗證後標記為已驗證", func(t *testing.T) {
+		user, token := signUp(t, "john@example.com")
+		assert.False(t, user.IsEmailVerified())
+
+		require.NoError(t, verifyUC.Execute(ctx, &VerifyEmailParams{Token: token}))
+
+		user, err := userRepo.Find(ctx, user.ID)
+		require.NoError(t, err)
+		assert.True(t, user.IsEmailVerified())
+
+		// token 只能使用一次
+		err = verifyUC.Execute(ctx, &VerifyEmailParams{Token: token})
+		assert.ErrorIs(t, err, domain.ErrInvalidEmailVerificationToken)
+	})
+
+	t.Run("token 不存在", func(t *testing.T) {
+		err := verifyUC.Execute(ctx, &VerifyEmailParams{Token: "unknown"})
+		assert.ErrorIs(t, err, domain.ErrInvalidEmailVerificationToken)
+
+		err = verifyUC.Execute(ctx, &VerifyEmailParams{})
+		assert.ErrorIs(t, err, domain.ErrInvalidParams)
+	})
+
+	t.Run("token 已過期", func(t *testing.T) {
+		user, _ := signUp(t, "expired@example.com")
+
+		token, tokenHash, err := auth.GenerateOpaqueToken()
+		require.NoError(t, err)
+		expired, err := domain.NewEmailVerificationToken(domain.EmailVerificationTokenParams{
+			UserID:    user.ID,
+			TokenHash: tokenHash,
+			CreatedAt: time.Now().UTC().Add(-2 * domain.EmailVerificationTokenExpiration),
+		})
+		require.NoError(t, err)
+		require.NoError(t, emailVerificationTokenRepo.Create(ctx, expired))
+
+		err = verifyUC.Execute(ctx, &VerifyEmailParams{Token: token})
+		assert.ErrorIs(t, err, domain.ErrEmailVerificationTokenExpired)
+	})
+}
+
+func TestResendVerificationEmailUC_Execute(t *testing.T) {
+	ctx := context.Background()
+	userRepo := repository.NewInMemoryUserRepository()
+	emailVerificationTokenRepo := repository.NewInMemoryEmailVerificationTokenRepository()
+	outbox := mailer.NewInMemoryOutbox()
+
+	resendUC := NewResendVerificationEmailUC(transaction.NewInMemoryTxManager(), userRepo, emailVerificationTokenRepo, outbox, testVerifyEmailURL)
+	verifyUC := NewVerifyEmailUC(userRepo, emailVerificationTokenRepo)
+
+	createUser := func(t *testing.T, email string) *domain.User {
+		t.Helper()
+		user, err := domain.NewUser(domain.UserParams{Name: "John Doe", Email: email, Password: "password123"})
+		require.NoError(t, err)
+		require.NoError(t, userRepo.Create(ctx, user))
+		return user
+	}
+
+	t.Run("重新寄送後舊的 token 失效", func(t *testing.T) {
+		user := createUser(t, "john@example.com")
+
+		// 模擬一分鐘前寄出的驗證信
+		oldToken, oldTokenHash, err := auth.GenerateOpaqueToken()
+		require.NoError(t, err)
+		old, err := domain.NewEmailVerificationToken(domain.EmailVerificationTokenParams{
+			UserID:    user.ID,
+			TokenHash: oldTokenHash,
+			CreatedAt: time.Now().UTC().Add(-domain.EmailVerificationResendInterval),
+		})
+		require.NoError(t, err)
+		require.NoError(t, emailVerificationTokenRepo.Create(ctx, old))
+
+		require.NoError(t, resendUC.Execute(ctx, &ResendVerificationEmailParams{UserID: user.ID}))
+		messages := outbox.MessagesTo("john@example.com")
+		require.Len(t, messages, 1)
+
+		err = verifyUC.Execute(ctx, &VerifyEmailParams{Token: oldToken})
+		assert.ErrorIs(t, err, domain.ErrInvalidEmailVerificationToken)
+		require.NoError(t, verifyUC.Execute(ctx, &VerifyEmailParams{Token: tokenFromEmailBody(t, messages[0].Body)}))
+
+		// 已驗證的使用者不需重新寄送
+		err = resendUC.Execute(ctx, &ResendVerificationEmailParams{UserID: user.ID})
+		assert.ErrorIs(t, err, domain.ErrEmailAlreadyVerified)
+	})
+
+	t.Run("間隔太短時拒絕寄送", func(t *testing.T) {
+		user := createUser(t, "jane@example.com")
+
+		require.NoError(t, resendUC.Execute(ctx, &ResendVerificationEmailParams{UserID: user.ID}))
+		err := resendUC.Execute(ctx, &ResendVerificationEmailParams{UserID: user.ID})
+		assert.ErrorIs(t, err, domain.ErrVerificationEmailThrottled)
+
+		var throttledErr *domain.VerificationEmailThrottledError
+		require.ErrorAs(t, err, &throttledErr)
+		assert.Positive(t, throttledErr.RetryAfter)
+		assert.LessOrEqual(t, throttledErr.RetryAfter, domain.EmailVerificationResendInterval)
+		assert.Len(t, outbox.MessagesTo("jane@example.com"), 1)
+	})
+	t.Run("同時重新寄送只寄出一封驗證信", func(t *testing.T) {
+		user := createUser(t, "alice@example.com")
+
+		const workers = 5
+		errs := make(chan error, workers)
+		for i := 0; i < workers; i++ {
+			go func() {
+				errs <- resendUC.Execute(ctx, &ResendVerificationEmailParams{UserID: user.ID})
+			}()
+		}
+		var succeeded int
+		for i := 0; i < workers; i++ {
+			if err := <-errs; err != nil {
+				assert.ErrorIs(t, err, domain.ErrVerificationEmailThrottled)
+				continue
+			}
+			succeeded++
+		}
+
+		assert.Equal(t, 1, succeeded)
+		assert.Len(t, outbox.MessagesTo("alice@example.com"), 1)
+	})
+}
@@ -3,148 +3,468 @@ package auth
 import (
 	"context"
 	"database/sql"
-	"encoding/base64"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
-	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
 	"portal_link/modules/user/domain"
+	"portal_link/pkg/http_error"
+	"portal_link/pkg/logger"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 )
 
-// TODO: 將常數移至配置文件，方便根據環境調整
+// 有效期為預設值，實際使用的值由 pkg/config 載入並透過 TokenManagerConfig 設定
 const (
-	// TokenExpiration 定義 token 的有效期為 1 天
-	TokenExpiration = 24 * time.Hour
+	// TokenExpiration 定義 access token 的有效期為 15 分鐘
+	TokenExpiration = 15 * time.Minute
+	// RefreshTokenExpiration 定義 refresh token 的有效期為 30 天
+	RefreshTokenExpiration = 30 * 24 * time.Hour
 	// ContextUserIDKey 用於在 gin.Context 中存儲使用者 ID 的鍵值
 	ContextUserIDKey = "userID"
+	// ContextAccessTokenKey 用於在 gin.Context 中存儲 access token claims 的鍵值
+	ContextAccessTokenKey = "accessToken"
+
+	// DefaultIssuer 預設的 token 簽發者（iss）
+	DefaultIssuer = "portal_link"
+	// DefaultAudience 預設的 token 接收者（aud）
+	DefaultAudience = "portal_link_api"
 )
 
-// TODO: 擴充錯誤類型以支援更多驗證場景
 var (
-	ErrInvalidToken  = errors.New("invalid token format")
-	ErrExpiredToken  = errors.New("token has expired")
-	ErrUserNotFound  = errors.New("user not found")
-	ErrInvalidUserID = errors.New("invalid user ID in token")
+	ErrInvalidToken      = errors.New("invalid token format")
+	ErrExpiredToken      = errors.New("token has expired")
+	ErrTokenNotYetValid  = errors.New("token is not valid yet")
+	ErrInvalidSignature  = errors.New("invalid token signature")
+	ErrUnknownKeyID      = errors.New("unknown token key ID")
+	ErrInvalidAlgorithm  = errors.New("unexpected token signing algorithm")
+	ErrInvalidIssuer     = errors.New("invalid token issuer")
+	ErrInvalidAudience   = errors.New("invalid token audience")
+	ErrUserNotFound      = errors.New("user not found")
+	ErrInvalidUserID     = errors.New("invalid user ID in token")
+	ErrMissingSigningKey = errors.New("no signing key available")
+	ErrRevokedToken      = errors.New("token has been revoked")
 )
 
-// TODO: 考慮加入額外的安全相關欄位，如 token 版本、裝置識別碼等
-type tokenData struct {
-	UserID    string    `json:"user_id"`
-	ExpiresAt time.Time `json:"expires_at"`
+// AccessTokenClaims access token 的 claims
+type AccessTokenClaims struct {
+	jwt.RegisteredClaims
+	// TokenVersion 簽發時使用者的 token 版本，低於目前版本的 token 視為已撤銷
+	TokenVersion int `json:"ver"`
+}
+
+// TokenManagerConfig TokenManager 的設定
+type TokenManagerConfig struct {
+	// Issuer 簽發與驗證時使用的 iss，預設為 DefaultIssuer
+	Issuer string
+	// Audience 簽發與驗證時使用的 aud，預設為 DefaultAudience
+	Audience string
+	// AccessTokenTTL access token 的有效期，預設為 TokenExpiration
+	AccessTokenTTL time.Duration
+	// RefreshTokenTTL refresh token 的有效期，預設為 RefreshTokenExpiration
+	RefreshTokenTTL time.Duration
+	// RevocationStore 保存撤銷紀錄與 token 版本，預設為 InMemoryRevocationStore
+	RevocationStore RevocationStore
+	// FailureRecorder 記錄被拒絕的 access token，預設不記錄
+	FailureRecorder TokenFailureRecorder
+	// PersonalAccessTokens 驗證 personal access token 時查詢的 repository，nil 時不接受 personal access token
+	PersonalAccessTokens domain.PersonalAccessTokenRepository
+}
+
+// TokenFailureRecorder 記錄 access token 驗證失敗的原因，用於監控（例如 Prometheus counter）
+type TokenFailureRecorder interface {
+	// RecordTokenValidationFailure reason 為 FailureReason 的結果，例如 ErrExpiredToken
+	RecordTokenValidationFailure(reason string)
+}
+
+// nopFailureRecorder 不記錄任何資料的 TokenFailureRecorder
+type nopFailureRecorder struct{}
+
+func (nopFailureRecorder) RecordTokenValidationFailure(reason string) {}
+
+// failureReasons 驗證失敗的錯誤與其名稱，依序比對
+var failureReasons = []struct {
+	err  error
+	name string
+}{
+	{ErrExpiredToken, "ErrExpiredToken"},
+	{ErrTokenNotYetValid, "ErrTokenNotYetValid"},
+	{ErrInvalidSignature, "ErrInvalidSignature"},
+	{ErrUnknownKeyID, "ErrUnknownKeyID"},
+	{ErrInvalidAlgorithm, "ErrInvalidAlgorithm"},
+	{ErrInvalidIssuer, "ErrInvalidIssuer"},
+	{ErrInvalidAudience, "ErrInvalidAudience"},
+	{ErrRevokedToken, "ErrRevokedToken"},
+	{ErrInsufficientScope, "ErrInsufficientScope"},
+	{ErrUserNotFound, "ErrUserNotFound"},
+	{ErrInvalidUserID, "ErrInvalidUserID"},
+	{ErrInvalidToken, "ErrInvalidToken"},
+}
+
+// FailureReason 返回 access token 驗證錯誤的名稱，例如 ErrExpiredToken；無法辨識的錯誤返回 Other
+func FailureReason(err error) string {
+	for _, reason := range failureReasons {
+		if errors.Is(err, reason.err) {
+			return reason.name
+		}
+	}
+	return "Other"
+}
+
+// TokenManager 負責簽發與驗證 JWT access token
+type TokenManager struct {
+	keyring         *Keyring
+	issuer          string
+	audience        string
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
+	revocationStore RevocationStore
+	failureRecorder TokenFailureRecorder
+	now             func() time.Time
+
+	personalAccessTokens domain.PersonalAccessTokenRepository
+}
+
+// NewTokenManager 建立新的 TokenManager
+func NewTokenManager(keyring *Keyring, config TokenManagerConfig) *TokenManager {
+	if config.Issuer == "" {
+		config.Issuer = DefaultIssuer
+	}
+	if config.Audience == "" {
+		config.Audience = DefaultAudience
+	}
+	if config.AccessTokenTTL <= 0 {
+		config.AccessTokenTTL = TokenExpiration
+	}
+	if config.RefreshTokenTTL <= 0 {
+		config.RefreshTokenTTL = RefreshTokenExpiration
+	}
+	if config.RevocationStore == nil {
+		config.RevocationStore = NewInMemoryRevocationStore()
+	}
+	if config.FailureRecorder == nil {
+		config.FailureRecorder = nopFailureRecorder{}
+	}
+
+	return &TokenManager{
+		keyring:         keyring,
+		issuer:          config.Issuer,
+		audience:        config.Audience,
+		accessTokenTTL:  config.AccessTokenTTL,
+		refreshTokenTTL: config.RefreshTokenTTL,
+		revocationStore: config.RevocationStore,
+		failureRecorder: config.FailureRecorder,
+		now:             func() time.Time { return time.Now().UTC() },
+
+		personalAccessTokens: config.PersonalAccessTokens,
+	}
+}
+
+// AccessTokenTTL 取得 access token 的有效期
+func (m *TokenManager) AccessTokenTTL() time.Duration {
+	return m.accessTokenTTL
+}
+
+// RefreshTokenTTL 取得 refresh token 的有效期
+func (m *TokenManager) RefreshTokenTTL() time.Duration {
+	return m.refreshTokenTTL
 }
 
 // GenerateAccessToken 產生使用者的 access token
-func GenerateAccessToken(userID string) (string, error) {
-	// TODO: 改用 JWT 實作，提供更安全和標準的 token 機制
-	// TODO: 加入 token 簽名機制確保資料完整性
-	// TODO: 考慮使用 Redis 等快取系統儲存 token 狀態
+func (m *TokenManager) GenerateAccessToken(ctx context.Context, userID string) (string, error) {
+	key := m.keyring.SigningKey()
+	if key == nil || !key.CanSign() {
+		return "", ErrMissingSigningKey
+	}
+
+	tokenID, err := generateTokenID()
+	if err != nil {
+		return "", err
+	}
+	version, err := m.revocationStore.TokenVersion(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get token version: %w", err)
+	}
 
-	// 建立 token 資料
-	data := tokenData{
-		UserID:    userID,
-		ExpiresAt: time.Now().UTC().Add(TokenExpiration),
+	now := m.now()
+	claims := AccessTokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			Issuer:    m.issuer,
+			Subject:   userID,
+			Audience:  jwt.ClaimStrings{m.audience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(m.accessTokenTTL)),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+		TokenVersion: version,
 	}
 
-	// 序列化資料
-	jsonData, err := json.Marshal(data)
+	token := jwt.NewWithClaims(key.signingMethod(), claims)
+	token.Header["kid"] = key.ID
+
+	signed, err := token.SignedString(key.signingKey)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal token data: %w", err)
+		return "", fmt.Errorf("failed to sign access token: %w", err)
 	}
+	return signed, nil
+}
 
-	// Base64 編碼
-	token := base64.StdEncoding.EncodeToString(jsonData)
-	return token, nil
+// ValidateAccessToken 驗證 access token 的簽章、簽發者、接收者與有效期，並檢查使用者是否存在
+func (m *TokenManager) ValidateAccessToken(ctx context.Context, token string, userRepo domain.UserRepository) (string, error) {
+	claims, err := m.validate(ctx, token, userRepo)
+	if err != nil {
+		return "", err
+	}
+	return claims.Subject, nil
 }
 
-// ValidateAccessToken 驗證 access token 的有效性，並檢查使用者是否存在
-func ValidateAccessToken(ctx context.Context, token string, userRepo domain.UserRepository) (string, error) {
-	// TODO: 實作 token 黑名單機制，支援 token 撤銷功能
-	// TODO: 加入 token 使用紀錄，以便追蹤可疑活動
-	// TODO: 實作 rate limiting 機制防止暴力破解
+// RevokeAccessToken 撤銷單一 access token，直到其過期為止
+func (m *TokenManager) RevokeAccessToken(ctx context.Context, claims *AccessTokenClaims) error {
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return ErrInvalidToken
+	}
+	return m.revocationStore.Revoke(ctx, claims.ID, claims.ExpiresAt.Time)
+}
 
-	// Base64 解碼
-	jsonData, err := base64.StdEncoding.DecodeString(token)
+// RevokeAllAccessTokens 遞增使用者的 token 版本，使該使用者所有已簽發的 access token 失效
+func (m *TokenManager) RevokeAllAccessTokens(ctx context.Context, userID string) error {
+	_, err := m.revocationStore.IncrementTokenVersion(ctx, userID)
+	return err
+}
+
+// validate 驗證 access token，失敗時記錄原因
+func (m *TokenManager) validate(ctx context.Context, token string, userRepo domain.UserRepository) (*AccessTokenClaims, error) {
+	claims, err := m.verify(ctx, token, userRepo)
 	if err != nil {
-		return "", ErrInvalidToken
+		m.failureRecorder.RecordTokenValidationFailure(FailureReason(err))
 	}
+	return claims, err
+}
 
-	// 解析 token 資料
-	var data tokenData
-	if err := json.Unmarshal(jsonData, &data); err != nil {
-		return "", ErrInvalidToken
-	}
+// verify 驗證 access token，並檢查撤銷紀錄、token 版本與使用者是否存在
+func (m *TokenManager) verify(ctx context.Context, token string, userRepo domain.UserRepository) (*AccessTokenClaims, error) {
+	// TODO: 加入 token 使用紀錄，以便追蹤可疑活動
+	// TODO: 實作 rate limiting 機制防止暴力破解
 
-	// 檢查是否過期
-	if time.Now().UTC().After(data.ExpiresAt) {
-		return "", ErrExpiredToken
+	claims, err := m.parse(token)
+	if err != nil {
+		return nil, err
 	}
 
 	// 將 userID 從字串轉換為整數
-	userID, err := strconv.Atoi(data.UserID)
+	userID, err := strconv.Atoi(claims.Subject)
 	if err != nil {
-		return "", ErrInvalidUserID
+		return nil, ErrInvalidUserID
+	}
+
+	// 檢查 token 是否已被撤銷
+	if err := m.checkRevocation(ctx, claims); err != nil {
+		return nil, err
 	}
 
 	// 檢查使用者是否存在於資料庫中
 	_, err = userRepo.Find(ctx, userID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return "", ErrUserNotFound
+			return nil, ErrUserNotFound
 		}
-		log.Printf("Error finding user: %v", err)
-		return "", ErrUserNotFound
+		logger.FromContext(ctx).Error("failed to find user of access token", "user_id", userID, "error", err)
+		return nil, ErrUserNotFound
 	}
 
-	return data.UserID, nil
+	return claims, nil
+}
+
+// checkRevocation 檢查 token 是否被單獨撤銷，或其版本已落後於使用者目前的 token 版本
+func (m *TokenManager) checkRevocation(ctx context.Context, claims *AccessTokenClaims) error {
+	if claims.ID == "" {
+		return ErrInvalidToken
+	}
+
+	revoked, err := m.revocationStore.IsRevoked(ctx, claims.ID)
+	if err != nil {
+		return fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	if revoked {
+		return ErrRevokedToken
+	}
+
+	version, err := m.revocationStore.TokenVersion(ctx, claims.Subject)
+	if err != nil {
+		return fmt.Errorf("failed to get token version: %w", err)
+	}
+	if claims.TokenVersion < version {
+		return ErrRevokedToken
+	}
+
+	return nil
+}
+
+// parse 解析並驗證 token，回傳其中的 claims
+func (m *TokenManager) parse(token string) (*AccessTokenClaims, error) {
+	claims := &AccessTokenClaims{}
+	parser := jwt.NewParser(
+		jwt.WithIssuer(m.issuer),
+		jwt.WithAudience(m.audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithTimeFunc(m.now),
+	)
+
+	_, err := parser.ParseWithClaims(token, claims, m.lookupKey)
+	if err != nil {
+		return nil, mapParseError(err)
+	}
+	return claims, nil
+}
+
+// lookupKey 根據 token header 的 kid 從 keyring 取得驗證金鑰
+// 金鑰的演算法必須與 header 的 alg 相符，避免演算法混淆攻擊
+func (m *TokenManager) lookupKey(token *jwt.Token) (any, error) {
+	kid, ok := token.Header["kid"].(string)
+	if !ok || kid == "" {
+		return nil, ErrUnknownKeyID
+	}
+
+	key, exists := m.keyring.VerificationKey(kid)
+	if !exists {
+		return nil, ErrUnknownKeyID
+	}
+	if token.Method.Alg() != key.Algorithm {
+		return nil, ErrInvalidAlgorithm
+	}
+
+	return key.verifyingKey, nil
+}
+
+// mapParseError 將 jwt 套件的錯誤轉換為 auth 套件的錯誤
+func mapParseError(err error) error {
+	switch {
+	case errors.Is(err, ErrUnknownKeyID):
+		return ErrUnknownKeyID
+	case errors.Is(err, ErrInvalidAlgorithm):
+		return ErrInvalidAlgorithm
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid):
+		return ErrInvalidSignature
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return ErrExpiredToken
+	case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		return ErrTokenNotYetValid
+	case errors.Is(err, jwt.ErrTokenInvalidIssuer):
+		return ErrInvalidIssuer
+	case errors.Is(err, jwt.ErrTokenInvalidAudience):
+		return ErrInvalidAudience
+	default:
+		return ErrInvalidToken
+	}
 }
 
 // AuthMiddleware Gin 框架的身份驗證中間件
-func AuthMiddleware(userRepo domain.UserRepository) gin.HandlerFunc {
+// 接受登入取得的 access token 與 personal access token；scopes 為路由需要的權限，
+// personal access token 必須具有所有 scopes，未指定 scopes 的路由只接受登入取得的 access token
+func AuthMiddleware(tokenManager *TokenManager, userRepo domain.UserRepository, scopes ...domain.Scope) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// TODO: 支援多種身份驗證方式（如 API Key、OAuth 等）
+		// TODO: 支援 OAuth 等其他身份驗證方式
 		// TODO: 加入請求來源驗證（CORS 設定）
-		// TODO: 實作 token 刷新機制
 
 		// 從 Authorization 標頭獲取 token
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"code":    "ErrUnauthorized",
-				"message": "Invalid access token",
-			})
+			http_error.ResponseUnauthorized(c, nil)
+			c.Abort()
 			return
 		}
 
 		// 檢查 Bearer token 格式
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"code":    "ErrUnauthorized",
-				"message": "Invalid access token",
-			})
+			tokenManager.failureRecorder.RecordTokenValidationFailure(FailureReason(ErrInvalidToken))
+			http_error.ResponseUnauthorized(c, nil)
+			c.Abort()
+			return
+		}
+
+		if IsPersonalAccessToken(parts[1]) {
+			authenticatePersonalAccessToken(c, tokenManager, userRepo, parts[1], scopes)
 			return
 		}
 
 		// 驗證 token 並檢查使用者是否存在
-		userID, err := ValidateAccessToken(c.Request.Context(), parts[1], userRepo)
+		claims, err := tokenManager.validate(c.Request.Context(), parts[1], userRepo)
 		if err != nil {
-			log.Println("ValidateAccessToken error:", err)
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"code":    "ErrUnauthorized",
-				"message": "Invalid access token",
-			})
+			logger.FromContext(c.Request.Context()).Info("access token rejected", "error", err)
+			http_error.ResponseUnauthorized(c, nil)
+			c.Abort()
+			return
+		}
+
+		// 將使用者 ID 與 token claims 存入 context
+		c.Set(ContextUserIDKey, claims.Subject)
+		c.Set(ContextAccessTokenKey, claims)
+		c.Next()
+	}
+}
+
+// authenticatePersonalAccessToken 驗證 personal access token 並檢查權限，缺少 scopes 中的任一權限時回應 403
+func authenticatePersonalAccessToken(c *gin.Context, tokenManager *TokenManager, userRepo domain.UserRepository, token string, scopes []domain.Scope) {
+	ctx := c.Request.Context()
+	personalAccessToken, err := tokenManager.validatePersonalAccessToken(ctx, token, userRepo)
+	if err != nil {
+		logger.FromContext(ctx).Info("personal access token rejected", "error", err)
+		http_error.ResponseUnauthorized(c, nil)
+		c.Abort()
+		return
+	}
+
+	if len(scopes) == 0 || !personalAccessToken.HasScopes(scopes...) {
+		tokenManager.failureRecorder.RecordTokenValidationFailure(FailureReason(ErrInsufficientScope))
+		logger.FromContext(ctx).Info("personal access token rejected", "token_id", personalAccessToken.ID, "error", ErrInsufficientScope)
+		http_error.ResponseForbidden(c, &http_error.ErrorResponse{Code: "ErrInsufficientScope"})
+		c.Abort()
+		return
+	}
+
+	tokenManager.markPersonalAccessTokenUsed(ctx, personalAccessToken)
+
+	// 將使用者 ID 與 personal access token 存入 context
+	c.Set(ContextUserIDKey, strconv.Itoa(personalAccessToken.UserID))
+	c.Set(ContextPersonalAccessTokenKey, personalAccessToken)
+	c.Next()
+}
+
+// RequireVerifiedEmail 要求目前登入的使用者已完成電子郵件驗證，需放在 AuthMiddleware 之後
+func RequireVerifiedEmail(userRepo domain.UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDStr, err := GetUserIDFromContext(c)
+		if err != nil {
+			http_error.ResponseUnauthorized(c, nil)
+			c.Abort()
+			return
+		}
+		userID, err := strconv.Atoi(userIDStr)
+		if err != nil {
+			http_error.ResponseUnauthorized(c, nil)
+			c.Abort()
+			return
+		}
+
+		user, err := userRepo.Find(c.Request.Context(), userID)
+		if err != nil {
+			logger.FromContext(c.Request.Context()).Error("failed to find user for email verification check", "user_id", userID, "error", err)
+			http_error.ResponseUnauthorized(c, nil)
+			c.Abort()
+			return
+		}
+		if !user.IsEmailVerified() {
+			http_error.ResponseForbidden(c, &http_error.ErrorResponse{Code: "ErrEmailNotVerified"})
+			c.Abort()
 			return
 		}
 
-		// 將使用者 ID 存入 context
-		c.Set(ContextUserIDKey, userID)
 		c.Next()
 	}
 }
@@ -166,3 +486,18 @@ func GetUserIDFromContext(c *gin.Context) (string, error) {
 
 	return userIDStr, nil
 }
+
+// GetAccessTokenFromContext 從 gin.Context 中取得目前請求的 access token claims
+func GetAccessTokenFromContext(c *gin.Context) (*AccessTokenClaims, error) {
+	value, exists := c.Get(ContextAccessTokenKey)
+	if !exists {
+		return nil, errors.New("access token not found in context")
+	}
+
+	claims, ok := value.(*AccessTokenClaims)
+	if !ok {
+		return nil, errors.New("invalid access token type in context")
+	}
+
+	return claims, nil
+}
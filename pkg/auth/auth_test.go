@@ -0,0 +1,665 @@
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"portal_link/modules/user/domain"
+	"portal_link/modules/user/repository"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestHMACKey(t *testing.T, id string) *Key {
+	t.Helper()
+
+	key, err := NewHMACKey(id, []byte(strings.Repeat(id, 32)))
+	require.NoError(t, err)
+	return key
+}
+
+func newTestUserRepository(t *testing.T) *repository.InMemoryUserRepository {
+	t.Helper()
+
+	repo := repository.NewInMemoryUserRepository()
+	err := repo.Create(context.Background(), &domain.User{
+		ID:       1,
+		Name:     "John Doe",
+		Email:    "john@example.com",
+		Password: "password123",
+	})
+	require.NoError(t, err)
+	return repo
+}
+
+func TestTokenManager_GenerateAndValidate(t *testing.T) {
+	ctx := context.Background()
+	userRepo := newTestUserRepository(t)
+
+	rsaPrivateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, ed25519PrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	rsaKey, err := NewRSAKey("rsa", rsaPrivateKey)
+	require.NoError(t, err)
+	ed25519Key, err := NewEd25519Key("ed25519", ed25519PrivateKey)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		key  *Key
+	}{
+		{name: "HS256", key: newTestHMACKey(t, "h")},
+		{name: "RS256", key: rsaKey},
+		{name: "EdDSA", key: ed25519Key},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyring, err := NewKeyring(tt.key)
+			require.NoError(t, err)
+			manager := NewTokenManager(keyring, TokenManagerConfig{})
+
+			token, err := manager.GenerateAccessToken(ctx, "1")
+			require.NoError(t, err)
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &jwt.RegisteredClaims{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.key.ID, parsed.Header["kid"])
+			assert.Equal(t, tt.key.Algorithm, parsed.Header["alg"])
+
+			userID, err := manager.ValidateAccessToken(ctx, token, userRepo)
+			require.NoError(t, err)
+			assert.Equal(t, "1", userID)
+		})
+	}
+}
+
+func TestTokenManager_ValidateAccessToken_Errors(t *testing.T) {
+	ctx := context.Background()
+	userRepo := newTestUserRepository(t)
+
+	keyring, err := NewKeyring(newTestHMACKey(t, "a"))
+	require.NoError(t, err)
+	manager := NewTokenManager(keyring, TokenManagerConfig{})
+
+	tests := []struct {
+		name        string
+		token       func(t *testing.T) string
+		expectedErr error
+	}{
+		{
+			name:        "格式錯誤",
+			token:       func(t *testing.T) string { return "not-a-jwt" },
+			expectedErr: ErrInvalidToken,
+		},
+		{
+			name: "舊版未簽章的 base64 token",
+			token: func(t *testing.T) string {
+				data, err := json.Marshal(map[string]any{
+					"user_id":    "1",
+					"expires_at": time.Now().Add(time.Hour),
+				})
+				require.NoError(t, err)
+				return base64.StdEncoding.EncodeToString(data)
+			},
+			expectedErr: ErrInvalidToken,
+		},
+		{
+			name: "簽章遭竄改",
+			token: func(t *testing.T) string {
+				token, err := manager.GenerateAccessToken(ctx, "1")
+				require.NoError(t, err)
+				parts := strings.Split(token, ".")
+				forged, err := NewTokenManager(keyring, TokenManagerConfig{}).GenerateAccessToken(ctx, "2")
+				require.NoError(t, err)
+				// 使用 user 1 的簽章搭配 user 2 的 payload
+				return strings.Join([]string{parts[0], strings.Split(forged, ".")[1], parts[2]}, ".")
+			},
+			expectedErr: ErrInvalidSignature,
+		},
+		{
+			name: "不同金鑰簽發（相同 kid）",
+			token: func(t *testing.T) string {
+				otherKey, err := NewHMACKey("a", []byte(strings.Repeat("b", 32)))
+				require.NoError(t, err)
+				otherKeyring, err := NewKeyring(otherKey)
+				require.NoError(t, err)
+				token, err := NewTokenManager(otherKeyring, TokenManagerConfig{}).GenerateAccessToken(ctx, "1")
+				require.NoError(t, err)
+				return token
+			},
+			expectedErr: ErrInvalidSignature,
+		},
+		{
+			name: "未知的 kid",
+			token: func(t *testing.T) string {
+				otherKeyring, err := NewKeyring(newTestHMACKey(t, "z"))
+				require.NoError(t, err)
+				token, err := NewTokenManager(otherKeyring, TokenManagerConfig{}).GenerateAccessToken(ctx, "1")
+				require.NoError(t, err)
+				return token
+			},
+			expectedErr: ErrUnknownKeyID,
+		},
+		{
+			name: "alg 為 none",
+			token: func(t *testing.T) string {
+				token := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.RegisteredClaims{
+					Issuer:    DefaultIssuer,
+					Subject:   "1",
+					Audience:  jwt.ClaimStrings{DefaultAudience},
+					ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+				})
+				token.Header["kid"] = "a"
+				signed, err := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+				require.NoError(t, err)
+				return signed
+			},
+			expectedErr: ErrInvalidAlgorithm,
+		},
+		{
+			name: "已過期",
+			token: func(t *testing.T) string {
+				expired := NewTokenManager(keyring, TokenManagerConfig{AccessTokenTTL: time.Minute})
+				expired.now = func() time.Time { return time.Now().UTC().Add(-time.Hour) }
+				token, err := expired.GenerateAccessToken(ctx, "1")
+				require.NoError(t, err)
+				return token
+			},
+			expectedErr: ErrExpiredToken,
+		},
+		{
+			name: "尚未生效",
+			token: func(t *testing.T) string {
+				future := NewTokenManager(keyring, TokenManagerConfig{})
+				future.now = func() time.Time { return time.Now().UTC().Add(time.Hour) }
+				token, err := future.GenerateAccessToken(ctx, "1")
+				require.NoError(t, err)
+				return token
+			},
+			expectedErr: ErrTokenNotYetValid,
+		},
+		{
+			name: "簽發者錯誤",
+			token: func(t *testing.T) string {
+				token, err := NewTokenManager(keyring, TokenManagerConfig{Issuer: "other"}).GenerateAccessToken(ctx, "1")
+				require.NoError(t, err)
+				return token
+			},
+			expectedErr: ErrInvalidIssuer,
+		},
+		{
+			name: "接收者錯誤",
+			token: func(t *testing.T) string {
+				token, err := NewTokenManager(keyring, TokenManagerConfig{Audience: "other"}).GenerateAccessToken(ctx, "1")
+				require.NoError(t, err)
+				return token
+			},
+			expectedErr: ErrInvalidAudience,
+		},
+		{
+			name: "使用者 ID 格式錯誤",
+			token: func(t *testing.T) string {
+				token, err := manager.GenerateAccessToken(ctx, "abc")
+				require.NoError(t, err)
+				return token
+			},
+			expectedErr: ErrInvalidUserID,
+		},
+		{
+			name: "使用者不存在",
+			token: func(t *testing.T) string {
+				token, err := manager.GenerateAccessToken(ctx, "999")
+				require.NoError(t, err)
+				return token
+			},
+			expectedErr: ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := manager.ValidateAccessToken(ctx, tt.token(t), userRepo)
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestTokenManager_AlgorithmConfusion(t *testing.T) {
+	ctx := context.Background()
+	userRepo := newTestUserRepository(t)
+
+	rsaPrivateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaKey, err := NewRSAKey("rsa", rsaPrivateKey)
+	require.NoError(t, err)
+	keyring, err := NewKeyring(rsaKey)
+	require.NoError(t, err)
+	manager := NewTokenManager(keyring, TokenManagerConfig{})
+
+	// 攻擊者以 RSA 公鑰作為 HMAC secret 簽發 HS256 token
+	publicKeyDER, err := x509.MarshalPKIXPublicKey(&rsaPrivateKey.PublicKey)
+	require.NoError(t, err)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Issuer:    DefaultIssuer,
+		Subject:   "1",
+		Audience:  jwt.ClaimStrings{DefaultAudience},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	})
+	token.Header["kid"] = "rsa"
+	signed, err := token.SignedString(publicKeyDER)
+	require.NoError(t, err)
+
+	_, err = manager.ValidateAccessToken(ctx, signed, userRepo)
+	assert.ErrorIs(t, err, ErrInvalidAlgorithm)
+}
+
+func TestKeyring_Rotation(t *testing.T) {
+	ctx := context.Background()
+	userRepo := newTestUserRepository(t)
+
+	oldKey := newTestHMACKey(t, "old")
+	newKey := newTestHMACKey(t, "new")
+
+	keyring, err := NewKeyring(oldKey)
+	require.NoError(t, err)
+	manager := NewTokenManager(keyring, TokenManagerConfig{})
+
+	oldToken, err := manager.GenerateAccessToken(ctx, "1")
+	require.NoError(t, err)
+
+	// 加入新金鑰並輪替
+	require.NoError(t, keyring.Add(newKey))
+	require.NoError(t, keyring.Rotate("new"))
+
+	newToken, err := manager.GenerateAccessToken(ctx, "1")
+	require.NoError(t, err)
+	parsed, _, err := jwt.NewParser().ParseUnverified(newToken, &jwt.RegisteredClaims{})
+	require.NoError(t, err)
+	assert.Equal(t, "new", parsed.Header["kid"])
+
+	// 輪替後舊 token 仍然有效
+	_, err = manager.ValidateAccessToken(ctx, oldToken, userRepo)
+	assert.NoError(t, err)
+	_, err = manager.ValidateAccessToken(ctx, newToken, userRepo)
+	assert.NoError(t, err)
+
+	// 無法移除目前的簽發金鑰
+	assert.ErrorIs(t, keyring.Remove("new"), ErrSigningKeyInUse)
+
+	// 移除舊金鑰後，舊 token 失效
+	require.NoError(t, keyring.Remove("old"))
+	_, err = manager.ValidateAccessToken(ctx, oldToken, userRepo)
+	assert.ErrorIs(t, err, ErrUnknownKeyID)
+	_, err = manager.ValidateAccessToken(ctx, newToken, userRepo)
+	assert.NoError(t, err)
+}
+
+func TestKeyring_Errors(t *testing.T) {
+	_, err := NewHMACKey("short", []byte("too-short"))
+	assert.ErrorIs(t, err, ErrInvalidKey)
+
+	publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	verifyOnly, err := NewEd25519PublicKey("public", publicKey)
+	require.NoError(t, err)
+
+	_, err = NewKeyring(verifyOnly)
+	assert.ErrorIs(t, err, ErrVerificationOnly)
+
+	keyring, err := NewKeyring(newTestHMACKey(t, "a"), verifyOnly)
+	require.NoError(t, err)
+	assert.ErrorIs(t, keyring.Rotate("public"), ErrVerificationOnly)
+	assert.ErrorIs(t, keyring.Rotate("missing"), ErrKeyNotFound)
+	assert.ErrorIs(t, keyring.Add(newTestHMACKey(t, "a")), ErrDuplicateKeyID)
+}
+
+func TestParseKeyPEM(t *testing.T) {
+	ctx := context.Background()
+	userRepo := newTestUserRepository(t)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	edPublicKey, edPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	encode := func(t *testing.T, blockType string, der []byte, err error) []byte {
+		t.Helper()
+		require.NoError(t, err)
+		return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	}
+	rsaPKCS8, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	rsaPrivatePEM := encode(t, "PRIVATE KEY", rsaPKCS8, err)
+	rsaPKCS1PEM := encode(t, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey), nil)
+	rsaPKIX, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	rsaPublicPEM := encode(t, "PUBLIC KEY", rsaPKIX, err)
+	edPKCS8, err := x509.MarshalPKCS8PrivateKey(edPrivateKey)
+	edPrivatePEM := encode(t, "PRIVATE KEY", edPKCS8, err)
+	edPKIX, err := x509.MarshalPKIXPublicKey(edPublicKey)
+	edPublicPEM := encode(t, "PUBLIC KEY", edPKIX, err)
+
+	t.Run("private keys sign tokens that public keys verify", func(t *testing.T) {
+		for _, tc := range []struct {
+			alg                   string
+			privatePEM, publicPEM []byte
+		}{
+			{AlgRS256, rsaPrivatePEM, rsaPublicPEM},
+			{AlgRS256, rsaPKCS1PEM, rsaPublicPEM},
+			{AlgEdDSA, edPrivatePEM, edPublicPEM},
+		} {
+			signingKey, err := ParseKeyPEM("key", tc.alg, tc.privatePEM)
+			require.NoError(t, err)
+			assert.True(t, signingKey.CanSign())
+			assert.Equal(t, tc.alg, signingKey.Algorithm)
+
+			publicKey, err := ParseKeyPEM("key", tc.alg, tc.publicPEM)
+			require.NoError(t, err)
+			assert.False(t, publicKey.CanSign())
+
+			signer, err := NewKeyring(signingKey)
+			require.NoError(t, err)
+			token, err := NewTokenManager(signer, TokenManagerConfig{}).GenerateAccessToken(ctx, "1")
+			require.NoError(t, err)
+
+			// 只持有公鑰的實例也能驗證
+			verifier, err := NewKeyring(newTestHMACKey(t, "hmac"), publicKey)
+			require.NoError(t, err)
+			_, err = NewTokenManager(verifier, TokenManagerConfig{}).ValidateAccessToken(ctx, token, userRepo)
+			assert.NoError(t, err)
+		}
+	})
+
+	t.Run("rejects invalid PEM and mismatched algorithms", func(t *testing.T) {
+		_, err := ParseKeyPEM("k", AlgRS256, []byte("not a pem"))
+		assert.ErrorIs(t, err, ErrInvalidKey)
+
+		_, err = ParseKeyPEM("k", AlgEdDSA, rsaPrivatePEM)
+		assert.ErrorIs(t, err, ErrInvalidKey)
+
+		_, err = ParseKeyPEM("k", AlgRS256, edPublicPEM)
+		assert.ErrorIs(t, err, ErrInvalidKey)
+
+		_, err = ParseKeyPEM("k", AlgRS256, encode(t, "CERTIFICATE", []byte("cert"), nil))
+		assert.ErrorIs(t, err, ErrInvalidKey)
+	})
+}
+
+func TestTokenManager_Revocation(t *testing.T) {
+	ctx := context.Background()
+	userRepo := newTestUserRepository(t)
+
+	keyring, err := NewKeyring(newTestHMACKey(t, "a"))
+	require.NoError(t, err)
+	manager := NewTokenManager(keyring, TokenManagerConfig{})
+
+	t.Run("撤銷單一 access token", func(t *testing.T) {
+		revoked, err := manager.GenerateAccessToken(ctx, "1")
+		require.NoError(t, err)
+		other, err := manager.GenerateAccessToken(ctx, "1")
+		require.NoError(t, err)
+
+		claims, err := manager.validate(ctx, revoked, userRepo)
+		require.NoError(t, err)
+		require.NoError(t, manager.RevokeAccessToken(ctx, claims))
+
+		_, err = manager.ValidateAccessToken(ctx, revoked, userRepo)
+		assert.ErrorIs(t, err, ErrRevokedToken)
+		_, err = manager.ValidateAccessToken(ctx, other, userRepo)
+		assert.NoError(t, err)
+	})
+
+	t.Run("遞增 token 版本撤銷所有 access token", func(t *testing.T) {
+		before, err := manager.GenerateAccessToken(ctx, "1")
+		require.NoError(t, err)
+
+		require.NoError(t, manager.RevokeAllAccessTokens(ctx, "1"))
+
+		_, err = manager.ValidateAccessToken(ctx, before, userRepo)
+		assert.ErrorIs(t, err, ErrRevokedToken)
+
+		after, err := manager.GenerateAccessToken(ctx, "1")
+		require.NoError(t, err)
+		_, err = manager.ValidateAccessToken(ctx, after, userRepo)
+		assert.NoError(t, err)
+	})
+}
+
+func TestInMemoryRevocationStore_PrunesExpiredEntries(t *testing.T) {
+	ctx := context.Background()
+	store := NewInMemoryRevocationStore()
+
+	require.NoError(t, store.Revoke(ctx, "expired", time.Now().UTC().Add(-time.Minute)))
+	require.NoError(t, store.Revoke(ctx, "active", time.Now().UTC().Add(time.Minute)))
+
+	revoked, err := store.IsRevoked(ctx, "expired")
+	require.NoError(t, err)
+	assert.False(t, revoked, "已過期的 token 不需要保留撤銷紀錄")
+
+	revoked, err = store.IsRevoked(ctx, "active")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+}
+
+func TestRequireVerifiedEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	repo := newTestUserRepository(t)
+	verifiedAt := time.Now().UTC()
+	require.NoError(t, repo.Create(ctx, &domain.User{
+		ID:              2,
+		Name:            "Jane Doe",
+		Email:           "jane@example.com",
+		Password:        "password123",
+		EmailVerifiedAt: &verifiedAt,
+	}))
+
+	keyring, err := NewKeyring(newTestHMACKey(t, "a"))
+	require.NoError(t, err)
+	manager := NewTokenManager(keyring, TokenManagerConfig{})
+
+	router := gin.New()
+	router.GET("/protected", AuthMiddleware(manager, repo), RequireVerifiedEmail(repo), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	request := func(t *testing.T, userID string) *httptest.ResponseRecorder {
+		t.Helper()
+		token, err := manager.GenerateAccessToken(ctx, userID)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("未驗證電子郵件返回 403", func(t *testing.T) {
+		rec := request(t, "1")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Contains(t, rec.Body.String(), "ErrEmailNotVerified")
+	})
+
+	t.Run("已驗證電子郵件可通過", func(t *testing.T) {
+		rec := request(t, "2")
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+}
+
+// fakeFailureRecorder 記錄 TokenFailureRecorder 收到的原因
+type fakeFailureRecorder struct {
+	reasons []string
+}
+
+func (r *fakeFailureRecorder) RecordTokenValidationFailure(reason string) {
+	r.reasons = append(r.reasons, reason)
+}
+
+func TestTokenManager_FailureRecorder(t *testing.T) {
+	ctx := context.Background()
+	userRepo := newTestUserRepository(t)
+
+	keyring, err := NewKeyring(newTestHMACKey(t, "a"))
+	require.NoError(t, err)
+	recorder := &fakeFailureRecorder{}
+	manager := NewTokenManager(keyring, TokenManagerConfig{AccessTokenTTL: time.Minute, FailureRecorder: recorder})
+
+	valid, err := manager.GenerateAccessToken(ctx, "1")
+	require.NoError(t, err)
+	expired := NewTokenManager(keyring, TokenManagerConfig{AccessTokenTTL: time.Minute})
+	expired.now = func() time.Time { return time.Now().UTC().Add(-time.Hour) }
+	expiredToken, err := expired.GenerateAccessToken(ctx, "1")
+	require.NoError(t, err)
+
+	_, err = manager.ValidateAccessToken(ctx, valid, userRepo)
+	require.NoError(t, err)
+	_, err = manager.ValidateAccessToken(ctx, expiredToken, userRepo)
+	require.ErrorIs(t, err, ErrExpiredToken)
+	_, err = manager.ValidateAccessToken(ctx, "not-a-jwt", userRepo)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	// Authorization 標頭格式錯誤也視為無效 token
+	router := gin.New()
+	router.GET("/protected", AuthMiddleware(manager, userRepo), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	req.Header.Set("Authorization", "Basic "+valid)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	assert.Equal(t, []string{"ErrExpiredToken", "ErrInvalidToken", "ErrInvalidToken"}, recorder.reasons)
+}
+
+func TestFailureReason(t *testing.T) {
+	assert.Equal(t, "ErrRevokedToken", FailureReason(fmt.Errorf("wrapped: %w", ErrRevokedToken)))
+	assert.Equal(t, "Other", FailureReason(errors.New("boom")))
+}
+
+func TestAuthMiddleware_PersonalAccessToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	userRepo := newTestUserRepository(t)
+	personalAccessTokenRepo := repository.NewInMemoryPersonalAccessTokenRepository()
+
+	keyring, err := NewKeyring(newTestHMACKey(t, "a"))
+	require.NoError(t, err)
+	recorder := &fakeFailureRecorder{}
+	manager := NewTokenManager(keyring, TokenManagerConfig{FailureRecorder: recorder, PersonalAccessTokens: personalAccessTokenRepo})
+
+	// createToken 建立 personal access token 並返回明文 token
+	createToken := func(t *testing.T, userID int, expiresAt *time.Time, scopes ...domain.Scope) (string, *domain.PersonalAccessToken) {
+		t.Helper()
+		token, tokenHash, err := GeneratePersonalAccessToken()
+		require.NoError(t, err)
+		personalAccessToken, err := domain.NewPersonalAccessToken(domain.PersonalAccessTokenParams{
+			UserID:    userID,
+			Name:      "ci",
+			TokenHash: tokenHash,
+			Scopes:    scopes,
+			ExpiresAt: expiresAt,
+			CreatedAt: time.Now().UTC(),
+		})
+		require.NoError(t, err)
+		require.NoError(t, personalAccessTokenRepo.Create(ctx, personalAccessToken))
+		return token, personalAccessToken
+	}
+
+	router := gin.New()
+	router.GET("/read", AuthMiddleware(manager, userRepo, domain.ScopePortalPagesRead), func(c *gin.Context) {
+		userID, err := GetUserIDFromContext(c)
+		require.NoError(t, err)
+		personalAccessToken, ok := GetPersonalAccessTokenFromContext(c)
+		require.True(t, ok)
+		c.String(http.StatusOK, "%s:%d", userID, personalAccessToken.ID)
+	})
+	router.GET("/write", AuthMiddleware(manager, userRepo, domain.ScopePortalPagesWrite), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	router.GET("/session", AuthMiddleware(manager, userRepo), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	request := func(t *testing.T, path, token string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("有所需的 scope 時通過並更新最後使用時間", func(t *testing.T) {
+		token, personalAccessToken := createToken(t, 1, nil, domain.ScopePortalPagesRead)
+
+		rec := request(t, "/read", token)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, fmt.Sprintf("1:%d", personalAccessToken.ID), rec.Body.String())
+
+		stored, err := personalAccessTokenRepo.Find(ctx, personalAccessToken.ID)
+		require.NoError(t, err)
+		assert.NotNil(t, stored.LastUsedAt)
+	})
+
+	t.Run("沒有所需的 scope 返回 403", func(t *testing.T) {
+		token, _ := createToken(t, 1, nil, domain.ScopePortalPagesRead)
+
+		rec := request(t, "/write", token)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Contains(t, rec.Body.String(), "ErrInsufficientScope")
+	})
+
+	t.Run("未宣告 scope 的路由不接受 personal access token", func(t *testing.T) {
+		token, _ := createToken(t, 1, nil, domain.Scopes...)
+
+		rec := request(t, "/session", token)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("登入取得的 access token 不受 scope 限制", func(t *testing.T) {
+		token, err := manager.GenerateAccessToken(ctx, "1")
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusNoContent, request(t, "/write", token).Code)
+	})
+
+	t.Run("已撤銷、已過期、使用者不存在或不存在的 token 返回 401", func(t *testing.T) {
+		revoked, personalAccessToken := createToken(t, 1, nil, domain.ScopePortalPagesRead)
+		require.NoError(t, personalAccessTokenRepo.Revoke(ctx, personalAccessToken))
+		past := time.Now().UTC().Add(-time.Minute)
+		expired, _ := createToken(t, 1, &past, domain.ScopePortalPagesRead)
+		orphaned, _ := createToken(t, 99, nil, domain.ScopePortalPagesRead)
+
+		for _, token := range []string{revoked, expired, orphaned, PersonalAccessTokenPrefix + "unknown"} {
+			assert.Equal(t, http.StatusUnauthorized, request(t, "/read", token).Code)
+		}
+	})
+
+	assert.Equal(t, []string{
+		"ErrInsufficientScope",
+		"ErrInsufficientScope",
+		"ErrRevokedToken",
+		"ErrExpiredToken",
+		"ErrUserNotFound",
+		"ErrInvalidToken",
+	}, recorder.reasons)
+}
@@ -0,0 +1,279 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 支援的簽章演算法
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+const (
+	// minHMACSecretLength HS256 金鑰的最短長度（bytes）
+	minHMACSecretLength = 32
+	// minRSAKeyBits RS256 金鑰的最短長度（bits）
+	minRSAKeyBits = 2048
+)
+
+var (
+	ErrInvalidKey       = errors.New("invalid signing key")
+	ErrKeyNotFound      = errors.New("key not found in keyring")
+	ErrDuplicateKeyID   = errors.New("key ID already exists in keyring")
+	ErrSigningKeyInUse  = errors.New("cannot remove the active signing key")
+	ErrVerificationOnly = errors.New("key cannot be used for signing")
+)
+
+// Key 代表 keyring 中的一把金鑰，以 kid 識別
+// 只有公鑰的 Key 僅能用於驗證簽章，無法用來簽發 token
+type Key struct {
+	ID        string
+	Algorithm string
+
+	signingKey   any
+	verifyingKey any
+}
+
+// NewHMACKey 建立 HS256 金鑰，secret 至少需要 32 bytes
+func NewHMACKey(id string, secret []byte) (*Key, error) {
+	if id == "" {
+		return nil, fmt.Errorf("%w: key ID is required", ErrInvalidKey)
+	}
+	if len(secret) < minHMACSecretLength {
+		return nil, fmt.Errorf("%w: HMAC secret must be at least %d bytes", ErrInvalidKey, minHMACSecretLength)
+	}
+
+	return &Key{
+		ID:           id,
+		Algorithm:    AlgHS256,
+		signingKey:   secret,
+		verifyingKey: secret,
+	}, nil
+}
+
+// NewRSAKey 建立可簽發與驗證的 RS256 金鑰
+func NewRSAKey(id string, privateKey *rsa.PrivateKey) (*Key, error) {
+	if privateKey == nil {
+		return nil, fmt.Errorf("%w: RSA private key is required", ErrInvalidKey)
+	}
+	key, err := NewRSAPublicKey(id, &privateKey.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	key.signingKey = privateKey
+	return key, nil
+}
+
+// NewRSAPublicKey 建立僅供驗證的 RS256 金鑰
+func NewRSAPublicKey(id string, publicKey *rsa.PublicKey) (*Key, error) {
+	if id == "" {
+		return nil, fmt.Errorf("%w: key ID is required", ErrInvalidKey)
+	}
+	if publicKey == nil {
+		return nil, fmt.Errorf("%w: RSA public key is required", ErrInvalidKey)
+	}
+	if publicKey.N.BitLen() < minRSAKeyBits {
+		return nil, fmt.Errorf("%w: RSA key must be at least %d bits", ErrInvalidKey, minRSAKeyBits)
+	}
+
+	return &Key{
+		ID:           id,
+		Algorithm:    AlgRS256,
+		verifyingKey: publicKey,
+	}, nil
+}
+
+// NewEd25519Key 建立可簽發與驗證的 EdDSA 金鑰
+func NewEd25519Key(id string, privateKey ed25519.PrivateKey) (*Key, error) {
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("%w: invalid Ed25519 private key", ErrInvalidKey)
+	}
+	key, err := NewEd25519PublicKey(id, privateKey.Public().(ed25519.PublicKey))
+	if err != nil {
+		return nil, err
+	}
+	key.signingKey = privateKey
+	return key, nil
+}
+
+// NewEd25519PublicKey 建立僅供驗證的 EdDSA 金鑰
+func NewEd25519PublicKey(id string, publicKey ed25519.PublicKey) (*Key, error) {
+	if id == "" {
+		return nil, fmt.Errorf("%w: key ID is required", ErrInvalidKey)
+	}
+	if len(publicKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("%w: invalid Ed25519 public key", ErrInvalidKey)
+	}
+
+	return &Key{
+		ID:           id,
+		Algorithm:    AlgEdDSA,
+		verifyingKey: publicKey,
+	}, nil
+}
+
+// ParseKeyPEM 依 alg 解析 PEM 編碼的 RS256 或 EdDSA 金鑰
+// 私鑰（PKCS#8，RSA 亦接受 PKCS#1）可簽發與驗證，公鑰（PKIX）只用於驗證
+func ParseKeyPEM(id, alg string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%w: no PEM block found", ErrInvalidKey)
+	}
+
+	var (
+		parsed any
+		err    error
+	)
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%w: unsupported PEM block type %q", ErrInvalidKey, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+
+	switch key := parsed.(type) {
+	case *rsa.PrivateKey:
+		if alg == AlgRS256 {
+			return NewRSAKey(id, key)
+		}
+	case *rsa.PublicKey:
+		if alg == AlgRS256 {
+			return NewRSAPublicKey(id, key)
+		}
+	case ed25519.PrivateKey:
+		if alg == AlgEdDSA {
+			return NewEd25519Key(id, key)
+		}
+	case ed25519.PublicKey:
+		if alg == AlgEdDSA {
+			return NewEd25519PublicKey(id, key)
+		}
+	}
+	return nil, fmt.Errorf("%w: %T cannot be used with %s", ErrInvalidKey, parsed, alg)
+}
+
+// CanSign 判斷金鑰是否可用於簽發 token
+func (k *Key) CanSign() bool {
+	return k.signingKey != nil
+}
+
+// signingMethod 取得金鑰對應的 JWT 簽章方法
+func (k *Key) signingMethod() jwt.SigningMethod {
+	return jwt.GetSigningMethod(k.Algorithm)
+}
+
+// Keyring 管理簽發用的金鑰與多把驗證用的金鑰
+//
+// 輪替流程：
+// 1. 透過 Add 加入新金鑰（此時舊 token 仍可驗證）
+// 2. 透過 Rotate 將新金鑰設為簽發金鑰
+// 3. 待舊金鑰簽發的 token 全部過期後，透過 Remove 移除舊金鑰
+type Keyring struct {
+	mu           sync.RWMutex
+	keys         map[string]*Key
+	signingKeyID string
+}
+
+// NewKeyring 建立新的 Keyring，signingKey 為目前用於簽發 token 的金鑰
+func NewKeyring(signingKey *Key, verificationKeys ...*Key) (*Keyring, error) {
+	if signingKey == nil {
+		return nil, fmt.Errorf("%w: signing key is required", ErrInvalidKey)
+	}
+	if !signingKey.CanSign() {
+		return nil, ErrVerificationOnly
+	}
+
+	keyring := &Keyring{
+		keys: map[string]*Key{signingKey.ID: signingKey},
+	}
+	keyring.signingKeyID = signingKey.ID
+
+	for _, key := range verificationKeys {
+		if err := keyring.Add(key); err != nil {
+			return nil, err
+		}
+	}
+
+	return keyring, nil
+}
+
+// Add 加入一把驗證用的金鑰
+func (r *Keyring) Add(key *Key) error {
+	if key == nil {
+		return fmt.Errorf("%w: key is required", ErrInvalidKey)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.keys[key.ID]; exists {
+		return ErrDuplicateKeyID
+	}
+	r.keys[key.ID] = key
+	return nil
+}
+
+// Rotate 將指定的金鑰設為簽發金鑰，先前的簽發金鑰仍保留供驗證
+func (r *Keyring) Rotate(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, exists := r.keys[id]
+	if !exists {
+		return ErrKeyNotFound
+	}
+	if !key.CanSign() {
+		return ErrVerificationOnly
+	}
+	r.signingKeyID = id
+	return nil
+}
+
+// Remove 移除指定的金鑰，之後以該金鑰簽發的 token 將無法通過驗證
+func (r *Keyring) Remove(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.keys[id]; !exists {
+		return ErrKeyNotFound
+	}
+	if id == r.signingKeyID {
+		return ErrSigningKeyInUse
+	}
+	delete(r.keys, id)
+	return nil
+}
+
+// SigningKey 取得目前的簽發金鑰
+func (r *Keyring) SigningKey() *Key {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.keys[r.signingKeyID]
+}
+
+// VerificationKey 根據 kid 取得驗證用的金鑰
+func (r *Keyring) VerificationKey(id string) (*Key, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, exists := r.keys[id]
+	return key, exists
+}
@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"portal_link/modules/user/domain"
+	"portal_link/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// PersonalAccessTokenPrefix personal access token 的前綴，用於與 JWT access token 區分，也方便掃描外洩的 token
+	PersonalAccessTokenPrefix = "plk_"
+	// ContextPersonalAccessTokenKey 用於在 gin.Context 中存儲 personal access token 的鍵值
+	ContextPersonalAccessTokenKey = "personalAccessToken"
+
+	// lastUsedUpdateInterval 最後使用時間的更新間隔，避免每個請求都寫入 repository
+	lastUsedUpdateInterval = time.Minute
+)
+
+// ErrInsufficientScope personal access token 沒有路由需要的權限
+var ErrInsufficientScope = errors.New("token does not have the required scope")
+
+// GeneratePersonalAccessToken 產生帶有 PersonalAccessTokenPrefix 的 personal access token
+// 回傳明文 token（只在建立時交給使用者）與其雜湊值（存入資料庫）
+func GeneratePersonalAccessToken() (token string, tokenHash string, err error) {
+	opaque, _, err := GenerateOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	token = PersonalAccessTokenPrefix + opaque
+	return token, HashOpaqueToken(token), nil
+}
+
+// IsPersonalAccessToken 判斷 Bearer token 是否為 personal access token
+func IsPersonalAccessToken(token string) bool {
+	return strings.HasPrefix(token, PersonalAccessTokenPrefix)
+}
+
+// validatePersonalAccessToken 驗證 personal access token，失敗時記錄原因
+func (m *TokenManager) validatePersonalAccessToken(ctx context.Context, token string, userRepo domain.UserRepository) (*domain.PersonalAccessToken, error) {
+	personalAccessToken, err := m.verifyPersonalAccessToken(ctx, token, userRepo)
+	if err != nil {
+		m.failureRecorder.RecordTokenValidationFailure(FailureReason(err))
+	}
+	return personalAccessToken, err
+}
+
+// verifyPersonalAccessToken 以雜湊值查詢 personal access token，並檢查撤銷、有效期與使用者是否存在
+func (m *TokenManager) verifyPersonalAccessToken(ctx context.Context, token string, userRepo domain.UserRepository) (*domain.PersonalAccessToken, error) {
+	if m.personalAccessTokens == nil {
+		return nil, ErrInvalidToken
+	}
+
+	personalAccessToken, err := m.personalAccessTokens.GetByTokenHash(ctx, HashOpaqueToken(token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidToken
+		}
+		return nil, fmt.Errorf("failed to get personal access token: %w", err)
+	}
+	if personalAccessToken.IsRevoked() {
+		return nil, ErrRevokedToken
+	}
+	if personalAccessToken.IsExpired(m.now()) {
+		return nil, ErrExpiredToken
+	}
+
+	// 檢查使用者是否存在於資料庫中
+	if _, err := userRepo.Find(ctx, personalAccessToken.UserID); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			logger.FromContext(ctx).Error("failed to find user of personal access token", "user_id", personalAccessToken.UserID, "error", err)
+		}
+		return nil, ErrUserNotFound
+	}
+
+	return personalAccessToken, nil
+}
+
+// markPersonalAccessTokenUsed 更新 personal access token 的最後使用時間，距離上次更新未滿 lastUsedUpdateInterval 時略過
+// 更新失敗不影響請求，只記錄日誌
+func (m *TokenManager) markPersonalAccessTokenUsed(ctx context.Context, personalAccessToken *domain.PersonalAccessToken) {
+	now := m.now()
+	if personalAccessToken.LastUsedAt != nil && now.Sub(*personalAccessToken.LastUsedAt) < lastUsedUpdateInterval {
+		return
+	}
+	if err := m.personalAccessTokens.MarkUsed(ctx, personalAccessToken.ID, now); err != nil {
+		logger.FromContext(ctx).Error("failed to update last used time of personal access token", "token_id", personalAccessToken.ID, "error", err)
+		return
+	}
+	personalAccessToken.LastUsedAt = &now
+}
+
+// GetPersonalAccessTokenFromContext 從 gin.Context 中取得目前請求使用的 personal access token
+// 以登入取得的 access token 驗證的請求返回 false
+func GetPersonalAccessTokenFromContext(c *gin.Context) (*domain.PersonalAccessToken, bool) {
+	value, exists := c.Get(ContextPersonalAccessTokenKey)
+	if !exists {
+		return nil, false
+	}
+	personalAccessToken, ok := value.(*domain.PersonalAccessToken)
+	return personalAccessToken, ok
+}
@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+const (
+	// opaqueTokenBytes 不透明 token（refresh token、密碼重設 token 等）的隨機位元組長度
+	opaqueTokenBytes = 32
+	// tokenFamilyIDBytes token family ID 的隨機位元組長度
+	tokenFamilyIDBytes = 16
+	// tokenIDBytes access token jti 的隨機位元組長度
+	tokenIDBytes = 16
+)
+
+// GenerateOpaqueToken 產生不透明（opaque）的隨機 token
+// 回傳明文 token（交給客戶端）與其雜湊值（存入資料庫）
+func GenerateOpaqueToken() (token string, tokenHash string, err error) {
+	buf := make([]byte, opaqueTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("failed to generate opaque token: %w", err)
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashOpaqueToken(token), nil
+}
+
+// HashOpaqueToken 計算不透明 token 的雜湊值，資料庫只保存雜湊值
+func HashOpaqueToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateRefreshToken 產生不透明（opaque）的 refresh token
+// 回傳明文 token（交給客戶端）與其雜湊值（存入資料庫）
+func GenerateRefreshToken() (token string, tokenHash string, err error) {
+	return GenerateOpaqueToken()
+}
+
+// HashRefreshToken 計算 refresh token 的雜湊值，資料庫只保存雜湊值
+func HashRefreshToken(token string) string {
+	return HashOpaqueToken(token)
+}
+
+// GenerateTokenFamilyID 產生 refresh token family 的識別碼
+// 同一次登入後輪替產生的 refresh token 都屬於同一個 family
+func GenerateTokenFamilyID() (string, error) {
+	buf := make([]byte, tokenFamilyIDBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token family ID: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// generateTokenID 產生 access token 的 jti，用於撤銷單一 token
+func generateTokenID() (string, error) {
+	buf := make([]byte, tokenIDBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token ID: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
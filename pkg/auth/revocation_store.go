@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RevocationStore 保存被撤銷的 access token 與每個使用者的 token 版本
+// AuthMiddleware 驗證 token 時會檢查此 store，可替換為 Redis 等共享儲存
+type RevocationStore interface {
+	// Revoke 撤銷指定 jti 的 access token，expiresAt 之後即可清除該筆紀錄
+	Revoke(ctx context.Context, tokenID string, expiresAt time.Time) error
+
+	// IsRevoked 判斷指定 jti 的 access token 是否已被撤銷
+	IsRevoked(ctx context.Context, tokenID string) (bool, error)
+
+	// TokenVersion 取得使用者目前的 token 版本，版本較舊的 token 一律失效
+	TokenVersion(ctx context.Context, userID string) (int, error)
+
+	// IncrementTokenVersion 遞增使用者的 token 版本，使所有已簽發的 token 失效
+	IncrementTokenVersion(ctx context.Context, userID string) (int, error)
+}
+
+var _ RevocationStore = (*InMemoryRevocationStore)(nil)
+
+// InMemoryRevocationStore 以記憶體實作的 RevocationStore，僅適用於單一實例部署
+type InMemoryRevocationStore struct {
+	mu       sync.RWMutex
+	revoked  map[string]time.Time // jti -> token 過期時間
+	versions map[string]int       // user ID -> token 版本
+	now      func() time.Time
+}
+
+// NewInMemoryRevocationStore 建立新的 InMemoryRevocationStore
+func NewInMemoryRevocationStore() *InMemoryRevocationStore {
+	return &InMemoryRevocationStore{
+		revoked:  make(map[string]time.Time),
+		versions: make(map[string]int),
+		now:      func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Revoke 撤銷指定 jti 的 access token，並順便清除已過期的紀錄
+func (s *InMemoryRevocationStore) Revoke(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for id, exp := range s.revoked {
+		if !now.Before(exp) {
+			delete(s.revoked, id)
+		}
+	}
+
+	if now.Before(expiresAt) {
+		s.revoked[tokenID] = expiresAt
+	}
+	return nil
+}
+
+// IsRevoked 判斷指定 jti 的 access token 是否已被撤銷
+func (s *InMemoryRevocationStore) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, revoked := s.revoked[tokenID]
+	return revoked, nil
+}
+
+// TokenVersion 取得使用者目前的 token 版本
+func (s *InMemoryRevocationStore) TokenVersion(ctx context.Context, userID string) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.versions[userID], nil
+}
+
+// IncrementTokenVersion 遞增使用者的 token 版本
+func (s *InMemoryRevocationStore) IncrementTokenVersion(ctx context.Context, userID string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.versions[userID]++
+	return s.versions[userID], nil
+}
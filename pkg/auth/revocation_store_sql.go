@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"portal_link/pkg/database"
+	"strconv"
+	"time"
+)
+
+var _ RevocationStore = (*SQLRevocationStore)(nil)
+
+// SQLRevocationStore 以資料庫實作的 RevocationStore（PostgreSQL 與 SQLite 共用），
+// 重新啟動後撤銷仍然有效，多個實例共用同一份紀錄
+type SQLRevocationStore struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// NewSQLRevocationStore 建立新的 SQLRevocationStore，需先建立 revoked_access_tokens 與 token_versions 資料表
+func NewSQLRevocationStore(db *sql.DB) *SQLRevocationStore {
+	return &SQLRevocationStore{
+		db:  db,
+		now: func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Revoke 撤銷指定 jti 的 access token，並順便清除已過期的紀錄
+func (s *SQLRevocationStore) Revoke(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	now := s.now()
+	conn := database.Conn(ctx, s.db)
+
+	if _, err := conn.ExecContext(ctx, `DELETE FROM revoked_access_tokens WHERE expires_at <= $1`, now); err != nil {
+		return fmt.Errorf("failed to prune revoked access tokens: %w", err)
+	}
+	if !now.Before(expiresAt) {
+		return nil
+	}
+
+	_, err := conn.ExecContext(ctx, `
+		INSERT INTO revoked_access_tokens (token_id, expires_at)
+		VALUES ($1, $2)
+		ON CONFLICT (token_id) DO NOTHING`,
+		tokenID, expiresAt.UTC(),
+	)
+	return err
+}
+
+// IsRevoked 判斷指定 jti 的 access token 是否已被撤銷
+func (s *SQLRevocationStore) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	var exists int
+	err := database.Conn(ctx, s.db).QueryRowContext(ctx, `SELECT 1 FROM revoked_access_tokens WHERE token_id = $1`, tokenID).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// TokenVersion 取得使用者目前的 token 版本，沒有紀錄時為 0
+func (s *SQLRevocationStore) TokenVersion(ctx context.Context, userID string) (int, error) {
+	id, err := strconv.Atoi(userID)
+	if err != nil {
+		return 0, fmt.Errorf("invalid user ID %q: %w", userID, err)
+	}
+
+	var version int
+	err = database.Conn(ctx, s.db).QueryRowContext(ctx, `SELECT version FROM token_versions WHERE user_id = $1`, id).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return version, err
+}
+
+// IncrementTokenVersion 原子地遞增使用者的 token 版本
+func (s *SQLRevocationStore) IncrementTokenVersion(ctx context.Context, userID string) (int, error) {
+	id, err := strconv.Atoi(userID)
+	if err != nil {
+		return 0, fmt.Errorf("invalid user ID %q: %w", userID, err)
+	}
+
+	var version int
+	err = database.Conn(ctx, s.db).QueryRowContext(ctx, `
+		INSERT INTO token_versions (user_id, version)
+		VALUES ($1, 1)
+		ON CONFLICT (user_id) DO UPDATE SET version = token_versions.version + 1
+		RETURNING version`, id,
+	).Scan(&version)
+	return version, err
+}
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"context"
+	"portal_link/pkg/database/databasetest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testRevocationStore 每一種 RevocationStore 實作都應滿足的行為，userID 須為已存在的使用者
+func testRevocationStore(t *testing.T, store RevocationStore, userID string) {
+	ctx := context.Background()
+
+	t.Run("撤銷 access token 並清除已過期的紀錄", func(t *testing.T) {
+		require.NoError(t, store.Revoke(ctx, "expired", time.Now().UTC().Add(-time.Minute)))
+		require.NoError(t, store.Revoke(ctx, "active", time.Now().UTC().Add(time.Minute)))
+		// 重複撤銷不會失敗
+		require.NoError(t, store.Revoke(ctx, "active", time.Now().UTC().Add(time.Minute)))
+
+		for tokenID, expected := range map[string]bool{"expired": false, "active": true, "unknown": false} {
+			revoked, err := store.IsRevoked(ctx, tokenID)
+			require.NoError(t, err)
+			assert.Equal(t, expected, revoked, tokenID)
+		}
+	})
+
+	t.Run("遞增使用者的 token 版本", func(t *testing.T) {
+		version, err := store.TokenVersion(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, 0, version)
+
+		for expected := 1; expected <= 2; expected++ {
+			version, err = store.IncrementTokenVersion(ctx, userID)
+			require.NoError(t, err)
+			assert.Equal(t, expected, version)
+		}
+
+		version, err = store.TokenVersion(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, version)
+	})
+}
+
+func TestInMemoryRevocationStore(t *testing.T) {
+	testRevocationStore(t, NewInMemoryRevocationStore(), "1")
+}
+
+func TestSQLRevocationStore(t *testing.T) {
+	for _, driver := range databasetest.Drivers {
+		t.Run(string(driver), func(t *testing.T) {
+			db := databasetest.Open(t, driver)
+			userID := strconv.Itoa(databasetest.InsertUser(t, db, "test@example.com"))
+
+			testRevocationStore(t, NewSQLRevocationStore(db), userID)
+
+			t.Run("重新建立 store 後撤銷仍然有效", func(t *testing.T) {
+				ctx := context.Background()
+				restarted := NewSQLRevocationStore(db)
+
+				revoked, err := restarted.IsRevoked(ctx, "active")
+				require.NoError(t, err)
+				assert.True(t, revoked)
+
+				version, err := restarted.TokenVersion(ctx, userID)
+				require.NoError(t, err)
+				assert.Equal(t, 2, version)
+			})
+		})
+	}
+}
@@ -0,0 +1,334 @@
+// Package config 載入並驗證伺服器設定
+//
+// 設定依序從預設值、設定檔、環境變數與命令列參數載入，後者覆寫前者。
+// 每個設定項目的 key、環境變數、命令列參數與預設值定義於 settings。
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"portal_link/pkg/auth"
+	"portal_link/pkg/database"
+	"portal_link/pkg/tracing"
+	"slices"
+	"time"
+)
+
+// Config 伺服器設定
+type Config struct {
+	Server   ServerConfig   `mapstructure:"server"`
+	CORS     CORSConfig     `mapstructure:"cors"`
+	Auth     AuthConfig     `mapstructure:"auth"`
+	Database DatabaseConfig `mapstructure:"database"`
+	Mail     MailConfig     `mapstructure:"mail"`
+	Log      LogConfig      `mapstructure:"log"`
+	Tracing  TracingConfig  `mapstructure:"tracing"`
+	OpenAPI  OpenAPIConfig  `mapstructure:"openapi"`
+}
+
+// ServerConfig HTTP 伺服器設定
+type ServerConfig struct {
+	// Addr 監聽位址，例如 :8080
+	Addr string `mapstructure:"addr"`
+	// ReadHeaderTimeout 讀取 request header 的逾時
+	ReadHeaderTimeout time.Duration `mapstructure:"read_header_timeout"`
+	// ReadTimeout 讀取整個 request 的逾時
+	ReadTimeout time.Duration `mapstructure:"read_timeout"`
+	// WriteTimeout 寫入 response 的逾時
+	WriteTimeout time.Duration `mapstructure:"write_timeout"`
+	// IdleTimeout keep-alive 連線閒置的逾時
+	IdleTimeout time.Duration `mapstructure:"idle_timeout"`
+	// ShutdownDelay 收到關閉訊號後，readiness 失敗到停止接受連線之間的等待時間
+	ShutdownDelay time.Duration `mapstructure:"shutdown_delay"`
+	// ShutdownTimeout 等待處理中請求完成的最長時間
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+	// TrustedProxies 信任的反向代理 IP 或 CIDR，只採用來自這些位址的 X-Forwarded-For 判斷用戶端 IP；未設定時不信任任何代理
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+}
+
+// CORSConfig 跨來源請求設定
+type CORSConfig struct {
+	// AllowOrigins 允許的來源，例如前端的 http://localhost:3000
+	AllowOrigins []string `mapstructure:"allow_origins"`
+}
+
+// AuthConfig 身份驗證設定
+type AuthConfig struct {
+	// JWTSecret 簽發 access token 的 HMAC 金鑰，至少 32 bytes；未設定時使用隨機金鑰
+	JWTSecret string `mapstructure:"jwt_secret" secret:"true"`
+	// JWTKeyID JWTSecret 的 key ID（JWT header 的 kid）
+	JWTKeyID string `mapstructure:"jwt_key_id"`
+	// VerificationKeys 輪替後仍需接受的舊 HMAC 金鑰，只用於驗證
+	VerificationKeys []KeyConfig `mapstructure:"verification_keys"`
+	// Keys 其他金鑰，支援 HS256、RS256 與 EdDSA；RS256 與 EdDSA 從 PEM 檔案或環境變數載入
+	Keys []JWTKeyConfig `mapstructure:"keys"`
+	// SigningKeyID 簽發 token 的金鑰 ID，需為 JWTKeyID 或 Keys 中的私鑰；未設定時為 JWTKeyID
+	SigningKeyID string `mapstructure:"signing_key_id"`
+	// AccessTokenTTL access token 的有效期
+	AccessTokenTTL time.Duration `mapstructure:"access_token_ttl"`
+	// RefreshTokenTTL refresh token 的有效期
+	RefreshTokenTTL time.Duration `mapstructure:"refresh_token_ttl"`
+	// RequireVerifiedEmail 建立與更新 Portal Page 是否需要已驗證電子郵件
+	RequireVerifiedEmail bool `mapstructure:"require_verified_email"`
+}
+
+// KeyConfig 一把 HMAC 金鑰
+type KeyConfig struct {
+	ID     string `mapstructure:"id"`
+	Secret string `mapstructure:"secret" secret:"true"`
+}
+
+// JWTKeyConfig auth.keys 中的一把金鑰
+type JWTKeyConfig struct {
+	// ID JWT header 的 kid
+	ID string `mapstructure:"id"`
+	// Algorithm 簽章演算法：HS256、RS256 或 EdDSA
+	Algorithm string `mapstructure:"alg"`
+	// Secret HS256 的金鑰，至少 32 bytes
+	Secret string `mapstructure:"secret" secret:"true"`
+	// PEMFile RS256 或 EdDSA 金鑰的 PEM 檔案路徑；私鑰可簽發與驗證，公鑰只用於驗證
+	PEMFile string `mapstructure:"pem_file"`
+	// PEMEnv 保存 PEM 內容的環境變數名稱，與 PEMFile 擇一
+	PEMEnv string `mapstructure:"pem_env"`
+}
+
+// Load 讀取金鑰內容並建立 auth.Key
+func (k JWTKeyConfig) Load() (*auth.Key, error) {
+	if k.Algorithm == auth.AlgHS256 {
+		return auth.NewHMACKey(k.ID, []byte(k.Secret))
+	}
+
+	var data []byte
+	if k.PEMFile != "" {
+		var err error
+		if data, err = os.ReadFile(k.PEMFile); err != nil {
+			return nil, fmt.Errorf("failed to read PEM file of key %q: %w", k.ID, err)
+		}
+	} else {
+		value, ok := os.LookupEnv(k.PEMEnv)
+		if !ok {
+			return nil, fmt.Errorf("environment variable %s of key %q is not set", k.PEMEnv, k.ID)
+		}
+		data = []byte(value)
+	}
+	return auth.ParseKeyPEM(k.ID, k.Algorithm, data)
+}
+
+// DatabaseConfig 資料保存設定
+type DatabaseConfig struct {
+	// Driver 資料庫種類：postgres 或 sqlite
+	Driver database.Driver `mapstructure:"driver"`
+	// URL 資料庫連線字串；未設定時資料保存在記憶體中
+	URL string `mapstructure:"url" secret:"dsn"`
+	// AutoMigrate 啟動時是否自動套用 migration
+	AutoMigrate bool `mapstructure:"auto_migrate"`
+	// TxMaxRetries 交易序列化失敗時最多重試的次數
+	TxMaxRetries int `mapstructure:"tx_max_retries"`
+}
+
+// InMemory 是否將資料保存在記憶體中
+func (c DatabaseConfig) InMemory() bool {
+	return c.URL == ""
+}
+
+// MailConfig 寄信設定
+type MailConfig struct {
+	// OutboxDir 信件寫入的目錄；未設定時信件只保存在記憶體中
+	OutboxDir string `mapstructure:"outbox_dir"`
+	// PasswordResetURL 前端重設密碼頁面的網址
+	PasswordResetURL string `mapstructure:"password_reset_url"`
+	// VerifyEmailURL 驗證電子郵件的網址
+	VerifyEmailURL string `mapstructure:"verify_email_url"`
+}
+
+// LogConfig 日誌設定
+type LogConfig struct {
+	// Level 日誌等級：debug、info、warn 或 error
+	Level string `mapstructure:"level"`
+}
+
+// TracingConfig OpenTelemetry tracing 設定
+type TracingConfig struct {
+	// Exporter span 的輸出方式：none 或 otlp
+	Exporter string `mapstructure:"exporter"`
+	// Endpoint OTLP/HTTP collector 的網址，例如 http://localhost:4318
+	Endpoint string `mapstructure:"endpoint"`
+	// ServiceName span 的 service.name
+	ServiceName string `mapstructure:"service_name"`
+	// SampleRatio 未帶有 trace context 的請求被取樣的比例，0 到 1
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+}
+
+// OpenAPIConfig 依 OpenAPI 規格驗證請求與回應的設定
+type OpenAPIConfig struct {
+	// ValidateRequests 是否拒絕不符合規格的請求
+	ValidateRequests bool `mapstructure:"validate_requests"`
+	// ValidateResponses 是否檢查回應是否符合規格，不符合時記錄 ERROR 日誌，用於開發與測試環境
+	ValidateResponses bool `mapstructure:"validate_responses"`
+}
+
+// logLevels 支援的日誌等級
+var logLevels = map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
+
+// Validate 驗證設定，返回所有不合法的項目
+func (c *Config) Validate() error {
+	var errs []error
+	invalid := func(key string, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+
+	if _, _, err := net.SplitHostPort(c.Server.Addr); err != nil {
+		invalid("server.addr", "must be host:port, got %q", c.Server.Addr)
+	}
+	for _, timeout := range []struct {
+		key   string
+		value time.Duration
+	}{
+		{"server.read_header_timeout", c.Server.ReadHeaderTimeout},
+		{"server.read_timeout", c.Server.ReadTimeout},
+		{"server.write_timeout", c.Server.WriteTimeout},
+		{"server.idle_timeout", c.Server.IdleTimeout},
+		{"server.shutdown_timeout", c.Server.ShutdownTimeout},
+	} {
+		if timeout.value <= 0 {
+			invalid(timeout.key, "must be positive")
+		}
+	}
+	if c.Server.ShutdownDelay < 0 {
+		invalid("server.shutdown_delay", "must not be negative")
+	}
+	for _, proxy := range c.Server.TrustedProxies {
+		if !isIPOrCIDR(proxy) {
+			invalid("server.trusted_proxies", "%q must be an IP address or CIDR", proxy)
+		}
+	}
+
+	if len(c.CORS.AllowOrigins) == 0 {
+		invalid("cors.allow_origins", "at least one origin is required")
+	}
+	for _, origin := range c.CORS.AllowOrigins {
+		if origin == "*" {
+			invalid("cors.allow_origins", "wildcard origin cannot be used with credentials")
+			continue
+		}
+		if !isOrigin(origin) {
+			invalid("cors.allow_origins", "%q must be an http(s) origin without a path", origin)
+		}
+	}
+
+	if c.Auth.JWTSecret != "" && len(c.Auth.JWTSecret) < 32 {
+		invalid("auth.jwt_secret", "must be at least 32 bytes")
+	}
+	if c.Auth.JWTKeyID == "" {
+		invalid("auth.jwt_key_id", "is required")
+	}
+	keyIDs := map[string]bool{c.Auth.JWTKeyID: true}
+	for i, key := range c.Auth.VerificationKeys {
+		if key.ID == "" {
+			invalid(fmt.Sprintf("auth.verification_keys[%d].id", i), "is required")
+		} else if keyIDs[key.ID] {
+			invalid(fmt.Sprintf("auth.verification_keys[%d].id", i), "duplicate key ID %q", key.ID)
+		}
+		keyIDs[key.ID] = true
+		if len(key.Secret) < 32 {
+			invalid(fmt.Sprintf("auth.verification_keys[%d].secret", i), "must be at least 32 bytes")
+		}
+	}
+	for i, key := range c.Auth.Keys {
+		prefix := fmt.Sprintf("auth.keys[%d]", i)
+		if key.ID == "" {
+			invalid(prefix+".id", "is required")
+		} else if keyIDs[key.ID] {
+			invalid(prefix+".id", "duplicate key ID %q", key.ID)
+		}
+		keyIDs[key.ID] = true
+
+		switch key.Algorithm {
+		case auth.AlgHS256:
+			if len(key.Secret) < 32 {
+				invalid(prefix+".secret", "must be at least 32 bytes")
+			}
+			if key.PEMFile != "" || key.PEMEnv != "" {
+				invalid(prefix, "pem_file and pem_env cannot be used with HS256")
+			}
+		case auth.AlgRS256, auth.AlgEdDSA:
+			if (key.PEMFile == "") == (key.PEMEnv == "") {
+				invalid(prefix, "exactly one of pem_file and pem_env is required for %s", key.Algorithm)
+			}
+			if key.Secret != "" {
+				invalid(prefix+".secret", "can only be used with HS256")
+			}
+		default:
+			invalid(prefix+".alg", "must be HS256, RS256 or EdDSA, got %q", key.Algorithm)
+		}
+	}
+	if id := c.Auth.SigningKeyID; id != "" && id != c.Auth.JWTKeyID && !slices.ContainsFunc(c.Auth.Keys, func(key JWTKeyConfig) bool { return key.ID == id }) {
+		invalid("auth.signing_key_id", "must be auth.jwt_key_id or the ID of an entry in auth.keys, got %q", id)
+	}
+	if c.Auth.AccessTokenTTL <= 0 {
+		invalid("auth.access_token_ttl", "must be positive")
+	}
+	if c.Auth.RefreshTokenTTL <= c.Auth.AccessTokenTTL {
+		invalid("auth.refresh_token_ttl", "must be longer than auth.access_token_ttl")
+	}
+
+	if _, err := database.ParseDriver(string(c.Database.Driver)); err != nil {
+		invalid("database.driver", "%v", err)
+	}
+	if c.Database.TxMaxRetries < 0 {
+		invalid("database.tx_max_retries", "must not be negative")
+	}
+
+	for _, link := range []struct{ key, value string }{
+		{"mail.password_reset_url", c.Mail.PasswordResetURL},
+		{"mail.verify_email_url", c.Mail.VerifyEmailURL},
+	} {
+		if u, err := url.Parse(link.value); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			invalid(link.key, "must be an absolute http(s) URL, got %q", link.value)
+		}
+	}
+
+	if !logLevels[c.Log.Level] {
+		invalid("log.level", "must be one of debug, info, warn or error, got %q", c.Log.Level)
+	}
+
+	switch c.Tracing.Exporter {
+	case tracing.ExporterNone:
+	case tracing.ExporterOTLP:
+		if u, err := url.Parse(c.Tracing.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			invalid("tracing.endpoint", "must be an absolute http(s) URL when tracing.exporter is otlp, got %q", c.Tracing.Endpoint)
+		}
+	default:
+		invalid("tracing.exporter", "must be none or otlp, got %q", c.Tracing.Exporter)
+	}
+	if c.Tracing.ServiceName == "" {
+		invalid("tracing.service_name", "is required")
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		invalid("tracing.sample_ratio", "must be between 0 and 1")
+	}
+
+	return errors.Join(errs...)
+}
+
+// isOrigin 判斷 value 是否為 scheme://host[:port] 形式的來源
+func isOrigin(value string) bool {
+	u, err := url.Parse(value)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" &&
+		(u.Path == "" || u.Path == "/") && u.RawQuery == "" && u.Fragment == "" && u.User == nil
+}
+
+// isIPOrCIDR 判斷 value 是否為 IP 位址或 CIDR
+func isIPOrCIDR(value string) bool {
+	if net.ParseIP(value) != nil {
+		return true
+	}
+	_, _, err := net.ParseCIDR(value)
+	return err == nil
+}
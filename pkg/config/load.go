@@ -0,0 +1,141 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"portal_link/pkg/auth"
+	"portal_link/pkg/database"
+	"portal_link/pkg/tracing"
+	"strings"
+	"time"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// ConfigFileEnv 指定設定檔路徑的環境變數，也可以使用 --config 參數
+const ConfigFileEnv = "CONFIG_FILE"
+
+// setting 一個設定項目
+// 環境變數名稱為 key 轉大寫並以 _ 取代 .（例如 server.addr 為 SERVER_ADDR），
+// legacyEnv 為同樣有效的舊名稱
+type setting struct {
+	key       string
+	value     any // 預設值
+	legacyEnv []string
+	flag      string // 命令列參數名稱，空字串表示只能由設定檔或環境變數設定
+	usage     string
+}
+
+// settings 所有設定項目；密鑰不提供命令列參數，避免出現在程序列表中
+var settings = []setting{
+	{key: "server.addr", value: ":8080", flag: "addr", usage: "HTTP listen address"},
+	{key: "server.read_header_timeout", value: 5 * time.Second},
+	{key: "server.read_timeout", value: 15 * time.Second},
+	{key: "server.write_timeout", value: 30 * time.Second},
+	{key: "server.idle_timeout", value: 60 * time.Second},
+	{key: "server.shutdown_delay", value: time.Duration(0), flag: "shutdown-delay", usage: "time between failing readiness and closing the listener on shutdown"},
+	{key: "server.shutdown_timeout", value: 30 * time.Second, flag: "shutdown-timeout", usage: "time to wait for in-flight requests on shutdown"},
+	{key: "server.trusted_proxies", value: []string(nil), flag: "trusted-proxies", usage: "comma separated IPs or CIDRs of reverse proxies trusted to set X-Forwarded-For"},
+	{key: "cors.allow_origins", value: []string{"http://localhost:3000"}, flag: "cors-allow-origins", usage: "comma separated CORS origins"},
+	{key: "auth.jwt_secret", value: "", legacyEnv: []string{"JWT_SECRET"}},
+	{key: "auth.jwt_key_id", value: "default", flag: "jwt-key-id", usage: "key ID of the JWT signing key"},
+	{key: "auth.verification_keys", value: []KeyConfig(nil)},
+	{key: "auth.keys", value: []JWTKeyConfig(nil)},
+	{key: "auth.signing_key_id", value: "", flag: "signing-key-id", usage: "key ID of the JWT signing key; defaults to jwt-key-id"},
+	{key: "auth.access_token_ttl", value: auth.TokenExpiration, flag: "access-token-ttl", usage: "access token lifetime"},
+	{key: "auth.refresh_token_ttl", value: auth.RefreshTokenExpiration, flag: "refresh-token-ttl", usage: "refresh token lifetime"},
+	{key: "auth.require_verified_email", value: false, legacyEnv: []string{"REQUIRE_VERIFIED_EMAIL"}, flag: "require-verified-email", usage: "require a verified email to create or update portal pages"},
+	{key: "database.driver", value: string(database.DriverPostgres), flag: "database-driver", usage: "database driver: postgres or sqlite"},
+	{key: "database.url", value: "", flag: "database-url", usage: "database DSN; data is kept in memory when empty"},
+	{key: "database.auto_migrate", value: true, flag: "database-auto-migrate", usage: "apply pending migrations at startup"},
+	{key: "database.tx_max_retries", value: 3, usage: "retries of a transaction after a serialization failure"},
+	{key: "mail.outbox_dir", value: "", flag: "mail-outbox-dir", usage: "directory emails are written to; kept in memory when empty"},
+	{key: "mail.password_reset_url", value: "http://localhost:3000/reset-password", legacyEnv: []string{"PASSWORD_RESET_URL"}},
+	{key: "mail.verify_email_url", value: "http://localhost:8080/api/v1/user/verify-email", legacyEnv: []string{"VERIFY_EMAIL_URL"}},
+	{key: "log.level", value: "info", flag: "log-level", usage: "log level: debug, info, warn or error"},
+	{key: "tracing.exporter", value: tracing.ExporterNone, flag: "tracing-exporter", usage: "span exporter: none or otlp"},
+	{key: "tracing.endpoint", value: "http://localhost:4318", legacyEnv: []string{"OTEL_EXPORTER_OTLP_ENDPOINT"}, flag: "tracing-endpoint", usage: "OTLP/HTTP collector URL"},
+	{key: "tracing.service_name", value: "portal_link", legacyEnv: []string{"OTEL_SERVICE_NAME"}},
+	{key: "tracing.sample_ratio", value: 1.0, flag: "tracing-sample-ratio", usage: "ratio of new traces that are sampled, between 0 and 1"},
+	{key: "openapi.validate_requests", value: true, flag: "openapi-validate-requests", usage: "reject requests that do not match the OpenAPI spec"},
+	{key: "openapi.validate_responses", value: false, flag: "openapi-validate-responses", usage: "log responses that do not match the OpenAPI spec (for development and tests)"},
+}
+
+// envName 設定項目的環境變數名稱
+func envName(key string) string {
+	return strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// Load 依序從預設值、設定檔、環境變數與 args 中的命令列參數載入設定並驗證
+// args 不含程式名稱；返回設定與命令列參數以外的位置參數（例如子命令）
+// args 包含 --help 時返回 pflag.ErrHelp，usage 會寫入 usageOut
+func Load(args []string, usageOut io.Writer) (*Config, []string, error) {
+	v := viper.New()
+
+	flags := pflag.NewFlagSet("portal_link", pflag.ContinueOnError)
+	flags.SetOutput(usageOut)
+	configFile := flags.String("config", "", "path to a YAML, JSON or TOML config file (env "+ConfigFileEnv+")")
+
+	for _, s := range settings {
+		v.SetDefault(s.key, s.value)
+		if err := v.BindEnv(append([]string{s.key, envName(s.key)}, s.legacyEnv...)...); err != nil {
+			return nil, nil, err
+		}
+		if s.flag != "" {
+			addFlag(flags, s)
+			if err := v.BindPFlag(s.key, flags.Lookup(s.flag)); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	path := *configFile
+	if path == "" {
+		path = os.Getenv(ConfigFileEnv)
+	}
+	if path != "" {
+		v.SetConfigFile(path)
+		if err := v.ReadInConfig(); err != nil {
+			return nil, nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+	}
+
+	var cfg Config
+	if err := v.Unmarshal(&cfg, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+	))); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("invalid config:\n%w", err)
+	}
+	return &cfg, flags.Args(), nil
+}
+
+// addFlag 依預設值的型態建立命令列參數
+func addFlag(flags *pflag.FlagSet, s setting) {
+	switch value := s.value.(type) {
+	case string:
+		flags.String(s.flag, value, s.usage)
+	case []string:
+		flags.StringSlice(s.flag, value, s.usage)
+	case bool:
+		flags.Bool(s.flag, value, s.usage)
+	case int:
+		flags.Int(s.flag, value, s.usage)
+	case float64:
+		flags.Float64(s.flag, value, s.usage)
+	case time.Duration:
+		flags.Duration(s.flag, value, s.usage)
+	default:
+		panic(fmt.Sprintf("config: unsupported flag type %T for %s", s.value, s.key))
+	}
+}
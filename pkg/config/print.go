@@ -0,0 +1,97 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Redacted 取代密鑰的文字
+const Redacted = "[REDACTED]"
+
+// dsnPasswordPattern key=value 形式 DSN 中的密碼，例如 host=db password=secret
+var dsnPasswordPattern = regexp.MustCompile(`(?i)(password=)('[^']*'|\S+)`)
+
+// Print 以 key = value 的形式逐行輸出實際使用的設定，密鑰以 [REDACTED] 取代
+// 標記 secret:"true" 的欄位不輸出內容；標記 secret:"dsn" 的欄位只遮蔽其中的密碼
+func (c *Config) Print(w io.Writer) error {
+	var lines []string
+	collect(reflect.ValueOf(*c), "", "", &lines)
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collect 依 mapstructure tag 展開 value，secret 為所屬欄位的 secret tag
+func collect(value reflect.Value, key string, secret string, lines *[]string) {
+	switch value.Kind() {
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			name := field.Tag.Get("mapstructure")
+			if key != "" {
+				name = key + "." + name
+			}
+			collect(value.Field(i), name, field.Tag.Get("secret"), lines)
+		}
+		return
+	case reflect.Slice:
+		if value.Type().Elem().Kind() == reflect.Struct {
+			if value.Len() == 0 {
+				*lines = append(*lines, key+" = []")
+			}
+			for i := 0; i < value.Len(); i++ {
+				collect(value.Index(i), fmt.Sprintf("%s[%d]", key, i), secret, lines)
+			}
+			return
+		}
+	}
+	*lines = append(*lines, key+" = "+format(value, secret))
+}
+
+// format 輸出單一欄位的值
+func format(value reflect.Value, secret string) string {
+	switch secret {
+	case "true":
+		if value.IsZero() {
+			return ""
+		}
+		return Redacted
+	case "dsn":
+		return redactDSN(value.String())
+	}
+
+	switch v := value.Interface().(type) {
+	case time.Duration:
+		return v.String()
+	case []string:
+		return strings.Join(v, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// redactDSN 遮蔽連線字串中的密碼，支援 URL 與 key=value 兩種形式
+func redactDSN(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && u.Scheme != "" && u.Opaque == "" {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), Redacted)
+		}
+		// URL 中的 password 參數也一併遮蔽
+		query := u.Query()
+		if query.Has("password") {
+			query.Set("password", Redacted)
+			u.RawQuery = query.Encode()
+		}
+		// 避免 [ ] 被轉義，輸出時還原
+		return strings.ReplaceAll(u.String(), url.PathEscape(Redacted), Redacted)
+	}
+	return dsnPasswordPattern.ReplaceAllString(dsn, "${1}"+Redacted)
+}
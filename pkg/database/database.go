@@ -0,0 +1,58 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Driver 資料庫種類
+type Driver string
+
+const (
+	// DriverPostgres PostgreSQL，DSN 為 PostgreSQL 連線字串
+	DriverPostgres Driver = "postgres"
+	// DriverSQLite 內嵌的 SQLite，DSN 為資料庫檔案路徑或 :memory:
+	DriverSQLite Driver = "sqlite"
+)
+
+// ParseDriver 解析資料庫種類，空字串時使用 PostgreSQL
+func ParseDriver(value string) (Driver, error) {
+	switch Driver(value) {
+	case "", DriverPostgres:
+		return DriverPostgres, nil
+	case DriverSQLite:
+		return DriverSQLite, nil
+	default:
+		return "", fmt.Errorf("unsupported database driver %q", value)
+	}
+}
+
+// Open 依照資料庫種類開啟連線並確認資料庫可以連線
+func Open(ctx context.Context, driver Driver, dsn string) (*sql.DB, error) {
+	switch driver {
+	case DriverPostgres:
+		return OpenPostgres(ctx, dsn)
+	case DriverSQLite:
+		return OpenSQLite(ctx, dsn)
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", driver)
+	}
+}
+
+// IsUniqueViolation 判斷 err 是否為違反唯一索引
+func IsUniqueViolation(err error) bool {
+	return isPostgresUniqueViolation(err) || isSQLiteUniqueViolation(err)
+}
+
+// ping 確認資料庫可以連線，失敗時關閉連線
+func ping(ctx context.Context, db *sql.DB) error {
+	pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := db.PingContext(pingCtx); err != nil {
+		return errors.Join(err, db.Close())
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsUniqueViolation(t *testing.T) {
+	t.Run("detects a wrapped postgres unique violation", func(t *testing.T) {
+		err := fmt.Errorf("insert: %w", &pgconn.PgError{Code: "23505", ConstraintName: "idx_users_email"})
+		assert.True(t, IsUniqueViolation(err))
+	})
+
+	t.Run("detects a sqlite unique violation", func(t *testing.T) {
+		ctx := context.Background()
+		db, err := OpenSQLite(ctx, SQLiteMemory)
+		require.NoError(t, err)
+		defer db.Close()
+		_, err = db.ExecContext(ctx, `CREATE TABLE users (email TEXT NOT NULL); CREATE UNIQUE INDEX idx_users_email ON users (email)`)
+		require.NoError(t, err)
+
+		insert := `INSERT INTO users (email) VALUES ('john@example.com')`
+		_, err = db.ExecContext(ctx, insert)
+		require.NoError(t, err)
+		_, err = db.ExecContext(ctx, insert)
+		assert.True(t, IsUniqueViolation(err))
+	})
+
+	t.Run("ignores other errors", func(t *testing.T) {
+		assert.False(t, IsUniqueViolation(&pgconn.PgError{Code: "23503", ConstraintName: "portal_pages_user_id_fkey"}))
+		assert.False(t, IsUniqueViolation(errors.New("boom")))
+		assert.False(t, IsUniqueViolation(nil))
+	})
+}
+
+func TestOpenSQLite(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("enforces foreign keys", func(t *testing.T) {
+		db, err := OpenSQLite(ctx, SQLiteMemory)
+		require.NoError(t, err)
+		defer db.Close()
+		_, err = db.ExecContext(ctx, `
+			CREATE TABLE users (id INTEGER PRIMARY KEY);
+			CREATE TABLE portal_pages (id INTEGER PRIMARY KEY, user_id INTEGER NOT NULL REFERENCES users (id))`)
+		require.NoError(t, err)
+
+		_, err = db.ExecContext(ctx, `INSERT INTO portal_pages (user_id) VALUES (999)`)
+		assert.Error(t, err)
+	})
+
+	t.Run("persists to a file", func(t *testing.T) {
+		path := t.TempDir() + "/portal_link.db"
+
+		db, err := OpenSQLite(ctx, path)
+		require.NoError(t, err)
+		_, err = db.ExecContext(ctx, `CREATE TABLE users (email TEXT NOT NULL); INSERT INTO users (email) VALUES ('john@example.com')`)
+		require.NoError(t, err)
+		require.NoError(t, db.Close())
+
+		reopened, err := OpenSQLite(ctx, path)
+		require.NoError(t, err)
+		defer reopened.Close()
+
+		var count int
+		require.NoError(t, reopened.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`).Scan(&count))
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("requires a path", func(t *testing.T) {
+		_, err := OpenSQLite(ctx, "")
+		assert.Error(t, err)
+	})
+}
+
+func TestParseDriver(t *testing.T) {
+	driver, err := ParseDriver("")
+	require.NoError(t, err)
+	assert.Equal(t, DriverPostgres, driver)
+
+	driver, err = ParseDriver("sqlite")
+	require.NoError(t, err)
+	assert.Equal(t, DriverSQLite, driver)
+
+	_, err = ParseDriver("mysql")
+	assert.Error(t, err)
+}
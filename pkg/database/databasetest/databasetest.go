@@ -0,0 +1,122 @@
+// Package databasetest 提供連線到測試用資料庫的輔助函式
+package databasetest
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"os"
+	"portal_link/pkg/database"
+	"portal_link/pkg/database/migrate"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// PostgresDSNEnv 測試用 PostgreSQL 連線字串的環境變數
+const PostgresDSNEnv = "POSTGRES_TEST_DSN"
+
+// Drivers 所有支援的資料庫種類，用於對每一種資料庫執行相同的測試
+var Drivers = []database.Driver{database.DriverSQLite, database.DriverPostgres}
+
+// Open 開啟已套用所有 migration 的測試用資料庫，測試結束後關閉
+// PostgreSQL 未設定 POSTGRES_TEST_DSN 時略過測試
+func Open(t *testing.T, driver database.Driver) *sql.DB {
+	t.Helper()
+
+	db := OpenEmpty(t, driver)
+	migrator, err := migrate.New(db, driver)
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return db
+}
+
+// OpenEmpty 開啟沒有任何資料表的測試用資料庫，測試結束後關閉
+// SQLite 使用記憶體資料庫；PostgreSQL 在 POSTGRES_TEST_DSN 指定的資料庫中建立獨立的 schema，
+// 測試結束後刪除該 schema，讓不同 package 的測試可以平行執行
+func OpenEmpty(t *testing.T, driver database.Driver) *sql.DB {
+	t.Helper()
+
+	switch driver {
+	case database.DriverPostgres:
+		return openPostgres(t)
+	case database.DriverSQLite:
+		return openSQLite(t)
+	default:
+		t.Fatalf("unsupported database driver %q", driver)
+		return nil
+	}
+}
+
+// InsertUser 在測試用資料庫中建立一個使用者並返回其 ID，用於需要滿足 users 外鍵的測試
+func InsertUser(t *testing.T, db *sql.DB, email string) int {
+	t.Helper()
+
+	var id int
+	err := db.QueryRow(`INSERT INTO users (name, email, password) VALUES ('Test', $1, 'hash') RETURNING id`, email).Scan(&id)
+	if err != nil {
+		t.Fatalf("failed to insert user: %v", err)
+	}
+	return id
+}
+
+func openSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := database.OpenSQLite(context.Background(), database.SQLiteMemory)
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func openPostgres(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dsn := os.Getenv(PostgresDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set, skipping PostgreSQL tests", PostgresDSNEnv)
+	}
+
+	ctx := context.Background()
+	admin, err := database.OpenPostgres(ctx, dsn)
+	if err != nil {
+		t.Fatalf("failed to open postgres: %v", err)
+	}
+	t.Cleanup(func() { admin.Close() })
+
+	schema := "test_" + randomSuffix(t)
+	if _, err := admin.ExecContext(ctx, "CREATE SCHEMA "+schema); err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.ExecContext(context.Background(), "DROP SCHEMA "+schema+" CASCADE"); err != nil {
+			t.Errorf("failed to drop schema: %v", err)
+		}
+	})
+
+	config, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		t.Fatalf("failed to parse dsn: %v", err)
+	}
+	config.RuntimeParams["search_path"] = schema
+	db := stdlib.OpenDB(*config)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func randomSuffix(t *testing.T) string {
+	t.Helper()
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatalf("failed to generate schema name: %v", err)
+	}
+	return hex.EncodeToString(b)
+}
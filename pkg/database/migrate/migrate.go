@@ -0,0 +1,371 @@
+// Package migrate 管理資料庫的版本化 schema migration
+//
+// Migration 檔案內嵌於 migrations 目錄，命名規則為 <version>_<name>.<up|down>[.<driver>].sql：
+// 沒有 driver 後綴的檔案由所有資料庫共用，有後綴的檔案只用於該資料庫並優先於共用檔案。
+// 已套用的 migration 記錄在 schema_migrations 資料表，並以 checksum 確認檔案在套用後沒有被修改。
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"portal_link/pkg/database"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var embedded embed.FS
+
+// ErrChecksumMismatch 已套用的 migration 檔案內容與資料庫中記錄的 checksum 不一致
+var ErrChecksumMismatch = errors.New("migration checksum mismatch")
+
+// ErrUnknownMigration 資料庫中記錄了此版本程式沒有的 migration
+var ErrUnknownMigration = errors.New("unknown migration applied")
+
+// advisoryLockKey 避免多個程序同時執行 PostgreSQL migration 的 advisory lock key
+const advisoryLockKey = 727_465_616
+
+const createMigrationsTable = `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    BIGINT PRIMARY KEY,
+		name       VARCHAR(255) NOT NULL,
+		checksum   VARCHAR(64) NOT NULL,
+		applied_at TIMESTAMP NOT NULL
+	)`
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)(?:\.([a-z]+))?\.sql$`)
+
+// Migration 單一版本的 schema 變更
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string // Up SQL 的 SHA-256
+}
+
+// Status 單一 migration 的套用狀態
+type Status struct {
+	Migration
+	AppliedAt        *time.Time
+	ChecksumMismatch bool
+}
+
+// Migrator 對資料庫套用或回滾 migration
+type Migrator struct {
+	db         *sql.DB
+	driver     database.Driver
+	migrations []Migration
+}
+
+// New 以內嵌的 migration 檔案建立 Migrator
+func New(db *sql.DB, driver database.Driver) (*Migrator, error) {
+	migrations, err := Load(embedded, "migrations", driver)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, driver: driver, migrations: migrations}, nil
+}
+
+// Load 從 fsys 的 dir 目錄讀取 driver 使用的 migration，依版本升冪排序
+func Load(fsys fs.FS, dir string, driver database.Driver) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	type files struct {
+		name     string
+		up, down string
+	}
+	byVersion := map[int64]*files{}
+
+	for _, entry := range entries {
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		name, direction, fileDriver := match[2], match[3], database.Driver(match[4])
+		if fileDriver != "" && fileDriver != database.DriverPostgres && fileDriver != database.DriverSQLite {
+			return nil, fmt.Errorf("migration %q targets unsupported driver %q", entry.Name(), fileDriver)
+		}
+		if fileDriver != "" && fileDriver != driver {
+			continue
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %q: %w", entry.Name(), err)
+		}
+
+		f, exists := byVersion[version]
+		if !exists {
+			f = &files{name: name}
+			byVersion[version] = f
+		}
+		if f.name != name {
+			return nil, fmt.Errorf("migration version %d has conflicting names %q and %q", version, f.name, name)
+		}
+
+		// driver 專用的檔案優先於共用檔案
+		specific := fileDriver != ""
+		switch direction {
+		case "up":
+			if f.up == "" || specific {
+				f.up = string(content)
+			}
+		case "down":
+			if f.down == "" || specific {
+				f.down = string(content)
+			}
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for version, f := range byVersion {
+		if f.up == "" || f.down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down files for %s", version, f.name, driver)
+		}
+		sum := sha256.Sum256([]byte(f.up))
+		migrations = append(migrations, Migration{
+			Version:  version,
+			Name:     f.name,
+			Up:       f.up,
+			Down:     f.down,
+			Checksum: hex.EncodeToString(sum[:]),
+		})
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Migrations 返回所有 migration，依版本升冪排序
+func (m *Migrator) Migrations() []Migration {
+	return append([]Migration(nil), m.migrations...)
+}
+
+// Up 依序套用所有尚未套用的 migration，返回本次套用的 migration
+// 已套用的 migration 被修改過時返回 ErrChecksumMismatch 且不套用任何 migration
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := m.withConn(ctx, func(conn *sql.Conn) error {
+		records, err := m.records(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := m.verify(records); err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if _, done := records[migration.Version]; done {
+				continue
+			}
+			if err := m.apply(ctx, conn, migration); err != nil {
+				return err
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down 依版本降冪回滾最近套用的 steps 個 migration，返回本次回滾的 migration
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("steps must be positive, got %d", steps)
+	}
+
+	var reverted []Migration
+	err := m.withConn(ctx, func(conn *sql.Conn) error {
+		records, err := m.records(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := m.verify(records); err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := m.migrations[i]
+			if _, done := records[migration.Version]; !done {
+				continue
+			}
+			if err := m.revert(ctx, conn, migration); err != nil {
+				return err
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status 返回每個 migration 的套用狀態，依版本升冪排序
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+	err := m.withConn(ctx, func(conn *sql.Conn) error {
+		records, err := m.records(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			status := Status{Migration: migration}
+			if record, done := records[migration.Version]; done {
+				appliedAt := record.appliedAt
+				status.AppliedAt = &appliedAt
+				status.ChecksumMismatch = record.checksum != migration.Checksum
+			}
+			statuses = append(statuses, status)
+		}
+		return nil
+	})
+	return statuses, err
+}
+
+// Pending 返回尚未套用的 migration，依版本升冪排序，用於 readiness 檢查
+// 與 Status 不同，Pending 不取得 migration lock 也不建立 schema_migrations，
+// 其他程序執行 migration 時不會被阻塞；已套用的 migration 被修改過時返回 ErrChecksumMismatch
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	records, err := m.records(ctx, conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	if err := m.verify(records); err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, migration := range m.migrations {
+		if _, done := records[migration.Version]; !done {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// record schema_migrations 中的一筆紀錄
+type record struct {
+	checksum  string
+	appliedAt time.Time
+}
+
+// withConn 在單一連線上執行 fn；PostgreSQL 會以 advisory lock 避免多個程序同時執行 migration
+func (m *Migrator) withConn(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if m.driver == database.DriverPostgres {
+		if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, advisoryLockKey); err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, advisoryLockKey)
+	}
+
+	if _, err := conn.ExecContext(ctx, createMigrationsTable); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	return fn(conn)
+}
+
+// records 讀取已套用的 migration
+func (m *Migrator) records(ctx context.Context, conn *sql.Conn) (map[int64]record, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version, checksum, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := map[int64]record{}
+	for rows.Next() {
+		var (
+			version int64
+			r       record
+		)
+		if err := rows.Scan(&version, &r.checksum, &r.appliedAt); err != nil {
+			return nil, err
+		}
+		r.appliedAt = r.appliedAt.UTC()
+		records[version] = r
+	}
+	return records, rows.Err()
+}
+
+// verify 確認已套用的 migration 都存在且沒有被修改
+func (m *Migrator) verify(records map[int64]record) error {
+	known := make(map[int64]Migration, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = migration
+	}
+
+	for version, r := range records {
+		migration, exists := known[version]
+		if !exists {
+			return fmt.Errorf("%w: version %d", ErrUnknownMigration, version)
+		}
+		if r.checksum != migration.Checksum {
+			return fmt.Errorf("%w: %d_%s", ErrChecksumMismatch, migration.Version, migration.Name)
+		}
+	}
+	return nil
+}
+
+// apply 在交易中套用 migration 並寫入紀錄
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	return inTx(ctx, conn, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+			return fmt.Errorf("failed to apply migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES ($1, $2, $3, $4)`,
+			migration.Version, migration.Name, migration.Checksum, time.Now().UTC(),
+		)
+		return err
+	})
+}
+
+// revert 在交易中回滾 migration 並刪除紀錄
+func (m *Migrator) revert(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	return inTx(ctx, conn, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, migration.Down); err != nil {
+			return fmt.Errorf("failed to revert migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		_, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, migration.Version)
+		return err
+	})
+}
+
+func inTx(ctx context.Context, conn *sql.Conn, fn func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,249 @@
+package migrate_test
+
+import (
+	"context"
+	"database/sql"
+	"portal_link/pkg/database"
+	"portal_link/pkg/database/databasetest"
+	"portal_link/pkg/database/migrate"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrator(t *testing.T) {
+	for _, driver := range databasetest.Drivers {
+		t.Run(string(driver), func(t *testing.T) {
+			testMigrator(t, driver)
+		})
+	}
+}
+
+func testMigrator(t *testing.T, driver database.Driver) {
+	ctx := context.Background()
+
+	newMigrator := func(t *testing.T) (*sql.DB, *migrate.Migrator) {
+		db := databasetest.OpenEmpty(t, driver)
+		migrator, err := migrate.New(db, driver)
+		require.NoError(t, err)
+		return db, migrator
+	}
+
+	t.Run("up applies every pending migration once", func(t *testing.T) {
+		db, migrator := newMigrator(t)
+
+		applied, err := migrator.Up(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, migrator.Migrations(), applied)
+		assert.True(t, tableExists(t, db, "links"))
+
+		applied, err = migrator.Up(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, applied)
+	})
+
+	t.Run("status reports applied and pending migrations", func(t *testing.T) {
+		_, migrator := newMigrator(t)
+
+		statuses, err := migrator.Status(ctx)
+		require.NoError(t, err)
+		require.Len(t, statuses, len(migrator.Migrations()))
+		for _, status := range statuses {
+			assert.Nil(t, status.AppliedAt)
+		}
+
+		_, err = migrator.Up(ctx)
+		require.NoError(t, err)
+
+		statuses, err = migrator.Status(ctx)
+		require.NoError(t, err)
+		for _, status := range statuses {
+			assert.NotNil(t, status.AppliedAt)
+			assert.False(t, status.ChecksumMismatch)
+		}
+	})
+
+	t.Run("pending lists migrations not applied yet", func(t *testing.T) {
+		db, migrator := newMigrator(t)
+
+		// 尚未建立 schema_migrations 時返回錯誤且不建立資料表
+		_, err := migrator.Pending(ctx)
+		assert.Error(t, err)
+		assert.False(t, tableExists(t, db, "schema_migrations"))
+
+		_, err = migrator.Up(ctx)
+		require.NoError(t, err)
+		pending, err := migrator.Pending(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, pending)
+
+		_, err = migrator.Down(ctx, 1)
+		require.NoError(t, err)
+		pending, err = migrator.Pending(ctx)
+		require.NoError(t, err)
+		migrations := migrator.Migrations()
+		assert.Equal(t, migrations[len(migrations)-1:], pending)
+	})
+
+	t.Run("down reverts the latest migrations", func(t *testing.T) {
+		db, migrator := newMigrator(t)
+		_, err := migrator.Up(ctx)
+		require.NoError(t, err)
+		migrations := migrator.Migrations()
+
+		reverted, err := migrator.Down(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, reverted, 1)
+		assert.Equal(t, migrations[len(migrations)-1].Version, reverted[0].Version)
+		assert.False(t, tableExists(t, db, "login_attempts"))
+		assert.True(t, tableExists(t, db, "email_verification_tokens"))
+
+		statuses, err := migrator.Status(ctx)
+		require.NoError(t, err)
+		assert.Nil(t, statuses[len(statuses)-1].AppliedAt)
+
+		reverted, err = migrator.Down(ctx, len(migrations)+1)
+		require.NoError(t, err)
+		assert.Len(t, reverted, len(migrations)-1)
+		assert.False(t, tableExists(t, db, "users"))
+
+		// 全部回滾後可以重新套用
+		applied, err := migrator.Up(ctx)
+		require.NoError(t, err)
+		assert.Len(t, applied, len(migrations))
+	})
+
+	t.Run("down rejects non-positive steps", func(t *testing.T) {
+		_, migrator := newMigrator(t)
+
+		_, err := migrator.Down(ctx, 0)
+		assert.Error(t, err)
+	})
+
+	t.Run("refuses to run when an applied migration was modified", func(t *testing.T) {
+		db, migrator := newMigrator(t)
+		_, err := migrator.Up(ctx)
+		require.NoError(t, err)
+
+		_, err = db.ExecContext(ctx, `UPDATE schema_migrations SET checksum = 'modified' WHERE version = 1`)
+		require.NoError(t, err)
+
+		_, err = migrator.Up(ctx)
+		assert.ErrorIs(t, err, migrate.ErrChecksumMismatch)
+		_, err = migrator.Down(ctx, 1)
+		assert.ErrorIs(t, err, migrate.ErrChecksumMismatch)
+		_, err = migrator.Pending(ctx)
+		assert.ErrorIs(t, err, migrate.ErrChecksumMismatch)
+
+		statuses, err := migrator.Status(ctx)
+		require.NoError(t, err)
+		assert.True(t, statuses[0].ChecksumMismatch)
+		assert.False(t, statuses[1].ChecksumMismatch)
+	})
+
+	t.Run("refuses to run when the database has an unknown migration", func(t *testing.T) {
+		db, migrator := newMigrator(t)
+		_, err := migrator.Up(ctx)
+		require.NoError(t, err)
+
+		_, err = db.ExecContext(ctx,
+			`INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (9999, 'from_the_future', 'x', CURRENT_TIMESTAMP)`)
+		require.NoError(t, err)
+
+		_, err = migrator.Up(ctx)
+		assert.ErrorIs(t, err, migrate.ErrUnknownMigration)
+	})
+
+	t.Run("rolls back a failing migration", func(t *testing.T) {
+		db, migrator := newMigrator(t)
+		_, err := db.ExecContext(ctx, `CREATE TABLE users (id INTEGER PRIMARY KEY)`)
+		require.NoError(t, err)
+
+		applied, err := migrator.Up(ctx)
+		assert.Error(t, err)
+		assert.Empty(t, applied)
+
+		statuses, err := migrator.Status(ctx)
+		require.NoError(t, err)
+		assert.Nil(t, statuses[0].AppliedAt)
+	})
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("prefers driver specific files and sorts by version", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"m/0002_second.up.sql":         {Data: []byte("CREATE TABLE b (id INTEGER);")},
+			"m/0002_second.down.sql":       {Data: []byte("DROP TABLE b;")},
+			"m/0001_first.up.sql":          {Data: []byte("CREATE TABLE a (id INTEGER);")},
+			"m/0001_first.up.sqlite.sql":   {Data: []byte("CREATE TABLE a (id INTEGER PRIMARY KEY AUTOINCREMENT);")},
+			"m/0001_first.up.postgres.sql": {Data: []byte("CREATE TABLE a (id SERIAL PRIMARY KEY);")},
+			"m/0001_first.down.sql":        {Data: []byte("DROP TABLE a;")},
+			"m/0001_first.down.sqlite.sql": {Data: []byte("DROP TABLE IF EXISTS a;")},
+		}
+
+		migrations, err := migrate.Load(fsys, "m", database.DriverSQLite)
+		require.NoError(t, err)
+		require.Len(t, migrations, 2)
+		assert.Equal(t, int64(1), migrations[0].Version)
+		assert.Equal(t, "first", migrations[0].Name)
+		assert.Equal(t, "CREATE TABLE a (id INTEGER PRIMARY KEY AUTOINCREMENT);", migrations[0].Up)
+		assert.Equal(t, "DROP TABLE IF EXISTS a;", migrations[0].Down)
+		assert.Equal(t, int64(2), migrations[1].Version)
+
+		migrations, err = migrate.Load(fsys, "m", database.DriverPostgres)
+		require.NoError(t, err)
+		assert.Equal(t, "CREATE TABLE a (id SERIAL PRIMARY KEY);", migrations[0].Up)
+		assert.Equal(t, "DROP TABLE a;", migrations[0].Down)
+	})
+
+	t.Run("checksum changes with the up sql", func(t *testing.T) {
+		load := func(up string) migrate.Migration {
+			migrations, err := migrate.Load(fstest.MapFS{
+				"m/0001_first.up.sql":   {Data: []byte(up)},
+				"m/0001_first.down.sql": {Data: []byte("DROP TABLE a;")},
+			}, "m", database.DriverSQLite)
+			require.NoError(t, err)
+			return migrations[0]
+		}
+
+		assert.Equal(t, load("CREATE TABLE a (id INTEGER);").Checksum, load("CREATE TABLE a (id INTEGER);").Checksum)
+		assert.NotEqual(t, load("CREATE TABLE a (id INTEGER);").Checksum, load("CREATE TABLE a (id TEXT);").Checksum)
+	})
+
+	t.Run("rejects invalid migration sets", func(t *testing.T) {
+		cases := map[string]fstest.MapFS{
+			"invalid file name": {
+				"m/first.up.sql": {Data: []byte("SELECT 1;")},
+			},
+			"missing down file": {
+				"m/0001_first.up.sql": {Data: []byte("SELECT 1;")},
+			},
+			"conflicting names": {
+				"m/0001_first.up.sql":   {Data: []byte("SELECT 1;")},
+				"m/0001_other.down.sql": {Data: []byte("SELECT 1;")},
+			},
+			"unsupported driver": {
+				"m/0001_first.up.mysql.sql": {Data: []byte("SELECT 1;")},
+			},
+		}
+		for name, fsys := range cases {
+			t.Run(name, func(t *testing.T) {
+				_, err := migrate.Load(fsys, "m", database.DriverSQLite)
+				assert.Error(t, err)
+			})
+		}
+	})
+}
+
+func tableExists(t *testing.T, db *sql.DB, table string) bool {
+	t.Helper()
+	// 以查詢資料表是否成功判斷存在與否，SQLite 與 PostgreSQL 皆適用
+	rows, err := db.Query(`SELECT 1 FROM ` + table + ` WHERE 1 = 0`)
+	if err != nil {
+		return false
+	}
+	rows.Close()
+	return true
+}
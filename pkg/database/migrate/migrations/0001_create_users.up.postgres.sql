@@ -0,0 +1,11 @@
+CREATE TABLE users (
+    id                SERIAL PRIMARY KEY,
+    name              VARCHAR(255) NOT NULL,
+    email             VARCHAR(255) NOT NULL,
+    password          VARCHAR(255) NOT NULL,
+    email_verified_at TIMESTAMP NULL,
+    created_at        TIMESTAMP DEFAULT (now() AT TIME ZONE 'utc'),
+    updated_at        TIMESTAMP DEFAULT (now() AT TIME ZONE 'utc')
+);
+CREATE UNIQUE INDEX idx_users_email ON users (email);
+CREATE INDEX idx_users_created_at ON users (created_at);
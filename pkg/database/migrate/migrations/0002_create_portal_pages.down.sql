@@ -0,0 +1 @@
+DROP TABLE portal_pages;
@@ -0,0 +1,14 @@
+CREATE TABLE portal_pages (
+    id                INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id           INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    slug              VARCHAR(255) NOT NULL,
+    title             VARCHAR(255) NOT NULL,
+    bio               TEXT,
+    profile_image_url VARCHAR(500),
+    theme             VARCHAR(100),
+    created_at        TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at        TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_portal_pages_user_id ON portal_pages (user_id);
+CREATE UNIQUE INDEX idx_portal_pages_slug ON portal_pages (slug);
+CREATE INDEX idx_portal_pages_created_at ON portal_pages (created_at);
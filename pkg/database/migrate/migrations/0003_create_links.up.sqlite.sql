@@ -0,0 +1,13 @@
+CREATE TABLE links (
+    id             INTEGER PRIMARY KEY AUTOINCREMENT,
+    portal_page_id INTEGER NOT NULL REFERENCES portal_pages (id) ON DELETE CASCADE,
+    title          VARCHAR(255) NOT NULL,
+    url            VARCHAR(500) NOT NULL,
+    description    TEXT,
+    icon_url       VARCHAR(500),
+    display_order  INTEGER NOT NULL,
+    created_at     TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at     TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_links_portal_page_id ON links (portal_page_id);
+CREATE INDEX idx_links_created_at ON links (created_at);
@@ -0,0 +1,5 @@
+CREATE TABLE revoked_access_tokens (
+    token_id   VARCHAR(255) PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL
+);
+CREATE INDEX idx_revoked_access_tokens_expires_at ON revoked_access_tokens (expires_at);
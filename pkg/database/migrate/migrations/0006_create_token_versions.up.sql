@@ -0,0 +1,4 @@
+CREATE TABLE token_versions (
+    user_id INTEGER PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    version INTEGER NOT NULL
+);
@@ -0,0 +1,7 @@
+CREATE TABLE login_attempts (
+    key             VARCHAR(255) PRIMARY KEY,
+    failures        INTEGER NOT NULL,
+    last_failure_at TIMESTAMP NOT NULL,
+    locked_until    TIMESTAMP NULL
+);
+CREATE INDEX idx_login_attempts_last_failure_at ON login_attempts (last_failure_at);
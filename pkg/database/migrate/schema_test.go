@@ -0,0 +1,302 @@
+package migrate_test
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"portal_link/pkg/database"
+	"portal_link/pkg/database/databasetest"
+	"portal_link/pkg/database/migrate"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dbmlPath 資料庫設計文件，migration 套用後的 schema 必須與其一致
+const dbmlPath = "../../../docs/dbml/schema.dbml"
+
+// schema 資料表、欄位、索引與外鍵的比較用表示法
+type schema struct {
+	Columns     map[string][]string // table -> "name type [not null]"
+	Indexes     map[string][]string // table -> "name (columns) [unique]"
+	ForeignKeys []string            // "table.column > table.column [cascade]"
+}
+
+func TestMigratedSchemaMatchesDBML(t *testing.T) {
+	expected := parseDBML(t, dbmlPath)
+
+	for _, driver := range databasetest.Drivers {
+		t.Run(string(driver), func(t *testing.T) {
+			db := databasetest.OpenEmpty(t, driver)
+			migrator, err := migrate.New(db, driver)
+			require.NoError(t, err)
+			_, err = migrator.Up(context.Background())
+			require.NoError(t, err)
+
+			var actual schema
+			switch driver {
+			case database.DriverSQLite:
+				actual = inspectSQLite(t, db)
+			case database.DriverPostgres:
+				actual = inspectPostgres(t, db)
+			}
+
+			assert.Equal(t, expected.Columns, actual.Columns)
+			assert.Equal(t, expected.Indexes, actual.Indexes)
+			assert.Equal(t, expected.ForeignKeys, actual.ForeignKeys)
+		})
+	}
+}
+
+var (
+	dbmlTablePattern  = regexp.MustCompile(`^Table (\w+) \{$`)
+	dbmlColumnPattern = regexp.MustCompile(`^(\w+) ([\w()]+)(?: \[(.*)\])?$`)
+	dbmlIndexPattern  = regexp.MustCompile(`^(\w+|\([\w, ]+\)) \[(.*)\]$`)
+	dbmlRefPattern    = regexp.MustCompile(`^Ref: (\w+)\.(\w+) > (\w+)\.(\w+)(?: \[(.*)\])?$`)
+	dbmlNamePattern   = regexp.MustCompile(`name: "(\w+)"`)
+)
+
+// parseDBML 解析 schema.dbml 中本專案使用到的語法：Table、欄位設定、indexes 與 Ref
+func parseDBML(t *testing.T, path string) schema {
+	t.Helper()
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	s := schema{Columns: map[string][]string{}, Indexes: map[string][]string{}}
+	var table string
+	inIndexes := false
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+		case line == "}":
+			if inIndexes {
+				inIndexes = false
+			} else {
+				table = ""
+			}
+		case dbmlTablePattern.MatchString(line):
+			table = dbmlTablePattern.FindStringSubmatch(line)[1]
+		case table != "" && line == "indexes {":
+			inIndexes = true
+		case inIndexes:
+			match := dbmlIndexPattern.FindStringSubmatch(line)
+			require.NotNil(t, match, "unsupported dbml index %q", line)
+			settings := splitSettings(match[2])
+			name := dbmlNamePattern.FindStringSubmatch(match[2])
+			require.NotNil(t, name, "dbml index %q must be named", line)
+			columns := strings.Trim(match[1], "()")
+			s.Indexes[table] = append(s.Indexes[table], formatIndex(name[1], strings.Split(columns, ","), settings["unique"]))
+		case table != "":
+			match := dbmlColumnPattern.FindStringSubmatch(line)
+			require.NotNil(t, match, "unsupported dbml column %q", line)
+			settings := splitSettings(match[3])
+			notNull := settings["not null"] || settings["primary key"]
+			s.Columns[table] = append(s.Columns[table], formatColumn(match[1], match[2], notNull))
+		case strings.HasPrefix(line, "Ref:"):
+			match := dbmlRefPattern.FindStringSubmatch(line)
+			require.NotNil(t, match, "unsupported dbml ref %q", line)
+			cascade := strings.Contains(match[5], "delete: cascade")
+			s.ForeignKeys = append(s.ForeignKeys, formatForeignKey(match[1], match[2], match[3], match[4], cascade))
+		default:
+			t.Fatalf("unsupported dbml line %q", line)
+		}
+	}
+	require.NoError(t, scanner.Err())
+
+	return s.sorted()
+}
+
+// splitSettings 取出欄位或索引設定中不含值的旗標，例如 not null、unique、primary key
+func splitSettings(settings string) map[string]bool {
+	flags := map[string]bool{}
+	for _, setting := range strings.Split(settings, ",") {
+		setting = strings.TrimSpace(setting)
+		if setting != "" && !strings.Contains(setting, ":") {
+			flags[setting] = true
+		}
+	}
+	return flags
+}
+
+func inspectSQLite(t *testing.T, db *sql.DB) schema {
+	t.Helper()
+
+	s := schema{Columns: map[string][]string{}, Indexes: map[string][]string{}}
+	for _, table := range queryStrings(t, db, `SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' AND name <> 'schema_migrations'`) {
+		rows, err := db.Query(`SELECT name, type, "notnull", pk FROM pragma_table_info($1)`, table)
+		require.NoError(t, err)
+		for rows.Next() {
+			var (
+				name, typ   string
+				notNull, pk int
+			)
+			require.NoError(t, rows.Scan(&name, &typ, &notNull, &pk))
+			s.Columns[table] = append(s.Columns[table], formatColumn(name, typ, notNull == 1 || pk > 0))
+		}
+		require.NoError(t, rows.Close())
+
+		// origin 'c' 為 CREATE INDEX 建立的索引，排除主鍵與 UNIQUE 限制自動建立的索引
+		rows, err = db.Query(`SELECT name, "unique" FROM pragma_index_list($1) WHERE origin = 'c'`, table)
+		require.NoError(t, err)
+		type index struct {
+			name   string
+			unique bool
+		}
+		var indexes []index
+		for rows.Next() {
+			var i index
+			require.NoError(t, rows.Scan(&i.name, &i.unique))
+			indexes = append(indexes, i)
+		}
+		require.NoError(t, rows.Close())
+		for _, i := range indexes {
+			columns := queryStrings(t, db, `SELECT name FROM pragma_index_info($1) ORDER BY seqno`, i.name)
+			s.Indexes[table] = append(s.Indexes[table], formatIndex(i.name, columns, i.unique))
+		}
+
+		rows, err = db.Query(`SELECT "from", "table", "to", on_delete FROM pragma_foreign_key_list($1)`, table)
+		require.NoError(t, err)
+		for rows.Next() {
+			var from, refTable, to, onDelete string
+			require.NoError(t, rows.Scan(&from, &refTable, &to, &onDelete))
+			s.ForeignKeys = append(s.ForeignKeys, formatForeignKey(table, from, refTable, to, onDelete == "CASCADE"))
+		}
+		require.NoError(t, rows.Close())
+	}
+	return s.sorted()
+}
+
+func inspectPostgres(t *testing.T, db *sql.DB) schema {
+	t.Helper()
+
+	s := schema{Columns: map[string][]string{}, Indexes: map[string][]string{}}
+
+	rows, err := db.Query(`
+		SELECT table_name, column_name, data_type, character_maximum_length, is_nullable
+		FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name <> 'schema_migrations'`)
+	require.NoError(t, err)
+	for rows.Next() {
+		var (
+			table, name, typ, nullable string
+			length                     sql.NullInt64
+		)
+		require.NoError(t, rows.Scan(&table, &name, &typ, &length, &nullable))
+		switch typ {
+		case "character varying":
+			typ = fmt.Sprintf("varchar(%d)", length.Int64)
+		case "timestamp without time zone":
+			typ = "timestamp"
+		}
+		s.Columns[table] = append(s.Columns[table], formatColumn(name, typ, nullable == "NO"))
+	}
+	require.NoError(t, rows.Close())
+
+	// 排除主鍵索引，其餘索引皆由 CREATE INDEX 建立
+	rows, err = db.Query(`
+		SELECT t.relname, i.relname, ix.indisunique, array_to_string(ARRAY(
+			SELECT a.attname FROM unnest(ix.indkey) WITH ORDINALITY AS k(attnum, ord)
+			JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+			ORDER BY k.ord
+		), ',')
+		FROM pg_index ix
+		JOIN pg_class t ON t.oid = ix.indrelid
+		JOIN pg_class i ON i.oid = ix.indexrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		WHERE n.nspname = current_schema() AND NOT ix.indisprimary AND t.relname <> 'schema_migrations'`)
+	require.NoError(t, err)
+	for rows.Next() {
+		var (
+			table, name, columns string
+			unique               bool
+		)
+		require.NoError(t, rows.Scan(&table, &name, &unique, &columns))
+		s.Indexes[table] = append(s.Indexes[table], formatIndex(name, strings.Split(columns, ","), unique))
+	}
+	require.NoError(t, rows.Close())
+
+	rows, err = db.Query(`
+		SELECT kcu.table_name, kcu.column_name, ccu.table_name, ccu.column_name, rc.delete_rule
+		FROM information_schema.referential_constraints rc
+		JOIN information_schema.key_column_usage kcu
+			ON kcu.constraint_schema = rc.constraint_schema AND kcu.constraint_name = rc.constraint_name
+		JOIN information_schema.constraint_column_usage ccu
+			ON ccu.constraint_schema = rc.unique_constraint_schema AND ccu.constraint_name = rc.unique_constraint_name
+		WHERE rc.constraint_schema = current_schema()`)
+	require.NoError(t, err)
+	for rows.Next() {
+		var table, column, refTable, refColumn, deleteRule string
+		require.NoError(t, rows.Scan(&table, &column, &refTable, &refColumn, &deleteRule))
+		s.ForeignKeys = append(s.ForeignKeys, formatForeignKey(table, column, refTable, refColumn, deleteRule == "CASCADE"))
+	}
+	require.NoError(t, rows.Close())
+
+	return s.sorted()
+}
+
+func queryStrings(t *testing.T, db *sql.DB, query string, args ...any) []string {
+	t.Helper()
+
+	rows, err := db.Query(query, args...)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var value string
+		require.NoError(t, rows.Scan(&value))
+		values = append(values, value)
+	}
+	require.NoError(t, rows.Err())
+	return values
+}
+
+func formatColumn(name, typ string, notNull bool) string {
+	column := name + " " + strings.ToLower(typ)
+	if notNull {
+		column += " not null"
+	}
+	return column
+}
+
+func formatIndex(name string, columns []string, unique bool) string {
+	for i := range columns {
+		columns[i] = strings.TrimSpace(columns[i])
+	}
+	index := fmt.Sprintf("%s (%s)", name, strings.Join(columns, ", "))
+	if unique {
+		index += " unique"
+	}
+	return index
+}
+
+func formatForeignKey(table, column, refTable, refColumn string, cascade bool) string {
+	fk := fmt.Sprintf("%s.%s > %s.%s", table, column, refTable, refColumn)
+	if cascade {
+		fk += " cascade"
+	}
+	return fk
+}
+
+// sorted 排序所有項目，使比較結果不受資料庫返回順序影響
+func (s schema) sorted() schema {
+	for _, columns := range s.Columns {
+		sort.Strings(columns)
+	}
+	for _, indexes := range s.Indexes {
+		sort.Strings(indexes)
+	}
+	sort.Strings(s.ForeignKeys)
+	return s
+}
@@ -0,0 +1,66 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// SQLiteMemory 使用記憶體資料庫的 DSN，關閉連線後資料即消失
+const SQLiteMemory = ":memory:"
+
+// OpenSQLite 開啟 SQLite 資料庫（純 Go 實作，不需要 cgo）
+// dsn 為資料庫檔案路徑，或使用 SQLiteMemory 開啟記憶體資料庫
+func OpenSQLite(ctx context.Context, dsn string) (*sql.DB, error) {
+	if dsn == "" {
+		return nil, errors.New("sqlite database path is required")
+	}
+
+	db, err := sql.Open("sqlite", sqliteDSN(dsn))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite: %w", err)
+	}
+
+	// SQLite 同一時間只允許一個寫入者；記憶體資料庫則是每個連線各自獨立，
+	// 因此固定使用單一連線，且不讓連線因閒置而被關閉
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxIdleTime(0)
+	db.SetConnMaxLifetime(0)
+
+	if err := ping(ctx, db); err != nil {
+		return nil, fmt.Errorf("failed to open sqlite: %w", err)
+	}
+	return db, nil
+}
+
+// sqliteDSN 將檔案路徑轉換為啟用外鍵（ON DELETE CASCADE）的連線字串
+func sqliteDSN(path string) string {
+	pragmas := url.Values{}
+	pragmas.Add("_pragma", "foreign_keys(1)")
+	pragmas.Add("_pragma", "busy_timeout(5000)")
+	if path == SQLiteMemory {
+		return "file::memory:?" + pragmas.Encode()
+	}
+
+	pragmas.Add("_pragma", "journal_mode(WAL)")
+	path = strings.TrimPrefix(path, "file:")
+	return "file:" + path + "?" + pragmas.Encode()
+}
+
+func isSQLiteUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+}
+
+// isSQLiteBusy 判斷 err 是否為資料庫被其他連線鎖定（包含 SQLITE_BUSY 的延伸錯誤代碼）
+func isSQLiteBusy(err error) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code()&0xff == sqlite3.SQLITE_BUSY
+}
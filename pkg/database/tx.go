@@ -0,0 +1,107 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"portal_link/pkg/logger"
+	"portal_link/pkg/transaction"
+	"time"
+)
+
+// Executor *sql.DB 與 *sql.Tx 共同的查詢方法
+type Executor interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type txKey struct{}
+
+// Conn 返回 ctx 中由 TxManager 開啟的交易，不在交易中時返回 db
+// SQL repository 的每個查詢都應透過 Conn 執行，才能加入用例的交易
+// （SQLite 只有一條連線，交易期間直接使用 db 會等待交易結束而卡住）
+func Conn(ctx context.Context, db *sql.DB) Executor {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+// WithTx 在交易中執行 fn，fn 返回錯誤或 panic 時回滾交易
+// ctx 已在 TxManager 的交易中時直接使用該交易，由外層決定提交或回滾
+func WithTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(tx)
+	}
+	return runTx(ctx, db, nil, fn)
+}
+
+// runTx 開啟交易執行 fn，fn 返回錯誤或 panic 時回滾，否則提交
+func runTx(ctx context.Context, db *sql.DB, opts *sql.TxOptions, fn func(tx *sql.Tx) error) (err error) {
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+var _ transaction.TxManager = (*TxManager)(nil)
+
+// TxManager 以 SQL 交易實作 transaction.TxManager
+// PostgreSQL 使用 SERIALIZABLE 隔離等級，序列化失敗或死結時重新執行整個交易
+type TxManager struct {
+	db         *sql.DB
+	opts       *sql.TxOptions
+	maxRetries int
+}
+
+// NewTxManager 建立 TxManager，maxRetries 為序列化失敗時最多重試的次數，0 表示不重試
+func NewTxManager(db *sql.DB, driver Driver, maxRetries int) *TxManager {
+	var opts *sql.TxOptions
+	if driver == DriverPostgres {
+		opts = &sql.TxOptions{Isolation: sql.LevelSerializable}
+	}
+	return &TxManager{db: db, opts: opts, maxRetries: maxRetries}
+}
+
+// WithinTx 在交易中執行 fn，fn 返回錯誤或 panic 時回滾交易
+// 已在交易中時加入外層交易；序列化失敗時 fn 可能被執行多次，fn 內不應有資料庫以外的副作用
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := runTx(ctx, m.db, m.opts, func(tx *sql.Tx) error {
+			return fn(context.WithValue(ctx, txKey{}, tx))
+		})
+		if err == nil || !IsSerializationFailure(err) || attempt >= m.maxRetries {
+			return err
+		}
+		logger.FromContext(ctx).Debug("retrying transaction after serialization failure", "attempt", attempt+1, "error", err)
+
+		// 等待一小段時間再重試，避免互相衝突的交易再次同時執行
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w (last error: %v)", ctx.Err(), err)
+		case <-time.After(time.Duration(attempt+1) * 10 * time.Millisecond):
+		}
+	}
+}
+
+// IsSerializationFailure 判斷 err 是否為重新執行交易即可能成功的錯誤，例如序列化失敗或死結
+func IsSerializationFailure(err error) bool {
+	return isPostgresSerializationFailure(err) || isSQLiteBusy(err)
+}
@@ -0,0 +1,165 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openTestSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := OpenSQLite(context.Background(), SQLiteMemory)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(`CREATE TABLE items (name TEXT NOT NULL)`)
+	require.NoError(t, err)
+	return db
+}
+
+func countItems(t *testing.T, db *sql.DB) int {
+	t.Helper()
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM items`).Scan(&count))
+	return count
+}
+
+func insertItem(ctx context.Context, db *sql.DB, name string) error {
+	_, err := Conn(ctx, db).ExecContext(ctx, `INSERT INTO items (name) VALUES ($1)`, name)
+	return err
+}
+
+func TestTxManager_WithinTx(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("commits when fn succeeds", func(t *testing.T) {
+		db := openTestSQLite(t)
+		manager := NewTxManager(db, DriverSQLite, 0)
+
+		err := manager.WithinTx(ctx, func(ctx context.Context) error {
+			if err := insertItem(ctx, db, "a"); err != nil {
+				return err
+			}
+			return insertItem(ctx, db, "b")
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, countItems(t, db))
+	})
+
+	t.Run("rolls back when fn fails", func(t *testing.T) {
+		db := openTestSQLite(t)
+		manager := NewTxManager(db, DriverSQLite, 0)
+		boom := errors.New("boom")
+
+		err := manager.WithinTx(ctx, func(ctx context.Context) error {
+			if err := insertItem(ctx, db, "a"); err != nil {
+				return err
+			}
+			return boom
+		})
+		assert.ErrorIs(t, err, boom)
+		assert.Zero(t, countItems(t, db))
+	})
+
+	t.Run("rolls back and re-panics when fn panics", func(t *testing.T) {
+		db := openTestSQLite(t)
+		manager := NewTxManager(db, DriverSQLite, 0)
+
+		assert.PanicsWithValue(t, "boom", func() {
+			_ = manager.WithinTx(ctx, func(ctx context.Context) error {
+				if err := insertItem(ctx, db, "a"); err != nil {
+					return err
+				}
+				panic("boom")
+			})
+		})
+
+		// 交易已回滾並釋放唯一的連線
+		assert.Zero(t, countItems(t, db))
+	})
+
+	t.Run("nested transactions join the outer transaction", func(t *testing.T) {
+		db := openTestSQLite(t)
+		manager := NewTxManager(db, DriverSQLite, 0)
+		boom := errors.New("boom")
+
+		err := manager.WithinTx(ctx, func(ctx context.Context) error {
+			if err := manager.WithinTx(ctx, func(ctx context.Context) error {
+				return insertItem(ctx, db, "inner")
+			}); err != nil {
+				return err
+			}
+			// repository 內部的 WithTx 也加入同一個交易
+			if err := WithTx(ctx, db, func(tx *sql.Tx) error {
+				_, err := tx.ExecContext(ctx, `INSERT INTO items (name) VALUES ('repository')`)
+				return err
+			}); err != nil {
+				return err
+			}
+			return boom
+		})
+		assert.ErrorIs(t, err, boom)
+		assert.Zero(t, countItems(t, db))
+	})
+
+	t.Run("retries serialization failures", func(t *testing.T) {
+		db := openTestSQLite(t)
+		manager := NewTxManager(db, DriverSQLite, 3)
+
+		attempts := 0
+		err := manager.WithinTx(ctx, func(ctx context.Context) error {
+			attempts++
+			if err := insertItem(ctx, db, fmt.Sprintf("attempt %d", attempts)); err != nil {
+				return err
+			}
+			if attempts < 3 {
+				return &pgconn.PgError{Code: pgSerializationFailure}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, attempts)
+		// 失敗的嘗試皆已回滾
+		assert.Equal(t, 1, countItems(t, db))
+	})
+
+	t.Run("gives up after max retries", func(t *testing.T) {
+		db := openTestSQLite(t)
+		manager := NewTxManager(db, DriverSQLite, 2)
+
+		attempts := 0
+		err := manager.WithinTx(ctx, func(ctx context.Context) error {
+			attempts++
+			return &pgconn.PgError{Code: pgDeadlockDetected}
+		})
+		assert.True(t, IsSerializationFailure(err))
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("does not retry other errors", func(t *testing.T) {
+		db := openTestSQLite(t)
+		manager := NewTxManager(db, DriverSQLite, 3)
+
+		attempts := 0
+		err := manager.WithinTx(ctx, func(ctx context.Context) error {
+			attempts++
+			return errors.New("boom")
+		})
+		assert.Error(t, err)
+		assert.Equal(t, 1, attempts)
+	})
+}
+
+func TestIsSerializationFailure(t *testing.T) {
+	assert.True(t, IsSerializationFailure(fmt.Errorf("commit: %w", &pgconn.PgError{Code: "40001"})))
+	assert.True(t, IsSerializationFailure(&pgconn.PgError{Code: "40P01"}))
+	assert.False(t, IsSerializationFailure(&pgconn.PgError{Code: "23505"}))
+	assert.False(t, IsSerializationFailure(errors.New("boom")))
+	assert.False(t, IsSerializationFailure(nil))
+}
@@ -0,0 +1,144 @@
+// Package health 提供 liveness（/healthz）與 readiness（/readyz）端點
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"portal_link/pkg/database/migrate"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultTimeout readiness 檢查的預設逾時
+const DefaultTimeout = 2 * time.Second
+
+const (
+	StatusOK       = "ok"
+	StatusFail     = "fail"
+	StatusDraining = "draining"
+)
+
+// Check 單一 readiness 檢查，Run 返回 nil 表示就緒
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Response /healthz 與 /readyz 的回應
+type Response struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// CheckResult 單一檢查的結果
+type CheckResult struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Handler 處理 liveness 與 readiness 請求
+type Handler struct {
+	checks   []Check
+	timeout  time.Duration
+	draining atomic.Bool
+}
+
+// NewHandler 建立 Handler，timeout 為所有檢查共用的逾時，0 表示 DefaultTimeout
+func NewHandler(timeout time.Duration, checks ...Check) *Handler {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Handler{checks: checks, timeout: timeout}
+}
+
+// Register 註冊 GET /healthz 與 GET /readyz
+func (h *Handler) Register(e *gin.Engine) {
+	e.GET("/healthz", h.Liveness)
+	e.GET("/readyz", h.Readiness)
+}
+
+// Drain 標記伺服器即將關閉，之後 readiness 一律回應 503，讓負載平衡器停止導入新請求
+func (h *Handler) Drain() {
+	h.draining.Store(true)
+}
+
+// Liveness 程序能處理請求即回應 200，不檢查外部依賴，避免依賴故障時被不必要地重啟
+func (h *Handler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, Response{Status: StatusOK})
+}
+
+// Readiness 同時執行所有檢查，全部通過時回應 200，否則回應 503
+func (h *Handler) Readiness(c *gin.Context) {
+	if h.draining.Load() {
+		c.JSON(http.StatusServiceUnavailable, Response{Status: StatusDraining})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.timeout)
+	defer cancel()
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		results = make(map[string]CheckResult, len(h.checks))
+	)
+	for _, check := range h.checks {
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+			start := time.Now()
+			err := check.Run(ctx)
+
+			result := CheckResult{Status: StatusOK, Duration: time.Since(start).String()}
+			if err != nil {
+				result.Status = StatusFail
+				result.Error = err.Error()
+			}
+			mu.Lock()
+			results[check.Name] = result
+			mu.Unlock()
+		}(check)
+	}
+	wg.Wait()
+
+	response := Response{Status: StatusOK, Checks: results}
+	code := http.StatusOK
+	for _, result := range results {
+		if result.Status != StatusOK {
+			response.Status = StatusFail
+			code = http.StatusServiceUnavailable
+		}
+	}
+	c.JSON(code, response)
+}
+
+// DatabaseCheck 檢查資料庫連線
+func DatabaseCheck(db *sql.DB) Check {
+	return Check{
+		Name: "database",
+		Run:  db.PingContext,
+	}
+}
+
+// MigrationCheck 檢查所有 migration 皆已套用且未被修改
+func MigrationCheck(migrator *migrate.Migrator) Check {
+	return Check{
+		Name: "migrations",
+		Run: func(ctx context.Context) error {
+			pending, err := migrator.Pending(ctx)
+			if err != nil {
+				return err
+			}
+			if len(pending) > 0 {
+				return fmt.Errorf("%d pending migrations, first is %d_%s", len(pending), pending[0].Version, pending[0].Name)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,112 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"portal_link/pkg/database/databasetest"
+	"portal_link/pkg/database/migrate"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func serve(t *testing.T, handler *Handler, path string) (int, Response) {
+	t.Helper()
+	r := gin.New()
+	handler.Register(r)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var response Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return w.Code, response
+}
+
+func TestHandler(t *testing.T) {
+	ok := Check{Name: "ok", Run: func(ctx context.Context) error { return nil }}
+	failing := Check{Name: "failing", Run: func(ctx context.Context) error { return errors.New("connection refused") }}
+
+	t.Run("liveness does not run checks", func(t *testing.T) {
+		code, response := serve(t, NewHandler(0, failing), "/healthz")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, StatusOK, response.Status)
+		assert.Empty(t, response.Checks)
+	})
+
+	t.Run("ready when every check passes", func(t *testing.T) {
+		code, response := serve(t, NewHandler(0, ok), "/readyz")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, StatusOK, response.Status)
+		assert.Equal(t, StatusOK, response.Checks["ok"].Status)
+	})
+
+	t.Run("not ready when a check fails", func(t *testing.T) {
+		code, response := serve(t, NewHandler(0, ok, failing), "/readyz")
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, StatusFail, response.Status)
+		assert.Equal(t, StatusOK, response.Checks["ok"].Status)
+		assert.Equal(t, StatusFail, response.Checks["failing"].Status)
+		assert.Equal(t, "connection refused", response.Checks["failing"].Error)
+	})
+
+	t.Run("checks are cancelled after the timeout", func(t *testing.T) {
+		slow := Check{Name: "slow", Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}}
+
+		code, response := serve(t, NewHandler(10*time.Millisecond, slow), "/readyz")
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, context.DeadlineExceeded.Error(), response.Checks["slow"].Error)
+	})
+
+	t.Run("not ready while draining", func(t *testing.T) {
+		handler := NewHandler(0, ok)
+		handler.Drain()
+
+		code, response := serve(t, handler, "/readyz")
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, StatusDraining, response.Status)
+
+		// liveness 不受影響，避免關閉期間被強制重啟
+		code, _ = serve(t, handler, "/healthz")
+		assert.Equal(t, http.StatusOK, code)
+	})
+}
+
+func TestDatabaseChecks(t *testing.T) {
+	ctx := context.Background()
+
+	for _, driver := range databasetest.Drivers {
+		t.Run(string(driver), func(t *testing.T) {
+			db := databasetest.OpenEmpty(t, driver)
+			migrator, err := migrate.New(db, driver)
+			require.NoError(t, err)
+
+			assert.NoError(t, DatabaseCheck(db).Run(ctx))
+			assert.Error(t, MigrationCheck(migrator).Run(ctx))
+
+			_, err = migrator.Up(ctx)
+			require.NoError(t, err)
+			assert.NoError(t, MigrationCheck(migrator).Run(ctx))
+
+			_, err = migrator.Down(ctx, 1)
+			require.NoError(t, err)
+			assert.ErrorContains(t, MigrationCheck(migrator).Run(ctx), "1 pending migrations")
+
+			require.NoError(t, db.Close())
+			assert.Error(t, DatabaseCheck(db).Run(ctx))
+		})
+	}
+}
@@ -2,6 +2,8 @@ package http_error
 
 import (
 	"net/http"
+	"portal_link/pkg/i18n"
+	"portal_link/pkg/validation"
 
 	"github.com/gin-gonic/gin"
 )
@@ -11,85 +13,101 @@ const (
 
 	ErrInvalidParams = "ErrInvalidParams"
 
+	ErrUnauthorized = "ErrUnauthorized"
+
 	ErrForbidden = "ErrForbidden"
 
 	ErrNotFound = "ErrNotFound"
+
+	ErrTooManyRequests = "ErrTooManyRequests"
 )
 
+// ContentType 錯誤回應的 media type（RFC 7807）
+const ContentType = "application/problem+json"
+
+// ErrorResponse RFC 7807 problem details 格式的錯誤回應
+// code 與 errors 為擴充欄位，用戶端以 code 判斷錯誤種類，以 errors 在表單上標示不合法的欄位
 type ErrorResponse struct {
-	Code    string `json:"code"`
-	Message string `json:"message"`
+	// Type 問題類型的 URI，沒有專屬說明文件時為 about:blank
+	Type string `json:"type"`
+	// Title 狀態碼的標準描述，例如 Bad Request
+	Title string `json:"title"`
+	// Status HTTP 狀態碼
+	Status int `json:"status"`
+	// Detail 此次錯誤的說明
+	Detail string `json:"detail,omitempty"`
+	// Instance 發生錯誤的請求路徑
+	Instance string `json:"instance,omitempty"`
+	// Code 錯誤代碼，例如 ErrInvalidParams
+	Code string `json:"code"`
+	// Errors 欄位驗證錯誤，沒有時省略
+	Errors []validation.FieldError `json:"errors,omitempty"`
 }
 
-// ResponseInternalServerError 回應 Internal Server Error
-func ResponseInternalServerError(c *gin.Context, errorResponse *ErrorResponse) {
-	code := ErrInternal
-	message := "Internal server error"
+// writeProblem 以 problem details 回應，errorResponse 中未設定的 Code 使用預設代碼
+// 未設定 Detail 時以 Accept-Language 翻譯錯誤代碼的訊息，欄位錯誤同樣附上翻譯的訊息
+func writeProblem(c *gin.Context, status int, code string, errorResponse *ErrorResponse) {
+	response := ErrorResponse{}
 	if errorResponse != nil {
-		if errorResponse.Code != "" {
-			code = errorResponse.Code
-		}
-		if errorResponse.Message != "" {
-			message = errorResponse.Message
-		}
+		response = *errorResponse
+	}
+	localizer := Localizer(c)
+	if response.Type == "" {
+		response.Type = "about:blank"
+	}
+	if response.Code == "" {
+		response.Code = code
 	}
+	if response.Detail == "" {
+		response.Detail = localizer.Message(response.Code, nil)
+	}
+	if response.Instance == "" && c.Request != nil {
+		response.Instance = c.Request.URL.Path
+	}
+	response.Title = http.StatusText(status)
+	response.Status = status
+	response.Errors = validation.Localize(response.Errors, localizer)
+
+	// 先設定 Content-Type，c.JSON 不會覆蓋已設定的值
+	c.Header("Content-Type", ContentType)
+	c.Header("Content-Language", localizer.Locale())
+	c.JSON(status, &response)
+}
 
-	c.JSON(http.StatusInternalServerError, &ErrorResponse{
-		Code:    code,
-		Message: message,
-	})
+// Localizer 依請求的 Accept-Language 返回 Localizer
+func Localizer(c *gin.Context) *i18n.Localizer {
+	if c.Request == nil {
+		return i18n.Default().Match("")
+	}
+	return i18n.Default().Match(c.GetHeader("Accept-Language"))
+}
+
+// ResponseInternalServerError 回應 Internal Server Error
+func ResponseInternalServerError(c *gin.Context, errorResponse *ErrorResponse) {
+	writeProblem(c, http.StatusInternalServerError, ErrInternal, errorResponse)
 }
 
 // ResponseBadRequest 回應 Bad Request
 func ResponseBadRequest(c *gin.Context, errorResponse *ErrorResponse) {
-	code := ErrInvalidParams
-	message := "Invalid request parameters"
-	if errorResponse != nil {
-		if errorResponse.Code != "" {
-			code = errorResponse.Code
-		}
-		if errorResponse.Message != "" {
-			message = errorResponse.Message
-		}
-	}
+	writeProblem(c, http.StatusBadRequest, ErrInvalidParams, errorResponse)
+}
 
-	c.JSON(http.StatusBadRequest, &ErrorResponse{
-		Code:    code,
-		Message: message,
-	})
+// ResponseUnauthorized 回應 Unauthorized
+func ResponseUnauthorized(c *gin.Context, errorResponse *ErrorResponse) {
+	writeProblem(c, http.StatusUnauthorized, ErrUnauthorized, errorResponse)
 }
 
 // ResponseForbidden 回應 Forbidden
 func ResponseForbidden(c *gin.Context, errorResponse *ErrorResponse) {
-	code := ErrForbidden
-	message := "You do not have permission"
-	if errorResponse != nil {
-		if errorResponse.Code != "" {
-			code = errorResponse.Code
-		}
-		if errorResponse.Message != "" {
-			message = errorResponse.Message
-		}
-	}
-
-	c.JSON(http.StatusForbidden, &ErrorResponse{
-		Code:    code,
-		Message: message,
-	})
+	writeProblem(c, http.StatusForbidden, ErrForbidden, errorResponse)
 }
 
 // ResponseNotFound 回應 Not Found
 func ResponseNotFound(c *gin.Context, errorResponse *ErrorResponse) {
-	code := ErrNotFound
-	message := "Resource not found"
-	if errorResponse != nil {
-		if errorResponse.Code != "" {
-			code = errorResponse.Code
-		}
-	}
+	writeProblem(c, http.StatusNotFound, ErrNotFound, errorResponse)
+}
 
-	c.JSON(http.StatusNotFound, &ErrorResponse{
-		Code:    code,
-		Message: message,
-	})
+// ResponseTooManyRequests 回應 Too Many Requests
+func ResponseTooManyRequests(c *gin.Context, errorResponse *ErrorResponse) {
+	writeProblem(c, http.StatusTooManyRequests, ErrTooManyRequests, errorResponse)
 }
@@ -0,0 +1,120 @@
+package http_error
+
+import (
+	"fmt"
+	"math"
+	"portal_link/pkg/logger"
+	"portal_link/pkg/validation"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// Mapping 一個 domain error 對應的 HTTP 回應
+type Mapping struct {
+	// Err domain error，以 errors.Is 比對，包含被 errors.Wrap 包裝的錯誤
+	Err error
+	// Status HTTP 狀態碼
+	Status int
+	// Code 回應給用戶端的錯誤代碼，例如 ErrInvalidParams
+	Code string
+	// MessageKey 選填，訊息目錄中的 key，多個 domain error 共用同一個 Code 時用來區分訊息；未設定時使用 Code
+	MessageKey string
+	// RetryAfter 選填，返回 Retry-After header 的時間，返回 0 時不設定
+	RetryAfter func(err error) time.Duration
+}
+
+// Key 返回訊息目錄中的 key
+func (m Mapping) Key() string {
+	if m.MessageKey != "" {
+		return m.MessageKey
+	}
+	return m.Code
+}
+
+// Registry 保存 domain error 與 HTTP 回應的對應
+type Registry struct {
+	mu       sync.RWMutex
+	mappings []Mapping
+}
+
+// NewRegistry 建立空的 Registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// defaultRegistry 各模組註冊 domain error 的全域 Registry
+var defaultRegistry = NewRegistry()
+
+// Register 註冊 domain error 的對應，先註冊的對應優先比對
+// Status 為 5xx 或 Code 為空時 panic，內部錯誤不需要註冊
+func (r *Registry) Register(mappings ...Mapping) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, mapping := range mappings {
+		if mapping.Err == nil || mapping.Code == "" || mapping.Status < 400 || mapping.Status >= 500 {
+			panic(fmt.Sprintf("http_error: invalid mapping for %v: status %d, code %q", mapping.Err, mapping.Status, mapping.Code))
+		}
+		r.mappings = append(r.mappings, mapping)
+	}
+}
+
+// Mappings 返回所有已註冊的對應
+func (r *Registry) Mappings() []Mapping {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]Mapping(nil), r.mappings...)
+}
+
+// Lookup 找出 err 對應的 Mapping
+func (r *Registry) Lookup(err error) (Mapping, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, mapping := range r.mappings {
+		if errors.Is(err, mapping.Err) {
+			return mapping, true
+		}
+	}
+	return Mapping{}, false
+}
+
+// Respond 將 err 轉換為 HTTP 回應
+// 已註冊的 domain error 以對應的狀態碼與代碼回應，detail 為依 Accept-Language 翻譯的訊息，驗證錯誤附上欄位錯誤；
+// 其他錯誤視為內部錯誤，連同 stack trace 記錄在日誌中，回應 500 且不包含錯誤內容
+func (r *Registry) Respond(c *gin.Context, err error) {
+	mapping, ok := r.Lookup(err)
+	if !ok {
+		c.Error(err)
+		logger.FromContext(c.Request.Context()).Error("internal error", "error", err, "stack", fmt.Sprintf("%+v", err))
+		ResponseInternalServerError(c, nil)
+		return
+	}
+
+	if mapping.RetryAfter != nil {
+		if retryAfter := mapping.RetryAfter(err); retryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		}
+	}
+	writeProblem(c, mapping.Status, mapping.Code, &ErrorResponse{
+		Detail: Localizer(c).Message(mapping.Key(), nil),
+		Errors: validation.FieldErrors(err),
+	})
+}
+
+// Register 在全域 Registry 註冊 domain error 的對應，通常在模組的 init 中呼叫
+func Register(mappings ...Mapping) {
+	defaultRegistry.Register(mappings...)
+}
+
+// Mappings 返回全域 Registry 中所有已註冊的對應
+func Mappings() []Mapping {
+	return defaultRegistry.Mappings()
+}
+
+// Respond 以全域 Registry 將 err 轉換為 HTTP 回應
+func Respond(c *gin.Context, err error) {
+	defaultRegistry.Respond(c, err)
+}
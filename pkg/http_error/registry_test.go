@@ -0,0 +1,149 @@
+package http_error
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"portal_link/pkg/logger"
+	"portal_link/pkg/validation"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+var (
+	errTestNotFound = errors.New("widget not found")
+	errTestLocked   = errors.New("widget is locked")
+	errTestInvalid  = errors.New("invalid widget")
+)
+
+// respond 以 registry 回應 err，返回回應與日誌
+func respond(t *testing.T, registry *Registry, err error, acceptLanguage string) (*httptest.ResponseRecorder, ErrorResponse, string) {
+	t.Helper()
+	var logs bytes.Buffer
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	req := httptest.NewRequest(http.MethodGet, "/widgets/42", nil)
+	req.Header.Set("Accept-Language", acceptLanguage)
+	c.Request = req.WithContext(logger.WithContext(req.Context(), slog.New(slog.NewJSONHandler(&logs, nil))))
+
+	registry.Respond(c, err)
+
+	var response ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return w, response, logs.String()
+}
+
+func TestRegistry_Respond(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(
+		Mapping{Err: errTestNotFound, Status: http.StatusNotFound, Code: ErrNotFound, MessageKey: "ErrPortalPageNotFound"},
+		Mapping{Err: errTestLocked, Status: http.StatusTooManyRequests, Code: ErrTooManyRequests, RetryAfter: func(err error) time.Duration {
+			return 1500 * time.Millisecond
+		}},
+	)
+
+	t.Run("registered error", func(t *testing.T) {
+		w, response, logs := respond(t, registry, errTestNotFound, "en")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, ContentType, w.Header().Get("Content-Type"))
+		assert.Equal(t, ErrorResponse{
+			Type:     "about:blank",
+			Title:    "Not Found",
+			Status:   http.StatusNotFound,
+			Detail:   "Portal page not found",
+			Instance: "/widgets/42",
+			Code:     ErrNotFound,
+		}, response)
+		assert.Empty(t, logs)
+	})
+
+	t.Run("wrapped registered error", func(t *testing.T) {
+		w, response, _ := respond(t, registry, errors.Wrap(errTestNotFound, "id 42"), "en")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, "Portal page not found", response.Detail)
+	})
+
+	t.Run("sets Retry-After", func(t *testing.T) {
+		w, response, _ := respond(t, registry, errTestLocked, "en")
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, ErrTooManyRequests, response.Code)
+		assert.Equal(t, "2", w.Header().Get("Retry-After"))
+	})
+
+	t.Run("validation error includes field errors", func(t *testing.T) {
+		registry := NewRegistry()
+		registry.Register(Mapping{Err: errTestInvalid, Status: http.StatusBadRequest, Code: ErrInvalidParams})
+
+		var v validation.Errors
+		v.Add("name", validation.RuleRequired, nil)
+		v.Add("password", validation.RuleTooShort, validation.Params{"min": 8})
+		w, _, _ := respond(t, registry, v.Err(errTestInvalid), "en")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{
+			"type": "about:blank",
+			"title": "Bad Request",
+			"status": 400,
+			"detail": "Invalid request parameters",
+			"instance": "/widgets/42",
+			"code": "ErrInvalidParams",
+			"errors": [
+				{"field": "name", "rule": "required", "message": "This field is required"},
+				{"field": "password", "rule": "too_short", "params": {"min": 8}, "message": "Must be at least 8 characters"}
+			]
+		}`, w.Body.String())
+	})
+
+	t.Run("localized by Accept-Language", func(t *testing.T) {
+		w, response, _ := respond(t, registry, errTestNotFound, "zh-Hant-TW, en;q=0.5")
+		assert.Equal(t, "找不到指定的 Portal Page", response.Detail)
+		assert.Equal(t, "zh-TW", w.Header().Get("Content-Language"))
+
+		// 沒有 Accept-Language 時使用預設語系
+		w, response, _ = respond(t, registry, errTestNotFound, "")
+		assert.Equal(t, "找不到指定的 Portal Page", response.Detail)
+		assert.Equal(t, "zh-TW", w.Header().Get("Content-Language"))
+	})
+
+	t.Run("internal error is logged but not returned", func(t *testing.T) {
+		w, response, logs := respond(t, registry, errors.Wrap(errors.New("connection refused"), "failed to query widgets"), "en")
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, ErrInternal, response.Code)
+		assert.Equal(t, "Internal server error", response.Detail)
+		assert.Contains(t, logs, "failed to query widgets: connection refused")
+		// stack trace 指向建立錯誤的位置
+		assert.Contains(t, logs, "registry_test.go")
+	})
+}
+
+func TestRegistry_Register(t *testing.T) {
+	registry := NewRegistry()
+	assert.Panics(t, func() {
+		registry.Register(Mapping{Err: errTestNotFound, Status: http.StatusInternalServerError, Code: ErrInternal})
+	})
+	assert.Panics(t, func() {
+		registry.Register(Mapping{Err: errTestNotFound, Status: http.StatusNotFound})
+	})
+	assert.Empty(t, registry.Mappings())
+}
+
+func TestResponseNotFound(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/portal-pages/john", nil)
+	ResponseNotFound(c, &ErrorResponse{Detail: "portal page not found"})
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, ContentType, w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"type":"about:blank","title":"Not Found","status":404,"detail":"portal page not found","instance":"/api/v1/portal-pages/john","code":"ErrNotFound"}`, w.Body.String())
+}
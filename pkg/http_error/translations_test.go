@@ -0,0 +1,39 @@
+package http_error_test
+
+import (
+	"portal_link/pkg/http_error"
+	"portal_link/pkg/i18n"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	// 各模組在 init 中註冊 domain error 的對應
+	_ "portal_link/modules/portal_page/adapter/restapi"
+	_ "portal_link/modules/user/adapter/restapi"
+)
+
+// TestTranslations 每個已註冊的 domain error 與預設錯誤代碼在每個語系都必須有訊息
+func TestTranslations(t *testing.T) {
+	mappings := http_error.Mappings()
+	require.NotEmpty(t, mappings)
+
+	keys := []string{
+		http_error.ErrInternal,
+		http_error.ErrInvalidParams,
+		http_error.ErrUnauthorized,
+		http_error.ErrForbidden,
+		http_error.ErrNotFound,
+		http_error.ErrTooManyRequests,
+	}
+	for _, mapping := range mappings {
+		keys = append(keys, mapping.Key())
+	}
+
+	bundle := i18n.Default()
+	for _, locale := range bundle.Locales() {
+		for _, key := range keys {
+			assert.True(t, bundle.Has(locale, key), "missing %s translation for %s", locale, key)
+		}
+	}
+}
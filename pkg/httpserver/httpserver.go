@@ -0,0 +1,54 @@
+// Package httpserver 執行 HTTP 伺服器並在收到關閉訊號時優雅關閉
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// ShutdownOptions 優雅關閉的設定
+type ShutdownOptions struct {
+	// OnShutdown 開始關閉時呼叫，例如讓 readiness 失敗
+	OnShutdown func()
+	// Delay 呼叫 OnShutdown 後、停止接受連線前的等待時間，讓負載平衡器有時間移除此實例
+	Delay time.Duration
+	// Timeout 等待處理中請求完成的最長時間，逾時後強制關閉所有連線
+	Timeout time.Duration
+}
+
+// Serve 在 listener 上執行 srv 直到 ctx 結束，之後停止接受新連線並等待處理中的請求完成
+// 伺服器正常關閉時返回 nil
+func Serve(ctx context.Context, srv *http.Server, listener net.Listener, opts ShutdownOptions) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	if opts.OnShutdown != nil {
+		opts.OnShutdown()
+	}
+	if opts.Delay > 0 {
+		time.Sleep(opts.Delay)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		srv.Close()
+		return fmt.Errorf("failed to drain in-flight requests: %w", err)
+	}
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package httpserver
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func listen(t *testing.T) net.Listener {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	return listener
+}
+
+func TestServe(t *testing.T) {
+	t.Run("drains in-flight requests on shutdown", func(t *testing.T) {
+		started := make(chan struct{})
+		release := make(chan struct{})
+		srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+			io.WriteString(w, "done")
+		})}
+		listener := listen(t)
+		url := "http://" + listener.Addr().String()
+
+		var shutdownCalled atomic.Bool
+		ctx, cancel := context.WithCancel(context.Background())
+		served := make(chan error, 1)
+		go func() {
+			served <- Serve(ctx, srv, listener, ShutdownOptions{
+				OnShutdown: func() { shutdownCalled.Store(true) },
+				Timeout:    5 * time.Second,
+			})
+		}()
+
+		type result struct {
+			body string
+			err  error
+		}
+		responses := make(chan result, 1)
+		go func() {
+			resp, err := http.Get(url)
+			if err != nil {
+				responses <- result{err: err}
+				return
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			responses <- result{body: string(body), err: err}
+		}()
+
+		<-started
+		cancel()
+
+		// 關閉開始後不再接受新連線
+		require.Eventually(t, func() bool {
+			_, err := net.DialTimeout("tcp", listener.Addr().String(), 100*time.Millisecond)
+			return err != nil
+		}, time.Second, 10*time.Millisecond)
+		assert.True(t, shutdownCalled.Load())
+
+		// 處理中的請求完成後 Serve 才返回
+		select {
+		case err := <-served:
+			t.Fatalf("Serve returned before the in-flight request finished: %v", err)
+		default:
+		}
+		close(release)
+
+		response := <-responses
+		require.NoError(t, response.err)
+		assert.Equal(t, "done", response.body)
+		assert.NoError(t, <-served)
+	})
+
+	t.Run("gives up after the shutdown timeout", func(t *testing.T) {
+		started := make(chan struct{})
+		release := make(chan struct{})
+		defer close(release)
+		srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+		})}
+		listener := listen(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		served := make(chan error, 1)
+		go func() {
+			served <- Serve(ctx, srv, listener, ShutdownOptions{Timeout: 50 * time.Millisecond})
+		}()
+		go http.Get("http://" + listener.Addr().String())
+
+		<-started
+		cancel()
+		assert.ErrorIs(t, <-served, context.DeadlineExceeded)
+	})
+
+	t.Run("returns listener errors", func(t *testing.T) {
+		listener := listen(t)
+		require.NoError(t, listener.Close())
+
+		err := Serve(context.Background(), &http.Server{}, listener, ShutdownOptions{Timeout: time.Second})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,222 @@
+// Package i18n 依 Accept-Language 選擇訊息語系
+//
+// 訊息目錄以錯誤代碼（例如 ErrInvalidParams）與驗證規則代碼（例如 validation.too_short）為 key，
+// 內嵌於執行檔的 locales/<語系>.json。找不到訊息時依序使用請求中其他可接受的語系、預設語系，最後返回 key 本身。
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLocale 預設語系，Accept-Language 沒有符合的語系時使用
+const DefaultLocale = "zh-TW"
+
+// maxAcceptLanguageTags Accept-Language 最多處理的語系數量
+const maxAcceptLanguageTags = 10
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+// Bundle 保存各語系的訊息目錄
+type Bundle struct {
+	defaultLocale string
+	locales       []string
+	catalogs      map[string]map[string]string
+}
+
+// NewBundle 建立以 defaultLocale 為預設語系的 Bundle
+func NewBundle(defaultLocale string) *Bundle {
+	return &Bundle{
+		defaultLocale: defaultLocale,
+		catalogs:      make(map[string]map[string]string),
+	}
+}
+
+// defaultBundle 內嵌訊息目錄的全域 Bundle
+var defaultBundle = mustLoadEmbedded()
+
+// Default 返回以內嵌訊息目錄建立的 Bundle
+func Default() *Bundle {
+	return defaultBundle
+}
+
+func mustLoadEmbedded() *Bundle {
+	bundle := NewBundle(DefaultLocale)
+	entries, err := localeFS.ReadDir("locales")
+	if err != nil {
+		panic(fmt.Sprintf("i18n: failed to read locales: %v", err))
+	}
+	for _, entry := range entries {
+		data, err := localeFS.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			panic(fmt.Sprintf("i18n: failed to read %s: %v", entry.Name(), err))
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("i18n: invalid catalog %s: %v", entry.Name(), err))
+		}
+		bundle.Add(strings.TrimSuffix(entry.Name(), ".json"), messages)
+	}
+	return bundle
+}
+
+// Add 加入語系的訊息，已存在的 key 會被覆蓋
+func (b *Bundle) Add(locale string, messages map[string]string) {
+	catalog, exists := b.catalogs[locale]
+	if !exists {
+		catalog = make(map[string]string, len(messages))
+		b.catalogs[locale] = catalog
+		b.locales = append(b.locales, locale)
+		sort.Strings(b.locales)
+	}
+	for key, message := range messages {
+		catalog[key] = message
+	}
+}
+
+// Locales 返回所有支援的語系
+func (b *Bundle) Locales() []string {
+	return append([]string(nil), b.locales...)
+}
+
+// Has 語系中是否有 key 的訊息（不使用備援語系）
+func (b *Bundle) Has(locale string, key string) bool {
+	_, exists := b.catalogs[locale][key]
+	return exists
+}
+
+// Match 依 Accept-Language 建立 Localizer
+// 依 q 值由高到低，每個語系先完全比對，再逐一去掉最後一段子標籤比對（zh-Hant-TW → zh-Hant → zh），
+// 只剩主要語言時也符合同語言的其他地區（zh-CN → zh-TW）；最後加上預設語系
+func (b *Bundle) Match(acceptLanguage string) *Localizer {
+	var chain []string
+	add := func(locale string) {
+		for _, existing := range chain {
+			if existing == locale {
+				return
+			}
+		}
+		chain = append(chain, locale)
+	}
+
+	for _, tag := range parseAcceptLanguage(acceptLanguage) {
+		if locale, ok := b.match(tag); ok {
+			add(locale)
+		}
+	}
+	add(b.defaultLocale)
+
+	return &Localizer{bundle: b, chain: chain}
+}
+
+// match 找出 tag 對應的語系
+func (b *Bundle) match(tag string) (string, bool) {
+	for candidate := tag; candidate != ""; {
+		for _, locale := range b.locales {
+			if strings.EqualFold(locale, candidate) {
+				return locale, true
+			}
+		}
+		index := strings.LastIndex(candidate, "-")
+		if index < 0 {
+			break
+		}
+		candidate = candidate[:index]
+	}
+
+	language, _, _ := strings.Cut(tag, "-")
+	for _, locale := range b.locales {
+		base, _, _ := strings.Cut(locale, "-")
+		if strings.EqualFold(base, language) {
+			return locale, true
+		}
+	}
+	return "", false
+}
+
+// parseAcceptLanguage 解析 Accept-Language，依 q 值由高到低返回語系標籤，忽略 * 與 q=0
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		if len(tags) == maxAcceptLanguageTags {
+			break
+		}
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		tags = append(tags, weighted{tag: tag, q: q})
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].q > tags[j].q
+	})
+	result := make([]string, len(tags))
+	for i, tag := range tags {
+		result[i] = tag.tag
+	}
+	return result
+}
+
+// Localizer 依語系的備援順序取得訊息
+type Localizer struct {
+	bundle *Bundle
+	chain  []string
+}
+
+// Locale 返回第一順位的語系，用於 Content-Language header
+func (l *Localizer) Locale() string {
+	return l.chain[0]
+}
+
+// Message 返回 key 的訊息，以 params 取代訊息中的 {name}
+// 所有語系都沒有 key 時返回 key 本身
+func (l *Localizer) Message(key string, params map[string]any) string {
+	for _, locale := range l.chain {
+		if message, exists := l.bundle.catalogs[locale][key]; exists {
+			return format(message, params)
+		}
+	}
+	return key
+}
+
+// format 以 params 取代 message 中的 {name}，字串陣列以逗號連接
+func format(message string, params map[string]any) string {
+	if len(params) == 0 {
+		return message
+	}
+	replacements := make([]string, 0, len(params)*2)
+	for name, value := range params {
+		var text string
+		switch value := value.(type) {
+		case []string:
+			text = strings.Join(value, ", ")
+		default:
+			text = fmt.Sprint(value)
+		}
+		replacements = append(replacements, "{"+name+"}", text)
+	}
+	return strings.NewReplacer(replacements...).Replace(message)
+}
@@ -0,0 +1,68 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestBundle() *Bundle {
+	bundle := NewBundle("zh-TW")
+	bundle.Add("zh-TW", map[string]string{"greeting": "你好，{name}", "only_zh": "只有中文"})
+	bundle.Add("en", map[string]string{"greeting": "Hello, {name}"})
+	bundle.Add("ja", map[string]string{"greeting": "こんにちは、{name}"})
+	return bundle
+}
+
+func TestBundle_Match(t *testing.T) {
+	bundle := newTestBundle()
+
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		expected       string
+	}{
+		{name: "empty header uses default", acceptLanguage: "", expected: "zh-TW"},
+		{name: "exact match ignores case", acceptLanguage: "EN", expected: "en"},
+		{name: "region falls back to language", acceptLanguage: "en-GB", expected: "en"},
+		{name: "script and region fall back to same language", acceptLanguage: "zh-Hant-HK", expected: "zh-TW"},
+		{name: "other region of same language", acceptLanguage: "zh-CN", expected: "zh-TW"},
+		{name: "highest q wins", acceptLanguage: "en;q=0.5, ja;q=0.8", expected: "ja"},
+		{name: "unsupported languages skipped", acceptLanguage: "fr-FR, de;q=0.9, en;q=0.1", expected: "en"},
+		{name: "q=0 and wildcard ignored", acceptLanguage: "en;q=0, *", expected: "zh-TW"},
+		{name: "malformed q ignored", acceptLanguage: "en;q=abc", expected: "zh-TW"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, bundle.Match(tt.acceptLanguage).Locale())
+		})
+	}
+}
+
+func TestLocalizer_Message(t *testing.T) {
+	bundle := newTestBundle()
+
+	localizer := bundle.Match("fr, en;q=0.9")
+	assert.Equal(t, "Hello, Ada", localizer.Message("greeting", map[string]any{"name": "Ada"}))
+	// en 沒有的訊息使用預設語系
+	assert.Equal(t, "只有中文", localizer.Message("only_zh", nil))
+	// 所有語系都沒有的訊息返回 key
+	assert.Equal(t, "missing", localizer.Message("missing", nil))
+	// 沒有提供的參數保留原樣
+	assert.Equal(t, "Hello, {name}", localizer.Message("greeting", nil))
+}
+
+func TestDefault(t *testing.T) {
+	bundle := Default()
+	assert.Equal(t, []string{"en", "zh-TW"}, bundle.Locales())
+
+	// 每個語系的 key 必須一致，避免新增訊息時只更新其中一個語系
+	for _, locale := range bundle.Locales() {
+		for key := range bundle.catalogs[DefaultLocale] {
+			assert.True(t, bundle.Has(locale, key), "missing %s translation for %s", locale, key)
+		}
+		for key := range bundle.catalogs[locale] {
+			assert.True(t, bundle.Has(DefaultLocale, key), "missing %s translation for %s", DefaultLocale, key)
+		}
+	}
+}
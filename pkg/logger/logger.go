@@ -0,0 +1,129 @@
+// Package logger 建立以 log/slog 為基礎、輸出 JSON 的 logger，並透過 context 傳遞 request 範圍的 logger
+//
+// 名稱看起來是密鑰的欄位（password、token、secret、authorization、cookie、api key）會自動以 [REDACTED] 取代，
+// 不需要呼叫端自行遮蔽。
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"reflect"
+	"strings"
+)
+
+// Redacted 取代密鑰欄位的文字
+const Redacted = "[REDACTED]"
+
+// sensitiveSuffixes 欄位名稱（轉小寫並移除 _ - .）以這些字尾結尾時視為密鑰，
+// 例如 password、new_password、refresh_token、X-Api-Key
+var sensitiveSuffixes = []string{"password", "token", "secret", "authorization", "cookie", "apikey"}
+
+// New 建立輸出 JSON 到 w 的 logger，低於 level 的紀錄不輸出
+func New(w io.Writer, level slog.Leveler) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redactAttr,
+	}))
+}
+
+// ParseLevel 解析 debug、info、warn 或 error
+func ParseLevel(value string) (slog.Level, error) {
+	var level slog.Level
+	err := level.UnmarshalText([]byte(value))
+	return level, err
+}
+
+// IsSensitive 判斷欄位名稱是否為密鑰
+func IsSensitive(key string) bool {
+	normalized := strings.Map(func(r rune) rune {
+		switch r {
+		case '_', '-', '.':
+			return -1
+		}
+		return r
+	}, strings.ToLower(key))
+
+	for _, suffix := range sensitiveSuffixes {
+		if strings.HasSuffix(normalized, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactAttr 遮蔽密鑰欄位；slog 對群組中的每個欄位都會呼叫，因此巢狀欄位也會被遮蔽
+// 以 slog.Any 記錄的 struct 與 map 會先轉為 JSON 物件，再遮蔽其中的密鑰欄位
+func redactAttr(groups []string, attr slog.Attr) slog.Attr {
+	if attr.Value.Kind() == slog.KindGroup {
+		return attr
+	}
+	if IsSensitive(attr.Key) {
+		return slog.String(attr.Key, Redacted)
+	}
+	if attr.Value.Kind() == slog.KindAny {
+		if redacted, ok := redactObject(attr.Value.Any()); ok {
+			return slog.Any(attr.Key, redacted)
+		}
+	}
+	return attr
+}
+
+// redactObject 將 struct 或 map 轉為 JSON 物件並遮蔽密鑰欄位，其他型態返回 false
+func redactObject(value any) (any, bool) {
+	if _, ok := value.(error); ok {
+		return nil, false
+	}
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct && v.Kind() != reflect.Map {
+		return nil, false
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, false
+	}
+	var object any
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, false
+	}
+	return redactJSON(object), true
+}
+
+// redactJSON 遞迴遮蔽 JSON 物件中的密鑰欄位
+func redactJSON(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if IsSensitive(key) {
+				v[key] = Redacted
+			} else {
+				v[key] = redactJSON(field)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactJSON(item)
+		}
+	}
+	return value
+}
+
+type contextKey struct{}
+
+// WithContext 將 logger 存入 context
+func WithContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext 取得 context 中的 logger，沒有時返回 slog.Default()
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// logOnce 以 New 建立的 logger 輸出一筆紀錄並解析 JSON
+func logOnce(t *testing.T, args ...any) map[string]any {
+	t.Helper()
+	var buf bytes.Buffer
+	New(&buf, slog.LevelDebug).Info("message", args...)
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	return record
+}
+
+func TestIsSensitive(t *testing.T) {
+	for _, key := range []string{"password", "new_password", "Password", "refresh_token", "accessToken", "jwt_secret", "Authorization", "Set-Cookie", "X-Api-Key"} {
+		assert.True(t, IsSensitive(key), key)
+	}
+	for _, key := range []string{"user_id", "email", "token_version", "request_id", "status"} {
+		assert.False(t, IsSensitive(key), key)
+	}
+}
+
+func TestNew_RedactsSecrets(t *testing.T) {
+	t.Run("attributes", func(t *testing.T) {
+		record := logOnce(t, "password", "hunter2", "refresh_token", "abc", "email", "a@example.com")
+		assert.Equal(t, Redacted, record["password"])
+		assert.Equal(t, Redacted, record["refresh_token"])
+		assert.Equal(t, "a@example.com", record["email"])
+	})
+
+	t.Run("groups", func(t *testing.T) {
+		record := logOnce(t, slog.Group("request", "authorization", "Bearer abc", "method", "POST"))
+		request := record["request"].(map[string]any)
+		assert.Equal(t, Redacted, request["authorization"])
+		assert.Equal(t, "POST", request["method"])
+	})
+
+	t.Run("structs and maps", func(t *testing.T) {
+		type credentials struct {
+			Email    string `json:"email"`
+			Password string `json:"password"`
+			Session  struct {
+				RefreshToken string `json:"refresh_token"`
+			} `json:"session"`
+		}
+		params := &credentials{Email: "a@example.com", Password: "hunter2"}
+		params.Session.RefreshToken = "abc"
+
+		record := logOnce(t, "params", params, "headers", map[string]string{"Cookie": "session=abc", "Accept": "*/*"})
+		logged := record["params"].(map[string]any)
+		assert.Equal(t, "a@example.com", logged["email"])
+		assert.Equal(t, Redacted, logged["password"])
+		assert.Equal(t, Redacted, logged["session"].(map[string]any)["refresh_token"])
+		// 原本的值不受影響
+		assert.Equal(t, "hunter2", params.Password)
+
+		headers := record["headers"].(map[string]any)
+		assert.Equal(t, Redacted, headers["Cookie"])
+		assert.Equal(t, "*/*", headers["Accept"])
+	})
+
+	t.Run("other values are kept", func(t *testing.T) {
+		record := logOnce(t, "error", errors.New("boom"), "count", 3, "tags", []string{"a"})
+		assert.Equal(t, "boom", record["error"])
+		assert.Equal(t, float64(3), record["count"])
+		assert.Equal(t, []any{"a"}, record["tags"])
+	})
+}
+
+func TestParseLevel(t *testing.T) {
+	level, err := ParseLevel("warn")
+	require.NoError(t, err)
+	assert.Equal(t, slog.LevelWarn, level)
+
+	_, err = ParseLevel("trace")
+	assert.Error(t, err)
+}
+
+func TestFromContext(t *testing.T) {
+	assert.Same(t, slog.Default(), FromContext(context.Background()))
+
+	logger := New(&bytes.Buffer{}, slog.LevelInfo)
+	assert.Same(t, logger, FromContext(WithContext(context.Background(), logger)))
+}
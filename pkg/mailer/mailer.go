@@ -0,0 +1,107 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Message 一封寄出的電子郵件
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+	SentAt  time.Time
+}
+
+// InMemoryOutbox 將寄出的電子郵件保存在記憶體中，實作 user domain 的 Mailer，適用於測試與本地開發
+type InMemoryOutbox struct {
+	mu       sync.RWMutex
+	messages []Message
+}
+
+// NewInMemoryOutbox 建立新的 InMemoryOutbox
+func NewInMemoryOutbox() *InMemoryOutbox {
+	return &InMemoryOutbox{}
+}
+
+// Send 將電子郵件保存到 outbox
+func (o *InMemoryOutbox) Send(ctx context.Context, to string, subject string, body string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.messages = append(o.messages, Message{
+		To:      to,
+		Subject: subject,
+		Body:    body,
+		SentAt:  time.Now().UTC(),
+	})
+	return nil
+}
+
+// Messages 依寄出順序返回所有電子郵件
+func (o *InMemoryOutbox) Messages() []Message {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	messages := make([]Message, len(o.messages))
+	copy(messages, o.messages)
+	return messages
+}
+
+// MessagesTo 依寄出順序返回寄給指定收件者的電子郵件
+func (o *InMemoryOutbox) MessagesTo(to string) []Message {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	var messages []Message
+	for _, message := range o.messages {
+		if message.To == to {
+			messages = append(messages, message)
+		}
+	}
+	return messages
+}
+
+// Reset 清除所有電子郵件
+func (o *InMemoryOutbox) Reset() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.messages = nil
+}
+
+// FileOutbox 將寄出的電子郵件逐封寫入目錄中的 .eml 檔案，實作 user domain 的 Mailer，適用於本地開發
+type FileOutbox struct {
+	mu  sync.Mutex
+	dir string
+	seq int
+}
+
+// NewFileOutbox 建立新的 FileOutbox，目錄不存在時會自動建立
+func NewFileOutbox(dir string) (*FileOutbox, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create outbox directory: %w", err)
+	}
+	return &FileOutbox{dir: dir}, nil
+}
+
+// Send 將電子郵件寫入 outbox 目錄
+func (o *FileOutbox) Send(ctx context.Context, to string, subject string, body string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	sentAt := time.Now().UTC()
+	o.seq++
+	name := fmt.Sprintf("%s-%04d.eml", sentAt.Format("20060102T150405.000000000Z"), o.seq)
+
+	content := fmt.Sprintf("Date: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n%s\r\n",
+		sentAt.Format(time.RFC1123Z), to, subject, body)
+	if err := os.WriteFile(filepath.Join(o.dir, name), []byte(content), 0o600); err != nil {
+		return fmt.Errorf("failed to write message to outbox: %w", err)
+	}
+	return nil
+}
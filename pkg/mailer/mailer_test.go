@@ -0,0 +1,53 @@
+package mailer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryOutbox(t *testing.T) {
+	ctx := context.Background()
+	outbox := NewInMemoryOutbox()
+
+	require.NoError(t, outbox.Send(ctx, "a@example.com", "Hello", "first"))
+	require.NoError(t, outbox.Send(ctx, "b@example.com", "Hello", "second"))
+	require.NoError(t, outbox.Send(ctx, "a@example.com", "Hello", "third"))
+
+	messages := outbox.Messages()
+	require.Len(t, messages, 3)
+	assert.Equal(t, "first", messages[0].Body)
+	assert.False(t, messages[0].SentAt.IsZero())
+
+	toA := outbox.MessagesTo("a@example.com")
+	require.Len(t, toA, 2)
+	assert.Equal(t, "third", toA[1].Body)
+
+	outbox.Reset()
+	assert.Empty(t, outbox.Messages())
+}
+
+func TestFileOutbox(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "outbox")
+
+	outbox, err := NewFileOutbox(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, outbox.Send(ctx, "a@example.com", "Reset your password", "https://example.com/reset?token=abc"))
+	require.NoError(t, outbox.Send(ctx, "b@example.com", "Hello", "second"))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	content, err := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "To: a@example.com\r\n")
+	assert.Contains(t, string(content), "Subject: Reset your password\r\n")
+	assert.Contains(t, string(content), "https://example.com/reset?token=abc")
+}
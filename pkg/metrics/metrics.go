@@ -0,0 +1,155 @@
+// Package metrics 收集 HTTP、登入、token 驗證與 repository 的 Prometheus 指標，並提供 /metrics 端點
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "portal_link"
+
+// unmatchedRoute 沒有符合任何路由的請求使用的 route label，避免任意路徑造成 label 數量暴增
+const unmatchedRoute = "unmatched"
+
+// Repository 呼叫結果的 result label
+const (
+	ResultOK       = "ok"
+	ResultNotFound = "not_found"
+	ResultError    = "error"
+)
+
+// RepositoryObserver 記錄 repository 方法的呼叫，由 repository decorator 呼叫
+type RepositoryObserver interface {
+	ObserveRepositoryCall(repository, method string, duration time.Duration, err error)
+}
+
+var _ RepositoryObserver = (*Metrics)(nil)
+
+// Metrics 所有指標與其 registry
+// 同時實作 user domain 的 SignInRecorder、auth 的 TokenFailureRecorder 與 RepositoryObserver
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests      *prometheus.CounterVec
+	httpDuration      *prometheus.HistogramVec
+	signIns           *prometheus.CounterVec
+	signInLockouts    *prometheus.CounterVec
+	tokenFailures     *prometheus.CounterVec
+	repositoryLatency *prometheus.HistogramVec
+}
+
+// New 建立 Metrics 並註冊所有指標，包含 Go runtime 與 process 指標
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by method, Gin route template and status code.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by method and Gin route template.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		signIns: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "sign_ins_total",
+			Help:      "Sign-in attempts by outcome.",
+		}, []string{"outcome"}),
+		signInLockouts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "sign_in_lockouts_total",
+			Help:      "Lockouts started after too many failed sign-ins, by scope (email or ip).",
+		}, []string{"scope"}),
+		tokenFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "token_validation_failures_total",
+			Help:      "Rejected access tokens by error.",
+		}, []string{"reason"}),
+		repositoryLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "repository_call_duration_seconds",
+			Help:      "Repository call latency by repository, method and result.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"repository", "method", "result"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.signIns,
+		m.signInLockouts,
+		m.tokenFailures,
+		m.repositoryLatency,
+	)
+	return m
+}
+
+// Registry 返回註冊所有指標的 registry
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// Handler 以 Prometheus 文字格式輸出所有指標
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Register 註冊 GET /metrics
+func (m *Metrics) Register(e *gin.Engine) {
+	e.GET("/metrics", gin.WrapH(m.Handler()))
+}
+
+// Middleware 記錄每個請求的次數與延遲，route 使用 Gin 的路由樣板（例如 /api/v1/portal-pages/:slug）
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		m.httpRequests.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		m.httpDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// RecordSignIn 記錄一次登入的結果
+func (m *Metrics) RecordSignIn(outcome string) {
+	m.signIns.WithLabelValues(outcome).Inc()
+}
+
+// RecordLockout 記錄一次因登入失敗次數過多而開始的鎖定
+func (m *Metrics) RecordLockout(scope string) {
+	m.signInLockouts.WithLabelValues(scope).Inc()
+}
+
+// RecordTokenValidationFailure 記錄一次被拒絕的 access token
+func (m *Metrics) RecordTokenValidationFailure(reason string) {
+	m.tokenFailures.WithLabelValues(reason).Inc()
+}
+
+// ObserveRepositoryCall 記錄 repository 方法的延遲，sql.ErrNoRows 視為 not_found 而非錯誤
+func (m *Metrics) ObserveRepositoryCall(repository, method string, duration time.Duration, err error) {
+	result := ResultOK
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		result = ResultNotFound
+	case err != nil:
+		result = ResultError
+	}
+	m.repositoryLatency.WithLabelValues(repository, method, result).Observe(duration.Seconds())
+}
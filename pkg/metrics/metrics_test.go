@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func TestMiddleware(t *testing.T) {
+	m := New()
+	r := gin.New()
+	r.Use(m.Middleware())
+	r.GET("/api/v1/portal-pages/:slug", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for _, path := range []string{"/api/v1/portal-pages/a", "/api/v1/portal-pages/b", "/not-found"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	// 路徑參數不會成為 label，而是使用路由樣板
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.httpRequests.WithLabelValues(http.MethodGet, "/api/v1/portal-pages/:slug", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.httpRequests.WithLabelValues(http.MethodGet, unmatchedRoute, "404")))
+	assert.Equal(t, 2, testutil.CollectAndCount(m.httpDuration))
+}
+
+func TestRecorders(t *testing.T) {
+	m := New()
+	m.RecordSignIn("success")
+	m.RecordSignIn("success")
+	m.RecordSignIn("invalid_credentials")
+	m.RecordLockout("email")
+	m.RecordTokenValidationFailure("ErrExpiredToken")
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.signIns.WithLabelValues("success")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.signIns.WithLabelValues("invalid_credentials")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.signInLockouts.WithLabelValues("email")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.tokenFailures.WithLabelValues("ErrExpiredToken")))
+}
+
+func TestObserveRepositoryCall(t *testing.T) {
+	m := New()
+	m.ObserveRepositoryCall("user", "Find", time.Millisecond, nil)
+	m.ObserveRepositoryCall("user", "Find", time.Millisecond, sql.ErrNoRows)
+	m.ObserveRepositoryCall("user", "Find", time.Millisecond, errors.New("connection refused"))
+
+	expected := `
+# HELP portal_link_repository_call_duration_seconds Repository call latency by repository, method and result.
+# TYPE portal_link_repository_call_duration_seconds histogram
+`
+	var series []string
+	for _, result := range []string{ResultError, ResultNotFound, ResultOK} {
+		series = append(series, `portal_link_repository_call_duration_seconds_count{method="Find",repository="user",result="`+result+`"} 1`)
+	}
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), strings.TrimSpace(expected))
+	for _, s := range series {
+		assert.Contains(t, w.Body.String(), s)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	m := New()
+	r := gin.New()
+	m.Register(r)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "go_goroutines")
+}
@@ -0,0 +1,126 @@
+package openapi
+
+import (
+	"fmt"
+	"portal_link/pkg/validation"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+)
+
+// bodyField 無法對應到欄位的 request body 錯誤（缺少 body、格式或 Content-Type 不正確）使用的欄位名稱
+const bodyField = "body"
+
+// fieldErrors 將請求驗證錯誤轉為欄位錯誤，欄位名稱與用例的驗證一致，例如 links[1].url
+func fieldErrors(err error) []validation.FieldError {
+	var fields []validation.FieldError
+	collectFieldErrors(&fields, "", err)
+	return fields
+}
+
+// collectFieldErrors 依序展開 MultiError、RequestError 與 SchemaError，field 為目前所在的參數或 body 欄位
+func collectFieldErrors(fields *[]validation.FieldError, field string, err error) {
+	// MultiError 的 As 會返回其中第一個符合的錯誤，因此依實際型別展開
+	switch err := err.(type) {
+	case openapi3.MultiError:
+		for _, err := range err {
+			collectFieldErrors(fields, field, err)
+		}
+	case *openapi3filter.RequestError:
+		switch {
+		case err.Parameter != nil:
+			field = err.Parameter.Name
+		case err.RequestBody != nil:
+			field = ""
+		}
+		switch {
+		case errors.Is(err.Err, openapi3filter.ErrInvalidRequired), errors.Is(err.Err, openapi3filter.ErrInvalidEmptyValue):
+			*fields = append(*fields, validation.FieldError{Field: fieldOrBody(field), Rule: validation.RuleRequired})
+		case err.Err == nil:
+			// 例如不支援的 Content-Type
+			*fields = append(*fields, validation.FieldError{Field: fieldOrBody(field), Rule: validation.RuleInvalidFormat})
+		default:
+			collectFieldErrors(fields, field, err.Err)
+		}
+	case *openapi3.SchemaError:
+		rule, params := schemaRule(err)
+		*fields = append(*fields, validation.FieldError{
+			Field:  joinField(field, err.JSONPointer()),
+			Rule:   rule,
+			Params: params,
+		})
+	default:
+		// 無法解析的 body 或參數
+		*fields = append(*fields, validation.FieldError{Field: fieldOrBody(field), Rule: validation.RuleInvalidFormat})
+	}
+}
+
+// schemaRule 將不符合的 schema 限制對應到驗證規則
+func schemaRule(err *openapi3.SchemaError) (string, validation.Params) {
+	schema := err.Schema
+	switch err.SchemaField {
+	case "required":
+		return validation.RuleRequired, nil
+	case "minLength":
+		return validation.RuleTooShort, validation.Params{"min": schema.MinLength}
+	case "maxLength":
+		if schema.MaxLength != nil {
+			return validation.RuleTooLong, validation.Params{"max": *schema.MaxLength}
+		}
+	case "minItems":
+		if schema.MinItems == 1 {
+			return validation.RuleRequired, nil
+		}
+		return validation.RuleTooShort, validation.Params{"min": schema.MinItems}
+	case "maxItems":
+		if schema.MaxItems != nil {
+			return validation.RuleTooLong, validation.Params{"max": *schema.MaxItems}
+		}
+	case "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum":
+		params := validation.Params{}
+		if schema.Min != nil {
+			params["min"] = *schema.Min
+		}
+		if schema.Max != nil {
+			params["max"] = *schema.Max
+		}
+		return validation.RuleOutOfRange, params
+	case "enum":
+		values := make([]string, 0, len(schema.Enum))
+		for _, value := range schema.Enum {
+			values = append(values, fmt.Sprint(value))
+		}
+		return validation.RuleOneOf, validation.Params{"values": values}
+	case "uniqueItems":
+		return validation.RuleNotUnique, nil
+	}
+	return validation.RuleInvalidFormat, nil
+}
+
+// joinField 將 JSON pointer 轉為欄位名稱，陣列索引以 [i] 表示
+func joinField(field string, pointer []string) string {
+	var b strings.Builder
+	b.WriteString(field)
+	for _, part := range pointer {
+		if _, err := strconv.Atoi(part); err == nil {
+			b.WriteString("[" + part + "]")
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString(".")
+		}
+		b.WriteString(part)
+	}
+	return fieldOrBody(b.String())
+}
+
+// fieldOrBody 沒有欄位名稱時使用 bodyField
+func fieldOrBody(field string) string {
+	if field == "" {
+		return bodyField
+	}
+	return field
+}
@@ -0,0 +1,237 @@
+// Package openapi 提供內嵌的 OpenAPI 規格與 Swagger UI，並依規格驗證請求與回應
+//
+// 規格只描述 /api/v1 之下的路由，其他路由（例如 /healthz、/metrics）不驗證。
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"html/template"
+	"net/http"
+	"portal_link/pkg/http_error"
+	"portal_link/pkg/logger"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// BasePath 規格中路徑的前綴
+	BasePath = "/api/v1"
+	// SpecPath 輸出規格的路徑
+	SpecPath = BasePath + "/openapi.yaml"
+	// DocsPath Swagger UI 的路徑
+	DocsPath = BasePath + "/docs"
+)
+
+// Spec 已載入並驗證過的 OpenAPI 規格
+type Spec struct {
+	raw    []byte
+	doc    *openapi3.T
+	router routers.Router
+}
+
+// Load 解析並驗證 OpenAPI 規格
+// 規格中的 servers 為部署環境的網址，比對請求時一律以 BasePath 為前綴
+func Load(data []byte) (*Spec, error) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse OpenAPI spec")
+	}
+	if err := doc.Validate(loader.Context); err != nil {
+		return nil, errors.Wrap(err, "invalid OpenAPI spec")
+	}
+
+	routed := *doc
+	routed.Servers = openapi3.Servers{{URL: BasePath}}
+	router, err := legacy.NewRouter(&routed)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build OpenAPI router")
+	}
+
+	return &Spec{
+		raw:    data,
+		doc:    doc,
+		router: router,
+	}, nil
+}
+
+// Register 註冊 GET SpecPath 輸出規格，GET DocsPath 輸出 Swagger UI
+func (s *Spec) Register(e *gin.Engine) {
+	e.GET(SpecPath, s.ServeSpec)
+	e.GET(DocsPath, s.ServeDocs)
+}
+
+// ServeSpec 以 YAML 輸出規格
+func (s *Spec) ServeSpec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/yaml; charset=utf-8", s.raw)
+}
+
+// ServeDocs 輸出載入 SpecPath 的 Swagger UI 頁面
+func (s *Spec) ServeDocs(c *gin.Context) {
+	var page bytes.Buffer
+	if err := docsTemplate.Execute(&page, struct{ Title, SpecURL string }{
+		Title:   s.doc.Info.Title,
+		SpecURL: SpecPath,
+	}); err != nil {
+		http_error.Respond(c, err)
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", page.Bytes())
+}
+
+// docsTemplate Swagger UI 頁面，swagger-ui 由 CDN 載入
+var docsTemplate = template.Must(template.New("docs").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>{{.Title}}</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.onload = function () {
+      window.ui = SwaggerUIBundle({ url: {{.SpecURL}}, dom_id: "#swagger-ui" });
+    };
+  </script>
+</body>
+</html>
+`))
+
+// Options 驗證 middleware 的設定
+type Options struct {
+	// ValidateRequests 是否拒絕不符合規格的請求，以 400 與欄位錯誤回應
+	ValidateRequests bool
+	// ValidateResponses 是否檢查回應是否符合規格，用於開發與測試環境
+	// 回應照常送出，不符合時記錄 ERROR 日誌並呼叫 OnInvalidResponse
+	ValidateResponses bool
+	// OnInvalidResponse 回應不符合規格時呼叫，例如在測試中標記失敗；可為 nil
+	OnInvalidResponse func(c *gin.Context, err error)
+}
+
+// errMissingCredentials 請求沒有帶規格要求的憑證
+var errMissingCredentials = errors.New("missing credentials")
+
+// Middleware 依規格驗證請求與回應，規格中沒有的路由不驗證
+// 身份驗證由各路由的 middleware 負責，這裡只檢查參數與 body；
+// 需要身份驗證的路由在請求沒有帶憑證時不驗證請求，交由路由的 middleware 回應 401，避免未驗證的請求取得欄位錯誤
+func (s *Spec) Middleware(opts Options) gin.HandlerFunc {
+	filterOptions := &openapi3filter.Options{
+		MultiError:            true,
+		SkipSettingDefaults:   true,
+		IncludeResponseStatus: true,
+		AuthenticationFunc:    hasCredentials,
+	}
+
+	return func(c *gin.Context) {
+		if !opts.ValidateRequests && !opts.ValidateResponses {
+			c.Next()
+			return
+		}
+
+		route, pathParams, err := s.router.FindRoute(c.Request)
+		if err != nil {
+			c.Next()
+			return
+		}
+		input := &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    filterOptions,
+		}
+
+		if opts.ValidateRequests && s.authenticated(c.Request.Context(), input) {
+			if err := openapi3filter.ValidateRequest(c.Request.Context(), input); err != nil {
+				http_error.ResponseBadRequest(c, &http_error.ErrorResponse{Errors: fieldErrors(err)})
+				c.Abort()
+				return
+			}
+		}
+
+		if !opts.ValidateResponses {
+			c.Next()
+			return
+		}
+
+		writer := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		if err := validateResponse(c.Request.Context(), input, c.Writer, writer.body.Bytes()); err != nil {
+			logger.FromContext(c.Request.Context()).Error("response does not match the OpenAPI spec",
+				"method", c.Request.Method,
+				"route", route.Path,
+				"status", c.Writer.Status(),
+				"error", err,
+			)
+			if opts.OnInvalidResponse != nil {
+				opts.OnInvalidResponse(c, err)
+			}
+		}
+	}
+}
+
+// authenticated 判斷請求是否帶有路由的 security requirement 要求的憑證，只檢查格式，不驗證 token
+func (s *Spec) authenticated(ctx context.Context, input *openapi3filter.RequestValidationInput) bool {
+	security := input.Route.Operation.Security
+	if security == nil {
+		security = &s.doc.Security
+	}
+	return openapi3filter.ValidateSecurityRequirements(ctx, input, *security) == nil
+}
+
+// hasCredentials 檢查請求是否帶有 security scheme 的憑證，目前只有 Bearer token
+func hasCredentials(ctx context.Context, input *openapi3filter.AuthenticationInput) error {
+	scheme := input.SecurityScheme
+	if scheme.Type != "http" || !strings.EqualFold(scheme.Scheme, "bearer") {
+		return nil
+	}
+
+	parts := strings.Split(input.RequestValidationInput.Request.Header.Get("Authorization"), " ")
+	if len(parts) != 2 || parts[0] != "Bearer" || parts[1] == "" {
+		return errMissingCredentials
+	}
+	return nil
+}
+
+// validateResponse 檢查已送出的回應是否符合規格
+func validateResponse(ctx context.Context, input *openapi3filter.RequestValidationInput, w gin.ResponseWriter, body []byte) error {
+	responseInput := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 w.Status(),
+		Header:                 w.Header(),
+		Options:                input.Options,
+	}
+	responseInput.SetBodyBytes(body)
+	if err := openapi3filter.ValidateResponse(ctx, responseInput); err != nil {
+		return errors.Wrapf(err, "%s %s", input.Request.Method, input.Route.Path)
+	}
+	return nil
+}
+
+// recordingWriter 寫入回應的同時保留 body，供回應送出後驗證
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
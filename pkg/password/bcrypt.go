@@ -0,0 +1,41 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// DefaultBcryptCost 預設的 bcrypt cost
+const DefaultBcryptCost = 12
+
+// hashBcrypt 產生 bcrypt 雜湊值（$2a$<cost>$<salt+hash>）
+func hashBcrypt(password string, cost int) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), cost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// verifyBcrypt 比對密碼與 bcrypt 雜湊值，bcrypt 本身即以固定時間比對
+func verifyBcrypt(password string, encodedHash string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encodedHash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	if err != nil {
+		return false, ErrInvalidHash
+	}
+	return true, nil
+}
+
+// bcryptCost 取得 bcrypt 雜湊值使用的 cost
+func bcryptCost(encodedHash string) (int, error) {
+	cost, err := bcrypt.Cost([]byte(encodedHash))
+	if err != nil {
+		return 0, ErrInvalidHash
+	}
+	return cost, nil
+}
@@ -0,0 +1,111 @@
+package password
+
+import (
+	"crypto/subtle"
+	"errors"
+	"strings"
+)
+
+// 支援的雜湊演算法
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+)
+
+var (
+	ErrInvalidHash          = errors.New("invalid password hash format")
+	ErrIncompatibleVersion  = errors.New("incompatible argon2 version")
+	ErrUnsupportedAlgorithm = errors.New("unsupported password hash algorithm")
+)
+
+// Config Hasher 的設定
+type Config struct {
+	// Algorithm 產生新雜湊值時使用的演算法，預設為 AlgorithmArgon2id
+	Algorithm string
+	// Argon2id argon2id 的參數，預設為 DefaultArgon2idParams
+	Argon2id Argon2idParams
+	// BcryptCost bcrypt 的 cost，預設為 DefaultBcryptCost
+	BcryptCost int
+}
+
+// Hasher 密碼雜湊器，實作 user domain 的 PasswordHasher
+//
+// 以設定的演算法產生新的雜湊值，並可驗證 argon2id、bcrypt 以及舊資料的明文密碼
+type Hasher struct {
+	algorithm  string
+	argon2id   Argon2idParams
+	bcryptCost int
+}
+
+// NewHasher 建立新的 Hasher
+func NewHasher(config Config) (*Hasher, error) {
+	if config.Algorithm == "" {
+		config.Algorithm = AlgorithmArgon2id
+	}
+	if config.Algorithm != AlgorithmArgon2id && config.Algorithm != AlgorithmBcrypt {
+		return nil, ErrUnsupportedAlgorithm
+	}
+	if config.Argon2id == (Argon2idParams{}) {
+		config.Argon2id = DefaultArgon2idParams
+	}
+	if config.BcryptCost == 0 {
+		config.BcryptCost = DefaultBcryptCost
+	}
+
+	return &Hasher{
+		algorithm:  config.Algorithm,
+		argon2id:   config.Argon2id,
+		bcryptCost: config.BcryptCost,
+	}, nil
+}
+
+// Hash 以設定的演算法產生密碼雜湊值
+func (h *Hasher) Hash(password string) (string, error) {
+	if h.algorithm == AlgorithmBcrypt {
+		return hashBcrypt(password, h.bcryptCost)
+	}
+	return hashArgon2id(password, h.argon2id)
+}
+
+// Verify 以固定時間比對密碼與雜湊值是否相符
+func (h *Hasher) Verify(password string, encodedHash string) (bool, error) {
+	switch {
+	case isArgon2idHash(encodedHash):
+		return verifyArgon2id(password, encodedHash)
+	case isBcryptHash(encodedHash):
+		return verifyBcrypt(password, encodedHash)
+	default:
+		// 尚未遷移的舊資料以明文保存
+		return subtle.ConstantTimeCompare([]byte(password), []byte(encodedHash)) == 1, nil
+	}
+}
+
+// NeedsRehash 判斷雜湊值是否為明文，或與目前設定的演算法、參數不同
+func (h *Hasher) NeedsRehash(encodedHash string) bool {
+	switch {
+	case isArgon2idHash(encodedHash):
+		if h.algorithm != AlgorithmArgon2id {
+			return true
+		}
+		params, _, _, err := decodeArgon2id(encodedHash)
+		return err != nil || params != h.argon2id
+	case isBcryptHash(encodedHash):
+		if h.algorithm != AlgorithmBcrypt {
+			return true
+		}
+		cost, err := bcryptCost(encodedHash)
+		return err != nil || cost != h.bcryptCost
+	default:
+		return true
+	}
+}
+
+func isArgon2idHash(encodedHash string) bool {
+	return strings.HasPrefix(encodedHash, "$argon2id$")
+}
+
+func isBcryptHash(encodedHash string) bool {
+	return strings.HasPrefix(encodedHash, "$2a$") ||
+		strings.HasPrefix(encodedHash, "$2b$") ||
+		strings.HasPrefix(encodedHash, "$2y$")
+}
@@ -0,0 +1,122 @@
+package password
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testArgon2idParams = Argon2idParams{
+	Memory:      1024,
+	Iterations:  1,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+func TestHasher_HashAndVerify(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		prefix string
+	}{
+		{
+			name:   "argon2id",
+			config: Config{Argon2id: testArgon2idParams},
+			prefix: "$argon2id$v=19$m=1024,t=1,p=1$",
+		},
+		{
+			name:   "bcrypt",
+			config: Config{Algorithm: AlgorithmBcrypt, BcryptCost: 4},
+			prefix: "$2a$04$",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hasher, err := NewHasher(tt.config)
+			require.NoError(t, err)
+
+			hash, err := hasher.Hash("password123")
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(hash, tt.prefix), hash)
+
+			// 相同密碼每次產生的雜湊值不同（salt 隨機）
+			other, err := hasher.Hash("password123")
+			require.NoError(t, err)
+			assert.NotEqual(t, hash, other)
+
+			matched, err := hasher.Verify("password123", hash)
+			require.NoError(t, err)
+			assert.True(t, matched)
+
+			matched, err = hasher.Verify("wrongpassword123", hash)
+			require.NoError(t, err)
+			assert.False(t, matched)
+
+			assert.False(t, hasher.NeedsRehash(hash))
+		})
+	}
+}
+
+func TestHasher_Verify_Plaintext(t *testing.T) {
+	hasher, err := NewHasher(Config{Argon2id: testArgon2idParams})
+	require.NoError(t, err)
+
+	matched, err := hasher.Verify("password123", "password123")
+	require.NoError(t, err)
+	assert.True(t, matched)
+
+	matched, err = hasher.Verify("password124", "password123")
+	require.NoError(t, err)
+	assert.False(t, matched)
+
+	assert.True(t, hasher.NeedsRehash("password123"))
+}
+
+func TestHasher_Verify_InvalidHash(t *testing.T) {
+	hasher, err := NewHasher(Config{Argon2id: testArgon2idParams})
+	require.NoError(t, err)
+
+	_, err = hasher.Verify("password123", "$argon2id$v=19$m=1024,t=1,p=1$not-base64!$abc")
+	assert.ErrorIs(t, err, ErrInvalidHash)
+
+	_, err = hasher.Verify("password123", "$argon2id$v=16$m=1024,t=1,p=1$c2FsdA$aGFzaA")
+	assert.ErrorIs(t, err, ErrIncompatibleVersion)
+}
+
+func TestHasher_NeedsRehash(t *testing.T) {
+	oldHasher, err := NewHasher(Config{Argon2id: testArgon2idParams})
+	require.NoError(t, err)
+	bcryptHasher, err := NewHasher(Config{Algorithm: AlgorithmBcrypt, BcryptCost: 4})
+	require.NoError(t, err)
+
+	newParams := testArgon2idParams
+	newParams.Iterations = 2
+	newHasher, err := NewHasher(Config{Argon2id: newParams})
+	require.NoError(t, err)
+
+	oldHash, err := oldHasher.Hash("password123")
+	require.NoError(t, err)
+	bcryptHash, err := bcryptHasher.Hash("password123")
+	require.NoError(t, err)
+
+	// 參數調整後，舊參數的雜湊值需要重新雜湊，但仍可驗證
+	assert.True(t, newHasher.NeedsRehash(oldHash))
+	matched, err := newHasher.Verify("password123", oldHash)
+	require.NoError(t, err)
+	assert.True(t, matched)
+
+	// 預設演算法為 argon2id 時，bcrypt 雜湊值需要重新雜湊，但仍可驗證
+	assert.True(t, newHasher.NeedsRehash(bcryptHash))
+	matched, err = newHasher.Verify("password123", bcryptHash)
+	require.NoError(t, err)
+	assert.True(t, matched)
+}
+
+func TestNewHasher_UnsupportedAlgorithm(t *testing.T) {
+	_, err := NewHasher(Config{Algorithm: "md5"})
+	assert.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+}
@@ -0,0 +1,126 @@
+// Package requestlog 為每個請求指定 request ID、將 request 範圍的 logger 放入 context，並輸出 access log
+package requestlog
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"portal_link/pkg/auth"
+	"portal_link/pkg/http_error"
+	"portal_link/pkg/logger"
+	"regexp"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HeaderRequestID 傳遞 request ID 的 header
+const HeaderRequestID = "X-Request-ID"
+
+// requestIDPattern 接受由呼叫端提供的 request ID，避免任意內容寫入日誌
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+type requestIDKey struct{}
+
+// RequestIDFromContext 取得 context 中的 request ID，沒有時返回空字串
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// Middleware 接受合法的 X-Request-ID 或產生新的 request ID 並寫入回應 header，
+// 將帶有 request_id 的 logger 放入 request context，請求結束後輸出 access log
+// 需放在所有中間件之前，才能記錄其他中間件中止的請求
+func Middleware(base *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestID := c.GetHeader(HeaderRequestID)
+		if !requestIDPattern.MatchString(requestID) {
+			requestID = newRequestID()
+		}
+		c.Header(HeaderRequestID, requestID)
+
+		log := base.With("request_id", requestID)
+		ctx := context.WithValue(c.Request.Context(), requestIDKey{}, requestID)
+		c.Request = c.Request.WithContext(logger.WithContext(ctx, log))
+
+		c.Next()
+
+		status := c.Writer.Status()
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes", max(c.Writer.Size(), 0)),
+			slog.String("client_ip", c.ClientIP()),
+			slog.String("user_agent", c.Request.UserAgent()),
+		}
+		if query := c.Request.URL.RawQuery; query != "" {
+			attrs = append(attrs, slog.String("query", redactQuery(query)))
+		}
+		if userID := c.GetString(auth.ContextUserIDKey); userID != "" {
+			attrs = append(attrs, slog.String("user_id", userID))
+		}
+		if errs := c.Errors.ByType(gin.ErrorTypePrivate); len(errs) > 0 {
+			attrs = append(attrs, slog.String("error", strings.Join(errs.Errors(), "; ")))
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		log.LogAttrs(c.Request.Context(), level, "request", attrs...)
+	}
+}
+
+// Recovery 將 panic 記錄到 request 範圍的 logger 並回應 500，需放在 Middleware 之後
+func Recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				if recovered == http.ErrAbortHandler {
+					panic(recovered)
+				}
+				logger.FromContext(c.Request.Context()).Error("panic recovered",
+					"panic", recovered,
+					"stack", string(debug.Stack()),
+				)
+				c.Abort()
+				http_error.ResponseInternalServerError(c, nil)
+			}
+		}()
+		c.Next()
+	}
+}
+
+// newRequestID 產生 128 bits 的隨機 request ID
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// redactQuery 遮蔽 query string 中的密鑰參數，例如驗證信連結的 token
+func redactQuery(rawQuery string) string {
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "[unparsable]"
+	}
+	for key := range values {
+		if logger.IsSensitive(key) {
+			values[key] = []string{logger.Redacted}
+		}
+	}
+	return values.Encode()
+}
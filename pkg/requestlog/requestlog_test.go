@@ -0,0 +1,152 @@
+package requestlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"portal_link/pkg/auth"
+	"portal_link/pkg/logger"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// newRouter 建立使用 Middleware 與 Recovery 的 router，日誌寫入 buf
+func newRouter(buf *bytes.Buffer) *gin.Engine {
+	r := gin.New()
+	r.Use(Middleware(logger.New(buf, slog.LevelDebug)), Recovery())
+	return r
+}
+
+// records 解析每一行 JSON 日誌
+func records(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var result []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		result = append(result, record)
+	}
+	return result
+}
+
+func TestMiddleware_RequestID(t *testing.T) {
+	var buf bytes.Buffer
+	r := newRouter(&buf)
+	r.GET("/ping", func(c *gin.Context) {
+		c.String(http.StatusOK, RequestIDFromContext(c.Request.Context()))
+	})
+
+	t.Run("accepts the caller's request ID", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		req.Header.Set(HeaderRequestID, "abc-123")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, "abc-123", w.Header().Get(HeaderRequestID))
+		assert.Equal(t, "abc-123", w.Body.String())
+	})
+
+	for name, requestID := range map[string]string{
+		"missing":  "",
+		"invalid":  "abc\n{\"level\":\"ERROR\"}",
+		"too long": strings.Repeat("a", 129),
+	} {
+		t.Run("generates one when "+name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+			req.Header.Set(HeaderRequestID, requestID)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			generated := w.Header().Get(HeaderRequestID)
+			assert.Regexp(t, `^[0-9a-f]{32}$`, generated)
+			assert.Equal(t, generated, w.Body.String())
+		})
+	}
+}
+
+func TestMiddleware_AccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	r := newRouter(&buf)
+	// 模擬 AuthMiddleware 設定的使用者 ID
+	r.GET("/me/pages/:id", func(c *gin.Context) {
+		c.Set(auth.ContextUserIDKey, "42")
+		logger.FromContext(c.Request.Context()).Info("handling", "password", "hunter2")
+		c.String(http.StatusOK, "ok")
+	})
+	r.GET("/fail", func(c *gin.Context) {
+		c.Error(errors.New("database is down"))
+		c.Status(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/me/pages/7?token=secret-token&page=2", nil)
+	req.Header.Set(HeaderRequestID, "req-1")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	logged := records(t, &buf)
+	require.Len(t, logged, 2)
+
+	// use case 透過 context 取得的 logger 帶有 request_id，且密鑰被遮蔽
+	handling := logged[0]
+	assert.Equal(t, "handling", handling["msg"])
+	assert.Equal(t, "req-1", handling["request_id"])
+	assert.Equal(t, logger.Redacted, handling["password"])
+
+	access := logged[1]
+	assert.Equal(t, "request", access["msg"])
+	assert.Equal(t, "INFO", access["level"])
+	assert.Equal(t, "req-1", access["request_id"])
+	assert.Equal(t, "GET", access["method"])
+	assert.Equal(t, "/me/pages/7", access["path"])
+	assert.Equal(t, "/me/pages/:id", access["route"])
+	assert.Equal(t, float64(http.StatusOK), access["status"])
+	assert.Equal(t, "42", access["user_id"])
+	assert.Contains(t, access, "latency_ms")
+	assert.NotContains(t, access["query"], "secret-token")
+	assert.Contains(t, access["query"], "page=2")
+
+	buf.Reset()
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+	access = records(t, &buf)[0]
+	assert.Equal(t, "ERROR", access["level"])
+	assert.Equal(t, "database is down", access["error"])
+	assert.NotContains(t, access, "user_id")
+
+	buf.Reset()
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+	assert.Equal(t, "WARN", records(t, &buf)[0]["level"])
+}
+
+func TestRecovery(t *testing.T) {
+	var buf bytes.Buffer
+	r := newRouter(&buf)
+	r.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	req.Header.Set(HeaderRequestID, "req-panic")
+	req.Header.Set("Accept-Language", "en")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.JSONEq(t, `{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"Internal server error","instance":"/panic","code":"ErrInternal"}`, w.Body.String())
+
+	logged := records(t, &buf)
+	require.Len(t, logged, 2)
+	assert.Equal(t, "panic recovered", logged[0]["msg"])
+	assert.Equal(t, "boom", logged[0]["panic"])
+	assert.Equal(t, "req-panic", logged[0]["request_id"])
+	assert.Equal(t, float64(http.StatusInternalServerError), logged[1]["status"])
+}
@@ -0,0 +1,105 @@
+// Package tracing 建立 OpenTelemetry tracer provider，提供建立 span 的 Gin 中間件與輔助函式
+//
+// span 以全域的 tracer provider 建立，並以 W3C trace context（traceparent 與 tracestate header）傳遞。
+// 未呼叫 Install 時使用 OpenTelemetry 預設的 noop provider，不會記錄任何 span。
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName 建立 span 的 tracer 名稱
+const instrumentationName = "portal_link"
+
+// 支援的 span 輸出方式
+const (
+	ExporterNone = "none"
+	ExporterOTLP = "otlp"
+)
+
+// NewProvider 建立 tracer provider，依 sampleRatio（0 到 1）取樣並批次輸出到 exporter
+// 收到的 trace context 已取樣時沿用其決定；exporter 為 nil 時不輸出任何 span
+func NewProvider(exporter sdktrace.SpanExporter, serviceName string, sampleRatio float64) *sdktrace.TracerProvider {
+	options := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+	}
+	if exporter != nil {
+		options = append(options, sdktrace.WithBatcher(exporter))
+	}
+	return sdktrace.NewTracerProvider(options...)
+}
+
+// NewOTLPExporter 建立以 OTLP/HTTP 輸出 span 的 exporter，endpoint 為 collector 的網址（例如 http://localhost:4318）
+func NewOTLPExporter(ctx context.Context, endpoint string) (sdktrace.SpanExporter, error) {
+	return otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpoint))
+}
+
+// Install 將 provider 設為全域 tracer provider，並以 W3C trace context 傳遞
+func Install(provider trace.TracerProvider) {
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+}
+
+// Start 以全域 tracer provider 建立 ctx 的子 span
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End 結束 span，err 不為 nil 時記錄錯誤並將狀態設為 Error
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Middleware 為每個請求建立 server span，名稱使用 Gin 的路由樣板（例如 GET /api/v1/portal-pages/:slug）
+// 請求帶有 traceparent header 時，span 成為呼叫端 trace 的一部分
+// 需放在 requestlog.Middleware 之後，span 才會包含其他中間件與 handler
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		route := c.FullPath()
+		name := c.Request.Method
+		if route != "" {
+			name += " " + route
+		}
+		ctx, span := otel.Tracer(instrumentationName).Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(c.Request.URL.Path),
+			),
+		)
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		for _, err := range c.Errors.ByType(gin.ErrorTypePrivate) {
+			span.RecordError(err.Err)
+		}
+		// 依 OpenTelemetry HTTP 慣例，server span 只有 5xx 視為錯誤
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"portal_link/pkg/tracing"
+	"portal_link/pkg/tracing/tracingtest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// attributes 將 span 的屬性轉為 map 方便比對
+func attributes(kvs []attribute.KeyValue) map[attribute.Key]attribute.Value {
+	m := make(map[attribute.Key]attribute.Value, len(kvs))
+	for _, kv := range kvs {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
+
+func TestMiddleware(t *testing.T) {
+	exporter := tracingtest.NewExporter(t)
+
+	r := gin.New()
+	r.Use(tracing.Middleware())
+	r.GET("/api/v1/portal-pages/:slug", func(c *gin.Context) {
+		_, span := tracing.Start(c.Request.Context(), "child")
+		span.End()
+		c.Status(http.StatusOK)
+	})
+	r.GET("/fail", func(c *gin.Context) {
+		c.Error(errors.New("boom"))
+		c.Status(http.StatusInternalServerError)
+	})
+
+	t.Run("names the server span after the route template", func(t *testing.T) {
+		exporter.Reset()
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/portal-pages/my-page", nil))
+
+		assert.Equal(t, []string{"child", "GET /api/v1/portal-pages/:slug"}, tracingtest.SpanNames(exporter))
+		spans := exporter.GetSpans()
+		child, server := spans[0], spans[1]
+		assert.Equal(t, trace.SpanKindServer, server.SpanKind)
+		assert.Equal(t, server.SpanContext.SpanID(), child.Parent.SpanID())
+
+		attrs := attributes(server.Attributes)
+		assert.Equal(t, "GET", attrs["http.request.method"].AsString())
+		assert.Equal(t, "/api/v1/portal-pages/:slug", attrs["http.route"].AsString())
+		assert.Equal(t, "/api/v1/portal-pages/my-page", attrs["url.path"].AsString())
+		assert.Equal(t, int64(http.StatusOK), attrs["http.response.status_code"].AsInt64())
+		assert.Equal(t, codes.Unset, server.Status.Code)
+	})
+
+	t.Run("continues the trace of a traceparent header", func(t *testing.T) {
+		exporter.Reset()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/portal-pages/my-page", nil)
+		req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+
+		server := exporter.GetSpans()[1]
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext.TraceID().String())
+		assert.Equal(t, "00f067aa0ba902b7", server.Parent.SpanID().String())
+		assert.True(t, server.Parent.IsRemote())
+	})
+
+	t.Run("marks 5xx responses as errors", func(t *testing.T) {
+		exporter.Reset()
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, codes.Error, spans[0].Status.Code)
+		require.Len(t, spans[0].Events, 1)
+		assert.Equal(t, "exception", spans[0].Events[0].Name)
+	})
+
+	t.Run("unmatched routes use the method only", func(t *testing.T) {
+		exporter.Reset()
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/not-found", nil))
+		assert.Equal(t, []string{"GET"}, tracingtest.SpanNames(exporter))
+	})
+}
+
+func TestEnd(t *testing.T) {
+	exporter := tracingtest.NewExporter(t)
+
+	_, ok := tracing.Start(context.Background(), "ok")
+	tracing.End(ok, nil)
+	_, failed := tracing.Start(context.Background(), "failed")
+	tracing.End(failed, errors.New("boom"))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, codes.Unset, spans[0].Status.Code)
+	assert.Equal(t, codes.Error, spans[1].Status.Code)
+	assert.Equal(t, "boom", spans[1].Status.Description)
+}
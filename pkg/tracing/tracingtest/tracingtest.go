@@ -0,0 +1,37 @@
+// Package tracingtest 提供在測試中記錄 span 的工具
+package tracingtest
+
+import (
+	"context"
+	"portal_link/pkg/tracing"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// NewExporter 將同步輸出到記憶體的 tracer provider 設為全域 provider，測試結束後還原為 noop provider
+// 全域 provider 由整個測試程式共用，使用的測試不能以 t.Parallel 執行
+func NewExporter(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	tracing.Install(provider)
+	t.Cleanup(func() {
+		tracing.Install(noop.NewTracerProvider())
+		_ = provider.Shutdown(context.Background())
+	})
+	return exporter
+}
+
+// SpanNames 返回 exporter 中依結束順序排列的 span 名稱
+func SpanNames(exporter *tracetest.InMemoryExporter) []string {
+	spans := exporter.GetSpans()
+	names := make([]string, 0, len(spans))
+	for _, span := range spans {
+		names = append(names, span.Name)
+	}
+	return names
+}
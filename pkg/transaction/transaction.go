@@ -0,0 +1,98 @@
+// Package transaction 提供用例層使用的 unit of work 抽象
+//
+// 用例透過 TxManager.WithinTx 在同一個交易中執行多個 repository 操作；
+// repository 從 ctx 取得目前的交易，因此用例不需要知道資料保存在哪裡。
+package transaction
+
+import (
+	"context"
+	"sync"
+)
+
+// TxManager 在交易中執行 fn
+type TxManager interface {
+	// WithinTx 在交易中執行 fn，fn 必須使用傳入的 ctx 呼叫 repository
+	// fn 返回錯誤或 panic 時回滾交易；已在交易中時加入外層交易
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// RecordUndo 在 ctx 所在的記憶體交易中登記回滾時執行的 undo 函式，不在交易中時不做任何事
+// 記憶體 repository 每次寫入時登記還原該筆資料的函式；回滾時依相反順序執行，
+// 因此只會還原交易本身寫入的資料，交易期間在交易外的寫入不受影響
+func RecordUndo(ctx context.Context, undo func()) {
+	if tx, ok := ctx.Value(inMemoryTxKey{}).(*inMemoryTx); ok {
+		tx.record(undo)
+	}
+}
+
+var _ TxManager = (*InMemoryTxManager)(nil)
+
+// InMemoryTxManager 記憶體 repository 使用的 TxManager
+// 交易之間以互斥鎖序列化；fn 失敗時依 undo log 還原交易寫入的資料，已分配的 ID 不會重複使用。
+type InMemoryTxManager struct {
+	mu sync.Mutex
+}
+
+// NewInMemoryTxManager 建立記憶體 TxManager
+func NewInMemoryTxManager() *InMemoryTxManager {
+	return &InMemoryTxManager{}
+}
+
+type inMemoryTxKey struct{}
+
+// inMemoryTx 一個記憶體交易與其 undo log
+type inMemoryTx struct {
+	manager *InMemoryTxManager
+	mu      sync.Mutex
+	undos   []func()
+}
+
+func (tx *inMemoryTx) record(undo func()) {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	tx.undos = append(tx.undos, undo)
+}
+
+// rollback 依相反順序執行 undo log
+func (tx *inMemoryTx) rollback() {
+	tx.mu.Lock()
+	undos := tx.undos
+	tx.undos = nil
+	tx.mu.Unlock()
+
+	for i := len(undos) - 1; i >= 0; i-- {
+		undos[i]()
+	}
+}
+
+// WithinTx 在交易中執行 fn，fn 返回錯誤或 panic 時還原交易寫入的資料
+// 在其他 InMemoryTxManager 的交易中時，成功後的 undo log 併入外層交易，隨外層一起回滾
+func (m *InMemoryTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	parent, _ := ctx.Value(inMemoryTxKey{}).(*inMemoryTx)
+	// 已在此 manager 的交易中時直接執行，避免重複加鎖
+	if parent != nil && parent.manager == m {
+		return fn(ctx)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tx := &inMemoryTx{manager: m}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, inMemoryTxKey{}, tx)); err != nil {
+		tx.rollback()
+		return err
+	}
+	if parent != nil {
+		for _, undo := range tx.undos {
+			parent.record(undo)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStore 以 map 保存資料，寫入時登記還原該筆資料的 undo
+type fakeStore struct {
+	mu   sync.Mutex
+	data map[string]int
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{data: map[string]int{}}
+}
+
+func (s *fakeStore) get(key string) (int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.data[key]
+	return value, ok
+}
+
+func (s *fakeStore) set(ctx context.Context, key string, value int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous, existed := s.data[key]
+	s.data[key] = value
+
+	RecordUndo(ctx, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.data[key] != value {
+			return
+		}
+		if existed {
+			s.data[key] = previous
+		} else {
+			delete(s.data, key)
+		}
+	})
+}
+
+func TestInMemoryTxManager_WithinTx(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("keeps changes when fn succeeds", func(t *testing.T) {
+		store := newFakeStore()
+		manager := NewInMemoryTxManager()
+
+		err := manager.WithinTx(ctx, func(ctx context.Context) error {
+			store.set(ctx, "a", 1)
+			return nil
+		})
+		require.NoError(t, err)
+
+		value, ok := store.get("a")
+		assert.True(t, ok)
+		assert.Equal(t, 1, value)
+	})
+
+	t.Run("undoes every write when fn fails", func(t *testing.T) {
+		first, second := newFakeStore(), newFakeStore()
+		first.set(ctx, "a", 1)
+		manager := NewInMemoryTxManager()
+		boom := errors.New("boom")
+
+		err := manager.WithinTx(ctx, func(ctx context.Context) error {
+			first.set(ctx, "a", 2)
+			second.set(ctx, "b", 1)
+			return boom
+		})
+		assert.ErrorIs(t, err, boom)
+
+		value, _ := first.get("a")
+		assert.Equal(t, 1, value)
+		_, ok := second.get("b")
+		assert.False(t, ok)
+	})
+
+	t.Run("keeps writes made outside the transaction when fn fails", func(t *testing.T) {
+		store := newFakeStore()
+		store.set(ctx, "shared", 1)
+		manager := NewInMemoryTxManager()
+		boom := errors.New("boom")
+
+		err := manager.WithinTx(ctx, func(txCtx context.Context) error {
+			store.set(txCtx, "inside", 1)
+			// 交易期間在交易外的寫入
+			store.set(ctx, "outside", 1)
+			store.set(ctx, "shared", 2)
+			return boom
+		})
+		assert.ErrorIs(t, err, boom)
+
+		_, ok := store.get("inside")
+		assert.False(t, ok)
+		value, ok := store.get("outside")
+		assert.True(t, ok)
+		assert.Equal(t, 1, value)
+		value, _ = store.get("shared")
+		assert.Equal(t, 2, value)
+	})
+
+	t.Run("does not undo a record overwritten outside the transaction", func(t *testing.T) {
+		store := newFakeStore()
+		manager := NewInMemoryTxManager()
+		boom := errors.New("boom")
+
+		err := manager.WithinTx(ctx, func(txCtx context.Context) error {
+			store.set(txCtx, "a", 1)
+			store.set(ctx, "a", 2)
+			return boom
+		})
+		assert.ErrorIs(t, err, boom)
+
+		value, _ := store.get("a")
+		assert.Equal(t, 2, value)
+	})
+
+	t.Run("undoes writes and re-panics when fn panics", func(t *testing.T) {
+		store := newFakeStore()
+		manager := NewInMemoryTxManager()
+
+		assert.PanicsWithValue(t, "boom", func() {
+			_ = manager.WithinTx(ctx, func(ctx context.Context) error {
+				store.set(ctx, "a", 1)
+				panic("boom")
+			})
+		})
+
+		_, ok := store.get("a")
+		assert.False(t, ok)
+
+		// panic 後鎖已釋放，可以開始新的交易
+		require.NoError(t, manager.WithinTx(ctx, func(ctx context.Context) error { return nil }))
+	})
+
+	t.Run("nested calls join the outer transaction", func(t *testing.T) {
+		store := newFakeStore()
+		manager := NewInMemoryTxManager()
+		boom := errors.New("boom")
+
+		err := manager.WithinTx(ctx, func(ctx context.Context) error {
+			if err := manager.WithinTx(ctx, func(ctx context.Context) error {
+				store.set(ctx, "inner", 1)
+				return nil
+			}); err != nil {
+				return err
+			}
+			return boom
+		})
+		assert.ErrorIs(t, err, boom)
+
+		// 內層的變更隨外層一起回滾
+		_, ok := store.get("inner")
+		assert.False(t, ok)
+	})
+
+	t.Run("merges a nested transaction of another manager into the outer one", func(t *testing.T) {
+		store := newFakeStore()
+		outer, inner := NewInMemoryTxManager(), NewInMemoryTxManager()
+		boom := errors.New("boom")
+
+		err := outer.WithinTx(ctx, func(ctx context.Context) error {
+			if err := inner.WithinTx(ctx, func(ctx context.Context) error {
+				store.set(ctx, "inner", 1)
+				return nil
+			}); err != nil {
+				return err
+			}
+			return boom
+		})
+		assert.ErrorIs(t, err, boom)
+
+		_, ok := store.get("inner")
+		assert.False(t, ok)
+	})
+
+	t.Run("serializes check-then-insert", func(t *testing.T) {
+		store := newFakeStore()
+		manager := NewInMemoryTxManager()
+		errExists := errors.New("exists")
+
+		const workers = 20
+		var (
+			wg      sync.WaitGroup
+			mu      sync.Mutex
+			created int
+		)
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				err := manager.WithinTx(ctx, func(ctx context.Context) error {
+					if _, exists := store.get("shared"); exists {
+						return errExists
+					}
+					store.set(ctx, "shared", i)
+					return nil
+				})
+				if err == nil {
+					mu.Lock()
+					created++
+					mu.Unlock()
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		assert.Equal(t, 1, created)
+	})
+}
@@ -0,0 +1,154 @@
+// Package validation 收集欄位驗證錯誤，讓用例一次回報所有不合法的欄位
+//
+// 每個錯誤包含欄位名稱、規則代碼與限制值，HTTP 層依此回應 problem+json 的 errors，
+// 前端可依欄位與規則代碼在表單中顯示錯誤。
+package validation
+
+import (
+	"fmt"
+	"portal_link/pkg/i18n"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+)
+
+// 規則代碼
+const (
+	RuleRequired      = "required"
+	RuleTooShort      = "too_short"
+	RuleTooLong       = "too_long"
+	RuleInvalidFormat = "invalid_format"
+	RuleMissingLetter = "missing_letter"
+	RuleMissingDigit  = "missing_digit"
+	RuleReserved      = "reserved"
+	RuleOneOf         = "one_of"
+	RuleOutOfRange    = "out_of_range"
+	RuleNotUnique     = "not_unique"
+	RuleNotContiguous = "not_contiguous"
+)
+
+// Params 規則的限制值，例如 {"min": 8}
+type Params map[string]any
+
+// FieldError 單一欄位的驗證錯誤
+type FieldError struct {
+	// Field 欄位名稱，與請求的 JSON 欄位相同；陣列元素以索引表示，例如 links[0].url
+	Field string `json:"field"`
+	// Rule 違反的規則代碼，例如 too_short
+	Rule string `json:"rule"`
+	// Params 規則的限制值，沒有時省略
+	Params Params `json:"params,omitempty"`
+	// Message 依請求語系翻譯的訊息，由 Localize 填入
+	Message string `json:"message,omitempty"`
+}
+
+// String 以 field: rule (key=value) 的形式描述錯誤
+func (e FieldError) String() string {
+	if len(e.Params) == 0 {
+		return e.Field + ": " + e.Rule
+	}
+	keys := make([]string, 0, len(e.Params))
+	for key := range e.Params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	params := make([]string, 0, len(keys))
+	for _, key := range keys {
+		params = append(params, fmt.Sprintf("%s=%v", key, e.Params[key]))
+	}
+	return fmt.Sprintf("%s: %s (%s)", e.Field, e.Rule, strings.Join(params, ", "))
+}
+
+// MessageKey 規則在訊息目錄中的 key，例如 validation.too_short
+func MessageKey(rule string) string {
+	return "validation." + rule
+}
+
+// Localize 返回填入翻譯訊息的欄位錯誤複本
+func Localize(fields []FieldError, localizer *i18n.Localizer) []FieldError {
+	if fields == nil {
+		return nil
+	}
+	localized := make([]FieldError, len(fields))
+	for i, field := range fields {
+		field.Message = localizer.Message(MessageKey(field.Rule), field.Params)
+		localized[i] = field
+	}
+	return localized
+}
+
+// Error 驗證失敗的錯誤，包含所有欄位錯誤
+// 以 errors.Is 比對時等同建立時指定的 cause（各模組的 ErrInvalidParams）
+type Error struct {
+	Fields []FieldError
+	cause  error
+}
+
+func (e *Error) Error() string {
+	fields := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		fields = append(fields, field.String())
+	}
+	return fmt.Sprintf("%s: %s", strings.Join(fields, "; "), e.cause)
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Errors 收集欄位錯誤，零值即可使用
+type Errors struct {
+	fields []FieldError
+}
+
+// Add 加入一個欄位錯誤，params 可為 nil
+func (v *Errors) Add(field, rule string, params Params) {
+	v.fields = append(v.fields, FieldError{Field: field, Rule: rule, Params: params})
+}
+
+// Merge 將 err 中的欄位錯誤加上 prefix 後加入，例如 prefix 為 links[0]. 時 url 成為 links[0].url
+// err 不是驗證錯誤時原樣返回，讓呼叫端中止驗證；其他情況返回 nil
+func (v *Errors) Merge(prefix string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var validationErr *Error
+	if !errors.As(err, &validationErr) {
+		return err
+	}
+	for _, field := range validationErr.Fields {
+		field.Field = prefix + field.Field
+		v.fields = append(v.fields, field)
+	}
+	return nil
+}
+
+// Empty 是否沒有任何欄位錯誤
+func (v *Errors) Empty() bool {
+	return len(v.fields) == 0
+}
+
+// Err 沒有欄位錯誤時返回 nil，否則返回以 cause 為原因的 *Error
+func (v *Errors) Err(cause error) error {
+	if v.Empty() {
+		return nil
+	}
+	return &Error{Fields: append([]FieldError(nil), v.fields...), cause: cause}
+}
+
+// FieldErrors 返回 err 中的欄位錯誤，err 不是驗證錯誤時返回 nil
+func FieldErrors(err error) []FieldError {
+	var validationErr *Error
+	if errors.As(err, &validationErr) {
+		return validationErr.Fields
+	}
+	return nil
+}
+
+// NewError 建立只有一個欄位錯誤的 *Error
+func NewError(cause error, field, rule string, params Params) error {
+	var v Errors
+	v.Add(field, rule, params)
+	return v.Err(cause)
+}
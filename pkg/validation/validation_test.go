@@ -0,0 +1,81 @@
+package validation
+
+import (
+	"portal_link/pkg/i18n"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errTestInvalid = errors.New("invalid parameters")
+
+func TestErrors(t *testing.T) {
+	t.Run("empty returns nil", func(t *testing.T) {
+		var v Errors
+		assert.True(t, v.Empty())
+		assert.NoError(t, v.Err(errTestInvalid))
+	})
+
+	t.Run("collects every field error", func(t *testing.T) {
+		var v Errors
+		v.Add("name", RuleRequired, nil)
+		v.Add("password", RuleTooShort, Params{"min": 8})
+
+		err := v.Err(errTestInvalid)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, errTestInvalid)
+		assert.Equal(t, "name: required; password: too_short (min=8): invalid parameters", err.Error())
+		assert.Equal(t, []FieldError{
+			{Field: "name", Rule: RuleRequired},
+			{Field: "password", Rule: RuleTooShort, Params: Params{"min": 8}},
+		}, FieldErrors(err))
+
+		// 包裝後仍能取得欄位錯誤
+		assert.Len(t, FieldErrors(errors.Wrap(err, "sign up")), 2)
+	})
+
+	t.Run("merge adds prefix", func(t *testing.T) {
+		var v Errors
+		require.NoError(t, v.Merge("links[2].", NewError(errTestInvalid, "url", RuleInvalidFormat, nil)))
+		require.NoError(t, v.Merge("", nil))
+		assert.Equal(t, []FieldError{{Field: "links[2].url", Rule: RuleInvalidFormat}}, FieldErrors(v.Err(errTestInvalid)))
+	})
+
+	t.Run("merge returns other errors", func(t *testing.T) {
+		var v Errors
+		errNotFound := errors.New("link not found")
+		assert.Equal(t, errNotFound, v.Merge("links[0].", errNotFound))
+		assert.True(t, v.Empty())
+	})
+
+	t.Run("non validation error has no field errors", func(t *testing.T) {
+		assert.Nil(t, FieldErrors(errTestInvalid))
+	})
+}
+
+func TestLocalize(t *testing.T) {
+	rules := []string{
+		RuleRequired, RuleTooShort, RuleTooLong, RuleInvalidFormat, RuleMissingLetter, RuleMissingDigit,
+		RuleReserved, RuleOneOf, RuleOutOfRange, RuleNotUnique, RuleNotContiguous,
+	}
+	bundle := i18n.Default()
+	for _, locale := range bundle.Locales() {
+		for _, rule := range rules {
+			assert.True(t, bundle.Has(locale, MessageKey(rule)), "missing %s translation for %s", locale, rule)
+		}
+	}
+
+	fields := []FieldError{
+		{Field: "password", Rule: RuleTooShort, Params: Params{"min": 8}},
+		{Field: "theme", Rule: RuleOneOf, Params: Params{"values": []string{"light", "dark"}}},
+	}
+	localized := Localize(fields, bundle.Match("en-US"))
+	assert.Equal(t, "Must be at least 8 characters", localized[0].Message)
+	assert.Equal(t, "Must be one of light, dark", localized[1].Message)
+	assert.Empty(t, fields[0].Message)
+
+	localized = Localize(fields, bundle.Match("zh-TW"))
+	assert.Equal(t, "至少需要 8 個字元", localized[0].Message)
+}
@@ -7,10 +7,20 @@ export function useAuth() {
 
   const loading = ref(false)
   const error = ref<string | null>(null)
+  // 欄位名稱對應的驗證錯誤訊息，用於在表單欄位下方顯示
+  const fieldErrors = ref<Record<string, string>>({})
+
+  function handleError(err: any, fallback: string) {
+    if (err instanceof ApiError) {
+      fieldErrors.value = fieldErrorMessages(err.fieldErrors)
+    }
+    error.value = err.message || fallback
+  }
 
   async function signUp(data: SignUpRequest) {
     loading.value = true
     error.value = null
+    fieldErrors.value = {}
 
     try {
       const response = await api.signUp(data)
@@ -18,7 +28,7 @@ export function useAuth() {
       await router.push('/dashboard')
       return true
     } catch (err: any) {
-      error.value = err.message || '註冊失敗'
+      handleError(err, '註冊失敗')
       return false
     } finally {
       loading.value = false
@@ -28,6 +38,7 @@ export function useAuth() {
   async function signIn(data: SignInRequest) {
     loading.value = true
     error.value = null
+    fieldErrors.value = {}
 
     try {
       const response = await api.signIn(data)
@@ -35,7 +46,7 @@ export function useAuth() {
       await router.push('/dashboard')
       return true
     } catch (err: any) {
-      error.value = err.message || '登入失敗'
+      handleError(err, '登入失敗')
       return false
     } finally {
       loading.value = false
@@ -49,6 +60,7 @@ export function useAuth() {
   return {
     loading: readonly(loading),
     error: readonly(error),
+    fieldErrors: readonly(fieldErrors),
     isAuthenticated: authStore.isAuthenticated,
     signUp,
     signIn,
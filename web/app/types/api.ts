@@ -98,9 +98,37 @@ export interface ListPortalPagesResponse {
 
 // ===== 錯誤回應 =====
 
+// RFC 7807 problem details（Content-Type: application/problem+json）
 export interface ErrorResponse {
-  error: string
-  message: string
+  type: string
+  title: string
+  status: number
+  detail?: string
+  instance?: string
+  code: string
+  errors?: FieldError[]
+}
+
+export type FieldErrorRule =
+  | 'required'
+  | 'too_short'
+  | 'too_long'
+  | 'invalid_format'
+  | 'missing_letter'
+  | 'missing_digit'
+  | 'reserved'
+  | 'one_of'
+  | 'out_of_range'
+  | 'not_unique'
+  | 'not_contiguous'
+
+// 欄位驗證錯誤，陣列元素的欄位以索引表示，例如 links[0].url
+export interface FieldError {
+  field: string
+  rule: FieldErrorRule
+  params?: Record<string, unknown>
+  // 依 Accept-Language 翻譯的訊息
+  message?: string
 }
 
 // ===== API 錯誤代碼 =====
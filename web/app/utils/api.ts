@@ -10,20 +10,34 @@ import type {
   FindPortalPageByIDResponse,
   FindPortalPageBySlugResponse,
   ListPortalPagesResponse,
-  ErrorResponse
+  ErrorResponse,
+  FieldError
 } from '~/types/api'
 
 export class ApiError extends Error {
   constructor(
     public statusCode: number,
     public errorCode: string,
-    message: string
+    message: string,
+    public fieldErrors: FieldError[] = []
   ) {
     super(message)
     this.name = 'ApiError'
   }
 }
 
+// 將欄位驗證錯誤轉換為顯示在表單上的訊息，每個欄位只保留第一個錯誤
+// 訊息由 API 依 Accept-Language 翻譯
+export function fieldErrorMessages(errors: FieldError[]): Record<string, string> {
+  const messages: Record<string, string> = {}
+  for (const error of errors) {
+    if (!(error.field in messages)) {
+      messages[error.field] = error.message ?? error.rule
+    }
+  }
+  return messages
+}
+
 export function useApi() {
   const config = useRuntimeConfig()
   const apiBase = config.public.apiBase as string
@@ -55,8 +69,9 @@ export function useApi() {
         const errorData = data as ErrorResponse
         throw new ApiError(
           response.status,
-          errorData.error,
-          errorData.message
+          errorData.code,
+          errorData.detail || errorData.title,
+          errorData.errors
         )
       }
 